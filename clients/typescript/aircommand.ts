@@ -0,0 +1,1845 @@
+// Code generated by cmd/apigen. DO NOT EDIT.
+
+export interface ATIS {
+  closed: string[];
+  dispersals: string[];
+  letter: string;
+  runways: string[];
+  text: string;
+  time: string;
+  visibility: number;
+  wildlife: string;
+  wind: WindState;
+}
+
+export interface AcceptanceGate {
+  holdingThreshold: number;
+  maxDeferred?: number;
+  mode?: string;
+  resumeBelow?: number;
+}
+
+export interface AcceptanceStatus {
+  AcceptanceGate: AcceptanceGate;
+  deferred: number;
+  holding: number;
+  oldestDeferredSeconds: number;
+  refused: number;
+  released: number;
+  restricted: boolean;
+  totalDeferred: number;
+}
+
+export interface Action {
+  metric?: string;
+  rate?: number;
+  runway?: string;
+  type: string;
+  value?: number;
+}
+
+export interface AgentStatus {
+  agent: AirlineAgent;
+  spent: number;
+  won: number;
+}
+
+export interface AircraftPosition {
+  call: string;
+  distance: number;
+  flightId: number;
+  position: Point;
+  runway: string;
+  track: number;
+}
+
+export interface AirframeStatus {
+  call: string;
+  flightId: number;
+  lastDelaySeconds: number;
+  legs: number;
+  registration: string;
+  returnsAt?: string;
+}
+
+export interface AirlineAgent {
+  airline: string;
+  budget: number;
+  valuePerMinute: number;
+}
+
+export interface AnalyticsResult {
+  groupBy: string[];
+  metric: string;
+  rows: AnalyticsRow[];
+}
+
+export interface AnalyticsRow {
+  count: number;
+  day?: string;
+  hour?: string;
+  max: number;
+  mean: number;
+  min: number;
+  outcome?: string;
+  p50: number;
+  p90: number;
+  runway?: string;
+  sum: number;
+}
+
+export interface Anomaly {
+  kind: string;
+  mean: number;
+  metric: string;
+  stdDev: number;
+  time: string;
+  value: number;
+  zScore: number;
+}
+
+export interface ApproachPath {
+  blocked: boolean;
+  deviating: boolean;
+  path: Point[];
+  runway: string;
+}
+
+export interface ArrivalTiming {
+  landingSeconds?: number;
+  spacingSeconds?: number;
+}
+
+export interface Atmosphere {
+  elevation: number;
+  qnh: number;
+  temperatureC: number;
+}
+
+export interface AtmosphereState {
+  atmosphere: Atmosphere;
+  densityAltitude: number;
+  landing: Record<string, string[]>;
+  takeoff: Record<string, string[]>;
+}
+
+export interface AuctionReport {
+  agents: AgentStatus[];
+  mechanism: string;
+  rounds: AuctionRound[];
+}
+
+export interface AuctionRound {
+  at: string;
+  awards: SlotAward[];
+  revenue: number;
+}
+
+export interface Backpressure {
+  backlog: number;
+  causes: Record<string, number>;
+  delaySeconds: number;
+  maxDelaySeconds: number;
+  onTime: number;
+  peakBacklog: number;
+  spawned: number;
+}
+
+export interface BatchRequest {
+  commands: Command[];
+}
+
+export interface BatchResult {
+  applied: boolean;
+  pending?: PendingAction;
+  results: CommandResult[];
+}
+
+export interface BranchResult {
+  branchedAt: string;
+  checkpoint: string;
+  discarded: number;
+  restored: number;
+}
+
+export interface BurstResult {
+  count: number;
+  flights: Flight[];
+}
+
+export interface ChaosConfig {
+  clockJumpEverySeconds?: number;
+  clockJumpSeconds?: number;
+  dropRate?: number;
+  landingDelaySeconds?: number;
+  lockEveryMillis?: number;
+  lockHoldMillis?: number;
+  seed?: number;
+}
+
+export interface ChaosStatus {
+  clockJumps: number;
+  clockOffsetSeconds: number;
+  config: ChaosConfig;
+  delayedLandings: number;
+  droppedMessages: number;
+  enabled: boolean;
+  invariantChecks: number;
+  landingDelaySeconds: number;
+  lockHolds: number;
+  since?: string;
+  violations: string[];
+}
+
+export interface Charge {
+  call: string;
+  fee: number;
+  flightId: number;
+  landedAt: string;
+  period: string;
+  runway: string;
+  weight: string;
+}
+
+export interface Checkpoint {
+  ceiling?: number;
+  closed: string[];
+  flights: RecoveredState;
+  incidents: Incident[];
+  name: string;
+  rate: number;
+  takenAt: string;
+  visibility: number;
+  wind: WindState;
+}
+
+export interface CheckpointRequest {
+  name: string;
+}
+
+export interface ClientStats {
+  connectedAt: string;
+  controller?: string;
+  droppedFrames: number;
+  id: number;
+  kind: string;
+  messagesPerSecond: number;
+  messagesSent: number;
+  queueCapacity: number;
+  queueDepth: number;
+  remote: string;
+}
+
+export interface ClockInfo {
+  elapsedSeconds?: number;
+  epoch?: string;
+  speed?: number;
+  time: string;
+  wallTime: string;
+}
+
+export interface Command {
+  closed?: boolean;
+  count?: number;
+  rampSeconds?: number;
+  rate?: number;
+  runway?: string;
+  type: string;
+  visibility?: VisibilityState;
+  wildlife?: WildlifeHazard;
+  wind?: WindState;
+}
+
+export interface CommandResult {
+  command: Command;
+  effects?: string[];
+  error?: string;
+}
+
+export interface Condition {
+  metric: string;
+  op: string;
+  value: number;
+}
+
+export interface Conflict {
+  followerCall: string;
+  followerId: number;
+  leaderCall: string;
+  leaderId: number;
+  requiredSeconds: number;
+  runway: string;
+  spacingSeconds: number;
+  time: string;
+}
+
+export interface CustomMetric {
+  callPrefix?: string;
+  event?: string;
+  help?: string;
+  kind: string;
+  name: string;
+  runway?: string;
+  source?: string;
+  while?: Condition;
+}
+
+export interface DailyReport {
+  arrivals: number;
+  averageWaitSeconds: number;
+  closures: RunwayClosure[];
+  conflicts: number;
+  custom: Record<string, number>;
+  date: string;
+  delaySecondsByCause: Record<string, number>;
+  departures: number;
+  diversions: number;
+  from: string;
+  goArounds: number;
+  holdingPatterns: number;
+  incursions: number;
+  partial?: boolean;
+  to: string;
+  totalDelaySeconds: number;
+}
+
+export interface Decision {
+  at: string;
+  flight: Flight;
+  kind: string;
+  runway?: string;
+}
+
+export interface DelayCause {
+  cause: string;
+  seconds: number;
+  share: number;
+}
+
+export interface DelayReport {
+  causes: DelayCause[];
+  primarySeconds: number;
+  reactionarySeconds: number;
+  totalSeconds: number;
+}
+
+export interface DepartingFlight {
+  call: string;
+  flightId: number;
+  queuedAt: string;
+}
+
+export interface DepartureState {
+  holdingPoints: HoldingPointQueue[];
+  taxiing: TaxiingFlight[];
+}
+
+export interface ETAAccuracy {
+  buckets: ETABucket[];
+  early: number;
+  landings: number;
+  late: number;
+  meanAbsErrorSeconds: number;
+  meanErrorSeconds: number;
+  p50AbsErrorSeconds: number;
+  p90AbsErrorSeconds: number;
+  p99AbsErrorSeconds: number;
+}
+
+export interface ETABucket {
+  count: number;
+  maxSeconds?: number;
+}
+
+export interface EmissionsReport {
+  co2Kg: number;
+  flights: FlightEmissions[];
+  fuelKg: number;
+}
+
+export interface Event {
+  call?: string;
+  detail?: string;
+  flightId?: number;
+  runway?: string;
+  seq: number;
+  time: string;
+  type: string;
+}
+
+export interface Flight {
+  airframe?: string;
+  approach?: string;
+  call: string;
+  createdAt: string;
+  equipage?: string[];
+  id: number;
+  scheduledAt: string;
+  tags?: string[];
+  uuid?: string;
+  weight?: string;
+}
+
+export interface FlightEmissions {
+  call: string;
+  co2Kg: number;
+  flightId: number;
+  fuelKg: number;
+  holdingSeconds: number;
+  vectoringSeconds: number;
+}
+
+export interface FlightHistory {
+  call: string;
+  events: Event[];
+  flightId: number;
+  goArounds: number;
+  holdingCount: number;
+  holdingSeconds: number;
+  landedAt?: string;
+  outcome: string;
+  route?: string[];
+  runway?: string;
+  spawnedAt?: string;
+  waitSeconds?: number;
+}
+
+export interface FlightSearchResult {
+  averageWaitSeconds: number;
+  count: number;
+  diverted: number;
+  flights: FlightHistory[];
+  goArounds: number;
+  heldFlights: number;
+  landed: number;
+}
+
+export interface FlightTimer {
+  call: string;
+  expectedApproachAt?: string;
+  flightId: number;
+  holdingSince?: string;
+  runway?: string;
+  secondsHolding?: number;
+  secondsToTouchdown?: number;
+  touchdownAt?: string;
+}
+
+export interface FlightTimers {
+  flights: FlightTimer[];
+  serverTime: string;
+}
+
+export interface Frequency {
+  instructionsPerMinute: number;
+}
+
+export interface FrequencyStatus {
+  Frequency: Frequency;
+  averageQueueSeconds: number;
+  backlogSeconds: number;
+  instructions: number;
+  instructionsLastMinute: number;
+  longestQueueSeconds: number;
+  queued: number;
+  utilizationLastMinute: number;
+}
+
+export interface FrictionRunway {
+  due: string;
+  lastTest?: string;
+  reading?: number;
+  runway: string;
+  slot?: string;
+}
+
+export interface FrictionState {
+  runways: FrictionRunway[];
+  schedule: FrictionTests;
+}
+
+export interface FrictionTests {
+  durationSeconds: number;
+  intervalSeconds: number;
+}
+
+export interface Gate {
+  airline?: string;
+  distance: number;
+  name: string;
+  remote?: boolean;
+  size?: string;
+}
+
+export interface GateOccupant {
+  call: string;
+  flightId: number;
+  plannedOff: string;
+  since: string;
+}
+
+export interface GateReport {
+  gates: GateStatus[];
+  utilization: number;
+}
+
+export interface GateStatus {
+  gate: Gate;
+  movements: number;
+  occupant?: GateOccupant;
+  reservedFor?: number;
+  utilization: number;
+}
+
+export interface Geography {
+  magneticVariation: number;
+  reference: LatLon;
+  thresholds?: Record<string, RunwayThresholds>;
+  tiles?: MapTiles;
+}
+
+export interface GeographyState {
+  geography: Geography;
+  runways: RunwayPlacement[];
+}
+
+export interface GroundMovement {
+  id: string;
+  kind: string;
+  runway: string;
+  since: string;
+  until?: string;
+}
+
+export interface GroundMovementRequest {
+  kind: string;
+  runway: string;
+  seconds?: number;
+}
+
+export interface HoldingFlight {
+  call: string;
+  flightId: number;
+  minutes: number;
+  since: string;
+}
+
+export interface HoldingOrbit {
+  call: string;
+  fixAt: string;
+  flightId: number;
+  nextFixAt: string;
+  orbits: number;
+}
+
+export interface HoldingPattern {
+  legSeconds: number;
+  turnRate: number;
+}
+
+export interface HoldingPatternStatus {
+  flights: HoldingOrbit[];
+  orbitFuelKg: number;
+  orbitSeconds: number;
+  pattern: HoldingPattern;
+}
+
+export interface HoldingPointQueue {
+  flights: DepartingFlight[];
+  runway: string;
+}
+
+export interface HourlyAggregate {
+  count: number;
+  hour: string;
+  runway?: string;
+  type: string;
+}
+
+export interface Incident {
+  id: string;
+  repairBy: string;
+  runway: string;
+  started: string;
+  type: string;
+}
+
+export interface IncidentRequest {
+  repairSeconds?: number;
+  runway: string;
+  type: string;
+}
+
+export interface Instruction {
+  call: string;
+  flightId: number;
+  kind: string;
+  readback: string;
+  reason?: string;
+  runway?: string;
+  source: string;
+  text: string;
+  time: string;
+}
+
+export interface Invoice {
+  airline: string;
+  charges: Charge[];
+  movements: number;
+  total: number;
+}
+
+export interface InvoiceReport {
+  invoices: Invoice[];
+  revenue: number;
+}
+
+export interface LVPState {
+  active: boolean;
+  ceilingFeet: number;
+  declaredArrivalsPerHour: number;
+  entries: number;
+  heldAtStand: number;
+  maxTaxiing?: number;
+  secondsInLvp: number;
+  since?: string;
+  spacingSeconds: number;
+  visibility: number;
+}
+
+export interface LatLon {
+  lat: number;
+  lon: number;
+}
+
+export interface MapTiles {
+  attribution?: string;
+  maxZoom?: number;
+  minZoom?: number;
+  url: string;
+}
+
+export interface MetricsSnapshot {
+  assignmentsByPreference: Record<string, number>;
+  assignmentsByRunway: Record<string, number>;
+  averageDeicingWaitSeconds: number;
+  averageDepartureDelaySeconds: number;
+  averageHoldingMinutes: number;
+  averageIncursionResolutionSeconds: number;
+  averageLandingSeconds: number;
+  averageOccupancySeconds: number;
+  averageTaxiOutSeconds: number;
+  averageWaitSeconds: number;
+  co2Kg: number;
+  conflicts: number;
+  custom?: Record<string, number>;
+  deicing: number;
+  deicingQueue: number;
+  delaySecondsByCause: Record<string, number>;
+  departureQueue: number;
+  departures: number;
+  diversions: number;
+  eta: ETAAccuracy;
+  flightPhases: Record<string, number>;
+  fuelBurnedKg: number;
+  gateConflicts: number;
+  gatesOccupied: number;
+  goArounds: number;
+  holdingCurrent: number;
+  holdingMinutes: number;
+  holdingPatterns: number;
+  incursions: number;
+  landingFeeRevenue: number;
+  longestHolding?: HoldingFlight;
+  primaryDelaySeconds: number;
+  queueLengths: Record<string, number>;
+  reactionaryDelaySeconds: number;
+  runwayTransitions: number;
+  slaAttainment?: Record<string, number>;
+  taxiing: number;
+  totalArrivals: number;
+  tows: number;
+  transitionLostSeconds: number;
+}
+
+export interface NoiseReport {
+  sectors: NoiseSector[];
+  usage: RunwayUsage[];
+}
+
+export interface NoiseSector {
+  bearing: number;
+  movements: number;
+  score: number;
+  sector: string;
+}
+
+export interface ParallelApproaches {
+  groups?: string[][];
+  mode: string;
+}
+
+export interface PendingAction {
+  command: string;
+  confirmedBy?: string;
+  effects: string[];
+  expiresAt: string;
+  id: string;
+  reason: string;
+  requestedAt: string;
+  requestedBy: string;
+}
+
+export interface PilotLatency {
+  goAroundSeconds?: number;
+  vectorMaxSeconds?: number;
+  vectorMinSeconds?: number;
+}
+
+export interface Point {
+  x: number;
+  y: number;
+}
+
+export interface PositionState {
+  aircraft: AircraftPosition[];
+  time: string;
+}
+
+export interface QueuedArrival {
+  dueAt?: string;
+  estimatedAt?: string;
+  flight: Flight;
+  phase?: string;
+}
+
+export interface QuotaAdherence {
+  airline: string;
+  deferred: number;
+  met: boolean;
+  quota: number;
+  share: number;
+  slots: number;
+}
+
+export interface QuotaReport {
+  airlines: QuotaAdherence[];
+  peak: number;
+  peakSlots: number;
+}
+
+export interface RateRamp {
+  durationSeconds: number;
+  from: number;
+  progress: number;
+  startedAt: string;
+  to: number;
+}
+
+export interface RecoveredState {
+  deferred: Decision[];
+  holding: Flight[];
+  inProgress: Decision[];
+  lastId: number;
+}
+
+export interface RecoveryFlight {
+  call: string;
+  delaySeconds: number;
+  flightId: number;
+  runway?: string;
+}
+
+export interface RecoveryPlan {
+  arrivalsPerHour: number;
+  averageDelaySeconds: number;
+  backlog: number;
+  boostSeconds: number;
+  boostSpacingSeconds: number;
+  boostedArrivalsPerHour: number;
+  boostedUntil?: string;
+  priority: RecoveryFlight[];
+  time: string;
+}
+
+export interface Rule {
+  cooldownSeconds?: number;
+  disabled?: boolean;
+  id: string;
+  if: Condition;
+  name?: string;
+  then: Action;
+}
+
+export interface RuleFiring {
+  action: Action;
+  metric: string;
+  ruleId: string;
+  time: string;
+  value: number;
+}
+
+export interface RunwayClosure {
+  from: string;
+  runway: string;
+  seconds: number;
+  to?: string;
+}
+
+export interface RunwayDefinition {
+  approach?: string;
+  exits?: RunwayExit[];
+  heading: number;
+  length?: number;
+  name: string;
+  reciprocal?: string;
+  requires?: string[];
+}
+
+export interface RunwayExit {
+  distance: number;
+  highSpeed?: boolean;
+  name: string;
+}
+
+export interface RunwayExits {
+  arrivalsPerHour: Record<string, number>;
+  exits: RunwayExit[];
+  landingDistance?: number;
+  occupancySeconds: Record<string, number>;
+  runway: string;
+}
+
+export interface RunwayPlacement {
+  length: number;
+  magneticBearing: number;
+  reciprocal: string;
+  reciprocalThreshold: LatLon;
+  runway: string;
+  threshold: LatLon;
+  trueBearing: number;
+}
+
+export interface RunwayPreference {
+  preference: string;
+  runway: string;
+  weight: number;
+}
+
+export interface RunwayShortening {
+  end: string;
+  landingDistance: number;
+  meters: number;
+  runway: string;
+}
+
+export interface RunwaySnapshot {
+  activeEnd: string;
+  arrivals: QueuedArrival[];
+  available: boolean;
+  closed: boolean;
+  departures: number;
+  heading: number;
+  headwindKnots: number;
+  landingSeconds: number;
+  name: string;
+  reciprocal?: string;
+  stats: RunwayStats;
+  trueHeading: number;
+}
+
+export interface RunwayStats {
+  averageOccupancySeconds: number;
+  directions: RunwayUsage[];
+  idleSeconds: number;
+  landings: number;
+  occupiedSeconds: number;
+  since: string;
+  takeoffs: number;
+  unavailableSeconds: number;
+  utilization: number;
+}
+
+export interface RunwayThresholds {
+  reciprocal: LatLon;
+  threshold: LatLon;
+}
+
+export interface RunwayTimeline {
+  landings: TimelineEntry[];
+  open: boolean;
+  runway: string;
+}
+
+export interface RunwayTiming {
+  categories?: Record<string, ArrivalTiming>;
+  landingSeconds?: number;
+  runway: string;
+  spacingSeconds?: number;
+}
+
+export interface RunwayTransition {
+  firstNewArrival: string;
+  from: number;
+  gapSeconds: number;
+  lastOldArrival: string;
+  lostSeconds: number;
+  lostSlots: number;
+  oldArrivals: number;
+  runway: string;
+  to: number;
+}
+
+export interface RunwayUsage {
+  heading: number;
+  landings: number;
+  runway: string;
+}
+
+export interface SLA {
+  id: string;
+  measure: string;
+  name?: string;
+  target: number;
+  thresholdSeconds: number;
+  window?: number;
+}
+
+export interface SLAStatus {
+  attainment: number;
+  breached: boolean;
+  breachedSeconds: number;
+  breaches: number;
+  samples: number;
+  sla: SLA;
+  windowAttainment: number;
+}
+
+export interface Scenario {
+  description: string;
+  durationSeconds: number;
+  expectedOutcome: string[];
+  name: string;
+  params?: ScenarioParam[];
+  runways: string[];
+  steps: ScenarioStep[];
+  title: string;
+}
+
+export interface ScenarioParam {
+  default: number;
+  description?: string;
+  name: string;
+}
+
+export interface ScenarioRun {
+  endsAt: string;
+  name: string;
+  params?: Record<string, number>;
+  startedAt: string;
+}
+
+export interface ScenarioStartRequest {
+  params?: Record<string, number>;
+}
+
+export interface ScenarioStep {
+  atSeconds: number;
+  command?: Command;
+  incident?: IncidentRequest;
+  storm?: StormCell;
+  wildlife?: WildlifeEvent;
+}
+
+export interface ScheduledCommand {
+  command: Command;
+  executeAt: string;
+  id: string;
+  requestedBy?: string;
+}
+
+export interface SessionDescription {
+  sdp: string;
+  type: string;
+}
+
+export interface SlotAward {
+  airline: string;
+  bid: number;
+  call: string;
+  flightId: number;
+  price: number;
+  slot: number;
+}
+
+export interface StateSnapshot {
+  holding: Flight[];
+  magneticWind: WindState;
+  metrics?: MetricsSnapshot;
+  ramp?: RateRamp;
+  rate: number;
+  runways: RunwaySnapshot[];
+  time: string;
+  version: number;
+  visibility: VisibilityState;
+  wind: WindState;
+}
+
+export interface StormCell {
+  id: string;
+  intensity: number;
+  polygon: Point[];
+  velocity: Point;
+}
+
+export interface StormState {
+  approaches: ApproachPath[];
+  cells: StormCell[];
+  time: string;
+}
+
+export interface TaxiingFlight {
+  call: string;
+  flightId: number;
+  holdingPointAt: string;
+  since: string;
+  unimpededSeconds: number;
+}
+
+export interface TenantConfig {
+  id: string;
+  quota?: TenantQuota;
+  rate?: number;
+  runways?: RunwayDefinition[];
+  speed?: number;
+  template?: string;
+}
+
+export interface TenantInfo {
+  basePath: string;
+  createdAt: string;
+  id: string;
+  quota: TenantQuota;
+  restricted: boolean;
+  runways: string[];
+  scenario?: string;
+  template?: string;
+  usage: TenantUsage;
+}
+
+export interface TenantQuota {
+  maxClients?: number;
+  maxFlights?: number;
+  maxRate?: number;
+}
+
+export interface TenantTemplate {
+  access?: Record<string, string>;
+  description?: string;
+  gates?: Gate[];
+  name: string;
+  params?: Record<string, number>;
+  quota?: TenantQuota;
+  rate?: number;
+  runways?: RunwayDefinition[];
+  scenario?: string;
+  speed?: number;
+  strategy?: string;
+  wind?: WindState;
+}
+
+export interface TenantTemplateView {
+  description?: string;
+  gates?: Gate[];
+  name: string;
+  params?: Record<string, number>;
+  quota?: TenantQuota;
+  rate?: number;
+  runways?: RunwayDefinition[];
+  scenario?: string;
+  speed?: number;
+  strategy?: string;
+  users?: string[];
+  wind?: WindState;
+}
+
+export interface TenantUsage {
+  clients: number;
+  flights: number;
+  rate: number;
+}
+
+export interface Timeline {
+  holding: number;
+  runways: RunwayTimeline[];
+  time: string;
+}
+
+export interface TimelineEntry {
+  call: string;
+  delaySeconds: number;
+  estimate: string;
+  flightId: number;
+  target: string;
+}
+
+export interface Turnaround {
+  arrivalDelaySeconds: number;
+  call: string;
+  flightId: number;
+  gate?: string;
+  landedAt: string;
+  ready: boolean;
+  scheduledOffBlock: string;
+  step: string;
+  stepSince: string;
+}
+
+export interface VisibilityState {
+  ceilingFeet?: number;
+  meters: number;
+}
+
+export interface WildlifeEvent {
+  hazard?: WildlifeHazard;
+  runway?: string;
+}
+
+export interface WildlifeHazard {
+  hours: string[];
+  override?: string;
+}
+
+export interface WildlifeState {
+  dispersals: Record<string, string>;
+  hazard: WildlifeHazard;
+  level: string;
+}
+
+export interface WindState {
+  direction: number;
+  gust?: number;
+  speed: number;
+  variable?: WindVariation;
+}
+
+export interface WindVariation {
+  from: number;
+  to: number;
+}
+
+export interface WinterOps {
+  enabled: boolean;
+  pads: number;
+  precipitation: string;
+}
+
+export interface WinterOpsState {
+  deicing: number;
+  mode: WinterOps;
+  queued: number;
+}
+
+export interface GetAnalyticsParams {
+  metric?: string;
+  groupby?: string;
+  from?: string;
+  to?: string;
+}
+
+export interface ApplyBatchParams {
+  dryRun?: boolean;
+}
+
+export interface SpawnBurstParams {
+  dryRun?: boolean;
+}
+
+export interface ListConflictsParams {
+  limit?: number;
+}
+
+export interface ListHistoryParams {
+  flight?: number;
+  type?: string;
+  runway?: string;
+  limit?: number;
+  from?: string;
+  to?: string;
+}
+
+export interface SearchFlightHistoryParams {
+  callsign?: string;
+  limit?: number;
+  from?: string;
+  to?: string;
+}
+
+export interface ListHourlyHistoryParams {
+  from?: string;
+  to?: string;
+}
+
+export interface GetDailyReportParams {
+  format?: string;
+}
+
+export interface RemoveRunwayParams {
+  dryRun?: boolean;
+}
+
+export interface GetTranscriptParams {
+  flight?: number;
+  format?: string;
+}
+
+export interface SetVisibilityParams {
+  dryRun?: boolean;
+}
+
+export interface SetRateParams {
+  ramp?: number;
+  dryRun?: boolean;
+}
+
+export class AirCommandError extends Error {
+  constructor(readonly status: number, message: string) {
+    super(message);
+  }
+}
+
+export class AirCommandClient {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly fetchImpl: typeof fetch = fetch,
+  ) {}
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query: Record<string, string | number | boolean | undefined>,
+    body?: unknown,
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+    const url = this.baseUrl.replace(/\/+$/, "") + path + (search ? "?" + search : "");
+    const init: RequestInit = { method };
+    if (body !== undefined) {
+      init.headers = { "Content-Type": "application/json" };
+      init.body = JSON.stringify(body);
+    }
+    const resp = await this.fetchImpl(url, init);
+    if (!resp.ok) {
+      throw new AirCommandError(resp.status, method + " " + path + ": " + (await resp.text()).trim());
+    }
+    if (resp.status === 204) {
+      return undefined as T;
+    }
+    return (await resp.json()) as T;
+  }
+
+  /** The arrival acceptance gate and the demand it defers. */
+  getAcceptance(): Promise<AcceptanceStatus> {
+    return this.request<AcceptanceStatus>("GET", `/api/v1/acceptance`, {});
+  }
+
+  /** Replace the arrival acceptance gate; a zero threshold disables it. */
+  setAcceptance(body: AcceptanceGate): Promise<AcceptanceStatus> {
+    return this.request<AcceptanceStatus>("PUT", `/api/v1/acceptance`, {}, body);
+  }
+
+  /** Flights spawned on time versus held back because the scheduler stalled, by cause. */
+  getBackpressure(): Promise<Backpressure> {
+    return this.request<Backpressure>("GET", `/api/v1/admin/backpressure`, {});
+  }
+
+  /** Stop injecting faults. */
+  stopChaos(): Promise<ChaosStatus> {
+    return this.request<ChaosStatus>("DELETE", `/api/v1/admin/chaos`, {});
+  }
+
+  /** Chaos mode: the faults configured, those injected and any scheduler invariants broken. */
+  getChaos(): Promise<ChaosStatus> {
+    return this.request<ChaosStatus>("GET", `/api/v1/admin/chaos`, {});
+  }
+
+  /** Configure the faults chaos mode injects. */
+  setChaos(body: ChaosConfig): Promise<ChaosStatus> {
+    return this.request<ChaosStatus>("PUT", `/api/v1/admin/chaos`, {}, body);
+  }
+
+  /** Connected websocket clients with message rates, queue depth and dropped frames. */
+  listClients(): Promise<ClientStats[]> {
+    return this.request<ClientStats[]>("GET", `/api/v1/admin/clients`, {});
+  }
+
+  /** Airframes and the legs they have flown, most legs first. */
+  getAirframes(): Promise<AirframeStatus[]> {
+    return this.request<AirframeStatus[]>("GET", `/api/v1/airframes`, {});
+  }
+
+  /** Arrival manager landing ladder per runway. */
+  getTimeline(): Promise<Timeline> {
+    return this.request<Timeline>("GET", `/api/v1/aman`, {});
+  }
+
+  /** A metric of archived flights aggregated by group, as tidy rows. */
+  getAnalytics(params: GetAnalyticsParams = {}): Promise<AnalyticsResult> {
+    return this.request<AnalyticsResult>("GET", `/api/v1/analytics`, { ...params });
+  }
+
+  /** Recent metric anomalies. */
+  listAnomalies(): Promise<Anomaly[]> {
+    return this.request<Anomaly[]>("GET", `/api/v1/anomalies`, {});
+  }
+
+  /** The current ATIS information: runways in use, weather, bird activity and wildlife dispersal closures. */
+  getAtis(): Promise<ATIS> {
+    return this.request<ATIS>("GET", `/api/v1/atis`, {});
+  }
+
+  /** Temperature, pressure, density altitude and the runways each weight category can use. */
+  getAtmosphere(): Promise<AtmosphereState> {
+    return this.request<AtmosphereState>("GET", `/api/v1/atmosphere`, {});
+  }
+
+  /** Set the temperature, altimeter setting and field elevation. */
+  setAtmosphere(body: Atmosphere): Promise<AtmosphereState> {
+    return this.request<AtmosphereState>("PUT", `/api/v1/atmosphere`, {}, body);
+  }
+
+  /** Slot auction agents and recent clearing rounds. */
+  getAuction(): Promise<AuctionReport> {
+    return this.request<AuctionReport>("GET", `/api/v1/auction`, {});
+  }
+
+  /** Replace the airline agents bidding in the slot auction. */
+  setAuctionAgents(body: AirlineAgent[]): Promise<AuctionReport> {
+    return this.request<AuctionReport>("PUT", `/api/v1/auction`, {}, body);
+  }
+
+  /** Apply rate, runway, wind, visibility and burst commands together, all or nothing. */
+  applyBatch(body: BatchRequest, params: ApplyBatchParams = {}): Promise<BatchResult> {
+    return this.request<BatchResult>("POST", `/api/v1/batch`, { ...params }, body);
+  }
+
+  /** Spawn count flights at once, routed through normal runway assignment. */
+  spawnBurst(count: number, params: SpawnBurstParams = {}): Promise<BurstResult> {
+    return this.request<BurstResult>("POST", `/api/v1/burst`, { count, ...params });
+  }
+
+  /** Named checkpoints of the live run, oldest first. */
+  listCheckpoints(): Promise<Checkpoint[]> {
+    return this.request<Checkpoint[]>("GET", `/api/v1/checkpoints`, {});
+  }
+
+  /** Checkpoint the live run under a name. */
+  takeCheckpoint(body: CheckpointRequest): Promise<Checkpoint> {
+    return this.request<Checkpoint>("POST", `/api/v1/checkpoints`, {}, body);
+  }
+
+  /** Delete a checkpoint. */
+  deleteCheckpoint(name: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/checkpoints/${encodeURIComponent(name)}`, {});
+  }
+
+  /** A checkpoint's conditions and arrivals. */
+  getCheckpoint(name: string): Promise<Checkpoint> {
+    return this.request<Checkpoint>("GET", `/api/v1/checkpoints/${encodeURIComponent(name)}`, {});
+  }
+
+  /** Replace the current arrivals and conditions with a checkpoint's and continue from there. */
+  branchFromCheckpoint(name: string): Promise<BranchResult> {
+    return this.request<BranchResult>("POST", `/api/v1/checkpoints/${encodeURIComponent(name)}/branch`, {});
+  }
+
+  /** Commands queued to run at a later simulation time, soonest first. */
+  listScheduledCommands(): Promise<ScheduledCommand[]> {
+    return this.request<ScheduledCommand[]>("GET", `/api/v1/commands`, {});
+  }
+
+  /** Queue a rate, runway, wind, visibility or burst command to run at executeAt. */
+  scheduleCommand(body: ScheduledCommand): Promise<ScheduledCommand> {
+    return this.request<ScheduledCommand>("POST", `/api/v1/commands`, {}, body);
+  }
+
+  /** Cancel a scheduled command. */
+  cancelScheduledCommand(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/commands/${encodeURIComponent(id)}`, {});
+  }
+
+  /** High-impact actions awaiting a second controller. */
+  listConfirmations(): Promise<PendingAction[]> {
+    return this.request<PendingAction[]>("GET", `/api/v1/confirmations`, {});
+  }
+
+  /** Reject a pending action. */
+  rejectAction(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/confirmations/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Confirm and apply a pending action as a second controller. */
+  confirmAction(id: string): Promise<PendingAction> {
+    return this.request<PendingAction>("POST", `/api/v1/confirmations/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Recent spacing conflicts with the flights involved. */
+  listConflicts(params: ListConflictsParams = {}): Promise<Conflict[]> {
+    return this.request<Conflict[]>("GET", `/api/v1/conflicts`, { ...params });
+  }
+
+  /** Delay attributed to each cause. */
+  getDelayReport(): Promise<DelayReport> {
+    return this.request<DelayReport>("GET", `/api/v1/delays`, {});
+  }
+
+  /** Aircraft taxiing out and queued at runway holding points. */
+  getDepartures(): Promise<DepartureState> {
+    return this.request<DepartureState>("GET", `/api/v1/departures`, {});
+  }
+
+  /** Fuel burn and CO2 from holding and vectoring. */
+  getEmissionsReport(): Promise<EmissionsReport> {
+    return this.request<EmissionsReport>("GET", `/api/v1/emissions`, {});
+  }
+
+  /** Runway exits with the occupancy and capacity they allow. */
+  getExits(): Promise<RunwayExits[]> {
+    return this.request<RunwayExits[]>("GET", `/api/v1/exits`, {});
+  }
+
+  /** Replace a runway's exits. */
+  setExits(runway: string, body: RunwayExit[]): Promise<RunwayExits[]> {
+    return this.request<RunwayExits[]>("PUT", `/api/v1/exits/${encodeURIComponent(runway)}`, {}, body);
+  }
+
+  /** The radio frequency's instruction throughput and load. */
+  getFrequency(): Promise<FrequencyStatus> {
+    return this.request<FrequencyStatus>("GET", `/api/v1/frequency`, {});
+  }
+
+  /** Limit the instructions the frequency carries per minute; zero removes the limit. */
+  setFrequency(body: Frequency): Promise<FrequencyStatus> {
+    return this.request<FrequencyStatus>("PUT", `/api/v1/frequency`, {}, body);
+  }
+
+  /** Friction test schedule, reserved gaps and latest readings. */
+  getFrictionTests(): Promise<FrictionState> {
+    return this.request<FrictionState>("GET", `/api/v1/friction`, {});
+  }
+
+  /** Schedule periodic friction tests slotted into gaps in the landing sequence. */
+  setFrictionTests(body: FrictionTests): Promise<FrictionState> {
+    return this.request<FrictionState>("PUT", `/api/v1/friction`, {}, body);
+  }
+
+  /** Stand occupancy and utilization. */
+  getGates(): Promise<GateReport> {
+    return this.request<GateReport>("GET", `/api/v1/gates`, {});
+  }
+
+  /** Airport reference point, magnetic variation, map tiles and runway threshold positions, with each runway's bearing and length. */
+  getGeography(): Promise<GeographyState> {
+    return this.request<GeographyState>("GET", `/api/v1/geography`, {});
+  }
+
+  /** Vehicles and crossing aircraft occupying runways. */
+  listGroundMovements(): Promise<GroundMovement[]> {
+    return this.request<GroundMovement[]>("GET", `/api/v1/ground`, {});
+  }
+
+  /** Place ground traffic on a runway. */
+  addGroundMovement(body: GroundMovementRequest): Promise<GroundMovement> {
+    return this.request<GroundMovement>("POST", `/api/v1/ground`, {}, body);
+  }
+
+  /** Vacate a runway. */
+  clearGroundMovement(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/ground/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Archived events. */
+  listHistory(params: ListHistoryParams = {}): Promise<Event[]> {
+    return this.request<Event[]>("GET", `/api/v1/history`, { ...params });
+  }
+
+  /** Archived flights matching a callsign pattern, with summary statistics. */
+  searchFlightHistory(params: SearchFlightHistoryParams = {}): Promise<FlightSearchResult> {
+    return this.request<FlightSearchResult>("GET", `/api/v1/history/flights`, { ...params });
+  }
+
+  /** Compacted hourly event counts. */
+  listHourlyHistory(params: ListHourlyHistoryParams = {}): Promise<HourlyAggregate[]> {
+    return this.request<HourlyAggregate[]>("GET", `/api/v1/history/hourly`, { ...params });
+  }
+
+  /** The holding pattern, each orbit's time and fuel, and the holding flights' progress around it. */
+  getHoldingPattern(): Promise<HoldingPatternStatus> {
+    return this.request<HoldingPatternStatus>("GET", `/api/v1/holding-pattern`, {});
+  }
+
+  /** Set the holding pattern's leg time and turn rate. */
+  setHoldingPattern(body: HoldingPattern): Promise<HoldingPatternStatus> {
+    return this.request<HoldingPatternStatus>("PUT", `/api/v1/holding-pattern`, {}, body);
+  }
+
+  /** Active equipment failures. */
+  listIncidents(): Promise<Incident[]> {
+    return this.request<Incident[]>("GET", `/api/v1/incidents`, {});
+  }
+
+  /** Inject an equipment failure on a runway. */
+  injectIncident(body: IncidentRequest): Promise<Incident> {
+    return this.request<Incident>("POST", `/api/v1/incidents`, {}, body);
+  }
+
+  /** Repair an equipment failure immediately. */
+  resolveIncident(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/incidents/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Landing fees billed per airline. */
+  getInvoices(): Promise<InvoiceReport> {
+    return this.request<InvoiceReport>("GET", `/api/v1/invoices`, {});
+  }
+
+  /** Low-visibility procedures and time spent in them. */
+  getLVP(): Promise<LVPState> {
+    return this.request<LVPState>("GET", `/api/v1/lvp`, {});
+  }
+
+  /** Operator-defined counters and gauges. */
+  listCustomMetrics(): Promise<CustomMetric[]> {
+    return this.request<CustomMetric[]>("GET", `/api/v1/metrics/custom`, {});
+  }
+
+  /** Add or replace a custom metric. */
+  createCustomMetric(body: CustomMetric): Promise<CustomMetric> {
+    return this.request<CustomMetric>("POST", `/api/v1/metrics/custom`, {}, body);
+  }
+
+  /** Remove a custom metric. */
+  deleteCustomMetric(name: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/metrics/custom/${encodeURIComponent(name)}`, {});
+  }
+
+  /** Noise exposure per compass sector from runway usage so far. */
+  getNoiseReport(): Promise<NoiseReport> {
+    return this.request<NoiseReport>("GET", `/api/v1/noise`, {});
+  }
+
+  /** Noise exposure for a hypothetical runway usage. */
+  estimateNoise(body: RunwayUsage[]): Promise<NoiseReport> {
+    return this.request<NoiseReport>("POST", `/api/v1/noise`, {}, body);
+  }
+
+  /** OpenAPI description of this API. */
+  getOpenAPI(): Promise<Record<string, unknown>> {
+    return this.request<Record<string, unknown>>("GET", `/api/v1/openapi.json`, {});
+  }
+
+  /** Parallel approach mode and the runways it couples. */
+  getParallelApproaches(): Promise<ParallelApproaches> {
+    return this.request<ParallelApproaches>("GET", `/api/v1/parallel`, {});
+  }
+
+  /** Select independent, dependent or single-stream parallel approaches. */
+  setParallelApproaches(body: ParallelApproaches): Promise<ParallelApproaches> {
+    return this.request<ParallelApproaches>("PUT", `/api/v1/parallel`, {}, body);
+  }
+
+  /** How long pilots take to act on vectors and go-around instructions. */
+  getPilotLatency(): Promise<PilotLatency> {
+    return this.request<PilotLatency>("GET", `/api/v1/pilot-latency`, {});
+  }
+
+  /** Replace the pilot response delays. */
+  setPilotLatency(body: PilotLatency): Promise<PilotLatency> {
+    return this.request<PilotLatency>("PUT", `/api/v1/pilot-latency`, {}, body);
+  }
+
+  /** Sequenced arrivals' positions on their approaches. */
+  getPositions(): Promise<PositionState> {
+    return this.request<PositionState>("GET", `/api/v1/positions`, {});
+  }
+
+  /** Answer a WebRTC offer for the experimental unordered, unreliable position data channel, negotiated as "positions" with ID 0. */
+  answerPositionOffer(body: SessionDescription): Promise<SessionDescription> {
+    return this.request<SessionDescription>("POST", `/api/v1/positions/webrtc`, {}, body);
+  }
+
+  /** How favored each runway is for arrivals. */
+  getPreferences(): Promise<RunwayPreference[]> {
+    return this.request<RunwayPreference[]>("GET", `/api/v1/preferences`, {});
+  }
+
+  /** Make a runway preferred, secondary or last-resort. */
+  setPreference(runway: string, body: RunwayPreference): Promise<RunwayPreference[]> {
+    return this.request<RunwayPreference[]>("PUT", `/api/v1/preferences/${encodeURIComponent(runway)}`, {}, body);
+  }
+
+  /** Share of peak arrival slots each airline received against its quota. */
+  getQuotaReport(): Promise<QuotaReport> {
+    return this.request<QuotaReport>("GET", `/api/v1/quotas`, {});
+  }
+
+  /** Catch-up plan for the current backlog: a temporary landing rate boost and longest-delayed flights first. */
+  getRecoveryPlan(): Promise<RecoveryPlan> {
+    return this.request<RecoveryPlan>("GET", `/api/v1/recovery`, {});
+  }
+
+  /** Put a fresh recovery plan into effect. */
+  applyRecoveryPlan(): Promise<RecoveryPlan> {
+    return this.request<RecoveryPlan>("POST", `/api/v1/recovery`, {});
+  }
+
+  /** Completed end-of-day summary reports, oldest first. */
+  listDailyReports(): Promise<DailyReport[]> {
+    return this.request<DailyReport[]>("GET", `/api/v1/reports/daily`, {});
+  }
+
+  /** The end-of-day report for a date, or the day so far for "today". */
+  getDailyReport(date: string, params: GetDailyReportParams = {}): Promise<DailyReport> {
+    return this.request<DailyReport>("GET", `/api/v1/reports/daily/${encodeURIComponent(date)}`, { ...params });
+  }
+
+  /** Configured automation rules. */
+  listRules(): Promise<Rule[]> {
+    return this.request<Rule[]>("GET", `/api/v1/rules`, {});
+  }
+
+  /** Add or replace a rule. */
+  createRule(body: Rule): Promise<Rule> {
+    return this.request<Rule>("POST", `/api/v1/rules`, {}, body);
+  }
+
+  /** Recent rule executions. */
+  listRuleFirings(): Promise<RuleFiring[]> {
+    return this.request<RuleFiring[]>("GET", `/api/v1/rules/firings`, {});
+  }
+
+  /** Remove a rule. */
+  deleteRule(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/rules/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Runway definitions in assignment order. */
+  listRunways(): Promise<RunwayDefinition[]> {
+    return this.request<RunwayDefinition[]>("GET", `/api/v1/runways`, {});
+  }
+
+  /** Commission a runway without restarting. */
+  addRunway(body: RunwayDefinition): Promise<RunwayDefinition> {
+    return this.request<RunwayDefinition>("POST", `/api/v1/runways`, {}, body);
+  }
+
+  /** Decommission a runway, sending its arrivals to holding. */
+  removeRunway(runway: string, params: RemoveRunwayParams = {}): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/runways/${encodeURIComponent(runway)}`, { ...params });
+  }
+
+  /** A runway's state, queue and statistics: landings by direction, takeoffs, average occupancy, idle time and utilization. */
+  getRunway(runway: string): Promise<RunwaySnapshot> {
+    return this.request<RunwaySnapshot>("GET", `/api/v1/runways/${encodeURIComponent(runway)}`, {});
+  }
+
+  /** The built-in scenarios. */
+  listScenarios(): Promise<Scenario[]> {
+    return this.request<Scenario[]>("GET", `/api/v1/scenarios`, {});
+  }
+
+  /** A built-in scenario with its steps and expected outcome. */
+  getScenario(name: string): Promise<Scenario> {
+    return this.request<Scenario>("GET", `/api/v1/scenarios/${encodeURIComponent(name)}`, {});
+  }
+
+  /** Start a built-in scenario with optional parameter values, cancelling the rest of any running one. */
+  startScenario(name: string, body: ScenarioStartRequest): Promise<ScenarioRun> {
+    return this.request<ScenarioRun>("POST", `/api/v1/scenarios/${encodeURIComponent(name)}/start`, {}, body);
+  }
+
+  /** AsyncAPI description of the websocket messages. */
+  getMessageSchema(): Promise<Record<string, unknown>> {
+    return this.request<Record<string, unknown>>("GET", `/api/v1/schema`, {});
+  }
+
+  /** Runways temporarily shortened. */
+  listShortenings(): Promise<RunwayShortening[]> {
+    return this.request<RunwayShortening[]>("GET", `/api/v1/shortening`, {});
+  }
+
+  /** Restore a shortened runway to full length. */
+  restoreRunway(runway: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/shortening/${encodeURIComponent(runway)}`, {});
+  }
+
+  /** Close part of a runway at one end. */
+  shortenRunway(runway: string, body: RunwayShortening): Promise<RunwayShortening> {
+    return this.request<RunwayShortening>("PUT", `/api/v1/shortening/${encodeURIComponent(runway)}`, {}, body);
+  }
+
+  /** Service level agreements and their attainment. */
+  listSLAs(): Promise<SLAStatus[]> {
+    return this.request<SLAStatus[]>("GET", `/api/v1/slas`, {});
+  }
+
+  /** Add or replace a service level agreement. */
+  createSLA(body: SLA): Promise<SLA> {
+    return this.request<SLA>("POST", `/api/v1/slas`, {}, body);
+  }
+
+  /** Remove a service level agreement. */
+  deleteSLA(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/slas/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Consistent snapshot of flights, queues, holding, wind, runways and metrics. */
+  getState(): Promise<StateSnapshot> {
+    return this.request<StateSnapshot>("GET", `/api/v1/state`, {});
+  }
+
+  /** Thunderstorm cells at their current positions and the approaches they affect. */
+  getStorms(): Promise<StormState> {
+    return this.request<StormState>("GET", `/api/v1/storms`, {});
+  }
+
+  /** Place a moving thunderstorm cell. */
+  addStormCell(body: StormCell): Promise<StormCell> {
+    return this.request<StormCell>("POST", `/api/v1/storms`, {}, body);
+  }
+
+  /** Remove a thunderstorm cell. */
+  removeStormCell(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/storms/${encodeURIComponent(id)}`, {});
+  }
+
+  /** Exercise environments simulation instances can be created from. */
+  listTenantTemplates(): Promise<TenantTemplateView[]> {
+    return this.request<TenantTemplateView[]>("GET", `/api/v1/tenant-templates`, {});
+  }
+
+  /** Add or replace an exercise environment: airport, scenario, strategy and access list. */
+  saveTenantTemplate(body: TenantTemplate): Promise<TenantTemplateView> {
+    return this.request<TenantTemplateView>("POST", `/api/v1/tenant-templates`, {}, body);
+  }
+
+  /** Delete an exercise environment; instances created from it keep running. */
+  deleteTenantTemplate(name: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/tenant-templates/${encodeURIComponent(name)}`, {});
+  }
+
+  /** An exercise environment. */
+  getTenantTemplate(name: string): Promise<TenantTemplateView> {
+    return this.request<TenantTemplateView>("GET", `/api/v1/tenant-templates/${encodeURIComponent(name)}`, {});
+  }
+
+  /** Simulation instances running alongside the primary one. */
+  listTenants(): Promise<TenantInfo[]> {
+    return this.request<TenantInfo[]>("GET", `/api/v1/tenants`, {});
+  }
+
+  /** Start an independent simulation instance served under /tenants/{id}/. */
+  createTenant(body: TenantConfig): Promise<TenantInfo> {
+    return this.request<TenantInfo>("POST", `/api/v1/tenants`, {}, body);
+  }
+
+  /** Stop and remove a simulation instance. */
+  deleteTenant(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/tenants/${encodeURIComponent(id)}`, {});
+  }
+
+  /** A simulation instance. */
+  getTenant(id: string): Promise<TenantInfo> {
+    return this.request<TenantInfo>("GET", `/api/v1/tenants/${encodeURIComponent(id)}`, {});
+  }
+
+  /** The simulation clock: current sim time, speed factor and epoch. */
+  getClock(): Promise<ClockInfo> {
+    return this.request<ClockInfo>("GET", `/api/v1/time`, {});
+  }
+
+  /** Each flight's countdown to touchdown, time in holding and expected approach time. */
+  getFlightTimers(): Promise<FlightTimers> {
+    return this.request<FlightTimers>("GET", `/api/v1/timers`, {});
+  }
+
+  /** Arrival spacing and landing time of each runway. */
+  getTimings(): Promise<RunwayTiming[]> {
+    return this.request<RunwayTiming[]>("GET", `/api/v1/timing`, {});
+  }
+
+  /** Return a runway to the default arrival timing. */
+  resetTiming(runway: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/timing/${encodeURIComponent(runway)}`, {});
+  }
+
+  /** Set a runway's arrival spacing and landing time, overall and per weight category. */
+  setTiming(runway: string, body: RunwayTiming): Promise<RunwayTiming[]> {
+    return this.request<RunwayTiming[]>("PUT", `/api/v1/timing/${encodeURIComponent(runway)}`, {}, body);
+  }
+
+  /** Every instruction issued, in controller phraseology, in the order spoken. */
+  getTranscript(params: GetTranscriptParams = {}): Promise<Instruction[]> {
+    return this.request<Instruction[]>("GET", `/api/v1/transcript`, { ...params });
+  }
+
+  /** Runway direction changes in progress. */
+  listTransitions(): Promise<RunwayTransition[]> {
+    return this.request<RunwayTransition[]>("GET", `/api/v1/transitions`, {});
+  }
+
+  /** Aircraft being turned around, earliest scheduled off-block first. */
+  getTurnarounds(): Promise<Turnaround[]> {
+    return this.request<Turnaround[]>("GET", `/api/v1/turnarounds`, {});
+  }
+
+  /** Prevailing visibility and ceiling. */
+  getVisibility(): Promise<VisibilityState> {
+    return this.request<VisibilityState>("GET", `/api/v1/visibility`, {});
+  }
+
+  /** Set the prevailing visibility and ceiling. */
+  setVisibility(body: VisibilityState, params: SetVisibilityParams = {}): Promise<VisibilityState> {
+    return this.request<VisibilityState>("PUT", `/api/v1/visibility`, { ...params }, body);
+  }
+
+  /** Wildlife hazard level now, by hour, and recent dispersal closures. */
+  getWildlifeHazard(): Promise<WildlifeState> {
+    return this.request<WildlifeState>("GET", `/api/v1/wildlife`, {});
+  }
+
+  /** Set the wildlife hazard level by hour of day or override it. */
+  setWildlifeHazard(body: WildlifeHazard): Promise<WildlifeState> {
+    return this.request<WildlifeState>("PUT", `/api/v1/wildlife`, {}, body);
+  }
+
+  /** Winter operations mode and de-icing pad usage. */
+  getWinterOps(): Promise<WinterOpsState> {
+    return this.request<WinterOpsState>("GET", `/api/v1/winter`, {});
+  }
+
+  /** Configure winter operations and precipitation. */
+  setWinterOps(body: WinterOps): Promise<WinterOpsState> {
+    return this.request<WinterOpsState>("PUT", `/api/v1/winter`, {}, body);
+  }
+
+  /** Current scheduler metrics. */
+  getMetrics(): Promise<MetricsSnapshot> {
+    return this.request<MetricsSnapshot>("GET", `/metrics`, {});
+  }
+
+  /** Set the arrival rate in planes per minute. */
+  setRate(rate: number, params: SetRateParams = {}): Promise<void> {
+    return this.request<void>("POST", `/rate`, { rate, ...params });
+  }
+
+  /** Recorded sessions available for playback. */
+  listRecordings(): Promise<string[]> {
+    return this.request<string[]>("GET", `/recordings`, {});
+  }
+}
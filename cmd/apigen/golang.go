@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+
+	"aircommand/internal/control"
+)
+
+// goGen accumulates the Go client source and the imports it needs.
+type goGen struct {
+	body    strings.Builder
+	imports map[string]bool
+}
+
+// generateGo emits methods on aircommand.Client for every operation. Schema
+// names resolve to the aliases the package re-exports from control.
+func generateGo(ops []operation) ([]byte, error) {
+	g := &goGen{imports: map[string]bool{"context": true, "net/url": true}}
+	for _, op := range ops {
+		g.operation(op)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "// %s\n\npackage aircommand\n\nimport (\n", generatedHeader)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n")
+	out.WriteString(g.body.String())
+	return format.Source([]byte(out.String()))
+}
+
+func (g *goGen) operation(op operation) {
+	name := exported(op.ID)
+	w := &g.body
+
+	if len(op.Optional) > 0 {
+		fmt.Fprintf(w, "\n// %sParams holds the optional parameters of %s.\ntype %sParams struct {\n", name, name, name)
+		for _, p := range op.Optional {
+			if p.Description != "" {
+				fmt.Fprintf(w, "\t// %s\n", p.Description)
+			}
+			fmt.Fprintf(w, "\t%s %s\n", exported(p.Name), g.typeOf(p.Schema))
+		}
+		w.WriteString("}\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range append(append([]control.OpenAPIParameter(nil), op.PathParams...), op.Required...) {
+		args = append(args, goIdent(p.Name)+" "+g.typeOf(p.Schema))
+	}
+	if op.Body != nil {
+		args = append(args, "body "+g.typeOf(op.Body))
+	}
+	if len(op.Optional) > 0 {
+		args = append(args, "params "+name+"Params")
+	}
+	result := "error"
+	if op.Response != nil {
+		result = "(" + g.typeOf(op.Response) + ", error)"
+	}
+
+	fmt.Fprintf(w, "\n// %s calls %s %s. %s\n", name, op.Method, op.Path, op.Summary)
+	fmt.Fprintf(w, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), result)
+	if op.Response != nil {
+		fmt.Fprintf(w, "\tvar out %s\n", g.typeOf(op.Response))
+	}
+	w.WriteString("\tquery := url.Values{}\n")
+	for _, p := range op.Required {
+		g.setQuery(p, goIdent(p.Name), true)
+	}
+	for _, p := range op.Optional {
+		g.setQuery(p, "params."+exported(p.Name), false)
+	}
+
+	parts, params := pathSegments(op.Path)
+	exprs := make([]string, len(parts))
+	for i, part := range parts {
+		if params[i] {
+			exprs[i] = "url.PathEscape(" + goIdent(part) + ")"
+		} else {
+			exprs[i] = fmt.Sprintf("%q", part)
+		}
+	}
+	body, out := "nil", "nil"
+	if op.Body != nil {
+		body = "body"
+	}
+	if op.Response != nil {
+		out = "&out"
+	}
+	call := fmt.Sprintf("c.call(ctx, %q, %s, query, %s, %s)", op.Method, strings.Join(exprs, "+"), body, out)
+	if op.Response == nil {
+		fmt.Fprintf(w, "\treturn %s\n}\n", call)
+		return
+	}
+	fmt.Fprintf(w, "\terr := %s\n\treturn out, err\n}\n", call)
+}
+
+// setQuery writes the statement adding a parameter to the query, skipping
+// zero values of optional parameters.
+func (g *goGen) setQuery(p control.OpenAPIParameter, expr string, required bool) {
+	var value, isSet string
+	switch goType := g.typeOf(p.Schema); goType {
+	case "string":
+		value, isSet = expr, expr+` != ""`
+	case "int64":
+		g.imports["strconv"] = true
+		value, isSet = "strconv.FormatInt("+expr+", 10)", expr+" != 0"
+	case "float64":
+		g.imports["strconv"] = true
+		value, isSet = "strconv.FormatFloat("+expr+", 'g', -1, 64)", expr+" != 0"
+	case "bool":
+		g.imports["strconv"] = true
+		value, isSet = "strconv.FormatBool("+expr+")", expr
+	case "time.Time":
+		value, isSet = expr+".Format(time.RFC3339)", "!"+expr+".IsZero()"
+	default:
+		value, isSet = "fmt.Sprint("+expr+")", "true"
+		g.imports["fmt"] = true
+	}
+	if required {
+		fmt.Fprintf(&g.body, "\tquery.Set(%q, %s)\n", p.Name, value)
+		return
+	}
+	fmt.Fprintf(&g.body, "\tif %s {\n\t\tquery.Set(%q, %s)\n\t}\n", isSet, p.Name, value)
+}
+
+func (g *goGen) typeOf(s *control.Schema) string {
+	switch {
+	case s == nil:
+		return "any"
+	case s.Ref != "":
+		return refName(s.Ref)
+	}
+	switch s.Type {
+	case "array":
+		return "[]" + g.typeOf(s.Items)
+	case "object":
+		if s.AdditionalProperties == nil {
+			return "map[string]any"
+		}
+		return "map[string]" + g.typeOf(s.AdditionalProperties)
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "any"
+}
+
+func goIdent(name string) string {
+	if token.IsKeyword(name) {
+		return name + "Param"
+	}
+	return name
+}
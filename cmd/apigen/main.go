@@ -0,0 +1,155 @@
+// Command apigen writes the OpenAPI document and the generated TypeScript and
+// Go clients from the server's route table:
+//
+//	go run ./cmd/apigen          # regenerate
+//	go run ./cmd/apigen -check   # fail if the checked-in files are stale
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"aircommand/internal/control"
+)
+
+const generatedHeader = "Code generated by cmd/apigen. DO NOT EDIT."
+
+// Output locations relative to the repository root.
+const (
+	specPath = "api/openapi.json"
+	tsPath   = "clients/typescript/aircommand.ts"
+	goPath   = "pkg/aircommand/client_gen.go"
+)
+
+// operation is a REST endpoint flattened from the document for the client
+// generators.
+type operation struct {
+	ID         string
+	Method     string
+	Path       string
+	Summary    string
+	PathParams []control.OpenAPIParameter
+	Required   []control.OpenAPIParameter
+	Optional   []control.OpenAPIParameter
+	Body       *control.Schema
+	Response   *control.Schema
+}
+
+func main() {
+	root := flag.String("root", ".", "repository root to write into")
+	check := flag.Bool("check", false, "verify the generated files are up to date instead of writing them")
+	flag.Parse()
+
+	doc := control.OpenAPI(control.NewServer(nil, nil, nil, nil).Routes())
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("encode spec: %v", err)
+	}
+	ops := operations(doc)
+	goSrc, err := generateGo(ops)
+	if err != nil {
+		log.Fatalf("go client: %v", err)
+	}
+	outputs := map[string][]byte{
+		specPath: append(spec, '\n'),
+		tsPath:   generateTypeScript(doc, ops),
+		goPath:   goSrc,
+	}
+
+	stale := false
+	for _, rel := range []string{specPath, tsPath, goPath} {
+		path := filepath.Join(*root, rel)
+		if *check {
+			current, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(current, outputs[rel]) {
+				fmt.Fprintf(os.Stderr, "%s is out of date; run go run ./cmd/apigen\n", rel)
+				stale = true
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(path, outputs[rel], 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
+
+// operations lists the non-streaming operations ordered by path and method.
+func operations(doc *control.OpenAPIDocument) []operation {
+	var ops []operation
+	for path, methods := range doc.Paths {
+		for method, op := range methods {
+			if op.Stream != "" {
+				continue
+			}
+			o := operation{ID: op.OperationID, Method: strings.ToUpper(method), Path: path, Summary: op.Summary}
+			for _, p := range op.Parameters {
+				switch {
+				case p.In == "path":
+					o.PathParams = append(o.PathParams, p)
+				case p.Required:
+					o.Required = append(o.Required, p)
+				default:
+					o.Optional = append(o.Optional, p)
+				}
+			}
+			if op.RequestBody != nil {
+				o.Body = op.RequestBody.Content["application/json"].Schema
+			}
+			for _, resp := range op.Responses {
+				if media, ok := resp.Content["application/json"]; ok {
+					o.Response = media.Schema
+				}
+			}
+			ops = append(ops, o)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops
+}
+
+// pathSegments splits a templated path into literal and parameter parts.
+func pathSegments(path string) (parts []string, params []bool) {
+	for path != "" {
+		open := strings.IndexByte(path, '{')
+		if open < 0 {
+			parts, params = append(parts, path), append(params, false)
+			break
+		}
+		close := strings.IndexByte(path[open:], '}') + open
+		if open > 0 {
+			parts, params = append(parts, path[:open]), append(params, false)
+		}
+		parts, params = append(parts, path[open+1:close]), append(params, true)
+		path = path[close+1:]
+	}
+	return parts, params
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndexByte(ref, '/')+1:]
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"aircommand/internal/control"
+)
+
+const tsRuntime = `
+export class AirCommandError extends Error {
+  constructor(readonly status: number, message: string) {
+    super(message);
+  }
+}
+
+export class AirCommandClient {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly fetchImpl: typeof fetch = fetch,
+  ) {}
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query: Record<string, string | number | boolean | undefined>,
+    body?: unknown,
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+    const url = this.baseUrl.replace(/\/+$/, "") + path + (search ? "?" + search : "");
+    const init: RequestInit = { method };
+    if (body !== undefined) {
+      init.headers = { "Content-Type": "application/json" };
+      init.body = JSON.stringify(body);
+    }
+    const resp = await this.fetchImpl(url, init);
+    if (!resp.ok) {
+      throw new AirCommandError(resp.status, method + " " + path + ": " + (await resp.text()).trim());
+    }
+    if (resp.status === 204) {
+      return undefined as T;
+    }
+    return (await resp.json()) as T;
+  }
+`
+
+// generateTypeScript emits an interface per schema and a fetch-based client
+// class with one method per operation.
+func generateTypeScript(doc *control.OpenAPIDocument, ops []operation) []byte {
+	var w strings.Builder
+	fmt.Fprintf(&w, "// %s\n", generatedHeader)
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&w, "\nexport interface %s {\n", name)
+		writeTSProperties(&w, doc.Components.Schemas[name])
+		w.WriteString("}\n")
+	}
+	for _, op := range ops {
+		if len(op.Optional) == 0 {
+			continue
+		}
+		fmt.Fprintf(&w, "\nexport interface %sParams {\n", exported(op.ID))
+		for _, p := range op.Optional {
+			fmt.Fprintf(&w, "  %s?: %s;\n", p.Name, tsType(p.Schema))
+		}
+		w.WriteString("}\n")
+	}
+
+	w.WriteString(tsRuntime)
+	for _, op := range ops {
+		var args, query []string
+		for _, p := range op.PathParams {
+			args = append(args, p.Name+": "+tsType(p.Schema))
+		}
+		for _, p := range op.Required {
+			args = append(args, p.Name+": "+tsType(p.Schema))
+			query = append(query, p.Name)
+		}
+		if op.Body != nil {
+			args = append(args, "body: "+tsType(op.Body))
+		}
+		if len(op.Optional) > 0 {
+			args = append(args, "params: "+exported(op.ID)+"Params = {}")
+			query = append(query, "...params")
+		}
+		result := "void"
+		if op.Response != nil {
+			result = tsType(op.Response)
+		}
+		parts, params := pathSegments(op.Path)
+		var path strings.Builder
+		for i, part := range parts {
+			if params[i] {
+				fmt.Fprintf(&path, "${encodeURIComponent(%s)}", part)
+			} else {
+				path.WriteString(part)
+			}
+		}
+		body := ""
+		if op.Body != nil {
+			body = ", body"
+		}
+		fmt.Fprintf(&w, "\n  /** %s */\n", op.Summary)
+		fmt.Fprintf(&w, "  %s(%s): Promise<%s> {\n", op.ID, strings.Join(args, ", "), result)
+		queryArg := "{}"
+		if len(query) > 0 {
+			queryArg = "{ " + strings.Join(query, ", ") + " }"
+		}
+		fmt.Fprintf(&w, "    return this.request<%s>(%q, `%s`, %s%s);\n  }\n", result, op.Method, path.String(), queryArg, body)
+	}
+	w.WriteString("}\n")
+	return []byte(w.String())
+}
+
+func writeTSProperties(w *strings.Builder, s *control.Schema) {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	props := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	sort.Strings(props)
+	for _, name := range props {
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(w, "  %s%s: %s;\n", name, optional, tsType(s.Properties[name]))
+	}
+}
+
+func tsType(s *control.Schema) string {
+	switch {
+	case s == nil:
+		return "unknown"
+	case s.Ref != "":
+		return refName(s.Ref)
+	}
+	switch s.Type {
+	case "array":
+		return tsType(s.Items) + "[]"
+	case "object":
+		if s.AdditionalProperties == nil {
+			return "Record<string, unknown>"
+		}
+		return "Record<string, " + tsType(s.AdditionalProperties) + ">"
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	}
+	return "unknown"
+}
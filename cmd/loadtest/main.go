@@ -0,0 +1,195 @@
+// Command loadtest stresses a running AirCommand server: it opens many
+// control websockets, drives a high arrival rate and checks broadcast
+// latency, assignment latency and dropped messages against SLO thresholds.
+// It exits non-zero when any SLO is missed.
+//
+// Events are stamped in simulation time, which a sped-up clock runs ahead
+// of the wall clock, so latencies map the stamps back to the server's wall
+// clock through /api/v1/time and compare them with when they were received.
+// Run it on the same host as the server or on one with a synchronized
+// clock; negative latencies, which only skew can produce, fail the run.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"aircommand/pkg/aircommand"
+)
+
+// clientStats is what one websocket client observed.
+type clientStats struct {
+	received  int64
+	dropped   int64
+	broadcast []time.Duration
+}
+
+func main() {
+	target := flag.String("url", "http://localhost:8080", "server base URL")
+	clients := flag.Int("clients", 100, "number of concurrent websocket clients")
+	rate := flag.Int64("rate", 600, "arrival rate to drive, in planes per minute")
+	duration := flag.Duration("duration", 30*time.Second, "length of the measurement window")
+	maxBroadcast := flag.Duration("slo-broadcast-p99", 250*time.Millisecond, "p99 broadcast latency SLO")
+	maxAssign := flag.Duration("slo-assign-p99", 100*time.Millisecond, "p99 spawn-to-assignment latency SLO")
+	maxDropRatio := flag.Float64("slo-drop-ratio", 0.001, "maximum fraction of events dropped per client")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	api := aircommand.NewClient(*target)
+	clock, err := api.GetClock(ctx)
+	if err != nil {
+		log.Fatalf("read clock: %v", err)
+	}
+	if clock.Speed <= 0 {
+		log.Fatalf("server clock is not running in real time (speed %g)", clock.Speed)
+	}
+	// wallTime is the server's wall clock time when its simulation clock
+	// read t.
+	wallTime := func(t time.Time) time.Time {
+		return clock.WallTime.Add(time.Duration(float64(t.Sub(clock.Time)) / clock.Speed))
+	}
+
+	streams := make([]*aircommand.Stream, 0, *clients)
+	for i := 0; i < *clients; i++ {
+		s, err := api.Dial(ctx)
+		if err != nil {
+			log.Fatalf("client %d: %v", i, err)
+		}
+		streams = append(streams, s)
+	}
+	log.Printf("connected %d clients to %s", len(streams), *target)
+
+	// The first message on every connection is the current rate; keep it so
+	// the server can be put back afterwards.
+	initial, err := streams[0].Next()
+	if err != nil {
+		log.Fatalf("read initial state: %v", err)
+	}
+	if err := api.SetRate(ctx, *rate, aircommand.SetRateParams{}); err != nil {
+		log.Fatalf("set rate: %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		stats     = make([]*clientStats, len(streams))
+		spawnedAt = make(map[int64]time.Time)
+		assign    []time.Duration
+	)
+	for i, s := range streams {
+		stats[i] = &clientStats{}
+		wg.Add(1)
+		go func(st *clientStats, s *aircommand.Stream, primary bool) {
+			defer wg.Done()
+			var lastSeq int64
+			for {
+				msg, err := s.Next()
+				if err != nil {
+					return
+				}
+				if msg.Type != "event" || msg.Event == nil {
+					continue
+				}
+				e := msg.Event
+				st.received++
+				st.broadcast = append(st.broadcast, time.Since(wallTime(e.Time)))
+				if lastSeq != 0 && e.Seq > lastSeq+1 {
+					st.dropped += e.Seq - lastSeq - 1
+				}
+				lastSeq = e.Seq
+				// Assignment latency is a property of the server, so one
+				// client measuring it is enough.
+				if !primary {
+					continue
+				}
+				mu.Lock()
+				switch e.Type {
+				case aircommand.EventSpawned:
+					spawnedAt[e.FlightID] = wallTime(e.Time)
+				case aircommand.EventAssigned, aircommand.EventHolding:
+					if at, ok := spawnedAt[e.FlightID]; ok {
+						assign = append(assign, wallTime(e.Time).Sub(at))
+						delete(spawnedAt, e.FlightID)
+					}
+				}
+				mu.Unlock()
+			}
+		}(stats[i], s, i == 0)
+	}
+
+	<-ctx.Done()
+	for _, s := range streams {
+		s.Close()
+	}
+	wg.Wait()
+	if err := api.SetRate(context.Background(), initial.Rate, aircommand.SetRateParams{}); err != nil {
+		log.Printf("restore rate: %v", err)
+	}
+
+	var received, dropped int64
+	var broadcast []time.Duration
+	for _, st := range stats {
+		received += st.received
+		dropped += st.dropped
+		broadcast = append(broadcast, st.broadcast...)
+	}
+	dropRatio := 0.0
+	if received+dropped > 0 {
+		dropRatio = float64(dropped) / float64(received+dropped)
+	}
+
+	fmt.Printf("clients:            %d\n", len(streams))
+	fmt.Printf("rate:               %d/min for %s\n", *rate, *duration)
+	fmt.Printf("events received:    %d\n", received)
+	fmt.Printf("events dropped:     %d (%.4f%%)\n", dropped, dropRatio*100)
+	fmt.Printf("broadcast latency:  p50 %s  p99 %s  max %s\n", percentile(broadcast, 0.50), percentile(broadcast, 0.99), percentile(broadcast, 1))
+	fmt.Printf("assignment latency: p50 %s  p99 %s  max %s (%d flights)\n", percentile(assign, 0.50), percentile(assign, 0.99), percentile(assign, 1), len(assign))
+
+	failed := false
+	check := func(name string, ok bool, detail string) {
+		status := "PASS"
+		if !ok {
+			status, failed = "FAIL", true
+		}
+		fmt.Printf("%s  %s (%s)\n", status, name, detail)
+	}
+	fmt.Println()
+	check("broadcast p99", percentile(broadcast, 0.99) <= *maxBroadcast, "limit "+maxBroadcast.String())
+	check("assignment p99", percentile(assign, 0.99) <= *maxAssign, "limit "+maxAssign.String())
+	check("drop ratio", dropRatio <= *maxDropRatio, fmt.Sprintf("limit %g", *maxDropRatio))
+	check("events observed", received > 0, "at least one event")
+	negative := countNegative(broadcast) + countNegative(assign)
+	check("latency samples", negative == 0, fmt.Sprintf("%d negative, limit 0", negative))
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// countNegative counts the samples below zero.
+func countNegative(samples []time.Duration) int {
+	n := 0
+	for _, d := range samples {
+		if d < 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// percentile returns the q-th quantile of the samples, 0 when empty.
+func percentile(samples []time.Duration, q float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(q*float64(len(sorted)-1) + 0.5)
+	return sorted[idx]
+}
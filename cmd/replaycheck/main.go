@@ -0,0 +1,69 @@
+// Command replaycheck replays recorded sessions through the scheduler on a
+// virtual clock and checks the metrics each ends with against its fixture,
+// exiting non-zero if any expectation is not met. See package replaytest
+// for the fixture format.
+//
+// With -print the metrics of each replay are listed instead, a starting
+// point for writing the expectations of a new fixture.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"aircommand/internal/replaytest"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/replaytest/testdata", "directory of fixtures")
+	print := flag.Bool("print", false, "list every metric of each replay")
+	verbose := flag.Bool("v", false, "show the scheduler log")
+	flag.Parse()
+
+	fixtures, err := replaytest.LoadDir(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		log.Fatalf("no fixtures in %s", *dir)
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	failed := 0
+	for _, f := range fixtures {
+		res, err := f.Run()
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", f.Name, err)
+			continue
+		case len(res.Failures) > 0:
+			failed++
+			fmt.Printf("FAIL %s\n", f.Name)
+			for _, failure := range res.Failures {
+				fmt.Printf("     %v\n", failure)
+			}
+		default:
+			fmt.Printf("ok   %s: %d expectations met over %d events\n", f.Name, len(f.Expect), len(res.Events))
+		}
+		if *print {
+			names := make([]string, 0, len(res.Values))
+			for name := range res.Values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("     %s = %g\n", name, res.Values[name])
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d fixtures failed\n", failed, len(fixtures))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"aircommand/internal/control"
+)
+
+// Config is the optional JSON configuration file accepted via -config.
+type Config struct {
+	MetricsPush *MetricsPushConfig `json:"metricsPush,omitempty"`
+	Archive     *ArchiveConfig     `json:"archive,omitempty"`
+	Retention   *RetentionConfig   `json:"retention,omitempty"`
+	Plugins     *PluginsConfig     `json:"plugins,omitempty"`
+	Scripts     *ScriptsConfig     `json:"scripts,omitempty"`
+	Rules       []control.Rule     `json:"rules,omitempty"`
+	// CustomMetrics registers operator-defined counters and gauges.
+	CustomMetrics []control.CustomMetric `json:"customMetrics,omitempty"`
+	// Gates replaces the default parking stands.
+	Gates []control.Gate `json:"gates,omitempty"`
+	// ParallelMode is "independent" (the default), "dependent" or
+	// "singleStream".
+	ParallelMode string `json:"parallelMode,omitempty"`
+	// Exits replaces the exits of the named runways; an empty list removes
+	// them.
+	Exits map[string][]control.RunwayExit `json:"exits,omitempty"`
+	// RunwayPreferences makes the named runways "preferred", "secondary"
+	// or "lastResort" for arrivals; runways not named are preferred.
+	RunwayPreferences map[string]string `json:"runwayPreferences,omitempty"`
+	// Timing sets the arrival spacing and landing time of the named
+	// runways, overall and per weight category.
+	Timing map[string]control.RunwayTiming `json:"timing,omitempty"`
+	// MaxRate caps the arrival rate in planes per minute; rates above it
+	// are clamped. Zero leaves only the built-in ceiling.
+	MaxRate int64 `json:"maxRate,omitempty"`
+	// Identity sets the callsign format and flight ID strategy.
+	Identity *control.FlightIdentity `json:"identity,omitempty"`
+	// Acceptance defers or refuses arrivals while too many are holding.
+	Acceptance *control.AcceptanceGate `json:"acceptance,omitempty"`
+	// PilotLatency delays pilots' response to vectors and go-arounds.
+	PilotLatency *control.PilotLatency `json:"pilotLatency,omitempty"`
+	// HoldingPattern sets the leg time and turn rate of the holding
+	// racetrack.
+	HoldingPattern *control.HoldingPattern `json:"holdingPattern,omitempty"`
+	// Frequency limits the instructions the radio frequency carries.
+	Frequency *control.Frequency `json:"frequency,omitempty"`
+	// Quotas reserves shares of the arrival slots for airlines during peaks.
+	Quotas *control.QuotaConfig `json:"quotas,omitempty"`
+	// FrictionTests schedules periodic runway friction tests.
+	FrictionTests *control.FrictionTests `json:"frictionTests,omitempty"`
+	// Atmosphere sets the temperature, pressure and field elevation.
+	Atmosphere *control.Atmosphere `json:"atmosphere,omitempty"`
+	// Geography places the airport and its runway thresholds in the real
+	// world for map-based clients.
+	Geography *control.Geography `json:"geography,omitempty"`
+	// WindPlayback drives the wind from a historical series.
+	WindPlayback *WindPlaybackConfig `json:"windPlayback,omitempty"`
+	// Wildlife sets the bird activity by hour of day.
+	Wildlife *control.WildlifeHazard `json:"wildlife,omitempty"`
+	// Auction enables the experimental slot auction.
+	Auction *AuctionConfig `json:"auction,omitempty"`
+	// SLAs defines the service levels tracked from startup.
+	SLAs []control.SLA `json:"slas,omitempty"`
+	// Anomaly tunes the metric anomaly detector.
+	Anomaly *control.AnomalyConfig `json:"anomaly,omitempty"`
+	// TwoPerson requires a second controller to confirm high-impact
+	// actions.
+	TwoPerson *control.ConfirmationPolicy `json:"twoPerson,omitempty"`
+	// Clock sets the simulation epoch and speed.
+	Clock *ClockConfig `json:"clock,omitempty"`
+	// Reports enables the end-of-day summary report, optionally emailed.
+	Reports *control.ReportConfig `json:"reports,omitempty"`
+	// Tenants enables independent simulation instances created on demand
+	// under /tenants/{id}/.
+	Tenants *TenantsConfig `json:"tenants,omitempty"`
+	// Multicast broadcasts telemetry to a UDP multicast group for
+	// classroom displays on a LAN.
+	Multicast *MulticastConfig `json:"multicast,omitempty"`
+	// MQTT publishes delays, runways and next arrivals to a broker for
+	// display boards.
+	MQTT *MQTTConfig `json:"mqtt,omitempty"`
+	// WebRTC enables the experimental WebRTC data channel transport for
+	// the position stream.
+	WebRTC *WebRTCConfig `json:"webrtc,omitempty"`
+}
+
+// TenantsConfig bounds the simulation instances running at once, 10 by
+// default. New instances share the server's runways, gates and clock
+// speed unless created with their own, and are limited by Quota unless
+// created with their own. Templates are the exercise environments
+// available from startup.
+type TenantsConfig struct {
+	Max       int                      `json:"max"`
+	Quota     control.TenantQuota      `json:"quota"`
+	Templates []control.TenantTemplate `json:"templates,omitempty"`
+}
+
+// ClockConfig starts simulation time at Epoch, by default the time the
+// server starts, running Speed simulated seconds per real second, 1 by
+// default.
+type ClockConfig struct {
+	Epoch time.Time `json:"epoch"`
+	Speed float64   `json:"speed"`
+}
+
+// AuctionConfig selects the slot auction clearing mechanism by registered
+// name, "second-price" by default, and the airline agents bidding in it.
+type AuctionConfig struct {
+	Clearing string                 `json:"clearing"`
+	Agents   []control.AirlineAgent `json:"agents"`
+}
+
+// WindPlaybackConfig replays the wind observations in a CSV File, with
+// time, speed and direction columns and optionally gust, variableFrom and
+// variableTo, starting over after the last when Loop is set. Relative paths
+// are resolved against the directory holding the config file.
+type WindPlaybackConfig struct {
+	File string `json:"file"`
+	Loop bool   `json:"loop"`
+}
+
+// ScriptsConfig points at a directory of Starlark hook scripts. Relative paths
+// are resolved against the directory holding the config file.
+type ScriptsConfig struct {
+	Dir string `json:"dir"`
+}
+
+// PluginsConfig lists Go plugins to load and selects registered extensions by
+// name. Empty selections keep the built-in behavior.
+type PluginsConfig struct {
+	Paths    []string `json:"paths"`
+	Spawner  string   `json:"spawner"`
+	Strategy string   `json:"strategy"`
+	// GateStrategy is "nearest" (the default), "airline", "best-fit" or one
+	// registered by a plugin.
+	GateStrategy string   `json:"gateStrategy"`
+	Sinks        []string `json:"sinks"`
+	Weather      string   `json:"weather"`
+}
+
+// RetentionConfig bounds archive and recording growth, e.g. keep "168h" of raw
+// events and compact every "1h".
+type RetentionConfig struct {
+	Keep         Duration `json:"keep"`
+	CompactEvery Duration `json:"compactEvery"`
+}
+
+// ArchiveConfig selects the SQL database backing the event history. Driver is
+// "postgres" or "sqlite"; for SQLite the DSN is the database file, as
+// "/var/lib/aircommand/events.db".
+type ArchiveConfig struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+// MetricsPushConfig enables pushing metrics to StatsD, Datadog or InfluxDB.
+type MetricsPushConfig struct {
+	Protocol string            `json:"protocol"`
+	Address  string            `json:"address"`
+	Interval Duration          `json:"interval"`
+	Prefix   string            `json:"prefix"`
+	Tags     map[string]string `json:"tags"`
+}
+
+// PushConfig converts the file representation into the control package type.
+func (c MetricsPushConfig) PushConfig() control.PushConfig {
+	return control.PushConfig{
+		Protocol: c.Protocol,
+		Address:  c.Address,
+		Interval: time.Duration(c.Interval),
+		Prefix:   c.Prefix,
+		Tags:     c.Tags,
+	}
+}
+
+// MulticastConfig enables the LAN telemetry broadcaster.
+type MulticastConfig struct {
+	Group     string   `json:"group"`
+	Interval  Duration `json:"interval"`
+	TTL       int      `json:"ttl"`
+	Interface string   `json:"interface"`
+}
+
+// MulticastConfig converts the file representation into the control
+// package type.
+func (c MulticastConfig) MulticastConfig() control.MulticastConfig {
+	return control.MulticastConfig{
+		Group:     c.Group,
+		Interval:  time.Duration(c.Interval),
+		TTL:       c.TTL,
+		Interface: c.Interface,
+	}
+}
+
+// MQTTConfig enables the MQTT bridge for display boards.
+type MQTTConfig struct {
+	Broker   string   `json:"broker"`
+	ClientID string   `json:"clientId"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Prefix   string   `json:"prefix"`
+	Interval Duration `json:"interval"`
+	Arrivals int      `json:"arrivals"`
+	QoS      byte     `json:"qos"`
+}
+
+// MQTTConfig converts the file representation into the control package
+// type.
+func (c MQTTConfig) MQTTConfig() control.MQTTConfig {
+	return control.MQTTConfig{
+		Broker:   c.Broker,
+		ClientID: c.ClientID,
+		Username: c.Username,
+		Password: c.Password,
+		Prefix:   c.Prefix,
+		Interval: time.Duration(c.Interval),
+		Arrivals: c.Arrivals,
+		QoS:      c.QoS,
+	}
+}
+
+// WebRTCConfig lists the STUN or TURN server URLs peers gather ICE
+// candidates through; with none, only host candidates are offered.
+type WebRTCConfig struct {
+	ICEServers []string `json:"iceServers"`
+}
+
+// Duration decodes Go duration strings such as "10s" from JSON.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadConfig reads the configuration file at path. An empty path yields the
+// zero configuration.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
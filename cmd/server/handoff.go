@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"aircommand/internal/control"
+)
+
+// Environment variables used to pass inherited descriptors to a successor
+// process during a zero-downtime restart.
+const (
+	listenFDEnv = "AIRCOMMAND_LISTEN_FD"
+	stateFDEnv  = "AIRCOMMAND_STATE_FD"
+)
+
+// handoffState is the simulation state transferred to a successor process.
+type handoffState struct {
+	Rate        int64                  `json:"rate"`
+	Wind        control.WindState      `json:"wind"`
+	Closed      []string               `json:"closed"`
+	Visibility  int64                  `json:"visibility"`
+	Ceiling     int64                  `json:"ceiling,omitempty"`
+	Incidents   []control.Incident     `json:"incidents,omitempty"`
+	Outstanding control.RecoveredState `json:"outstanding"`
+}
+
+// listen returns the listener inherited from a predecessor when present, or a
+// fresh one bound to addr.
+func listen(addr string) (net.Listener, error) {
+	raw := os.Getenv(listenFDEnv)
+	if raw == "" {
+		return net.Listen("tcp", addr)
+	}
+	fd, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", listenFDEnv, err)
+	}
+	file := os.NewFile(uintptr(fd), "inherited-listener")
+	defer file.Close()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("inherit listener: %w", err)
+	}
+	log.Printf("inherited listener on %s from previous process", ln.Addr())
+	return ln, nil
+}
+
+// inheritedState reads the state handed over by a predecessor, blocking until
+// it has written the whole snapshot. It returns nil on a cold start.
+func inheritedState() (*handoffState, error) {
+	raw := os.Getenv(stateFDEnv)
+	if raw == "" {
+		return nil, nil
+	}
+	fd, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", stateFDEnv, err)
+	}
+	file := os.NewFile(uintptr(fd), "inherited-state")
+	defer file.Close()
+	var state handoffState
+	if err := json.NewDecoder(file).Decode(&state); err != nil {
+		return nil, fmt.Errorf("read handoff state: %w", err)
+	}
+	return &state, nil
+}
+
+// captureState snapshots the simulation for transfer. The scheduler should be
+// stopped first so nothing changes underneath it.
+func captureState(gen *control.Generator, runways *control.RunwayManager) handoffState {
+	state := handoffState{Rate: gen.Rate(), Wind: runways.Wind(), Visibility: runways.Visibility(), Ceiling: runways.Ceiling(), Incidents: runways.Incidents(), Outstanding: runways.Outstanding()}
+	state.Outstanding.LastID = gen.LastID()
+	for _, name := range runways.RunwayNames() {
+		if runways.IsClosed(name) {
+			state.Closed = append(state.Closed, name)
+		}
+	}
+	return state
+}
+
+// applyState restores a transferred snapshot into freshly built components.
+func applyState(state *handoffState, gen *control.Generator, runways *control.RunwayManager) {
+	gen.SetRate(state.Rate)
+	gen.ResumeFrom(state.Outstanding.LastID)
+	runways.SetWindState(state.Wind)
+	if state.Visibility > 0 {
+		runways.SetVisibility(state.Visibility)
+	}
+	if state.Ceiling > 0 {
+		runways.SetCeiling(state.Ceiling)
+	}
+	for _, name := range state.Closed {
+		runways.SetRunwayClosed(name, true)
+	}
+	for _, inc := range state.Incidents {
+		// A zero remaining time would fall back to the default repair time.
+		remaining := max(time.Until(inc.RepairBy), time.Millisecond)
+		if _, err := runways.InjectIncident(inc.Type, inc.Runway, remaining); err != nil {
+			log.Printf("handoff: incident %s: %v", inc.ID, err)
+		}
+	}
+	runways.Restore(state.Outstanding)
+}
+
+// successor is a started replacement process waiting for its state.
+type successor struct {
+	cmd   *exec.Cmd
+	state *os.File
+}
+
+// startSuccessor re-executes the current binary with the listening socket and
+// a state pipe attached. The child accepts nothing until send is called, and
+// pending connections wait in the shared socket backlog meanwhile.
+func startSuccessor(ln net.Listener) (*successor, error) {
+	tcp, ok := ln.(*net.TCPListener)
+	if !ok {
+		return nil, fmt.Errorf("listener %T cannot be handed off", ln)
+	}
+	lnFile, err := tcp.File()
+	if err != nil {
+		return nil, fmt.Errorf("dup listener: %w", err)
+	}
+	defer lnFile.Close()
+
+	stateR, stateW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("state pipe: %w", err)
+	}
+	defer stateR.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		stateW.Close()
+		return nil, err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{lnFile, stateR}
+	cmd.Env = append(os.Environ(), listenFDEnv+"=3", stateFDEnv+"=4")
+	if err := cmd.Start(); err != nil {
+		stateW.Close()
+		return nil, fmt.Errorf("start successor: %w", err)
+	}
+	return &successor{cmd: cmd, state: stateW}, nil
+}
+
+// send transfers the snapshot, releasing the successor to start serving.
+func (s *successor) send(state handoffState) error {
+	defer s.state.Close()
+	if err := json.NewEncoder(s.state).Encode(state); err != nil {
+		return fmt.Errorf("send handoff state: %w", err)
+	}
+	log.Printf("handed off to successor pid %d", s.cmd.Process.Pid)
+	return nil
+}
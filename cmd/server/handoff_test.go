@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"aircommand/internal/control"
+)
+
+var handoffRunways = []control.RunwayDefinition{{Name: "09L", Heading: 90}, {Name: "09R", Heading: 90}}
+
+func newComponents() (*control.Generator, *control.RunwayManager) {
+	gen := control.NewGenerator(12)
+	metrics := control.NewSchedulerMetrics([]string{"09L", "09R"})
+	runways := control.NewRunwayManager(handoffRunways, metrics, control.NewEventBus())
+	return gen, runways
+}
+
+// TestHandoffRoundTrip captures a busy scheduler as a restart would, passes
+// the state through its wire encoding into fresh components and checks the
+// successor captures the same state again.
+func TestHandoffRoundTrip(t *testing.T) {
+	gen, runways := newComponents()
+	gen.SetRate(30)
+	runways.SetWindState(control.WindState{Speed: 12, Direction: 250, Gust: 22, Variable: &control.WindVariation{From: 220, To: 280}})
+	runways.SetVisibility(3000)
+	runways.SetCeiling(800)
+	if _, err := runways.InjectIncident(control.IncidentLightingFailure, "09L", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	for range 6 {
+		id := gen.NextID()
+		runways.Arrive(control.Flight{ID: id, Call: fmt.Sprintf("TST%04d", id), CreatedAt: time.Now(), Weight: control.WeightMedium})
+	}
+	// Closing 09R sends its arrivals to holding, so both kinds of
+	// outstanding work are handed over.
+	runways.SetRunwayClosed("09R", true)
+	runways.Stop()
+	before := captureState(gen, runways)
+	if len(before.Outstanding.InProgress) == 0 || len(before.Outstanding.Holding) == 0 {
+		t.Fatalf("want landings in progress and holding flights to hand over, got %+v", before.Outstanding)
+	}
+
+	data, err := json.Marshal(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received handoffState
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatal(err)
+	}
+
+	nextGen, nextRunways := newComponents()
+	applyState(&received, nextGen, nextRunways)
+	nextRunways.Stop()
+	after := captureState(nextGen, nextRunways)
+
+	if after.Rate != before.Rate || after.Visibility != before.Visibility || after.Ceiling != before.Ceiling {
+		t.Errorf("rate, visibility and ceiling: got %d, %d, %d, want %d, %d, %d", after.Rate, after.Visibility, after.Ceiling, before.Rate, before.Visibility, before.Ceiling)
+	}
+	if !reflect.DeepEqual(after.Wind, before.Wind) {
+		t.Errorf("wind: got %+v, want %+v", after.Wind, before.Wind)
+	}
+	if !reflect.DeepEqual(after.Closed, before.Closed) {
+		t.Errorf("closed runways: got %v, want %v", after.Closed, before.Closed)
+	}
+	if len(after.Incidents) != len(before.Incidents) {
+		t.Fatalf("incidents: got %d, want %d", len(after.Incidents), len(before.Incidents))
+	}
+	for i, inc := range after.Incidents {
+		want := before.Incidents[i]
+		if inc.Type != want.Type || inc.Runway != want.Runway || inc.RepairBy.Sub(want.RepairBy).Abs() > time.Second {
+			t.Errorf("incident %d: got %+v, want %+v", i, inc, want)
+		}
+	}
+	if after.Outstanding.LastID != before.Outstanding.LastID {
+		t.Errorf("last flight ID: got %d, want %d", after.Outstanding.LastID, before.Outstanding.LastID)
+	}
+	if got, want := len(after.Outstanding.InProgress), len(before.Outstanding.InProgress); got != want {
+		t.Fatalf("landings in progress: got %d, want %d", got, want)
+	}
+	for i, d := range after.Outstanding.InProgress {
+		want := before.Outstanding.InProgress[i]
+		if d.Flight.ID != want.Flight.ID || d.Runway != want.Runway || !d.At.Equal(want.At) {
+			t.Errorf("landing %d: got flight %d on %s assigned %s, want flight %d on %s assigned %s", i, d.Flight.ID, d.Runway, d.At, want.Flight.ID, want.Runway, want.At)
+		}
+	}
+	if !reflect.DeepEqual(flightIDs(after.Outstanding.Holding), flightIDs(before.Outstanding.Holding)) {
+		t.Errorf("holding: got %v, want %v", flightIDs(after.Outstanding.Holding), flightIDs(before.Outstanding.Holding))
+	}
+	if id := nextGen.NextID(); id <= before.Outstanding.LastID {
+		t.Errorf("successor issued flight ID %d, not after %d", id, before.Outstanding.LastID)
+	}
+}
+
+func flightIDs(flights []control.Flight) []int64 {
+	ids := make([]int64, len(flights))
+	for i, f := range flights {
+		ids[i] = f.ID
+	}
+	return ids
+}
@@ -2,39 +2,411 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"aircommand/internal/control"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a JSON configuration file")
+	recordDir := flag.String("record-dir", "", "directory to record the broadcast stream into (disabled when empty)")
+	walPath := flag.String("wal", "", "path of the scheduler decision write-ahead log (disabled when empty)")
+	validateMessages := flag.Bool("validate-messages", false, "check outgoing websocket messages against the published schema (test mode)")
+	scenario := flag.String("scenario", "", "built-in scenario to start with, e.g. nominal-day (see /api/v1/scenarios)")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	chaosMode := flag.Bool("chaos", false, "allow fault injection through /api/v1/admin/chaos (test mode)")
+	replicaOf := flag.String("replica-of", "", "base URL of a primary server to follow as a read-only replica, e.g. http://scheduler:8080")
+	flag.Parse()
+
+	if *replicaOf != "" {
+		runReplica(*addr, *replicaOf)
+		return
+	}
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx, stopServer := context.WithCancel(ctx)
+	defer stopServer()
+	// The simulation runs under its own context so a handoff can freeze it
+	// before capturing state while the HTTP server keeps draining.
+	simCtx, stopSim := context.WithCancel(ctx)
+	defer stopSim()
+
+	handoff, err := inheritedState()
+	if err != nil {
+		log.Fatalf("handoff: %v", err)
+	}
+	ln, err := listen(*addr)
+	if err != nil {
+		log.Fatalf("listen: %v", err)
+	}
 
 	generator := control.NewGenerator(5) // default 5 planes/minute
+	if cfg.MaxRate < 0 {
+		log.Fatalf("config: max rate must not be negative")
+	}
+	generator.SetMaxRate(cfg.MaxRate)
+	if cfg.Identity != nil {
+		if err := generator.SetIdentity(*cfg.Identity); err != nil {
+			log.Fatalf("config: identity: %v", err)
+		}
+	}
 	flights := make(chan control.Flight, 16)
-	go generator.Run(ctx, flights)
 
-	runwayDefs := []control.RunwayDefinition{{Name: "2L", Heading: 20}, {Name: "2R", Heading: 20}}
+	runwayDefs := []control.RunwayDefinition{
+		{Name: "2L", Heading: 20, Approach: control.ApproachCATIII, Requires: []string{control.EquipageRNAV}, Length: 3600,
+			Exits: []control.RunwayExit{{Name: "A3", Distance: 1400, HighSpeed: true}, {Name: "A5", Distance: 2100}, {Name: "A7", Distance: 3200}}},
+		{Name: "2R", Heading: 20, Approach: control.ApproachCATI, Length: 3000,
+			Exits: []control.RunwayExit{{Name: "B4", Distance: 1800}, {Name: "B6", Distance: 2800}}},
+	}
+	for i, def := range runwayDefs {
+		if exits, ok := cfg.Exits[def.Name]; ok {
+			runwayDefs[i].Exits = exits
+		}
+	}
 	metrics := control.NewSchedulerMetrics([]string{"2L", "2R"})
-	runways := control.NewRunwayManager(runwayDefs, metrics)
+	events := control.NewEventBus()
+	runways := control.NewRunwayManager(runwayDefs, metrics, events)
+	epoch, speed := time.Now(), 1.0
+	if cfg.Clock != nil {
+		if cfg.Clock.Speed < 0 {
+			log.Fatalf("config: clock: speed must be positive")
+		}
+		if !cfg.Clock.Epoch.IsZero() {
+			epoch = cfg.Clock.Epoch
+		}
+		if cfg.Clock.Speed > 0 {
+			speed = cfg.Clock.Speed
+		}
+	}
+	var clock control.Clock = control.NewSimClock(epoch, speed)
+	var chaos *control.Chaos
+	if *chaosMode {
+		chaos = control.NewChaos()
+		clock = chaos.Clock(clock)
+		runways.SetChaos(chaos)
+	}
+	generator.SetClock(clock)
+	runways.SetClock(clock)
+	events.SetClock(clock)
+	metrics.SetClock(clock)
 	runways.SetWind(8, 20)
-	go runways.Run(ctx, flights)
+	gates := cfg.Gates
+	if len(gates) == 0 {
+		gates = []control.Gate{
+			{Name: "A1", Size: control.WeightSuper, Distance: 600},
+			{Name: "A2", Size: control.WeightHeavy, Distance: 650},
+			{Name: "A3", Size: control.WeightHeavy, Distance: 700},
+			{Name: "B1", Size: control.WeightMedium, Distance: 800},
+			{Name: "B2", Size: control.WeightMedium, Distance: 850},
+			{Name: "B3", Size: control.WeightMedium, Distance: 900},
+			{Name: "C1", Size: control.WeightLight, Distance: 1000},
+			{Name: "R1", Distance: 1500, Remote: true},
+			{Name: "R2", Distance: 1550, Remote: true},
+			{Name: "R3", Distance: 1600, Remote: true},
+		}
+	}
+	runways.SetGates(gates)
+	if cfg.ParallelMode != "" {
+		if err := runways.SetParallelMode(cfg.ParallelMode); err != nil {
+			log.Fatalf("config: %v", err)
+		}
+	}
+	for runway, t := range cfg.Timing {
+		if err := runways.SetRunwayTiming(runway, t); err != nil {
+			log.Fatalf("config: timing %s: %v", runway, err)
+		}
+	}
+	for runway, pref := range cfg.RunwayPreferences {
+		if err := runways.SetRunwayPreference(runway, pref); err != nil {
+			log.Fatalf("config: %v", err)
+		}
+	}
+	runways.SetRotations(generator.NextID)
+	if cfg.Acceptance != nil {
+		if err := runways.SetAcceptanceGate(*cfg.Acceptance); err != nil {
+			log.Fatalf("config: acceptance: %v", err)
+		}
+	}
+	if cfg.PilotLatency != nil {
+		if err := runways.SetPilotLatency(*cfg.PilotLatency); err != nil {
+			log.Fatalf("config: pilot latency: %v", err)
+		}
+	}
+	if cfg.HoldingPattern != nil {
+		if err := runways.SetHoldingPattern(*cfg.HoldingPattern); err != nil {
+			log.Fatalf("config: holding pattern: %v", err)
+		}
+	}
+	if cfg.Frequency != nil {
+		if err := runways.SetFrequency(*cfg.Frequency); err != nil {
+			log.Fatalf("config: frequency: %v", err)
+		}
+	}
+	if cfg.Atmosphere != nil {
+		if err := runways.SetAtmosphere(*cfg.Atmosphere); err != nil {
+			log.Fatalf("config: atmosphere: %v", err)
+		}
+	}
+	if cfg.Geography != nil {
+		if err := runways.SetGeography(*cfg.Geography); err != nil {
+			log.Fatalf("config: geography: %v", err)
+		}
+	}
+	if cfg.Wildlife != nil {
+		if err := runways.SetWildlifeHazard(*cfg.Wildlife); err != nil {
+			log.Fatalf("config: wildlife: %v", err)
+		}
+	}
+	if cfg.FrictionTests != nil {
+		if err := runways.SetFrictionTests(*cfg.FrictionTests); err != nil {
+			log.Fatalf("config: friction tests: %v", err)
+		}
+	}
+	if *walPath != "" {
+		wal, recovered, err := control.OpenDecisionLog(*walPath)
+		if err != nil {
+			log.Fatalf("decision log: %v", err)
+		}
+		defer wal.Close()
+		generator.ResumeFrom(recovered.LastID)
+		runways.SetDecisionLog(wal)
+		if handoff == nil {
+			runways.Restore(recovered)
+		}
+	}
+	if handoff != nil {
+		applyState(handoff, generator, runways)
+	}
+	registry := control.NewRegistry()
+	if cfg.Plugins != nil {
+		if err := setupPlugins(simCtx, *cfg.Plugins, registry, generator, runways, events); err != nil {
+			log.Fatalf("plugins: %v", err)
+		}
+	}
+	var quotas *control.QuotaStrategy
+	if cfg.Quotas != nil {
+		q, err := control.NewQuotaStrategy(*cfg.Quotas, runways.Strategy())
+		if err != nil {
+			log.Fatalf("config: quotas: %v", err)
+		}
+		runways.SetStrategy(q)
+		quotas = q
+	}
+	var auction *control.SlotAuction
+	if cfg.Auction != nil {
+		name := cfg.Auction.Clearing
+		if name == "" {
+			name = "second-price"
+		}
+		mechanism, err := registry.Clearing(name)
+		if err != nil {
+			log.Fatalf("config: auction: %v", err)
+		}
+		auction = control.NewSlotAuction(name, mechanism, cfg.Auction.Agents)
+		runways.SetAuction(auction)
+	}
+	if cfg.Scripts != nil {
+		dir := cfg.Scripts.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(*configPath), dir)
+		}
+		hooks, err := control.NewScriptHooks(simCtx, dir, generator, runways)
+		if err != nil {
+			log.Fatalf("scripts: %v", err)
+		}
+		runways.SetArrivalHook(hooks.OnSpawn)
+		go hooks.Run(simCtx, events)
+	}
+	if cfg.WindPlayback != nil {
+		if cfg.Plugins != nil && cfg.Plugins.Weather != "" {
+			log.Fatalf("config: wind playback: weather source %q already drives the wind", cfg.Plugins.Weather)
+		}
+		file := cfg.WindPlayback.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(*configPath), file)
+		}
+		playback, err := control.LoadWindPlayback(file, clock, cfg.WindPlayback.Loop)
+		if err != nil {
+			log.Fatalf("config: wind playback: %v", err)
+		}
+		go control.RunWeather(simCtx, playback, runways)
+	}
+	go generator.Run(simCtx, flights)
+	go runways.Run(simCtx, flights)
 
-	server := control.NewServer(generator, runways, metrics)
+	if cfg.MetricsPush != nil {
+		pusher, err := control.NewMetricsPusher(metrics, cfg.MetricsPush.PushConfig())
+		if err != nil {
+			log.Fatalf("metrics push: %v", err)
+		}
+		go pusher.Run(ctx)
+	}
+
+	server := control.NewServer(generator, runways, metrics, events)
+	server.ValidateMessages = *validateMessages
+	if chaos != nil {
+		server.Chaos = chaos
+		go chaos.Run(simCtx, runways)
+		log.Printf("chaos mode available at /api/v1/admin/chaos")
+	}
+	server.Quotas = quotas
+	server.Auction = auction
+	if cfg.Archive != nil {
+		archive, err := control.OpenEventArchive(cfg.Archive.Driver, cfg.Archive.DSN)
+		if err != nil {
+			log.Fatalf("event archive: %v", err)
+		}
+		go archive.Run(ctx, events)
+		server.Archive = archive
+	}
+	if *recordDir != "" {
+		if _, err := server.StartRecorder(ctx, *recordDir); err != nil {
+			log.Fatalf("recorder: %v", err)
+		}
+		server.RecordingDir = *recordDir
+	}
+	server.Rules = control.NewRulesEngine(generator, runways, metrics, events)
+	for _, rule := range cfg.Rules {
+		if _, err := server.Rules.Add(rule); err != nil {
+			log.Fatalf("rule %q: %v", rule.ID, err)
+		}
+	}
+	for _, m := range cfg.CustomMetrics {
+		if _, err := server.Rules.AddMetric(m); err != nil {
+			log.Fatalf("custom metric %q: %v", m.Name, err)
+		}
+	}
+	go server.Rules.Run(simCtx)
+	var anomaly control.AnomalyConfig
+	if cfg.Anomaly != nil {
+		anomaly = *cfg.Anomaly
+	}
+	server.Anomalies = control.NewAnomalyDetector(metrics, events, anomaly)
+	go server.Anomalies.Run(simCtx)
+	server.SLAs = control.NewSLATracker(metrics, events)
+	for _, sla := range cfg.SLAs {
+		if _, err := server.SLAs.Add(sla); err != nil {
+			log.Fatalf("sla %q: %v", sla.ID, err)
+		}
+	}
+	runways.SetSLATracker(server.SLAs)
+	if cfg.TwoPerson != nil {
+		confirmations, err := control.NewConfirmations(*cfg.TwoPerson, events)
+		if err != nil {
+			log.Fatalf("config: two-person: %v", err)
+		}
+		server.Confirmations = confirmations
+	}
+	if cfg.Reports != nil {
+		reports, err := control.NewDailyReporter(metrics, events, clock, *cfg.Reports)
+		if err != nil {
+			log.Fatalf("config: reports: %v", err)
+		}
+		go reports.Run(simCtx)
+		server.Reports = reports
+	}
+	if cfg.Multicast != nil {
+		broadcaster, err := control.NewMulticastBroadcaster(server, cfg.Multicast.MulticastConfig())
+		if err != nil {
+			log.Fatalf("multicast: %v", err)
+		}
+		go broadcaster.Run(ctx)
+	}
+	if cfg.MQTT != nil {
+		bridge, err := control.NewMQTTBridge(server, cfg.MQTT.MQTTConfig())
+		if err != nil {
+			log.Fatalf("mqtt: %v", err)
+		}
+		go bridge.Run(ctx)
+	}
+	if cfg.WebRTC != nil {
+		server.Positions = control.NewPositionChannels(runways, cfg.WebRTC.ICEServers)
+		go func() {
+			<-ctx.Done()
+			server.Positions.Close()
+		}()
+		log.Printf("webrtc position transport available at /api/v1/positions/webrtc")
+	}
+	if cfg.Retention != nil {
+		policy := control.RetentionPolicy{Keep: time.Duration(cfg.Retention.Keep), Interval: time.Duration(cfg.Retention.CompactEvery)}
+		go control.RunRetention(ctx, policy, server.Archive, server.RecordingDir)
+	}
+	if cfg.Tenants != nil {
+		server.Tenants = control.NewTenants(simCtx, control.TenantDefaults{
+			Runways:    runwayDefs,
+			Gates:      gates,
+			Wind:       runways.Wind(),
+			Speed:      speed,
+			MaxTenants: cfg.Tenants.Max,
+			Quota:      cfg.Tenants.Quota,
+			Registry:   registry,
+		})
+		for _, tpl := range cfg.Tenants.Templates {
+			if err := server.Tenants.SaveTemplate(tpl); err != nil {
+				log.Fatalf("config: tenant template %q: %v", tpl.Name, err)
+			}
+		}
+	}
+	// A successor resumes the handed-off state rather than restarting the
+	// scenario.
+	if *scenario != "" && handoff == nil {
+		if _, err := server.StartScenario(*scenario, nil); err != nil {
+			log.Fatalf("scenario: %v", err)
+		}
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/control", server.HandleControl)
-	mux.HandleFunc("/rate", server.HandleRate)
-	mux.HandleFunc("/metrics", server.HandleMetrics)
+	server.Register(mux)
 	mux.HandleFunc("/", serveIndex)
+	if server.Tenants != nil {
+		mux.Handle("/tenants/{tenant}/", server.Tenants)
+	}
+
+	srv := &http.Server{
+		Handler: mux,
+		// Long-lived streams observe the root context so shutdown ends them.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 
-	srv := &http.Server{Addr: ":8080", Handler: mux}
+	// SIGHUP hands the listening socket and simulation state to a freshly
+	// executed binary, then drains this process.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			next, err := startSuccessor(ln)
+			if err != nil {
+				log.Printf("restart aborted: %v", err)
+				continue
+			}
+			stopSim()
+			// Stop the scheduler before capturing so no landing completes
+			// here after its flight was handed over.
+			runways.Stop()
+			if err := next.send(captureState(generator, runways)); err != nil {
+				log.Printf("restart: %v", err)
+			}
+			stopServer()
+			return
+		}
+	}()
 
 	go func() {
 		<-ctx.Done()
@@ -45,12 +417,58 @@ func main() {
 		}
 	}()
 
-	log.Println("AirCommand control server listening on :8080")
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	log.Printf("AirCommand control server listening on %s", ln.Addr())
+	if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("server error: %v", err)
 	}
 }
 
+// setupPlugins loads the configured plugins and installs the selected
+// extensions on the simulation components.
+func setupPlugins(ctx context.Context, cfg PluginsConfig, registry *control.Registry, gen *control.Generator, runways *control.RunwayManager, events *control.EventBus) error {
+	for _, path := range cfg.Paths {
+		if err := control.LoadPlugin(path, registry); err != nil {
+			return err
+		}
+	}
+	if cfg.Spawner != "" {
+		spawner, err := registry.Spawner(cfg.Spawner)
+		if err != nil {
+			return err
+		}
+		gen.SetSpawner(spawner)
+	}
+	if cfg.Strategy != "" {
+		strategy, err := registry.Strategy(cfg.Strategy)
+		if err != nil {
+			return err
+		}
+		runways.SetStrategy(strategy)
+	}
+	if cfg.GateStrategy != "" {
+		strategy, err := registry.GateStrategy(cfg.GateStrategy)
+		if err != nil {
+			return err
+		}
+		runways.SetGateStrategy(strategy)
+	}
+	for _, name := range cfg.Sinks {
+		sink, err := registry.Sink(name)
+		if err != nil {
+			return err
+		}
+		go control.RunEventSink(ctx, events, sink)
+	}
+	if cfg.Weather != "" {
+		weather, err := registry.Weather(cfg.Weather)
+		if err != nil {
+			return err
+		}
+		go control.RunWeather(ctx, weather, runways)
+	}
+	return nil
+}
+
 func serveIndex(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
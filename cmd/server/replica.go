@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"aircommand/internal/control"
+)
+
+// runReplica serves a read-only replica of the primary at primary instead
+// of running a simulation.
+func runReplica(addr, primary string) {
+	replica, err := control.NewReplica(primary)
+	if err != nil {
+		log.Fatalf("replica: %v", err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go replica.Run(ctx)
+
+	mux := http.NewServeMux()
+	replica.Register(mux)
+	mux.HandleFunc("/", serveIndex)
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("server shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("AirCommand read replica of %s listening on %s", primary, addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Event types for the acceptance gate closing and reopening.
+const (
+	EventFlowRestricted = "flowRestricted"
+	EventFlowRestored   = "flowRestored"
+)
+
+// Acceptance modes: what happens to arrivals while the gate is closed.
+const (
+	AcceptanceDefer  = "defer"
+	AcceptanceRefuse = "refuse"
+)
+
+// defaultMaxDeferred bounds the deferred arrivals unless configured.
+const defaultMaxDeferred = 1000
+
+// AcceptanceGate models upstream flow restrictions: once HoldingThreshold
+// flights are holding, new arrivals are deferred before entering the
+// airspace, or refused, until holding falls below ResumeBelow. Deferred
+// arrivals enter in order once the gate reopens, their wait counting from
+// when they were generated. A zero threshold disables the gate.
+type AcceptanceGate struct {
+	HoldingThreshold int `json:"holdingThreshold"`
+	// ResumeBelow defaults to HoldingThreshold.
+	ResumeBelow int `json:"resumeBelow,omitempty"`
+	// Mode is "defer" (the default) or "refuse".
+	Mode string `json:"mode,omitempty"`
+	// MaxDeferred bounds the deferred arrivals, 1000 by default; arrivals
+	// beyond it are refused.
+	MaxDeferred int `json:"maxDeferred,omitempty"`
+}
+
+// AcceptanceStatus reports the acceptance gate and the demand it holds
+// back.
+type AcceptanceStatus struct {
+	AcceptanceGate
+	Restricted bool `json:"restricted"`
+	Holding    int  `json:"holding"`
+	// Deferred is the arrivals waiting to enter, OldestDeferredSeconds how
+	// long the first of them has waited.
+	Deferred              int     `json:"deferred"`
+	OldestDeferredSeconds float64 `json:"oldestDeferredSeconds"`
+	TotalDeferred         int64   `json:"totalDeferred"`
+	Released              int64   `json:"released"`
+	Refused               int64   `json:"refused"`
+}
+
+// deferredArrival is an arrival held back by the acceptance gate.
+type deferredArrival struct {
+	flight Flight
+	since  time.Time
+}
+
+// acceptanceState is the gate's state, under RunwayManager.mu.
+type acceptanceState struct {
+	gate       AcceptanceGate
+	restricted bool
+	deferred   []deferredArrival
+	total      int64
+	released   int64
+	refused    int64
+}
+
+func (g AcceptanceGate) validate() error {
+	switch {
+	case g.HoldingThreshold < 0:
+		return fmt.Errorf("holding threshold must not be negative")
+	case g.ResumeBelow < 0 || g.ResumeBelow > g.HoldingThreshold:
+		return fmt.Errorf("resumeBelow must be between 0 and the holding threshold")
+	case g.MaxDeferred < 0:
+		return fmt.Errorf("maxDeferred must not be negative")
+	}
+	switch g.Mode {
+	case "", AcceptanceDefer, AcceptanceRefuse:
+	default:
+		return fmt.Errorf("unknown acceptance mode %q", g.Mode)
+	}
+	return nil
+}
+
+// SetAcceptanceGate replaces the acceptance gate. Arrivals deferred by the
+// previous gate enter as the new one allows.
+func (rm *RunwayManager) SetAcceptanceGate(g AcceptanceGate) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if g.Mode == "" {
+		g.Mode = AcceptanceDefer
+	}
+	if g.ResumeBelow == 0 {
+		g.ResumeBelow = g.HoldingThreshold
+	}
+	if g.MaxDeferred == 0 {
+		g.MaxDeferred = defaultMaxDeferred
+	}
+	rm.mu.Lock()
+	rm.acceptance.gate = g
+	rm.acceptance.restricted = false
+	if g.HoldingThreshold > 0 {
+		rm.updateRestrictionLocked()
+		log.Printf("acceptance gate: %s arrivals at %d holding, resuming below %d", g.Mode, g.HoldingThreshold, g.ResumeBelow)
+	} else {
+		log.Printf("acceptance gate disabled")
+	}
+	rm.mu.Unlock()
+	rm.admitDeferred()
+	return nil
+}
+
+// Acceptance reports the acceptance gate and the demand it holds back.
+func (rm *RunwayManager) Acceptance() AcceptanceStatus {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	a := &rm.acceptance
+	status := AcceptanceStatus{
+		AcceptanceGate: a.gate,
+		Restricted:     a.restricted,
+		Holding:        len(rm.holding),
+		Deferred:       len(a.deferred),
+		TotalDeferred:  a.total,
+		Released:       a.released,
+		Refused:        a.refused,
+	}
+	if len(a.deferred) > 0 {
+		status.OldestDeferredSeconds = rm.clock.Now().Sub(a.deferred[0].since).Seconds()
+	}
+	return status
+}
+
+// admitArrivalLocked reports whether f may enter now, deferring or
+// refusing it otherwise. Arrivals queue behind those already deferred.
+func (rm *RunwayManager) admitArrivalLocked(f Flight) bool {
+	a := &rm.acceptance
+	if a.gate.HoldingThreshold <= 0 {
+		return true
+	}
+	rm.updateRestrictionLocked()
+	if !a.restricted && len(a.deferred) == 0 {
+		return true
+	}
+	if a.gate.Mode == AcceptanceRefuse || len(a.deferred) >= a.gate.MaxDeferred {
+		a.refused++
+		log.Printf("flight %d (%s) refused: flow restricted with %d holding", f.ID, f.Call, len(rm.holding))
+		return false
+	}
+	a.deferred = append(a.deferred, deferredArrival{flight: f, since: rm.clock.Now()})
+	a.total++
+	rm.logDecisionLocked(DecisionDefer, f, "")
+	return false
+}
+
+// updateRestrictionLocked closes the gate once holding reaches the
+// threshold and reopens it once holding falls below ResumeBelow.
+func (rm *RunwayManager) updateRestrictionLocked() {
+	a := &rm.acceptance
+	holding := len(rm.holding)
+	switch {
+	case !a.restricted && holding >= a.gate.HoldingThreshold:
+		a.restricted = true
+		detail := fmt.Sprintf("%d holding; arrivals %s", holding, map[string]string{AcceptanceDefer: "deferred", AcceptanceRefuse: "refused"}[a.gate.Mode])
+		rm.publishEventLocked(Event{Type: EventFlowRestricted, Detail: detail})
+		log.Printf("flow restricted: %s", detail)
+	case a.restricted && holding < a.gate.ResumeBelow:
+		a.restricted = false
+		detail := fmt.Sprintf("%d holding; %d deferred arrivals to enter", holding, len(a.deferred))
+		rm.publishEventLocked(Event{Type: EventFlowRestored, Detail: detail})
+		log.Printf("flow restored: %s", detail)
+	}
+}
+
+// admitDeferred lets deferred arrivals enter in order while the gate
+// stays open, rechecking holding after each.
+func (rm *RunwayManager) admitDeferred() {
+	for {
+		rm.mu.Lock()
+		a := &rm.acceptance
+		if a.gate.HoldingThreshold > 0 {
+			rm.updateRestrictionLocked()
+		}
+		if a.restricted || len(a.deferred) == 0 {
+			rm.mu.Unlock()
+			return
+		}
+		d := a.deferred[0]
+		a.deferred = a.deferred[1:]
+		a.released++
+		waited := rm.clock.Now().Sub(d.since)
+		rm.mu.Unlock()
+		log.Printf("deferred flight %d (%s) released after %s", d.flight.ID, d.flight.Call, waited.Round(time.Second))
+		rm.enter(d.flight)
+	}
+}
+
+// HandleAcceptance reports (GET) or replaces (PUT) the acceptance gate.
+func (s *Server) HandleAcceptance(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var g AcceptanceGate
+		if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+			http.Error(w, "invalid acceptance gate: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetAcceptanceGate(g); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Acceptance())
+}
@@ -0,0 +1,188 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// amanStreamInterval is how often the timeline stream is refreshed when no
+// sequencing event has forced an update, so countdowns stay current.
+const amanStreamInterval = time.Second
+
+// amanEventTypes are the events that change a runway's landing sequence.
+var amanEventTypes = map[string]bool{
+	EventAssigned:     true,
+	EventHolding:      true,
+	EventLanded:       true,
+	EventGoAround:     true,
+	EventDiverted:     true,
+	EventRunwayClosed: true,
+	EventRunwayOpened: true,
+}
+
+// TimelineEntry is one arrival on a runway's landing ladder. Estimate is when
+// the flight would touch down unconstrained; Target is its sequenced slot
+// once minimum arrival spacing behind the preceding landing is applied.
+type TimelineEntry struct {
+	FlightID     int64     `json:"flightId"`
+	Call         string    `json:"call"`
+	Estimate     time.Time `json:"estimate"`
+	Target       time.Time `json:"target"`
+	DelaySeconds float64   `json:"delaySeconds"`
+}
+
+// RunwayTimeline is the ordered landing ladder for one runway.
+type RunwayTimeline struct {
+	Runway   string          `json:"runway"`
+	Open     bool            `json:"open"`
+	Landings []TimelineEntry `json:"landings"`
+}
+
+// Timeline is an arrival manager (AMAN) view of every runway's sequence.
+type Timeline struct {
+	Time    time.Time        `json:"time"`
+	Runways []RunwayTimeline `json:"runways"`
+	Holding int              `json:"holding"`
+}
+
+// Timeline builds the current landing ladder for each runway, in runway
+// order, with arrivals sorted by target time.
+func (rm *RunwayManager) Timeline() Timeline {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	now := rm.clock.Now()
+	out := Timeline{Time: now, Runways: make([]RunwayTimeline, 0, len(rm.order)), Holding: len(rm.holding)}
+	for _, name := range rm.order {
+		out.Runways = append(out.Runways, RunwayTimeline{Runway: name, Open: rm.runways[name].available(), Landings: rm.ladderLocked(name)})
+	}
+	return out
+}
+
+// ladderLocked sequences the arrivals queued for runway by estimate and
+// spaces their targets at least the runway's arrival spacing apart. Unless
+// parallel approaches are independent, arrivals to parallel runways are
+// sequenced together and also spaced behind each other by the mode's
+// stagger. Targets falling in a gap reserved for a friction test move to its
+// end.
+func (rm *RunwayManager) ladderLocked(runway string) []TimelineEntry {
+	type slot struct {
+		runway string
+		flight Flight
+		entry  TimelineEntry
+	}
+	var sequence []slot
+	for _, name := range rm.parallelGroupLocked(runway) {
+		for _, f := range rm.assigned[name] {
+			sequence = append(sequence, slot{runway: name, flight: f, entry: TimelineEntry{FlightID: f.ID, Call: f.Call, Estimate: rm.estimateLocked(name, f)}})
+		}
+	}
+	sort.SliceStable(sequence, func(i, j int) bool {
+		return sequence[i].entry.Estimate.Before(sequence[j].entry.Estimate)
+	})
+	stagger := rm.parallelStagger()
+	previous := make(map[string]time.Time)
+	ladder := make([]TimelineEntry, 0, len(rm.assigned[runway]))
+	for _, s := range sequence {
+		e := s.entry
+		e.Target = e.Estimate
+		for name, last := range previous {
+			spacing := stagger
+			if name == s.runway {
+				spacing = rm.sequenceSpacingLocked(name, s.flight)
+			}
+			if e.Target.Before(last.Add(spacing)) {
+				e.Target = last.Add(spacing)
+			}
+		}
+		e.Target = rm.frictionSlotLocked(s.runway, e.Target)
+		e.Target = rm.transitionSlotLocked(s.runway, e.FlightID, e.Target)
+		e.DelaySeconds = e.Target.Sub(e.Estimate).Seconds()
+		previous[s.runway] = e.Target
+		if s.runway == runway {
+			ladder = append(ladder, e)
+		}
+	}
+	return ladder
+}
+
+// sequencedLandingLocked is how long from now f, just queued for runway, is
+// sequenced to touch down.
+func (rm *RunwayManager) sequencedLandingLocked(runway string, f Flight) time.Duration {
+	for _, e := range rm.ladderLocked(runway) {
+		if e.FlightID == f.ID {
+			return e.Target.Sub(rm.clock.Now())
+		}
+	}
+	return rm.approachTimeLocked(runway, f)
+}
+
+// estimateLocked is when f is due to touch down on runway. Until its landing
+// is scheduled, that includes any vectoring around thunderstorms.
+func (rm *RunwayManager) estimateLocked(runway string, f Flight) time.Time {
+	if due, ok := rm.dueAt[f.ID]; ok {
+		return due
+	}
+	return rm.assignedAt[f.ID].Add(rm.approachTimeLocked(runway, f) + rm.stormDeviationLocked(runway))
+}
+
+// HandleTimeline returns the arrival manager timeline.
+func (s *Server) HandleTimeline(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Timeline())
+}
+
+// HandleTimelineStream streams the arrival manager timeline as server-sent
+// "timeline" events, sent whenever a runway sequence changes and at least
+// every amanStreamInterval.
+func (s *Server) HandleTimelineStream(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var events <-chan Event
+	if s.Events != nil {
+		ch, unsubscribe := s.Events.Subscribe(eventBufferSize)
+		defer unsubscribe()
+		events = ch
+	}
+
+	ticker := time.NewTicker(amanStreamInterval)
+	defer ticker.Stop()
+
+	if err := writeSSE(w, "timeline", s.Runways.Timeline()); err != nil {
+		return
+	}
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if !amanEventTypes[e.Type] {
+				continue
+			}
+		}
+		if err := writeSSE(w, "timeline", s.Runways.Timeline()); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,210 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// analyticsMetrics are the per-flight values analytics can aggregate. A nil
+// value leaves the flight out, as the wait of a flight that has not landed.
+var analyticsMetrics = map[string]func(FlightHistory) *float64{
+	"wait":    func(f FlightHistory) *float64 { return f.WaitSeconds },
+	"holding": func(f FlightHistory) *float64 { return &f.HoldingSeconds },
+	"holdingCount": func(f FlightHistory) *float64 {
+		v := float64(f.HoldingCount)
+		return &v
+	},
+	"goArounds": func(f FlightHistory) *float64 {
+		v := float64(f.GoArounds)
+		return &v
+	},
+	"diverted": func(f FlightHistory) *float64 {
+		v := 0.0
+		if f.Outcome == "diverted" {
+			v = 1
+		}
+		return &v
+	},
+	"flights": func(f FlightHistory) *float64 {
+		v := 1.0
+		return &v
+	},
+}
+
+// analyticsDimensions are the keys flights can be grouped by.
+var analyticsDimensions = map[string]bool{"runway": true, "hour": true, "day": true, "outcome": true}
+
+// AnalyticsQuery aggregates a metric over the archived flights in a time
+// range, grouped by any of runway, hour, day and outcome. Hours and days are
+// those the flight was first seen in, in UTC.
+type AnalyticsQuery struct {
+	Metric  string
+	GroupBy []string
+	From    time.Time
+	To      time.Time
+}
+
+// AnalyticsRow is one group of a tidy analytics result: the group's keys,
+// set only for the dimensions grouped by, then the metric's statistics.
+type AnalyticsRow struct {
+	Runway  *string    `json:"runway,omitempty"`
+	Hour    *time.Time `json:"hour,omitempty"`
+	Day     *string    `json:"day,omitempty"`
+	Outcome *string    `json:"outcome,omitempty"`
+	Count   int        `json:"count"`
+	Sum     float64    `json:"sum"`
+	Mean    float64    `json:"mean"`
+	Min     float64    `json:"min"`
+	Max     float64    `json:"max"`
+	P50     float64    `json:"p50"`
+	P90     float64    `json:"p90"`
+}
+
+// AnalyticsResult is an aggregated metric, one row per group, so it loads
+// directly into a data frame.
+type AnalyticsResult struct {
+	Metric  string         `json:"metric"`
+	GroupBy []string       `json:"groupBy"`
+	Rows    []AnalyticsRow `json:"rows"`
+}
+
+// validate checks the metric and dimensions, defaulting the metric to wait.
+func (q *AnalyticsQuery) validate() error {
+	if q.Metric == "" {
+		q.Metric = "wait"
+	}
+	if analyticsMetrics[q.Metric] == nil {
+		return fmt.Errorf("unknown metric %q", q.Metric)
+	}
+	seen := make(map[string]bool)
+	for _, dim := range q.GroupBy {
+		if !analyticsDimensions[dim] {
+			return fmt.Errorf("unknown groupby dimension %q", dim)
+		}
+		if seen[dim] {
+			return fmt.Errorf("duplicate groupby dimension %q", dim)
+		}
+		seen[dim] = true
+	}
+	return nil
+}
+
+// Aggregate groups flights and summarises the query's metric in each group,
+// ordered by the group keys.
+func (q AnalyticsQuery) Aggregate(flights []FlightHistory) AnalyticsResult {
+	metric := analyticsMetrics[q.Metric]
+	type group struct {
+		row    AnalyticsRow
+		values []float64
+	}
+	groups := make(map[string]*group)
+	for _, f := range flights {
+		v := metric(f)
+		if v == nil {
+			continue
+		}
+		var row AnalyticsRow
+		key := make([]string, len(q.GroupBy))
+		for i, dim := range q.GroupBy {
+			switch dim {
+			case "runway":
+				runway := f.Runway
+				row.Runway, key[i] = &runway, runway
+			case "hour":
+				hour := f.firstSeen.UTC().Truncate(time.Hour)
+				row.Hour, key[i] = &hour, hour.Format(time.RFC3339)
+			case "day":
+				day := f.firstSeen.UTC().Format(time.DateOnly)
+				row.Day, key[i] = &day, day
+			case "outcome":
+				outcome := f.Outcome
+				row.Outcome, key[i] = &outcome, outcome
+			}
+		}
+		k := strings.Join(key, "\x00")
+		g, ok := groups[k]
+		if !ok {
+			g = &group{row: row}
+			groups[k] = g
+		}
+		g.values = append(g.values, *v)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := AnalyticsResult{Metric: q.Metric, GroupBy: q.GroupBy, Rows: make([]AnalyticsRow, 0, len(keys))}
+	if result.GroupBy == nil {
+		result.GroupBy = []string{}
+	}
+	for _, k := range keys {
+		g := groups[k]
+		values := g.values
+		sort.Float64s(values)
+		row := g.row
+		row.Count = len(values)
+		row.Min, row.Max = values[0], values[len(values)-1]
+		for _, v := range values {
+			row.Sum += v
+		}
+		row.Mean = row.Sum / float64(row.Count)
+		row.P50, row.P90 = nearestRank(values, 0.5), nearestRank(values, 0.9)
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
+
+// nearestRank returns the p quantile of sorted values by the nearest-rank
+// method.
+func nearestRank(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// HandleAnalytics aggregates a metric over archived flights, e.g.
+// /api/v1/analytics?metric=wait&groupby=runway,hour&from=...&to=...
+func (s *Server) HandleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if s.Archive == nil {
+		http.Error(w, "event archive disabled", http.StatusServiceUnavailable)
+		return
+	}
+	params := r.URL.Query()
+	q := AnalyticsQuery{Metric: params.Get("metric")}
+	if raw := params.Get("groupby"); raw != "" {
+		for _, dim := range strings.Split(raw, ",") {
+			q.GroupBy = append(q.GroupBy, strings.TrimSpace(dim))
+		}
+	}
+	if err := q.validate(); err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var err error
+	if raw := params.Get("from"); raw != "" {
+		if q.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid from (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("to"); raw != "" {
+		if q.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid to (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	flights, err := s.Archive.Flights(q.From, q.To)
+	if err != nil {
+		log.Printf("analytics query: %v", err)
+		http.Error(w, "analytics query failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, q.Aggregate(flights))
+}
@@ -0,0 +1,210 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// EventAnomaly is published when a watched metric behaves unusually.
+const EventAnomaly = "anomaly"
+
+// Anomaly kinds.
+const (
+	// AnomalySpike is a single sample far outside the metric's usual range.
+	AnomalySpike = "spike"
+	// AnomalyDrift is the recent level moving away from the long-run one.
+	AnomalyDrift = "drift"
+)
+
+const (
+	anomalyInterval = 5 * time.Second
+	maxAnomalies    = 200
+	// anomalyWarmup is how many samples a metric needs before it is judged.
+	anomalyWarmup = 24
+	// slowAlpha and fastAlpha weight the newest sample in the long-run and
+	// recent moving averages.
+	slowAlpha = 0.05
+	fastAlpha = 0.3
+	// minDeviation keeps metrics that never vary from flagging every
+	// rounding difference.
+	minDeviation = 0.01
+)
+
+// AnomalyConfig tunes the detector. Thresholds are in standard deviations
+// of the long-run average; zero takes the defaults of 4 for spikes and 2.5
+// for drift.
+type AnomalyConfig struct {
+	SpikeThreshold float64 `json:"spikeThreshold,omitempty"`
+	DriftThreshold float64 `json:"driftThreshold,omitempty"`
+}
+
+// anomalyMetrics are the watched metrics. Counters are judged on their
+// increase per interval, gauges on their value.
+var anomalyMetrics = []struct {
+	name    string
+	counter bool
+	read    func(MetricsSnapshot) float64
+}{
+	{"conflicts", true, func(s MetricsSnapshot) float64 { return float64(s.ConflictDetections) }},
+	{"goArounds", true, func(s MetricsSnapshot) float64 { return float64(s.GoArounds) }},
+	{"diversions", true, func(s MetricsSnapshot) float64 { return float64(s.Diversions) }},
+	{"incursions", true, func(s MetricsSnapshot) float64 { return float64(s.Incursions) }},
+	{"holding", false, func(s MetricsSnapshot) float64 { return float64(s.HoldingCurrent) }},
+	{"averageHoldingMinutes", false, func(s MetricsSnapshot) float64 { return s.AverageHoldingMinutes }},
+	{"longestHoldingMinutes", false, longestHoldingMinutes},
+	{"averageWait", false, func(s MetricsSnapshot) float64 { return s.AverageWaitSeconds }},
+	{"departureQueue", false, func(s MetricsSnapshot) float64 { return float64(s.DepartureQueue) }},
+	{"averageDepartureDelay", false, func(s MetricsSnapshot) float64 { return s.AverageDepartureDelaySeconds }},
+}
+
+// series tracks one metric with an exponentially weighted moving average
+// and variance, plus a faster-moving average for drift.
+type series struct {
+	mean, variance, fast float64
+	last                 float64
+	samples              int
+	// spiking and drifting are set while an anomaly persists, so each is
+	// raised once.
+	spiking, drifting bool
+}
+
+// Anomaly is one unusual sample.
+type Anomaly struct {
+	Time   time.Time `json:"time"`
+	Metric string    `json:"metric"`
+	Kind   string    `json:"kind"`
+	// Value is the sample, or the recent average for drift; Mean and StdDev
+	// are the long-run average and spread it is judged against.
+	Value  float64 `json:"value"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	ZScore float64 `json:"zScore"`
+}
+
+// AnomalyDetector watches key metrics for sudden spikes and slow drift,
+// raising an alert on the event bus when one starts.
+type AnomalyDetector struct {
+	metrics *SchedulerMetrics
+	events  *EventBus
+	cfg     AnomalyConfig
+
+	mu        sync.Mutex
+	series    map[string]*series
+	anomalies []Anomaly
+}
+
+// NewAnomalyDetector builds a detector over metrics. The event bus is
+// optional; when nil anomalies are only recorded.
+func NewAnomalyDetector(metrics *SchedulerMetrics, events *EventBus, cfg AnomalyConfig) *AnomalyDetector {
+	if cfg.SpikeThreshold <= 0 {
+		cfg.SpikeThreshold = 4
+	}
+	if cfg.DriftThreshold <= 0 {
+		cfg.DriftThreshold = 2.5
+	}
+	return &AnomalyDetector{metrics: metrics, events: events, cfg: cfg, series: make(map[string]*series, len(anomalyMetrics))}
+}
+
+// Run samples the metrics every anomalyInterval until the context is
+// canceled.
+func (d *AnomalyDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(anomalyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Observe(time.Now())
+		}
+	}
+}
+
+// Observe takes one sample of every watched metric and raises the
+// anomalies that start with it.
+func (d *AnomalyDetector) Observe(now time.Time) {
+	snapshot := d.metrics.Snapshot()
+	d.mu.Lock()
+	var raised []Anomaly
+	for _, m := range anomalyMetrics {
+		s, ok := d.series[m.name]
+		raw := m.read(snapshot)
+		if !ok {
+			d.series[m.name] = &series{last: raw}
+			if m.counter {
+				continue
+			}
+			s = d.series[m.name]
+		}
+		value := raw
+		if m.counter {
+			value = raw - s.last
+		}
+		s.last = raw
+		raised = append(raised, d.judgeLocked(now, m.name, s, value)...)
+	}
+	d.anomalies = append(d.anomalies, raised...)
+	if len(d.anomalies) > maxAnomalies {
+		d.anomalies = d.anomalies[len(d.anomalies)-maxAnomalies:]
+	}
+	d.mu.Unlock()
+
+	for _, a := range raised {
+		detail := fmt.Sprintf("%s %s: %.2f against %.2f ± %.2f", a.Metric, a.Kind, a.Value, a.Mean, a.StdDev)
+		log.Printf("anomaly: %s", detail)
+		if d.events != nil {
+			d.events.Publish(Event{Type: EventAnomaly, Time: now, Detail: detail})
+		}
+	}
+}
+
+// judgeLocked scores value against s before folding it in.
+func (d *AnomalyDetector) judgeLocked(now time.Time, name string, s *series, value float64) []Anomaly {
+	var raised []Anomaly
+	if s.samples == 0 {
+		s.mean, s.fast = value, value
+	}
+	s.samples++
+	fast := s.fast + fastAlpha*(value-s.fast)
+	if s.samples > anomalyWarmup {
+		std := math.Max(math.Sqrt(s.variance), minDeviation)
+		spikeZ := (value - s.mean) / std
+		driftZ := (fast - s.mean) / std
+		spiking := math.Abs(spikeZ) > d.cfg.SpikeThreshold
+		drifting := math.Abs(driftZ) > d.cfg.DriftThreshold
+		if spiking && !s.spiking {
+			raised = append(raised, Anomaly{Time: now, Metric: name, Kind: AnomalySpike, Value: value, Mean: s.mean, StdDev: std, ZScore: spikeZ})
+		}
+		if drifting && !s.drifting && !spiking {
+			raised = append(raised, Anomaly{Time: now, Metric: name, Kind: AnomalyDrift, Value: fast, Mean: s.mean, StdDev: std, ZScore: driftZ})
+		}
+		s.spiking, s.drifting = spiking, drifting
+	}
+	diff := value - s.mean
+	incr := slowAlpha * diff
+	s.mean += incr
+	s.variance = (1 - slowAlpha) * (s.variance + diff*incr)
+	s.fast = fast
+	return raised
+}
+
+// Anomalies returns the most recent anomalies, oldest first.
+func (d *AnomalyDetector) Anomalies() []Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Anomaly{}, d.anomalies...)
+}
+
+// HandleAnomalies lists recent metric anomalies.
+func (s *Server) HandleAnomalies(w http.ResponseWriter, r *http.Request) {
+	if s.Anomalies == nil {
+		http.Error(w, "anomaly detection disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Anomalies.Anomalies())
+}
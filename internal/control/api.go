@@ -0,0 +1,387 @@
+package control
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the version advertised in the OpenAPI document.
+const APIVersion = "1.0.0"
+
+// Stream kinds for routes that do not return a single JSON document.
+const (
+	StreamWebSocket  = "websocket"
+	StreamSSE        = "sse"
+	StreamCSV        = "csv"
+	StreamPrometheus = "prometheus"
+)
+
+// Route describes one HTTP endpoint. The same table registers the handlers
+// and generates the OpenAPI document, so the published contract cannot drift
+// from what the server serves.
+type Route struct {
+	Method string
+	Path   string
+	// AnyMethod registers the handler for every method; Method is still the
+	// one advertised. Used by the unversioned endpoints that predate /api/v1.
+	AnyMethod   bool
+	OperationID string
+	Summary     string
+	Params      []Param
+	// Body and Response are zero values of the request and response payload
+	// types; nil means no body.
+	Body     any
+	Response any
+	// Status is the success status code, 200 when zero.
+	Status int
+	// Stream marks websocket and server-sent event endpoints.
+	Stream  string
+	Handler http.HandlerFunc
+}
+
+// Param is a query or path parameter. Type is a JSON Schema type, or
+// "date-time" for RFC 3339 timestamps.
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// Pattern is the ServeMux pattern the route is registered under.
+func (rt Route) Pattern() string {
+	if rt.AnyMethod {
+		return rt.Path
+	}
+	return rt.Method + " " + rt.Path
+}
+
+// Routes lists every endpoint the server exposes.
+func (s *Server) Routes() []Route {
+	dryRunParam := Param{Name: "dryRun", In: "query", Type: "boolean", Description: "Validate and return the predicted effect as a Preview without applying the change."}
+	timeRange := []Param{
+		{Name: "from", In: "query", Type: "date-time", Description: "Earliest event time."},
+		{Name: "to", In: "query", Type: "date-time", Description: "Latest event time."},
+	}
+	return []Route{
+		{Method: "GET", Path: "/control", AnyMethod: true, OperationID: "control", Summary: "Bidirectional control channel.", Stream: StreamWebSocket, Handler: s.HandleControl},
+		{Method: "GET", Path: "/spectate", AnyMethod: true, OperationID: "spectate", Summary: "Read-only spectator feed.", Stream: StreamSSE, Handler: s.HandleSpectate},
+		{Method: "GET", Path: "/api/v1/replication", OperationID: "streamReplication", Summary: "State snapshots and events for read replicas to follow.", Stream: StreamSSE, Handler: s.HandleReplication},
+		{Method: "GET", Path: "/playback", AnyMethod: true, OperationID: "playback", Summary: "Replay a recorded session.", Stream: StreamWebSocket, Handler: s.HandlePlayback,
+			Params: []Param{
+				{Name: "session", In: "query", Type: "string", Required: true, Description: "Recording file name."},
+				{Name: "speed", In: "query", Type: "number", Description: "Playback speed multiplier."},
+			}},
+		{Method: "GET", Path: "/voice", AnyMethod: true, OperationID: "voice", Summary: "Instructions and pilot readbacks for a text-to-speech frontend.", Stream: StreamWebSocket, Handler: s.HandleVoice},
+		{Method: "POST", Path: "/rate", AnyMethod: true, OperationID: "setRate", Summary: "Set the arrival rate in planes per minute.", Status: http.StatusNoContent, Handler: s.HandleRate,
+			Params: []Param{{Name: "rate", In: "query", Type: "integer", Required: true}, {Name: "ramp", In: "query", Type: "integer", Description: "Seconds over which to ramp to the new rate."}, dryRunParam}},
+		{Method: "GET", Path: "/metrics", AnyMethod: true, OperationID: "getMetrics", Summary: "Current scheduler metrics.", Response: MetricsSnapshot{}, Handler: s.HandleMetrics},
+		{Method: "GET", Path: "/recordings", AnyMethod: true, OperationID: "listRecordings", Summary: "Recorded sessions available for playback.", Response: []string{}, Handler: s.HandleRecordings},
+		{Method: "GET", Path: "/api/v1/openapi.json", OperationID: "getOpenAPI", Summary: "OpenAPI description of this API.", Response: map[string]any{}, Handler: s.HandleOpenAPI},
+		{Method: "GET", Path: "/api/v1/schema", OperationID: "getMessageSchema", Summary: "AsyncAPI description of the websocket messages.", Response: map[string]any{}, Handler: s.HandleMessageSchema},
+		{Method: "GET", Path: "/api/v1/admin/backpressure", OperationID: "getBackpressure", Summary: "Flights spawned on time versus held back because the scheduler stalled, by cause.", Response: Backpressure{}, Handler: s.HandleBackpressure},
+		{Method: "GET", Path: "/api/v1/admin/chaos", OperationID: "getChaos", Summary: "Chaos mode: the faults configured, those injected and any scheduler invariants broken.", Response: ChaosStatus{}, Handler: s.HandleChaos},
+		{Method: "PUT", Path: "/api/v1/admin/chaos", OperationID: "setChaos", Summary: "Configure the faults chaos mode injects.", Body: ChaosConfig{}, Response: ChaosStatus{}, Handler: s.HandleChaos},
+		{Method: "DELETE", Path: "/api/v1/admin/chaos", OperationID: "stopChaos", Summary: "Stop injecting faults.", Response: ChaosStatus{}, Handler: s.HandleChaos},
+		{Method: "GET", Path: "/api/v1/admin/clients", OperationID: "listClients", Summary: "Connected websocket clients with message rates, queue depth and dropped frames.", Response: []ClientStats{}, Handler: s.HandleClients},
+		{Method: "GET", Path: "/api/v1/time", OperationID: "getClock", Summary: "The simulation clock: current sim time, speed factor and epoch.", Response: ClockInfo{}, Handler: s.HandleTime},
+		{Method: "GET", Path: "/api/v1/timers", OperationID: "getFlightTimers", Summary: "Each flight's countdown to touchdown, time in holding and expected approach time.", Response: FlightTimers{}, Handler: s.HandleTimers},
+		{Method: "GET", Path: "/api/v1/state", OperationID: "getState", Summary: "Consistent snapshot of flights, queues, holding, wind, runways and metrics.", Response: StateSnapshot{}, Handler: s.HandleState},
+		{Method: "GET", Path: "/api/v1/history", OperationID: "listHistory", Summary: "Archived events.", Response: []Event{}, Handler: s.HandleHistory,
+			Params: append([]Param{
+				{Name: "flight", In: "query", Type: "integer", Description: "Flight ID."},
+				{Name: "type", In: "query", Type: "string", Description: "Event type."},
+				{Name: "runway", In: "query", Type: "string"},
+				{Name: "limit", In: "query", Type: "integer"},
+			}, timeRange...)},
+		{Method: "GET", Path: "/api/v1/history/flights", OperationID: "searchFlightHistory", Summary: "Archived flights matching a callsign pattern, with summary statistics.", Response: FlightSearchResult{}, Handler: s.HandleFlightHistory,
+			Params: append([]Param{
+				{Name: "callsign", In: "query", Type: "string", Description: "Callsign pattern; * and ? are wildcards."},
+				{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of flights."},
+			}, timeRange...)},
+		{Method: "GET", Path: "/api/v1/analytics", OperationID: "getAnalytics", Summary: "A metric of archived flights aggregated by group, as tidy rows.", Response: AnalyticsResult{}, Handler: s.HandleAnalytics,
+			Params: append([]Param{
+				{Name: "metric", In: "query", Type: "string", Description: "wait (default), holding, holdingCount, goArounds, diverted or flights."},
+				{Name: "groupby", In: "query", Type: "string", Description: "Comma-separated dimensions: runway, hour, day, outcome."},
+			}, timeRange...)},
+		{Method: "GET", Path: "/api/v1/reports/daily", OperationID: "listDailyReports", Summary: "Completed end-of-day summary reports, oldest first.", Response: []DailyReport{}, Handler: s.HandleDailyReports},
+		{Method: "GET", Path: "/api/v1/reports/daily/{date}", OperationID: "getDailyReport", Summary: "The end-of-day report for a date, or the day so far for \"today\".", Response: DailyReport{}, Handler: s.HandleDailyReport,
+			Params: []Param{
+				{Name: "date", In: "path", Type: "string", Required: true, Description: "Date as YYYY-MM-DD, or \"today\"."},
+				{Name: "format", In: "query", Type: "string", Description: "\"json\" (the default) or \"html\"."},
+			}},
+		{Method: "GET", Path: "/api/v1/history/hourly", OperationID: "listHourlyHistory", Summary: "Compacted hourly event counts.", Response: []HourlyAggregate{}, Handler: s.HandleHourlyHistory, Params: timeRange},
+		{Method: "GET", Path: "/api/v1/ground", OperationID: "listGroundMovements", Summary: "Vehicles and crossing aircraft occupying runways.", Response: []GroundMovement{}, Handler: s.HandleGround},
+		{Method: "POST", Path: "/api/v1/ground", OperationID: "addGroundMovement", Summary: "Place ground traffic on a runway.", Body: GroundMovementRequest{}, Response: GroundMovement{}, Status: http.StatusCreated, Handler: s.HandleGround},
+		{Method: "DELETE", Path: "/api/v1/ground/{id}", OperationID: "clearGroundMovement", Summary: "Vacate a runway.", Status: http.StatusNoContent, Handler: s.HandleGroundMovement,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/checkpoints", OperationID: "listCheckpoints", Summary: "Named checkpoints of the live run, oldest first.", Response: []Checkpoint{}, Handler: s.HandleCheckpoints},
+		{Method: "POST", Path: "/api/v1/checkpoints", OperationID: "takeCheckpoint", Summary: "Checkpoint the live run under a name.", Body: CheckpointRequest{}, Response: Checkpoint{}, Status: http.StatusCreated, Handler: s.HandleCheckpoints},
+		{Method: "GET", Path: "/api/v1/checkpoints/{name}", OperationID: "getCheckpoint", Summary: "A checkpoint's conditions and arrivals.", Response: Checkpoint{}, Handler: s.HandleCheckpoint,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/checkpoints/{name}", OperationID: "deleteCheckpoint", Summary: "Delete a checkpoint.", Status: http.StatusNoContent, Handler: s.HandleCheckpoint,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "POST", Path: "/api/v1/checkpoints/{name}/branch", OperationID: "branchFromCheckpoint", Summary: "Replace the current arrivals and conditions with a checkpoint's and continue from there.", Response: BranchResult{}, Handler: s.HandleBranch,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/tenants", OperationID: "listTenants", Summary: "Simulation instances running alongside the primary one.", Response: []TenantInfo{}, Handler: s.HandleTenants},
+		{Method: "POST", Path: "/api/v1/tenants", OperationID: "createTenant", Summary: "Start an independent simulation instance served under /tenants/{id}/.", Body: TenantConfig{}, Response: TenantInfo{}, Status: http.StatusCreated, Handler: s.HandleTenants},
+		{Method: "GET", Path: "/api/v1/tenants/{id}", OperationID: "getTenant", Summary: "A simulation instance.", Response: TenantInfo{}, Handler: s.HandleTenant,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/tenants/{id}", OperationID: "deleteTenant", Summary: "Stop and remove a simulation instance.", Status: http.StatusNoContent, Handler: s.HandleTenant,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/tenant-templates", OperationID: "listTenantTemplates", Summary: "Exercise environments simulation instances can be created from.", Response: []TenantTemplateView{}, Handler: s.HandleTenantTemplates},
+		{Method: "POST", Path: "/api/v1/tenant-templates", OperationID: "saveTenantTemplate", Summary: "Add or replace an exercise environment: airport, scenario, strategy and access list.", Body: TenantTemplate{}, Response: TenantTemplateView{}, Status: http.StatusCreated, Handler: s.HandleTenantTemplates},
+		{Method: "GET", Path: "/api/v1/tenant-templates/{name}", OperationID: "getTenantTemplate", Summary: "An exercise environment.", Response: TenantTemplateView{}, Handler: s.HandleTenantTemplate,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/tenant-templates/{name}", OperationID: "deleteTenantTemplate", Summary: "Delete an exercise environment; instances created from it keep running.", Status: http.StatusNoContent, Handler: s.HandleTenantTemplate,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/scenarios", OperationID: "listScenarios", Summary: "The built-in scenarios.", Response: []Scenario{}, Handler: s.HandleScenarios},
+		{Method: "GET", Path: "/api/v1/scenarios/{name}", OperationID: "getScenario", Summary: "A built-in scenario with its steps and expected outcome.", Response: Scenario{}, Handler: s.HandleScenario,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "POST", Path: "/api/v1/scenarios/{name}/start", OperationID: "startScenario", Summary: "Start a built-in scenario with optional parameter values, cancelling the rest of any running one.", Body: ScenarioStartRequest{}, Response: ScenarioRun{}, Status: http.StatusCreated, Handler: s.HandleStartScenario,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/incidents", OperationID: "listIncidents", Summary: "Active equipment failures.", Response: []Incident{}, Handler: s.HandleIncidents},
+		{Method: "POST", Path: "/api/v1/incidents", OperationID: "injectIncident", Summary: "Inject an equipment failure on a runway.", Body: IncidentRequest{}, Response: Incident{}, Status: http.StatusCreated, Handler: s.HandleIncidents},
+		{Method: "DELETE", Path: "/api/v1/incidents/{id}", OperationID: "resolveIncident", Summary: "Repair an equipment failure immediately.", Status: http.StatusNoContent, Handler: s.HandleIncident,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/visibility", OperationID: "getVisibility", Summary: "Prevailing visibility and ceiling.", Response: VisibilityState{}, Handler: s.HandleVisibility},
+		{Method: "PUT", Path: "/api/v1/visibility", OperationID: "setVisibility", Summary: "Set the prevailing visibility and ceiling.", Body: VisibilityState{}, Response: VisibilityState{}, Handler: s.HandleVisibility,
+			Params: []Param{dryRunParam}},
+		{Method: "GET", Path: "/api/v1/transitions", OperationID: "listTransitions", Summary: "Runway direction changes in progress.", Response: []RunwayTransition{}, Handler: s.HandleTransitions},
+		{Method: "GET", Path: "/api/v1/lvp", OperationID: "getLVP", Summary: "Low-visibility procedures and time spent in them.", Response: LVPState{}, Handler: s.HandleLVP},
+		{Method: "GET", Path: "/api/v1/exits", OperationID: "getExits", Summary: "Runway exits with the occupancy and capacity they allow.", Response: []RunwayExits{}, Handler: s.HandleExits},
+		{Method: "PUT", Path: "/api/v1/exits/{runway}", OperationID: "setExits", Summary: "Replace a runway's exits.", Body: []RunwayExit{}, Response: []RunwayExits{}, Handler: s.HandleRunwayExits,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/acceptance", OperationID: "getAcceptance", Summary: "The arrival acceptance gate and the demand it defers.", Response: AcceptanceStatus{}, Handler: s.HandleAcceptance},
+		{Method: "PUT", Path: "/api/v1/acceptance", OperationID: "setAcceptance", Summary: "Replace the arrival acceptance gate; a zero threshold disables it.", Body: AcceptanceGate{}, Response: AcceptanceStatus{}, Handler: s.HandleAcceptance},
+		{Method: "GET", Path: "/api/v1/pilot-latency", OperationID: "getPilotLatency", Summary: "How long pilots take to act on vectors and go-around instructions.", Response: PilotLatency{}, Handler: s.HandlePilotLatency},
+		{Method: "PUT", Path: "/api/v1/pilot-latency", OperationID: "setPilotLatency", Summary: "Replace the pilot response delays.", Body: PilotLatency{}, Response: PilotLatency{}, Handler: s.HandlePilotLatency},
+		{Method: "GET", Path: "/api/v1/holding-pattern", OperationID: "getHoldingPattern", Summary: "The holding pattern, each orbit's time and fuel, and the holding flights' progress around it.", Response: HoldingPatternStatus{}, Handler: s.HandleHoldingPattern},
+		{Method: "PUT", Path: "/api/v1/holding-pattern", OperationID: "setHoldingPattern", Summary: "Set the holding pattern's leg time and turn rate.", Body: HoldingPattern{}, Response: HoldingPatternStatus{}, Handler: s.HandleHoldingPattern},
+		{Method: "GET", Path: "/api/v1/frequency", OperationID: "getFrequency", Summary: "The radio frequency's instruction throughput and load.", Response: FrequencyStatus{}, Handler: s.HandleFrequency},
+		{Method: "PUT", Path: "/api/v1/frequency", OperationID: "setFrequency", Summary: "Limit the instructions the frequency carries per minute; zero removes the limit.", Body: Frequency{}, Response: FrequencyStatus{}, Handler: s.HandleFrequency},
+		{Method: "GET", Path: "/api/v1/transcript", OperationID: "getTranscript", Summary: "Every instruction issued, in controller phraseology, in the order spoken.", Response: []Instruction{}, Handler: s.HandleTranscript,
+			Params: []Param{{Name: "flight", In: "query", Type: "integer", Description: "Only instructions to this flight."}, {Name: "format", In: "query", Type: "string", Description: "json (the default) or text, one line per instruction."}}},
+		{Method: "GET", Path: "/api/v1/conflicts", OperationID: "listConflicts", Summary: "Recent spacing conflicts with the flights involved.", Response: []Conflict{}, Handler: s.HandleConflicts,
+			Params: []Param{{Name: "limit", In: "query", Type: "integer"}}},
+		{Method: "GET", Path: "/api/v1/runways", OperationID: "listRunways", Summary: "Runway definitions in assignment order.", Response: []RunwayDefinition{}, Handler: s.HandleRunways},
+		{Method: "POST", Path: "/api/v1/runways", OperationID: "addRunway", Summary: "Commission a runway without restarting.", Body: RunwayDefinition{}, Response: RunwayDefinition{}, Status: http.StatusCreated, Handler: s.HandleRunways},
+		{Method: "GET", Path: "/api/v1/runways/{runway}", OperationID: "getRunway", Summary: "A runway's state, queue and statistics: landings by direction, takeoffs, average occupancy, idle time and utilization.", Response: RunwaySnapshot{}, Handler: s.HandleRunway,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/runways/{runway}", OperationID: "removeRunway", Summary: "Decommission a runway, sending its arrivals to holding.", Status: http.StatusNoContent, Handler: s.HandleRunway,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}, dryRunParam}},
+		{Method: "GET", Path: "/api/v1/preferences", OperationID: "getPreferences", Summary: "How favored each runway is for arrivals.", Response: []RunwayPreference{}, Handler: s.HandlePreferences},
+		{Method: "PUT", Path: "/api/v1/preferences/{runway}", OperationID: "setPreference", Summary: "Make a runway preferred, secondary or last-resort.", Body: RunwayPreference{}, Response: []RunwayPreference{}, Handler: s.HandleRunwayPreference,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/timing", OperationID: "getTimings", Summary: "Arrival spacing and landing time of each runway.", Response: []RunwayTiming{}, Handler: s.HandleTimings},
+		{Method: "PUT", Path: "/api/v1/timing/{runway}", OperationID: "setTiming", Summary: "Set a runway's arrival spacing and landing time, overall and per weight category.", Body: RunwayTiming{}, Response: []RunwayTiming{}, Handler: s.HandleRunwayTiming,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/timing/{runway}", OperationID: "resetTiming", Summary: "Return a runway to the default arrival timing.", Status: http.StatusNoContent, Handler: s.HandleRunwayTiming,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/shortening", OperationID: "listShortenings", Summary: "Runways temporarily shortened.", Response: []RunwayShortening{}, Handler: s.HandleShortenings},
+		{Method: "PUT", Path: "/api/v1/shortening/{runway}", OperationID: "shortenRunway", Summary: "Close part of a runway at one end.", Body: RunwayShortening{}, Response: RunwayShortening{}, Handler: s.HandleShortening,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/shortening/{runway}", OperationID: "restoreRunway", Summary: "Restore a shortened runway to full length.", Status: http.StatusNoContent, Handler: s.HandleShortening,
+			Params: []Param{{Name: "runway", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/parallel", OperationID: "getParallelApproaches", Summary: "Parallel approach mode and the runways it couples.", Response: ParallelApproaches{}, Handler: s.HandleParallel},
+		{Method: "PUT", Path: "/api/v1/parallel", OperationID: "setParallelApproaches", Summary: "Select independent, dependent or single-stream parallel approaches.", Body: ParallelApproaches{}, Response: ParallelApproaches{}, Handler: s.HandleParallel},
+		{Method: "GET", Path: "/api/v1/friction", OperationID: "getFrictionTests", Summary: "Friction test schedule, reserved gaps and latest readings.", Response: FrictionState{}, Handler: s.HandleFriction},
+		{Method: "PUT", Path: "/api/v1/friction", OperationID: "setFrictionTests", Summary: "Schedule periodic friction tests slotted into gaps in the landing sequence.", Body: FrictionTests{}, Response: FrictionState{}, Handler: s.HandleFriction},
+		{Method: "GET", Path: "/api/v1/wildlife", OperationID: "getWildlifeHazard", Summary: "Wildlife hazard level now, by hour, and recent dispersal closures.", Response: WildlifeState{}, Handler: s.HandleWildlife},
+		{Method: "PUT", Path: "/api/v1/wildlife", OperationID: "setWildlifeHazard", Summary: "Set the wildlife hazard level by hour of day or override it.", Body: WildlifeHazard{}, Response: WildlifeState{}, Handler: s.HandleWildlife},
+		{Method: "GET", Path: "/api/v1/atis", OperationID: "getAtis", Summary: "The current ATIS information: runways in use, weather, bird activity and wildlife dispersal closures.", Response: ATIS{}, Handler: s.HandleATIS},
+		{Method: "GET", Path: "/api/v1/atmosphere", OperationID: "getAtmosphere", Summary: "Temperature, pressure, density altitude and the runways each weight category can use.", Response: AtmosphereState{}, Handler: s.HandleAtmosphere},
+		{Method: "PUT", Path: "/api/v1/atmosphere", OperationID: "setAtmosphere", Summary: "Set the temperature, altimeter setting and field elevation.", Body: Atmosphere{}, Response: AtmosphereState{}, Handler: s.HandleAtmosphere},
+		{Method: "GET", Path: "/api/v1/geography", OperationID: "getGeography", Summary: "Airport reference point, magnetic variation, map tiles and runway threshold positions, with each runway's bearing and length.", Response: GeographyState{}, Handler: s.HandleGeography},
+		{Method: "GET", Path: "/api/v1/storms", OperationID: "getStorms", Summary: "Thunderstorm cells at their current positions and the approaches they affect.", Response: StormState{}, Handler: s.HandleStorms},
+		{Method: "POST", Path: "/api/v1/storms", OperationID: "addStormCell", Summary: "Place a moving thunderstorm cell.", Body: StormCell{}, Response: StormCell{}, Status: http.StatusCreated, Handler: s.HandleStorms},
+		{Method: "DELETE", Path: "/api/v1/storms/{id}", OperationID: "removeStormCell", Summary: "Remove a thunderstorm cell.", Status: http.StatusNoContent, Handler: s.HandleStormCell,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/storms/stream", OperationID: "streamStorms", Summary: "Thunderstorm cell positions for map display.", Stream: StreamSSE, Handler: s.HandleStormStream},
+		{Method: "GET", Path: "/api/v1/positions", OperationID: "getPositions", Summary: "Sequenced arrivals' positions on their approaches.", Response: PositionState{}, Handler: s.HandlePositions},
+		{Method: "GET", Path: "/api/v1/positions/stream", OperationID: "streamPositions", Summary: "Arrival positions for map display, several times a second.", Stream: StreamSSE, Handler: s.HandlePositionStream},
+		{Method: "POST", Path: "/api/v1/positions/webrtc", OperationID: "answerPositionOffer", Summary: "Answer a WebRTC offer for the experimental unordered, unreliable position data channel, negotiated as \"positions\" with ID 0.", Body: SessionDescription{}, Response: SessionDescription{}, Handler: s.HandlePositionOffer},
+		{Method: "GET", Path: "/api/v1/aman", OperationID: "getTimeline", Summary: "Arrival manager landing ladder per runway.", Response: Timeline{}, Handler: s.HandleTimeline},
+		{Method: "GET", Path: "/api/v1/aman/stream", OperationID: "streamTimeline", Summary: "Arrival manager timeline updates.", Stream: StreamSSE, Handler: s.HandleTimelineStream},
+		{Method: "GET", Path: "/api/v1/delays", OperationID: "getDelayReport", Summary: "Delay attributed to each cause.", Response: DelayReport{}, Handler: s.HandleDelays},
+		{Method: "GET", Path: "/api/v1/recovery", OperationID: "getRecoveryPlan", Summary: "Catch-up plan for the current backlog: a temporary landing rate boost and longest-delayed flights first.", Response: RecoveryPlan{}, Handler: s.HandleRecovery},
+		{Method: "POST", Path: "/api/v1/recovery", OperationID: "applyRecoveryPlan", Summary: "Put a fresh recovery plan into effect.", Response: RecoveryPlan{}, Handler: s.HandleRecovery},
+		{Method: "GET", Path: "/api/v1/emissions", OperationID: "getEmissionsReport", Summary: "Fuel burn and CO2 from holding and vectoring.", Response: EmissionsReport{}, Handler: s.HandleEmissions},
+		{Method: "GET", Path: "/api/v1/noise", OperationID: "getNoiseReport", Summary: "Noise exposure per compass sector from runway usage so far.", Response: NoiseReport{}, Handler: s.HandleNoise},
+		{Method: "POST", Path: "/api/v1/noise", OperationID: "estimateNoise", Summary: "Noise exposure for a hypothetical runway usage.", Body: []RunwayUsage{}, Response: NoiseReport{}, Handler: s.HandleNoise},
+		{Method: "GET", Path: "/api/v1/invoices", OperationID: "getInvoices", Summary: "Landing fees billed per airline.", Response: InvoiceReport{}, Handler: s.HandleInvoices},
+		{Method: "GET", Path: "/api/v1/quotas", OperationID: "getQuotaReport", Summary: "Share of peak arrival slots each airline received against its quota.", Response: QuotaReport{}, Handler: s.HandleQuotas},
+		{Method: "GET", Path: "/api/v1/auction", OperationID: "getAuction", Summary: "Slot auction agents and recent clearing rounds.", Response: AuctionReport{}, Handler: s.HandleAuction},
+		{Method: "PUT", Path: "/api/v1/auction", OperationID: "setAuctionAgents", Summary: "Replace the airline agents bidding in the slot auction.", Body: []AirlineAgent{}, Response: AuctionReport{}, Handler: s.HandleAuction},
+		{Method: "GET", Path: "/api/v1/gates", OperationID: "getGates", Summary: "Stand occupancy and utilization.", Response: GateReport{}, Handler: s.HandleGates},
+		{Method: "GET", Path: "/api/v1/turnarounds", OperationID: "getTurnarounds", Summary: "Aircraft being turned around, earliest scheduled off-block first.", Response: []Turnaround{}, Handler: s.HandleTurnarounds},
+		{Method: "GET", Path: "/api/v1/airframes", OperationID: "getAirframes", Summary: "Airframes and the legs they have flown, most legs first.", Response: []AirframeStatus{}, Handler: s.HandleAirframes},
+		{Method: "GET", Path: "/api/v1/departures", OperationID: "getDepartures", Summary: "Aircraft taxiing out and queued at runway holding points.", Response: DepartureState{}, Handler: s.HandleDepartures},
+		{Method: "GET", Path: "/api/v1/winter", OperationID: "getWinterOps", Summary: "Winter operations mode and de-icing pad usage.", Response: WinterOpsState{}, Handler: s.HandleWinterOps},
+		{Method: "PUT", Path: "/api/v1/winter", OperationID: "setWinterOps", Summary: "Configure winter operations and precipitation.", Body: WinterOps{}, Response: WinterOpsState{}, Handler: s.HandleWinterOps},
+		{Method: "GET", Path: "/api/v1/rules", OperationID: "listRules", Summary: "Configured automation rules.", Response: []Rule{}, Handler: s.HandleRules},
+		{Method: "POST", Path: "/api/v1/rules", OperationID: "createRule", Summary: "Add or replace a rule.", Body: Rule{}, Response: Rule{}, Status: http.StatusCreated, Handler: s.HandleRules},
+		{Method: "DELETE", Path: "/api/v1/rules/{id}", OperationID: "deleteRule", Summary: "Remove a rule.", Status: http.StatusNoContent, Handler: s.HandleRule,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/metrics/stream.csv", OperationID: "streamMetricsCSV", Summary: "Metrics as CSV, one row per interval.", Stream: StreamCSV, Handler: s.HandleMetricsCSV,
+			Params: []Param{
+				{Name: "interval", In: "query", Type: "string", Description: "Row interval as a Go duration, \"1s\" by default."},
+				{Name: "rows", In: "query", Type: "integer", Description: "End the stream after this many rows."},
+			}},
+		{Method: "GET", Path: "/api/v1/metrics/prometheus", OperationID: "getPrometheusMetrics", Summary: "Metrics, including operator-defined counters and gauges, in the Prometheus text format.", Stream: StreamPrometheus, Handler: s.HandlePrometheus},
+		{Method: "GET", Path: "/api/v1/metrics/custom", OperationID: "listCustomMetrics", Summary: "Operator-defined counters and gauges.", Response: []CustomMetric{}, Handler: s.HandleCustomMetrics},
+		{Method: "POST", Path: "/api/v1/metrics/custom", OperationID: "createCustomMetric", Summary: "Add or replace a custom metric.", Body: CustomMetric{}, Response: CustomMetric{}, Status: http.StatusCreated, Handler: s.HandleCustomMetrics},
+		{Method: "DELETE", Path: "/api/v1/metrics/custom/{name}", OperationID: "deleteCustomMetric", Summary: "Remove a custom metric.", Status: http.StatusNoContent, Handler: s.HandleCustomMetric,
+			Params: []Param{{Name: "name", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/rules/firings", OperationID: "listRuleFirings", Summary: "Recent rule executions.", Response: []RuleFiring{}, Handler: s.HandleRuleFirings},
+		{Method: "GET", Path: "/api/v1/slas", OperationID: "listSLAs", Summary: "Service level agreements and their attainment.", Response: []SLAStatus{}, Handler: s.HandleSLAs},
+		{Method: "POST", Path: "/api/v1/slas", OperationID: "createSLA", Summary: "Add or replace a service level agreement.", Body: SLA{}, Response: SLA{}, Status: http.StatusCreated, Handler: s.HandleSLAs},
+		{Method: "DELETE", Path: "/api/v1/slas/{id}", OperationID: "deleteSLA", Summary: "Remove a service level agreement.", Status: http.StatusNoContent, Handler: s.HandleSLA,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "POST", Path: "/api/v1/batch", OperationID: "applyBatch", Summary: "Apply rate, runway, wind, visibility and burst commands together, all or nothing.", Body: BatchRequest{}, Response: BatchResult{}, Handler: s.HandleBatch,
+			Params: []Param{dryRunParam}},
+		{Method: "POST", Path: "/api/v1/burst", OperationID: "spawnBurst", Summary: "Spawn count flights at once, routed through normal runway assignment.", Response: BurstResult{}, Handler: s.HandleBurst,
+			Params: []Param{{Name: "count", In: "query", Type: "integer", Required: true}, dryRunParam}},
+		{Method: "GET", Path: "/api/v1/commands", OperationID: "listScheduledCommands", Summary: "Commands queued to run at a later simulation time, soonest first.", Response: []ScheduledCommand{}, Handler: s.HandleCommands},
+		{Method: "POST", Path: "/api/v1/commands", OperationID: "scheduleCommand", Summary: "Queue a rate, runway, wind, visibility or burst command to run at executeAt.", Body: ScheduledCommand{}, Response: ScheduledCommand{}, Status: http.StatusCreated, Handler: s.HandleCommands},
+		{Method: "DELETE", Path: "/api/v1/commands/{id}", OperationID: "cancelScheduledCommand", Summary: "Cancel a scheduled command.", Status: http.StatusNoContent, Handler: s.HandleCommand,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/confirmations", OperationID: "listConfirmations", Summary: "High-impact actions awaiting a second controller.", Response: []PendingAction{}, Handler: s.HandleConfirmations},
+		{Method: "POST", Path: "/api/v1/confirmations/{id}", OperationID: "confirmAction", Summary: "Confirm and apply a pending action as a second controller.", Response: PendingAction{}, Handler: s.HandleConfirmation,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "DELETE", Path: "/api/v1/confirmations/{id}", OperationID: "rejectAction", Summary: "Reject a pending action.", Status: http.StatusNoContent, Handler: s.HandleConfirmation,
+			Params: []Param{{Name: "id", In: "path", Type: "string", Required: true}}},
+		{Method: "GET", Path: "/api/v1/anomalies", OperationID: "listAnomalies", Summary: "Recent metric anomalies.", Response: []Anomaly{}, Handler: s.HandleAnomalies},
+	}
+}
+
+// Register installs every route on mux. Responses other than streams
+// carry the state version as their ETag; mutating routes honor the
+// If-Match and Idempotency-Key headers.
+func (s *Server) Register(mux *http.ServeMux) {
+	for _, rt := range s.Routes() {
+		handler := rt.Handler
+		if rt.Stream == "" {
+			handler = s.versioned(handler)
+		}
+		if rt.Method != http.MethodGet {
+			handler = s.idempotent(handler)
+		}
+		mux.HandleFunc(rt.Pattern(), handler)
+	}
+}
+
+// OpenAPIDocument is an OpenAPI 3.0 description of the REST API.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo carries the document title and version.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIComponents holds the shared schema definitions.
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// OpenAPIOperation is a single method on a path. Stream operations carry
+// their kind in the x-stream extension and have no generated client method.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Stream      string                      `json:"x-stream,omitempty"`
+}
+
+// OpenAPIParameter is a query or path parameter.
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// OpenAPIBody is a JSON request body.
+type OpenAPIBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]OpenAPIMedia `json:"content"`
+}
+
+// OpenAPIResponse is the documented response for a status code.
+type OpenAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]OpenAPIMedia `json:"content,omitempty"`
+}
+
+// OpenAPIMedia binds a schema to a content type.
+type OpenAPIMedia struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPI builds the document for the supplied routes.
+func OpenAPI(routes []Route) *OpenAPIDocument {
+	schemas := newSchemaSet("#/components/schemas/")
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: "AirCommand", Version: APIVersion},
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	for _, rt := range routes {
+		op := &OpenAPIOperation{OperationID: rt.OperationID, Summary: rt.Summary, Stream: rt.Stream, Responses: make(map[string]*OpenAPIResponse)}
+		for _, p := range rt.Params {
+			schema := &Schema{Type: p.Type}
+			if p.Type == "date-time" {
+				schema = &Schema{Type: "string", Format: "date-time"}
+			}
+			op.Parameters = append(op.Parameters, OpenAPIParameter{Name: p.Name, In: p.In, Required: p.Required, Description: p.Description, Schema: schema})
+		}
+		if rt.Body != nil {
+			op.RequestBody = &OpenAPIBody{Required: true, Content: map[string]OpenAPIMedia{
+				"application/json": {Schema: schemas.of(reflect.TypeOf(rt.Body))},
+			}}
+		}
+		status := rt.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := &OpenAPIResponse{Description: http.StatusText(status)}
+		switch {
+		case rt.Stream == StreamWebSocket:
+			status = http.StatusSwitchingProtocols
+			resp.Description = "WebSocket upgrade"
+		case rt.Stream == StreamSSE:
+			resp.Content = map[string]OpenAPIMedia{"text/event-stream": {Schema: &Schema{Type: "string"}}}
+		case rt.Stream == StreamCSV:
+			resp.Content = map[string]OpenAPIMedia{"text/csv": {Schema: &Schema{Type: "string"}}}
+		case rt.Stream == StreamPrometheus:
+			resp.Content = map[string]OpenAPIMedia{"text/plain": {Schema: &Schema{Type: "string"}}}
+		case rt.Response != nil:
+			resp.Content = map[string]OpenAPIMedia{"application/json": {Schema: schemas.of(reflect.TypeOf(rt.Response))}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+
+		if doc.Paths[rt.Path] == nil {
+			doc.Paths[rt.Path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[rt.Path][strings.ToLower(rt.Method)] = op
+	}
+	doc.Components.Schemas = schemas.defs
+	return doc
+}
+
+// HandleOpenAPI serves the OpenAPI document generated from the route table.
+func (s *Server) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPI(s.Routes()))
+}
@@ -0,0 +1,116 @@
+package control
+
+import "log"
+
+// Approach categories, from least to most capable. Runways declare the best
+// approach they support and flights the best they are certified for.
+const (
+	ApproachVisual = "visual"
+	ApproachCATI   = "CAT I"
+	ApproachCATII  = "CAT II"
+	ApproachCATIII = "CAT III"
+)
+
+// approachCategories lists the categories in ascending capability with the
+// lowest visibility, in meters, at which each still permits a landing.
+var approachCategories = []struct {
+	name    string
+	minimum int64
+}{
+	{ApproachVisual, 5000},
+	{ApproachCATI, 550},
+	{ApproachCATII, 300},
+	{ApproachCATIII, 75},
+}
+
+// approachRank orders categories by capability. An empty category means
+// CAT I, the common baseline for both runways and airliners.
+func approachRank(category string) int {
+	if category == "" {
+		category = ApproachCATI
+	}
+	for i, c := range approachCategories {
+		if c.name == category {
+			return i
+		}
+	}
+	log.Printf("unknown approach category %q; treating as %s", category, ApproachCATI)
+	return 1
+}
+
+// ValidApproach reports whether category is a known approach category.
+func ValidApproach(category string) bool {
+	for _, c := range approachCategories {
+		if c.name == category {
+			return true
+		}
+	}
+	return category == ""
+}
+
+// withinMinimaLocked reports whether f may land on runway at the current
+// visibility. The approach flown is the lesser of what the runway and the
+// aircraft support.
+func (rm *RunwayManager) withinMinimaLocked(runway string, f Flight) bool {
+	return rm.visibility >= rm.minimumLocked(runway, f)
+}
+
+// minimumLocked is the least visibility in meters f may land on runway in.
+func (rm *RunwayManager) minimumLocked(runway string, f Flight) int64 {
+	rank := min(approachRank(rm.runways[runway].definition.Approach), approachRank(flightApproach(f)))
+	return approachCategories[rank].minimum
+}
+
+// enforceMinimaLocked sends flights that can no longer land at the current
+// visibility from their runway queues to holding, as missed approaches.
+func (rm *RunwayManager) enforceMinimaLocked() {
+	for _, name := range rm.order {
+		if missed := rm.missApproachesLocked(name, rm.withinMinimaLocked, DelayWeather, "missed approach: below minima"); missed > 0 {
+			log.Printf("visibility %dm below minima on %s; %d flights to holding", rm.visibility, name, missed)
+		}
+	}
+}
+
+// missApproachesLocked sends the flights in runway's queue that fail can
+// from the queue to holding, delayed by cause, and returns how many it sent.
+func (rm *RunwayManager) missApproachesLocked(runway string, can func(string, Flight) bool, cause, detail string) int {
+	queue := rm.assigned[runway]
+	kept := queue[:0]
+	var missed []Flight
+	for _, f := range queue {
+		if can(runway, f) {
+			kept = append(kept, f)
+		} else {
+			missed = append(missed, f)
+		}
+	}
+	if len(missed) == 0 {
+		return 0
+	}
+	rm.assigned[runway] = kept
+	for _, f := range missed {
+		rm.logDecisionLocked(DecisionHold, f, "")
+		rm.startDelayLocked(f, cause)
+		rm.cancelGateLocked(f.ID)
+		delete(rm.assignedAt, f.ID)
+		rm.setPhaseLocked(f, PhaseHolding, runway)
+		rm.publishEventLocked(Event{Type: EventHolding, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: detail})
+	}
+	rm.holding = append(rm.holding, missed...)
+	rm.publishQueuesLocked(runway)
+	rm.recordHoldingLocked(len(missed))
+	rm.publishHoldingLocked()
+	return len(missed)
+}
+
+// defaultApproach gives generated flights a deterministic mix of approach
+// certifications: half CAT I, a quarter each CAT II and CAT III.
+func defaultApproach(id int64) string {
+	switch id % 4 {
+	case 1:
+		return ApproachCATII
+	case 2:
+		return ApproachCATIII
+	}
+	return ApproachCATI
+}
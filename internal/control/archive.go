@@ -0,0 +1,235 @@
+package control
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 500
+	maxHistoryLimit     = 5000
+)
+
+// EventArchive persists the event history into a SQL database. Postgres and
+// SQLite are supported; the matching database/sql driver must be linked into
+// the binary. "sqlite" also opens a driver registered as "sqlite3", as
+// github.com/mattn/go-sqlite3 is.
+type EventArchive struct {
+	db     *sql.DB
+	driver string
+}
+
+// HistoryQuery filters archived events. Zero values match everything.
+type HistoryQuery struct {
+	FlightID int64
+	Type     string
+	Runway   string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// OpenEventArchive connects to the database and ensures the schema exists.
+func OpenEventArchive(driver, dsn string) (*EventArchive, error) {
+	name := driver
+	if drivers := sql.Drivers(); driver == "sqlite" && !slices.Contains(drivers, "sqlite") && slices.Contains(drivers, "sqlite3") {
+		name = "sqlite3"
+	}
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect archive: %w", err)
+	}
+	if driver == "sqlite" {
+		// SQLite locks the whole file for a write; a single connection
+		// queues history queries behind the archiver instead of failing
+		// them as locked.
+		db.SetMaxOpenConns(1)
+	}
+	a := &EventArchive{db: db, driver: driver}
+	schema := []string{
+		`CREATE TABLE IF NOT EXISTS events (
+			seq BIGINT NOT NULL,
+			type TEXT NOT NULL,
+			at_ms BIGINT NOT NULL,
+			flight_id BIGINT NOT NULL DEFAULT 0,
+			callsign TEXT NOT NULL DEFAULT '',
+			runway TEXT NOT NULL DEFAULT '',
+			detail TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS events_at_idx ON events (at_ms)`,
+		`CREATE INDEX IF NOT EXISTS events_flight_idx ON events (flight_id)`,
+		`CREATE TABLE IF NOT EXISTS event_hourly (
+			hour_ms BIGINT NOT NULL,
+			type TEXT NOT NULL,
+			runway TEXT NOT NULL DEFAULT '',
+			count BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS event_hourly_hour_idx ON event_hourly (hour_ms)`,
+	}
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("create archive schema: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// Run archives every event published on the bus until the context is
+// canceled. Events the database has yet to take queue rather than being
+// dropped, so the archive stays a complete history under load.
+func (a *EventArchive) Run(ctx context.Context, bus *EventBus) {
+	events, unsubscribe := bus.SubscribeAll()
+	defer unsubscribe()
+	defer a.db.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			if err := a.Insert(e); err != nil {
+				log.Printf("archive event %d: %v", e.Seq, err)
+			}
+		}
+	}
+}
+
+// Insert stores a single event.
+func (a *EventArchive) Insert(e Event) error {
+	query := a.rebind(`INSERT INTO events (seq, type, at_ms, flight_id, callsign, runway, detail) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	_, err := a.db.Exec(query, e.Seq, e.Type, e.Time.UnixMilli(), e.FlightID, e.Call, e.Runway, e.Detail)
+	return err
+}
+
+// Query returns archived events matching q in chronological order.
+func (a *EventArchive) Query(q HistoryQuery) ([]Event, error) {
+	var where []string
+	var args []any
+	if q.FlightID != 0 {
+		where = append(where, "flight_id = ?")
+		args = append(args, q.FlightID)
+	}
+	if q.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, q.Type)
+	}
+	if q.Runway != "" {
+		where = append(where, "runway = ?")
+		args = append(args, q.Runway)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "at_ms >= ?")
+		args = append(args, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		where = append(where, "at_ms <= ?")
+		args = append(args, q.To.UnixMilli())
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	query := "SELECT seq, type, at_ms, flight_id, callsign, runway, detail FROM events"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY at_ms, seq LIMIT " + strconv.Itoa(limit)
+
+	rows, err := a.db.Query(a.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		var atMillis int64
+		if err := rows.Scan(&e.Seq, &e.Type, &atMillis, &e.FlightID, &e.Call, &e.Runway, &e.Detail); err != nil {
+			return nil, err
+		}
+		e.Time = time.UnixMilli(atMillis)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// rebind rewrites ? placeholders into $n form for Postgres.
+func (a *EventArchive) rebind(query string) string {
+	if a.driver != "postgres" && a.driver != "pgx" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// HandleHistory serves archived events filtered by flight, type, runway and
+// time range, e.g. /api/v1/history?type=conflict&from=...&to=...
+func (s *Server) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.Archive == nil {
+		http.Error(w, "event archive disabled", http.StatusServiceUnavailable)
+		return
+	}
+	params := r.URL.Query()
+	q := HistoryQuery{Type: params.Get("type"), Runway: params.Get("runway")}
+	var err error
+	if raw := params.Get("flight"); raw != "" {
+		if q.FlightID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			http.Error(w, "invalid flight", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("from"); raw != "" {
+		if q.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid from (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("to"); raw != "" {
+		if q.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid to (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("limit"); raw != "" {
+		if q.Limit, err = strconv.Atoi(raw); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := s.Archive.Query(q)
+	if err != nil {
+		log.Printf("history query: %v", err)
+		http.Error(w, "history query failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		log.Printf("encode history: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package control
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// archiveBackends opens an archive on every backend available: SQLite
+// always, and Postgres when AIRCOMMAND_TEST_POSTGRES holds a DSN. The
+// Postgres events table is emptied first.
+func archiveBackends(t *testing.T) map[string]*EventArchive {
+	t.Helper()
+	backends := make(map[string]*EventArchive)
+	sqlite, err := OpenEventArchive("sqlite", filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("open sqlite archive: %v", err)
+	}
+	backends["sqlite"] = sqlite
+	if dsn := os.Getenv("AIRCOMMAND_TEST_POSTGRES"); dsn != "" {
+		pg, err := OpenEventArchive("postgres", dsn)
+		if err != nil {
+			t.Fatalf("open postgres archive: %v", err)
+		}
+		if _, err := pg.db.Exec(`DELETE FROM events`); err != nil {
+			t.Fatalf("empty postgres archive: %v", err)
+		}
+		backends["postgres"] = pg
+	}
+	return backends
+}
+
+func archivedCount(t *testing.T, a *EventArchive) int {
+	t.Helper()
+	var n int
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&n); err != nil {
+		t.Fatalf("count archived events: %v", err)
+	}
+	return n
+}
+
+func TestEventArchiveQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Seq: 1, Type: EventSpawned, Time: start, FlightID: 7, Call: "BAW7"},
+		{Seq: 2, Type: EventAssigned, Time: start.Add(time.Second), FlightID: 7, Call: "BAW7", Runway: "27L", Detail: "heading 270"},
+		{Seq: 3, Type: EventRunwayClosed, Time: start.Add(2 * time.Second), Runway: "27R"},
+		{Seq: 4, Type: EventLanded, Time: start.Add(time.Minute), FlightID: 7, Call: "BAW7", Runway: "27L"},
+	}
+	for name, a := range archiveBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			defer a.db.Close()
+			for _, e := range events {
+				if err := a.Insert(e); err != nil {
+					t.Fatalf("insert %d: %v", e.Seq, err)
+				}
+			}
+			tests := []struct {
+				name string
+				q    HistoryQuery
+				want []int64
+			}{
+				{"all", HistoryQuery{}, []int64{1, 2, 3, 4}},
+				{"flight", HistoryQuery{FlightID: 7}, []int64{1, 2, 4}},
+				{"type", HistoryQuery{Type: EventRunwayClosed}, []int64{3}},
+				{"runway", HistoryQuery{Runway: "27L"}, []int64{2, 4}},
+				{"range", HistoryQuery{From: start.Add(time.Second), To: start.Add(2 * time.Second)}, []int64{2, 3}},
+				{"limit", HistoryQuery{Limit: 2}, []int64{1, 2}},
+			}
+			for _, tt := range tests {
+				got, err := a.Query(tt.q)
+				if err != nil {
+					t.Fatalf("%s: %v", tt.name, err)
+				}
+				seqs := make([]int64, len(got))
+				for i, e := range got {
+					seqs[i] = e.Seq
+				}
+				if len(seqs) != len(tt.want) {
+					t.Fatalf("%s: got events %v, want %v", tt.name, seqs, tt.want)
+				}
+				for i := range seqs {
+					if seqs[i] != tt.want[i] {
+						t.Fatalf("%s: got events %v, want %v", tt.name, seqs, tt.want)
+					}
+				}
+			}
+			got, err := a.Query(HistoryQuery{Type: EventAssigned})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e := got[0]; e.Call != "BAW7" || e.Runway != "27L" || e.Detail != "heading 270" || !e.Time.Equal(start.Add(time.Second)) {
+				t.Errorf("assigned event round-tripped as %+v", e)
+			}
+		})
+	}
+}
+
+// TestEventArchiveLossless publishes a burst far larger than any
+// subscriber buffer and checks every event reaches the database.
+func TestEventArchiveLossless(t *testing.T) {
+	const burst = eventBufferSize * 16
+	for name, a := range archiveBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			bus := NewEventBus()
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				a.Run(ctx, bus)
+				close(done)
+			}()
+			defer func() {
+				cancel()
+				<-done
+			}()
+			// Run subscribes asynchronously; wait until it has.
+			for deadline := time.Now().Add(5 * time.Second); ; {
+				if bus.subscribers() > 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("archive never subscribed")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			for i := 0; i < burst; i++ {
+				bus.Publish(Event{Type: EventSpawned, FlightID: int64(i + 1)})
+			}
+			var n int
+			for deadline := time.Now().Add(time.Minute); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				if n = archivedCount(t, a); n == burst {
+					break
+				}
+			}
+			if n != burst {
+				t.Fatalf("archived %d of %d events", n, burst)
+			}
+			if dropped := bus.Dropped(); dropped != 0 {
+				t.Errorf("bus dropped %d events", dropped)
+			}
+		})
+	}
+}
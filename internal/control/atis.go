@@ -0,0 +1,91 @@
+package control
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ATIS is the automatic terminal information service broadcast: the
+// runways in use, the weather and the hazards pilots should know of, under
+// a letter advanced whenever any of it changes.
+type ATIS struct {
+	Letter string    `json:"letter"`
+	Time   time.Time `json:"time"`
+	// Runways are the runway ends in use and Closed the runways otherwise
+	// not available.
+	Runways []string `json:"runways"`
+	Closed  []string `json:"closed"`
+	// Wind is in degrees magnetic, as the tower reports it.
+	Wind       WindState `json:"wind"`
+	Visibility int64     `json:"visibility"`
+	// Wildlife is the bird activity level, and Dispersals the runways
+	// closed for wildlife dispersal now.
+	Wildlife   string   `json:"wildlife"`
+	Dispersals []string `json:"dispersals"`
+	Text       string   `json:"text"`
+}
+
+// ATIS compiles the current information, advancing its letter if it
+// changed since it was last compiled.
+func (rm *RunwayManager) ATIS() ATIS {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := rm.clock.Now()
+	atis := ATIS{
+		Time:       now,
+		Runways:    []string{},
+		Closed:     []string{},
+		Wind:       rm.magneticWindLocked(),
+		Visibility: rm.visibility,
+		Wildlife:   rm.wildlifeLevelLocked(),
+		Dispersals: []string{},
+	}
+	dispersing := rm.dispersingLocked()
+	for _, name := range rm.order {
+		switch {
+		case rm.runways[name].available():
+			atis.Runways = append(atis.Runways, rm.activeEndLocked(name))
+		case slices.Contains(dispersing, name):
+			atis.Dispersals = append(atis.Dispersals, rm.runwayEndsLocked(name))
+		default:
+			atis.Closed = append(atis.Closed, rm.runwayEndsLocked(name))
+		}
+	}
+
+	var body []string
+	if len(atis.Runways) > 0 {
+		body = append(body, "landing runway "+strings.Join(atis.Runways, ", "))
+	}
+	for _, name := range atis.Closed {
+		body = append(body, "runway "+name+" closed")
+	}
+	body = append(body, "wind "+windDetail(atis.Wind), fmt.Sprintf("visibility %d meters", atis.Visibility))
+	if atis.Wildlife != WildlifeNone {
+		body = append(body, atis.Wildlife+" bird activity in the vicinity of the airport")
+	}
+	for _, name := range atis.Dispersals {
+		body = append(body, "runway "+name+" closed for wildlife dispersal")
+	}
+	text := strings.Join(body, ". ")
+	if text != rm.atisBody {
+		if rm.atisBody != "" {
+			rm.atisLetter = (rm.atisLetter + 1) % 26
+		}
+		rm.atisBody = text
+	}
+	atis.Letter = string(rune('A' + rm.atisLetter))
+	atis.Text = fmt.Sprintf("Information %s, %s. %s.", atis.Letter, now.UTC().Format("1504Z"), text)
+	return atis
+}
+
+// HandleATIS serves the current ATIS information.
+func (s *Server) HandleATIS(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.ATIS())
+}
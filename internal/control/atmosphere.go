@@ -0,0 +1,171 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// EventAtmosphereChanged is published when the temperature or pressure is
+// updated.
+const EventAtmosphereChanged = "atmosphereChanged"
+
+const (
+	// standardPressure and standardTemperature are the ISA sea level
+	// pressure in hPa and temperature in °C.
+	standardPressure    = 1013.25
+	standardTemperature = 15.0
+	// landingPerThousand and takeoffPerThousand are how much longer the
+	// landing and takeoff distances get per 1000 ft of density altitude.
+	landingPerThousand = 0.04
+	takeoffPerThousand = 0.1
+)
+
+// takeoffDistances is the takeoff distance, in meters, each weight category
+// needs at sea level in standard conditions.
+var takeoffDistances = map[string]float64{
+	WeightLight:  500,
+	WeightMedium: 1700,
+	WeightHeavy:  2500,
+	WeightSuper:  2900,
+}
+
+// Atmosphere is the temperature and altimeter setting at an airfield
+// Elevation feet above sea level. Hot, high or low-pressure days raise the
+// density altitude, lengthening the runway aircraft need and slowing their
+// climb out.
+type Atmosphere struct {
+	TemperatureC float64 `json:"temperatureC"`
+	QNH          float64 `json:"qnh"`
+	Elevation    float64 `json:"elevation"`
+}
+
+// standardAtmosphere is ISA at sea level.
+var standardAtmosphere = Atmosphere{TemperatureC: standardTemperature, QNH: standardPressure}
+
+// DensityAltitude is the altitude, in feet, in the standard atmosphere at
+// which the air is as thin as in a.
+func (a Atmosphere) DensityAltitude() float64 {
+	pressureAltitude := a.Elevation + (standardPressure-a.QNH)*30
+	isa := standardTemperature - 2*pressureAltitude/1000
+	return pressureAltitude + 120*(a.TemperatureC-isa)
+}
+
+// performanceFactor scales a distance by perThousand for each 1000 ft of
+// density altitude; thinner air than at sea level never shortens it.
+func (a Atmosphere) performanceFactor(perThousand float64) float64 {
+	return 1 + perThousand*max(a.DensityAltitude(), 0)/1000
+}
+
+// SetAtmosphere updates the temperature and pressure. Arrivals no longer
+// able to stop on their runway miss the approach; if conditions improved,
+// holding flights and waiting departures are released.
+func (rm *RunwayManager) SetAtmosphere(a Atmosphere) error {
+	if a.QNH <= 0 {
+		return fmt.Errorf("qnh must be positive")
+	}
+	rm.mu.Lock()
+	previous := rm.atmosphere.DensityAltitude()
+	rm.atmosphere = a
+	da := a.DensityAltitude()
+	rm.publishEventLocked(Event{Type: EventAtmosphereChanged, Detail: fmt.Sprintf("%.0f°C %.0fhPa, density altitude %.0fft", a.TemperatureC, a.QNH, da)})
+	log.Printf("atmosphere %.0f°C %.0fhPa; density altitude %.0fft", a.TemperatureC, a.QNH, da)
+	for _, name := range rm.order {
+		rm.missApproachesLocked(name, rm.longEnoughLocked, DelayWeather, "missed approach: runway too short at density altitude")
+	}
+	improved := da < previous
+	if improved {
+		for _, name := range rm.order {
+			rm.releaseDepartureLocked(name)
+		}
+	}
+	rm.mu.Unlock()
+	if improved {
+		rm.releaseHolding()
+	}
+	return nil
+}
+
+// Atmosphere returns the current temperature and pressure.
+func (rm *RunwayManager) Atmosphere() Atmosphere {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.atmosphere
+}
+
+// canDepartLocked reports whether runway is long enough for f to take off.
+// Runways of unknown length take any aircraft.
+func (rm *RunwayManager) canDepartLocked(runway string, f Flight) bool {
+	available := rm.landingDistanceLocked(runway)
+	if available <= 0 {
+		return true
+	}
+	required, ok := takeoffDistances[f.Weight]
+	if !ok {
+		required = takeoffDistances[WeightMedium]
+	}
+	return available >= required*rm.atmosphere.performanceFactor(takeoffPerThousand)
+}
+
+// takeoffTimeLocked is the runway occupancy of a departure, which thinner
+// air stretches.
+func (rm *RunwayManager) takeoffTimeLocked() time.Duration {
+	return time.Duration(float64(takeoffDuration) * rm.atmosphere.performanceFactor(takeoffPerThousand))
+}
+
+// AtmosphereState is the atmosphere with its density altitude and the
+// runways each weight category can land on and take off from.
+type AtmosphereState struct {
+	Atmosphere      Atmosphere `json:"atmosphere"`
+	DensityAltitude float64    `json:"densityAltitude"`
+	// Landing and Takeoff list usable runways by weight category.
+	Landing map[string][]string `json:"landing"`
+	Takeoff map[string][]string `json:"takeoff"`
+}
+
+// AtmosphereState reports the atmosphere and the runways it leaves usable.
+func (rm *RunwayManager) AtmosphereState() AtmosphereState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	state := AtmosphereState{
+		Atmosphere:      rm.atmosphere,
+		DensityAltitude: rm.atmosphere.DensityAltitude(),
+		Landing:         make(map[string][]string, len(takeoffDistances)),
+		Takeoff:         make(map[string][]string, len(takeoffDistances)),
+	}
+	for weight := range takeoffDistances {
+		f := Flight{Weight: weight}
+		state.Landing[weight], state.Takeoff[weight] = []string{}, []string{}
+		for _, name := range rm.order {
+			if rm.longEnoughLocked(name, f) {
+				state.Landing[weight] = append(state.Landing[weight], name)
+			}
+			if rm.canDepartLocked(name, f) {
+				state.Takeoff[weight] = append(state.Takeoff[weight], name)
+			}
+		}
+	}
+	return state
+}
+
+// HandleAtmosphere reports (GET) or sets (PUT) the temperature and pressure.
+func (s *Server) HandleAtmosphere(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req Atmosphere
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid atmosphere: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetAtmosphere(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.AtmosphereState())
+}
@@ -0,0 +1,243 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// auctionHistoryLimit bounds how many clearing rounds the auction report
+// keeps.
+const auctionHistoryLimit = 20
+
+// Bid is an airline's offer for an arrival slot for one of its flights.
+type Bid struct {
+	FlightID int64   `json:"flightId"`
+	Call     string  `json:"call"`
+	Airline  string  `json:"airline"`
+	Amount   float64 `json:"amount"`
+}
+
+// SlotAward is the slot a bid won and the price charged for it. Slot 0 is
+// the first to be sequenced.
+type SlotAward struct {
+	Slot     int     `json:"slot"`
+	FlightID int64   `json:"flightId"`
+	Call     string  `json:"call"`
+	Airline  string  `json:"airline"`
+	Bid      float64 `json:"bid"`
+	Price    float64 `json:"price"`
+}
+
+// ClearingMechanism allocates arrival slots among bids, returning one award
+// per bid in slot order. Mechanisms are called with the auction lock held
+// and must not call back into it.
+type ClearingMechanism interface {
+	Clear(bids []Bid) []SlotAward
+}
+
+// FirstPriceClearing awards slots in descending bid order; each winner pays
+// its own bid. Equal bids keep their order in the holding stack.
+type FirstPriceClearing struct{}
+
+// Clear implements ClearingMechanism.
+func (FirstPriceClearing) Clear(bids []Bid) []SlotAward {
+	awards := rankBids(bids)
+	for i := range awards {
+		awards[i].Price = awards[i].Bid
+	}
+	return awards
+}
+
+// SecondPriceClearing awards slots in descending bid order; each winner
+// pays the bid ranked just below it, so bidding one's true value is safe.
+type SecondPriceClearing struct{}
+
+// Clear implements ClearingMechanism.
+func (SecondPriceClearing) Clear(bids []Bid) []SlotAward {
+	awards := rankBids(bids)
+	for i := range awards {
+		if i+1 < len(awards) {
+			awards[i].Price = awards[i+1].Bid
+		}
+	}
+	return awards
+}
+
+func rankBids(bids []Bid) []SlotAward {
+	ranked := append([]Bid(nil), bids...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Amount > ranked[j].Amount })
+	awards := make([]SlotAward, len(ranked))
+	for i, b := range ranked {
+		awards[i] = SlotAward{Slot: i, FlightID: b.FlightID, Call: b.Call, Airline: b.Airline, Bid: b.Amount}
+	}
+	return awards
+}
+
+// AirlineAgent is a simulated airline bidding for slots. It values each
+// minute its flights have waited at ValuePerMinute, scaled by aircraft size
+// as landing fees are, and never bids more than its remaining Budget.
+type AirlineAgent struct {
+	Airline        string  `json:"airline"`
+	Budget         float64 `json:"budget"`
+	ValuePerMinute float64 `json:"valuePerMinute"`
+}
+
+// bid is what the agent offers for f after waiting the given time.
+func (a AirlineAgent) bid(f Flight, waited time.Duration, spent float64) float64 {
+	size, ok := landingFees[f.Weight]
+	if !ok {
+		size = landingFees[WeightMedium]
+	}
+	value := a.ValuePerMinute * waited.Minutes() * size / landingFees[WeightMedium]
+	return max(min(value, a.Budget-spent), 0)
+}
+
+// AuctionRound is one clearing of the slots released after a capacity
+// reduction.
+type AuctionRound struct {
+	At      time.Time   `json:"at"`
+	Awards  []SlotAward `json:"awards"`
+	Revenue float64     `json:"revenue"`
+}
+
+// SlotAuction is an experimental sandbox in which airline agents bid for
+// the arrival slots released when capacity returns after a reduction. The
+// clearing mechanism decides the order the holding stack is sequenced in;
+// flights of airlines without an agent bid nothing and go last.
+type SlotAuction struct {
+	mu        sync.Mutex
+	name      string
+	mechanism ClearingMechanism
+	agents    map[string]AirlineAgent
+	spent     map[string]float64
+	won       map[string]int64
+	rounds    []AuctionRound
+}
+
+// NewSlotAuction returns an auction cleared by mechanism, registered under
+// name, between the given agents.
+func NewSlotAuction(name string, mechanism ClearingMechanism, agents []AirlineAgent) *SlotAuction {
+	a := &SlotAuction{name: name, mechanism: mechanism, spent: make(map[string]float64), won: make(map[string]int64)}
+	a.SetAgents(agents)
+	return a
+}
+
+// SetAgents replaces the bidding agents. Spending so far is kept, so an
+// agent's remaining budget is its new Budget less what it has paid.
+func (a *SlotAuction) SetAgents(agents []AirlineAgent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.agents = make(map[string]AirlineAgent, len(agents))
+	for _, agent := range agents {
+		a.agents[agent.Airline] = agent
+	}
+}
+
+// Allocate collects the agents' bids for the flights, clears them and
+// returns the flights in the order they won their slots.
+func (a *SlotAuction) Allocate(flights []Flight, now time.Time) []Flight {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	byID := make(map[int64]Flight, len(flights))
+	bids := make([]Bid, 0, len(flights))
+	offered := make(map[string]float64)
+	for _, f := range flights {
+		byID[f.ID] = f
+		airline := airlineOf(f.Call)
+		b := Bid{FlightID: f.ID, Call: f.Call, Airline: airline}
+		if agent, ok := a.agents[airline]; ok {
+			b.Amount = agent.bid(f, now.Sub(f.CreatedAt), a.spent[airline]+offered[airline])
+			offered[airline] += b.Amount
+		}
+		bids = append(bids, b)
+	}
+	awards := a.mechanism.Clear(bids)
+	order := make([]Flight, 0, len(flights))
+	round := AuctionRound{At: now, Awards: awards}
+	for _, award := range awards {
+		f, ok := byID[award.FlightID]
+		if !ok {
+			continue
+		}
+		delete(byID, award.FlightID)
+		order = append(order, f)
+		a.spent[award.Airline] += award.Price
+		a.won[award.Airline]++
+		round.Revenue += award.Price
+	}
+	// Flights the mechanism left out keep their place behind the winners.
+	for _, f := range flights {
+		if _, ok := byID[f.ID]; ok {
+			order = append(order, f)
+		}
+	}
+	a.rounds = append(a.rounds, round)
+	if len(a.rounds) > auctionHistoryLimit {
+		a.rounds = a.rounds[len(a.rounds)-auctionHistoryLimit:]
+	}
+	log.Printf("slot auction cleared %d slots for %.0f", len(awards), round.Revenue)
+	return order
+}
+
+// AgentStatus is an agent's standing in the auction.
+type AgentStatus struct {
+	Agent AirlineAgent `json:"agent"`
+	Spent float64      `json:"spent"`
+	Won   int64        `json:"won"`
+}
+
+// AuctionReport is the auction's mechanism, agents and recent rounds, most
+// recent last.
+type AuctionReport struct {
+	Mechanism string         `json:"mechanism"`
+	Agents    []AgentStatus  `json:"agents"`
+	Rounds    []AuctionRound `json:"rounds"`
+}
+
+// Report summarizes the auction.
+func (a *SlotAuction) Report() AuctionReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	report := AuctionReport{Mechanism: a.name, Agents: make([]AgentStatus, 0, len(a.agents)), Rounds: append([]AuctionRound{}, a.rounds...)}
+	for _, agent := range a.agents {
+		report.Agents = append(report.Agents, AgentStatus{Agent: agent, Spent: a.spent[agent.Airline], Won: a.won[agent.Airline]})
+	}
+	sort.Slice(report.Agents, func(i, j int) bool { return report.Agents[i].Agent.Airline < report.Agents[j].Agent.Airline })
+	return report
+}
+
+// SetAuction makes holding flights bid for their order whenever the holding
+// stack is released; nil restores first-come, first-served.
+func (rm *RunwayManager) SetAuction(a *SlotAuction) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.auction = a
+}
+
+// HandleAuction reports the slot auction (GET) or replaces its agents (PUT).
+func (s *Server) HandleAuction(w http.ResponseWriter, r *http.Request) {
+	if s.Auction == nil {
+		http.Error(w, "slot auction disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var agents []AirlineAgent
+		if err := json.NewDecoder(r.Body).Decode(&agents); err != nil {
+			http.Error(w, "invalid agents: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, agent := range agents {
+			if agent.Airline == "" || agent.Budget < 0 || agent.ValuePerMinute < 0 {
+				http.Error(w, fmt.Sprintf("agent %q needs an airline and a non-negative budget and value", agent.Airline), http.StatusBadRequest)
+				return
+			}
+		}
+		s.Auction.SetAgents(agents)
+	}
+	writeJSON(w, http.StatusOK, s.Auction.Report())
+}
@@ -0,0 +1,120 @@
+package control
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxSpawnBacklog bounds the flights waiting for the scheduler to take
+// them; flights spawned beyond it are dropped.
+const maxSpawnBacklog = 4096
+
+// Reasons a spawned flight did not reach the scheduler at once.
+const (
+	// BackpressureDelayed counts flights that waited because the scheduler
+	// was not keeping up.
+	BackpressureDelayed = "delayed"
+	// BackpressureOverflow counts flights dropped because the backlog was
+	// full.
+	BackpressureOverflow = "overflow"
+	// BackpressureShutdown counts flights still waiting when the generator
+	// stopped.
+	BackpressureShutdown = "shutdown"
+)
+
+// Backpressure accounts for the flights between the generator and the
+// scheduler: how many reached it on time, how many were held back because
+// it stalled and for how long, so wait metrics can be read knowing how much
+// of the wait the pipe itself added.
+type Backpressure struct {
+	Spawned int64 `json:"spawned"`
+	// OnTime counts flights handed to the scheduler as they spawned.
+	OnTime int64 `json:"onTime"`
+	// Causes counts the flights that were not, by reason.
+	Causes map[string]int64 `json:"causes"`
+	// Backlog is the flights waiting now; PeakBacklog the most at once.
+	Backlog     int `json:"backlog"`
+	PeakBacklog int `json:"peakBacklog"`
+	// DelaySeconds totals the time delayed flights spent waiting, and
+	// MaxDelaySeconds is the longest single wait.
+	DelaySeconds    float64 `json:"delaySeconds"`
+	MaxDelaySeconds float64 `json:"maxDelaySeconds"`
+}
+
+type backlogFlight struct {
+	flight Flight
+	since  time.Time
+}
+
+// spawnPipe holds the backpressure counters of a generator.
+type spawnPipe struct {
+	mu    sync.Mutex
+	stats Backpressure
+}
+
+func (p *spawnPipe) onTime() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Spawned++
+	p.stats.OnTime++
+}
+
+func (p *spawnPipe) delayed(backlog int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Spawned++
+	p.countLocked(BackpressureDelayed, 1)
+	p.stats.Backlog = backlog
+	p.stats.PeakBacklog = max(p.stats.PeakBacklog, backlog)
+}
+
+func (p *spawnPipe) delivered(waited time.Duration, backlog int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Backlog = backlog
+	p.stats.DelaySeconds += waited.Seconds()
+	p.stats.MaxDelaySeconds = max(p.stats.MaxDelaySeconds, waited.Seconds())
+}
+
+func (p *spawnPipe) overflow() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Spawned++
+	p.countLocked(BackpressureOverflow, 1)
+}
+
+func (p *spawnPipe) shutdown(backlog int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.countLocked(BackpressureShutdown, int64(backlog))
+	p.stats.Backlog = 0
+}
+
+func (p *spawnPipe) countLocked(cause string, n int64) {
+	if n == 0 {
+		return
+	}
+	if p.stats.Causes == nil {
+		p.stats.Causes = make(map[string]int64)
+	}
+	p.stats.Causes[cause] += n
+}
+
+// Backpressure reports how flights have fared between the generator and
+// the scheduler.
+func (g *Generator) Backpressure() Backpressure {
+	g.pipe.mu.Lock()
+	defer g.pipe.mu.Unlock()
+	out := g.pipe.stats
+	out.Causes = make(map[string]int64, len(g.pipe.stats.Causes))
+	for cause, n := range g.pipe.stats.Causes {
+		out.Causes[cause] = n
+	}
+	return out
+}
+
+// HandleBackpressure reports the generator's backpressure accounting.
+func (s *Server) HandleBackpressure(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Generator.Backpressure())
+}
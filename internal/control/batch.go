@@ -0,0 +1,178 @@
+package control
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BatchRequest is a set of commands applied together, as in closing two
+// runways and setting the wind in one transaction.
+type BatchRequest struct {
+	Commands []Command `json:"commands"`
+}
+
+// CommandResult reports one command of a batch: why it was rejected, or
+// its predicted effects.
+type CommandResult struct {
+	Command Command  `json:"command"`
+	Error   string   `json:"error,omitempty"`
+	Effects []string `json:"effects,omitempty"`
+}
+
+// BatchResult is the combined report of a batch. It is all or nothing:
+// Applied is false when any command was rejected, and then none are
+// applied.
+type BatchResult struct {
+	Applied bool            `json:"applied"`
+	Results []CommandResult `json:"results"`
+	// Pending is set when the batch awaits a second controller under the
+	// two-person policy.
+	Pending *PendingAction `json:"pending,omitempty"`
+}
+
+// ApplyCommands applies runway, wind, visibility and wildlife hazard
+// commands in order under one lock, so no flight is assigned or landed part
+// way through them. Other commands are skipped.
+func (rm *RunwayManager) ApplyCommands(cmds []Command) {
+	rm.mu.Lock()
+	reopened, released := false, false
+	for _, cmd := range cmds {
+		switch cmd.Type {
+		case "runway":
+			reopened = rm.setRunwayClosedLocked(cmd.Runway, cmd.Closed) || reopened
+		case "wind":
+			rm.setWindLocked(*cmd.Wind)
+		case "visibility":
+			released = rm.setVisibilityLocked(cmd.Visibility.Meters) || released
+			if cmd.Visibility.CeilingFeet != nil {
+				rm.setCeilingLocked(*cmd.Visibility.CeilingFeet)
+			}
+		case "wildlife":
+			if cmd.Wildlife != nil {
+				rm.setWildlifeLocked(*cmd.Wildlife)
+			}
+		}
+	}
+	rm.mu.Unlock()
+	if reopened || released {
+		rm.releaseHolding()
+	}
+	if reopened {
+		rm.proposeRecovery()
+	}
+}
+
+// closesAll reports whether closing runways would leave none available.
+func (rm *RunwayManager) closesAll(runways []string) bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	for _, name := range rm.order {
+		if rm.runways[name].available() && !slices.Contains(runways, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBatch validates every command of a batch and predicts their
+// combined effect, reporting whether all were valid.
+func (s *Server) checkBatch(cmds []Command) ([]CommandResult, Preview, bool) {
+	results := make([]CommandResult, len(cmds))
+	combined := Preview{Command: "batch"}
+	valid := len(cmds) > 0
+	var closing []string
+	for i, cmd := range cmds {
+		results[i].Command = cmd
+		if err := s.validateCommand(cmd); err != nil {
+			results[i].Error = err.Error()
+			valid = false
+			continue
+		}
+		p := s.previewCommand(cmd)
+		results[i].Effects = p.Effects
+		combined.Effects = append(combined.Effects, p.Effects...)
+		combined.Diverted = append(combined.Diverted, p.Diverted...)
+		combined.ClosesLastRunway = combined.ClosesLastRunway || p.ClosesLastRunway
+		if cmd.Type == "runway" && cmd.Closed || cmd.Type == "wildlife" && cmd.Runway != "" {
+			closing = append(closing, cmd.Runway)
+		}
+	}
+	if valid && len(closing) > 0 && s.Runways.closesAll(closing) {
+		combined.ClosesLastRunway = true
+	}
+	return results, combined, valid
+}
+
+// ApplyBatch applies cmds together: none are applied unless all are
+// valid. Rate changes, wildlife dispersals and bursts are applied after the
+// scheduler changes, so a burst meets the runways as the batch leaves them.
+func (s *Server) ApplyBatch(cmds []Command) {
+	s.Runways.ApplyCommands(cmds)
+	for _, cmd := range cmds {
+		switch cmd.Type {
+		case "rate":
+			s.setRate(cmd.Rate, time.Duration(cmd.RampSeconds)*time.Second)
+		case "wildlife":
+			if cmd.Runway != "" {
+				if err := s.Runways.DisperseWildlife(cmd.Runway); err != nil {
+					log.Printf("wildlife dispersal ignored: %v", err)
+				}
+			}
+		case "burst":
+			s.Burst(cmd.Count)
+		}
+	}
+	applied := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		applied[i] = cmd.String()
+	}
+	log.Printf("batch applied: %s", strings.Join(applied, ", "))
+}
+
+// HandleBatch applies a batch of commands all or nothing, answering with
+// the combined report.
+func (s *Server) HandleBatch(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid batch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	results, preview, valid := s.checkBatch(req.Commands)
+	result := BatchResult{Results: results}
+	switch {
+	case len(req.Commands) == 0:
+		http.Error(w, "batch has no commands", http.StatusBadRequest)
+		return
+	case !valid:
+		writeJSON(w, http.StatusBadRequest, result)
+		return
+	case dryRun(r):
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	if s.Confirmations != nil {
+		if reason := s.Confirmations.Check(preview); reason != "" {
+			controller, ok := s.Confirmations.Authenticate(r)
+			if !ok {
+				http.Error(w, "controller authentication required: "+reason, http.StatusUnauthorized)
+				return
+			}
+			cmds := req.Commands
+			pending := s.Confirmations.Request(controller, preview, reason, func() { s.ApplyBatch(cmds) })
+			result.Pending = &pending
+			writeJSON(w, http.StatusAccepted, result)
+			return
+		}
+	}
+	s.ApplyBatch(req.Commands)
+	result.Applied = true
+	writeJSON(w, http.StatusOK, result)
+}
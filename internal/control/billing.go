@@ -0,0 +1,177 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Aircraft weight categories, after the ICAO wake turbulence categories.
+const (
+	WeightLight  = "light"
+	WeightMedium = "medium"
+	WeightHeavy  = "heavy"
+	WeightSuper  = "super"
+)
+
+// landingFees is the base landing fee per weight category.
+var landingFees = map[string]float64{
+	WeightLight:  150,
+	WeightMedium: 600,
+	WeightHeavy:  1800,
+	WeightSuper:  3500,
+}
+
+// Tariff periods by local time of day.
+const (
+	PeriodNight   = "night"
+	PeriodPeak    = "peak"
+	PeriodOffPeak = "offPeak"
+)
+
+// periodMultipliers scales the base fee by tariff period: night movements
+// carry a noise surcharge and peak hours a congestion surcharge.
+var periodMultipliers = map[string]float64{
+	PeriodNight:   1.5,
+	PeriodPeak:    1.2,
+	PeriodOffPeak: 1,
+}
+
+// chargeHistoryLimit bounds how many individual charges are kept for the
+// invoice report; airline totals are kept indefinitely.
+const chargeHistoryLimit = 1000
+
+// tariffPeriod classifies a landing time: night is 23:00-06:00, peak is
+// 07:00-09:00 and 17:00-19:00.
+func tariffPeriod(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 23 || h < 6:
+		return PeriodNight
+	case h >= 7 && h < 9, h >= 17 && h < 19:
+		return PeriodPeak
+	}
+	return PeriodOffPeak
+}
+
+// LandingFee is the charge for f landing at t. Flights without a known
+// weight category are billed as medium.
+func LandingFee(f Flight, t time.Time) float64 {
+	base, ok := landingFees[f.Weight]
+	if !ok {
+		base = landingFees[WeightMedium]
+	}
+	return base * periodMultipliers[tariffPeriod(t)]
+}
+
+// airlineOf derives the operator from the callsign's leading letters.
+func airlineOf(call string) string {
+	i := strings.IndexFunc(call, func(r rune) bool { return !unicode.IsLetter(r) })
+	if i < 0 {
+		i = len(call)
+	}
+	if i == 0 {
+		return "unknown"
+	}
+	return strings.ToUpper(call[:i])
+}
+
+// defaultWeight gives generated flights a deterministic fleet mix: mostly
+// medium, with some heavies and the occasional light or super.
+func defaultWeight(id int64) string {
+	switch {
+	case id%20 == 0:
+		return WeightSuper
+	case id%7 == 0:
+		return WeightLight
+	case id%4 == 3:
+		return WeightHeavy
+	}
+	return WeightMedium
+}
+
+// Charge is the landing fee billed for one movement.
+type Charge struct {
+	FlightID int64     `json:"flightId"`
+	Call     string    `json:"call"`
+	Weight   string    `json:"weight"`
+	Runway   string    `json:"runway"`
+	LandedAt time.Time `json:"landedAt"`
+	Period   string    `json:"period"`
+	Fee      float64   `json:"fee"`
+}
+
+// Invoice totals an airline's landing fees, with its recent charges.
+type Invoice struct {
+	Airline   string   `json:"airline"`
+	Movements int64    `json:"movements"`
+	Total     float64  `json:"total"`
+	Charges   []Charge `json:"charges"`
+}
+
+// InvoiceReport is the landing fee revenue broken down by airline.
+type InvoiceReport struct {
+	Revenue  float64   `json:"revenue"`
+	Invoices []Invoice `json:"invoices"`
+}
+
+// billLocked charges the landing fee for f touching down on runway now.
+func (rm *RunwayManager) billLocked(runway string, f Flight) {
+	now := rm.clock.Now()
+	c := Charge{FlightID: f.ID, Call: f.Call, Weight: f.Weight, Runway: runway, LandedAt: now, Period: tariffPeriod(now), Fee: LandingFee(f, now)}
+	if c.Weight == "" {
+		c.Weight = WeightMedium
+	}
+	airline := airlineOf(f.Call)
+	acc := rm.accounts[airline]
+	acc.movements++
+	acc.total += c.Fee
+	rm.accounts[airline] = acc
+	rm.charges = append(rm.charges, c)
+	if n := len(rm.charges); n > chargeHistoryLimit {
+		rm.charges = rm.charges[n-chargeHistoryLimit:]
+	}
+	if rm.metrics != nil {
+		rm.metrics.RecordRevenue(c.Fee)
+	}
+}
+
+// account is an airline's running landing fee total.
+type account struct {
+	movements int64
+	total     float64
+}
+
+// Invoices builds the per-airline invoice report, largest total first.
+func (rm *RunwayManager) Invoices() InvoiceReport {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	report := InvoiceReport{Invoices: make([]Invoice, 0, len(rm.accounts))}
+	byAirline := make(map[string]int, len(rm.accounts))
+	for airline, acc := range rm.accounts {
+		byAirline[airline] = len(report.Invoices)
+		report.Invoices = append(report.Invoices, Invoice{Airline: airline, Movements: acc.movements, Total: acc.total, Charges: []Charge{}})
+		report.Revenue += acc.total
+	}
+	for _, c := range rm.charges {
+		inv := &report.Invoices[byAirline[airlineOf(c.Call)]]
+		inv.Charges = append(inv.Charges, c)
+	}
+	sort.Slice(report.Invoices, func(i, j int) bool {
+		if report.Invoices[i].Total == report.Invoices[j].Total {
+			return report.Invoices[i].Airline < report.Invoices[j].Airline
+		}
+		return report.Invoices[i].Total > report.Invoices[j].Total
+	})
+	return report
+}
+
+// HandleInvoices serves the landing fee invoice report.
+func (s *Server) HandleInvoices(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Invoices())
+}
@@ -0,0 +1,88 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// EventBurst is published when a bank of flights is spawned at once.
+const EventBurst = "burst"
+
+// maxBurst bounds the flights one burst may spawn.
+const maxBurst = 200
+
+// BurstResult lists the flights spawned by a burst.
+type BurstResult struct {
+	Count   int      `json:"count"`
+	Flights []Flight `json:"flights"`
+}
+
+func validateBurst(count int) error {
+	if count <= 0 || count > maxBurst {
+		return fmt.Errorf("burst count must be between 1 and %d", maxBurst)
+	}
+	return nil
+}
+
+// previewBurst predicts spawning count flights at once.
+func (rm *RunwayManager) previewBurst(count int) Preview {
+	p := Preview{Command: "burst"}
+	if err := validateBurst(count); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	available := 0
+	for _, name := range rm.order {
+		if rm.runways[name].available() {
+			available++
+		}
+	}
+	p.Effects = append(p.Effects, fmt.Sprintf("%d flights will arrive at once across %d available runways, joining %d already holding", count, available, len(rm.holding)))
+	return p
+}
+
+// Burst spawns count flights at once, as in a radar handoff of a whole
+// bank, and routes each through the normal assignment path.
+func (s *Server) Burst(count int) ([]Flight, error) {
+	if err := validateBurst(count); err != nil {
+		return nil, err
+	}
+	flights := make([]Flight, 0, count)
+	for range count {
+		f := s.Generator.spawn()
+		s.Runways.Arrive(f)
+		flights = append(flights, f)
+	}
+	log.Printf("burst of %d flights spawned", count)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventBurst, Detail: fmt.Sprintf("%d flights", count)})
+	}
+	return flights, nil
+}
+
+// HandleBurst spawns the number of flights given by count at once.
+func (s *Server) HandleBurst(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	count, err := strconv.Atoi(r.FormValue("count"))
+	if err != nil {
+		http.Error(w, "invalid count", http.StatusBadRequest)
+		return
+	}
+	if dryRun(r) {
+		writeJSON(w, http.StatusOK, s.Runways.previewBurst(count))
+		return
+	}
+	flights, err := s.Burst(count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, BurstResult{Count: len(flights), Flights: flights})
+}
@@ -0,0 +1,380 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultChaosLockEvery = time.Second
+	defaultChaosJumpEvery = time.Minute
+	// chaosCheckInterval is how often the scheduler invariants are checked
+	// while chaos mode is on.
+	chaosCheckInterval = time.Second
+	// maxChaosViolations bounds the violations kept for the status.
+	maxChaosViolations = 20
+)
+
+// ChaosConfig selects the faults chaos mode injects; the zero value injects
+// none.
+type ChaosConfig struct {
+	// DropRate is the fraction, 0 to 1, of websocket messages to clients
+	// silently dropped.
+	DropRate float64 `json:"dropRate,omitempty"`
+	// LockHoldMillis is how long the scheduler lock is held, every
+	// LockEveryMillis (1000 by default), to contend with its users.
+	LockHoldMillis  int64 `json:"lockHoldMillis,omitempty"`
+	LockEveryMillis int64 `json:"lockEveryMillis,omitempty"`
+	// LandingDelaySeconds is the most each landing is held past its due
+	// time, chosen at random.
+	LandingDelaySeconds float64 `json:"landingDelaySeconds,omitempty"`
+	// ClockJumpSeconds steps the simulation clock by that much, back when
+	// negative, every ClockJumpEverySeconds (60 by default).
+	ClockJumpSeconds      float64 `json:"clockJumpSeconds,omitempty"`
+	ClockJumpEverySeconds float64 `json:"clockJumpEverySeconds,omitempty"`
+	// Seed seeds the random faults so a run can be repeated; zero picks
+	// one.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+func (c ChaosConfig) validate() error {
+	switch {
+	case c.DropRate < 0 || c.DropRate > 1:
+		return errors.New("dropRate must be between 0 and 1")
+	case c.LockHoldMillis < 0 || c.LockEveryMillis < 0:
+		return errors.New("lock durations must not be negative")
+	case c.LandingDelaySeconds < 0:
+		return errors.New("landingDelaySeconds must not be negative")
+	case c.ClockJumpEverySeconds < 0:
+		return errors.New("clockJumpEverySeconds must not be negative")
+	}
+	return nil
+}
+
+func (c ChaosConfig) lockEvery() time.Duration {
+	if c.LockEveryMillis > 0 {
+		return time.Duration(c.LockEveryMillis) * time.Millisecond
+	}
+	return defaultChaosLockEvery
+}
+
+func (c ChaosConfig) jumpEvery() time.Duration {
+	if c.ClockJumpEverySeconds > 0 {
+		return time.Duration(c.ClockJumpEverySeconds * float64(time.Second))
+	}
+	return defaultChaosJumpEvery
+}
+
+// ChaosStatus reports chaos mode: the faults configured, those injected so
+// far and the scheduler invariants found broken.
+type ChaosStatus struct {
+	Enabled bool        `json:"enabled"`
+	Config  ChaosConfig `json:"config"`
+	Since   *time.Time  `json:"since,omitempty"`
+	// DroppedMessages counts websocket messages dropped.
+	DroppedMessages int64 `json:"droppedMessages"`
+	LockHolds       int64 `json:"lockHolds"`
+	// DelayedLandings counts landings held past their due time, by
+	// LandingDelaySeconds in total.
+	DelayedLandings     int64   `json:"delayedLandings"`
+	LandingDelaySeconds float64 `json:"landingDelaySeconds"`
+	ClockJumps          int64   `json:"clockJumps"`
+	// ClockOffsetSeconds is how far the jumps have moved the clock.
+	ClockOffsetSeconds float64 `json:"clockOffsetSeconds"`
+	// InvariantChecks counts checks of the scheduler invariants and
+	// Violations lists the latest broken ones found.
+	InvariantChecks int64    `json:"invariantChecks"`
+	Violations      []string `json:"violations"`
+}
+
+// Chaos injects faults into a running simulation to show its invariants
+// and recovery logic hold up: dropped client messages, contention on the
+// scheduler lock, late landings and clock jumps. It injects nothing until
+// configured.
+type Chaos struct {
+	mu      sync.Mutex
+	cfg     ChaosConfig
+	enabled bool
+	status  ChaosStatus
+	rng     *rand.Rand
+	// offset is the clock jump total in nanoseconds, read by the clock on
+	// every call.
+	offset  atomic.Int64
+	changed chan struct{}
+}
+
+// NewChaos returns a chaos injector with no faults configured.
+func NewChaos() *Chaos {
+	return &Chaos{rng: rand.New(rand.NewSource(time.Now().UnixNano())), changed: make(chan struct{}, 1)}
+}
+
+// Configure replaces the faults injected; a zero configuration stops them.
+func (c *Chaos) Configure(cfg ChaosConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cfg = cfg
+	c.enabled = cfg != (ChaosConfig{})
+	if cfg.Seed != 0 {
+		c.rng = rand.New(rand.NewSource(cfg.Seed))
+	}
+	if c.enabled {
+		now := time.Now()
+		c.status = ChaosStatus{Since: &now, ClockOffsetSeconds: c.status.ClockOffsetSeconds}
+	}
+	c.mu.Unlock()
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+	if c.enabled {
+		log.Printf("chaos mode on: %+v", cfg)
+	} else {
+		log.Printf("chaos mode off")
+	}
+	return nil
+}
+
+// Status reports the faults configured and injected.
+func (c *Chaos) Status() ChaosStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.status
+	out.Enabled = c.enabled
+	out.Config = c.cfg
+	out.Violations = append([]string{}, c.status.Violations...)
+	return out
+}
+
+func (c *Chaos) config() (ChaosConfig, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg, c.enabled
+}
+
+// drop reports whether to drop a message to a client. A nil Chaos drops
+// nothing.
+func (c *Chaos) drop() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled || c.cfg.DropRate == 0 || c.rng.Float64() >= c.cfg.DropRate {
+		return false
+	}
+	c.status.DroppedMessages++
+	return true
+}
+
+// landingDelay returns how long to hold a landing past its due time. A nil
+// Chaos holds none.
+func (c *Chaos) landingDelay() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled || c.cfg.LandingDelaySeconds == 0 {
+		return 0
+	}
+	d := time.Duration(c.rng.Float64() * c.cfg.LandingDelaySeconds * float64(time.Second))
+	c.status.DelayedLandings++
+	c.status.LandingDelaySeconds += d.Seconds()
+	return d
+}
+
+// Clock wraps inner so chaos mode can make it jump. Timers still run on
+// inner: a forward jump makes those pending fire late by the clock's
+// reading, a backward one early.
+func (c *Chaos) Clock(inner Clock) Clock {
+	return chaosClock{inner: inner, chaos: c}
+}
+
+type chaosClock struct {
+	inner Clock
+	chaos *Chaos
+}
+
+// Now implements Clock.
+func (k chaosClock) Now() time.Time {
+	return k.inner.Now().Add(time.Duration(k.chaos.offset.Load()))
+}
+
+// AfterFunc implements Clock.
+func (k chaosClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return k.inner.AfterFunc(d, f)
+}
+
+// Unwrap returns the clock chaos mode skews.
+func (k chaosClock) Unwrap() Clock {
+	return k.inner
+}
+
+// Run injects the timed faults, holding the lock of rm and jumping the
+// clock, and checks rm's invariants while chaos mode is on, until the
+// context is canceled.
+func (c *Chaos) Run(ctx context.Context, rm *RunwayManager) {
+	var lock, jump, check *time.Ticker
+	stop := func() {
+		for _, t := range []*time.Ticker{lock, jump, check} {
+			if t != nil {
+				t.Stop()
+			}
+		}
+		lock, jump, check = nil, nil, nil
+	}
+	defer stop()
+	tick := func(t *time.Ticker) <-chan time.Time {
+		if t == nil {
+			return nil
+		}
+		return t.C
+	}
+	for {
+		cfg, on := c.config()
+		stop()
+		if on {
+			check = time.NewTicker(chaosCheckInterval)
+			if cfg.LockHoldMillis > 0 {
+				lock = time.NewTicker(cfg.lockEvery())
+			}
+			if cfg.ClockJumpSeconds != 0 {
+				jump = time.NewTicker(cfg.jumpEvery())
+			}
+		}
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.changed:
+				break wait
+			case <-tick(lock):
+				rm.holdLock(time.Duration(cfg.LockHoldMillis) * time.Millisecond)
+				c.mu.Lock()
+				c.status.LockHolds++
+				c.mu.Unlock()
+			case <-tick(jump):
+				d := time.Duration(cfg.ClockJumpSeconds * float64(time.Second))
+				offset := c.offset.Add(int64(d))
+				c.mu.Lock()
+				c.status.ClockJumps++
+				c.status.ClockOffsetSeconds = time.Duration(offset).Seconds()
+				c.mu.Unlock()
+				log.Printf("chaos: clock jumped %s", d)
+			case <-tick(check):
+				violations := rm.InvariantViolations()
+				c.mu.Lock()
+				c.status.InvariantChecks++
+				for _, v := range violations {
+					log.Printf("chaos: invariant broken: %s", v)
+					c.status.Violations = append(c.status.Violations, v)
+				}
+				if n := len(c.status.Violations); n > maxChaosViolations {
+					c.status.Violations = c.status.Violations[n-maxChaosViolations:]
+				}
+				c.mu.Unlock()
+			}
+		}
+	}
+}
+
+// holdLock takes the scheduler lock for d, as chaos mode's contention.
+func (rm *RunwayManager) holdLock(d time.Duration) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	time.Sleep(d)
+}
+
+// SetChaos lets chaos mode delay landings; nil stops it.
+func (rm *RunwayManager) SetChaos(c *Chaos) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.chaos = c
+}
+
+// InvariantViolations checks the scheduler's bookkeeping and describes each
+// inconsistency found: every arrival is in exactly one of the runway
+// queues, the holding stack and the go-arounds, in the lifecycle phase that
+// matches, and sequenced arrivals have a landing due.
+func (rm *RunwayManager) InvariantViolations() []string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	var out []string
+	seen := make(map[int64]string)
+	place := func(f Flight, where string, phases ...FlightPhase) {
+		if prev, ok := seen[f.ID]; ok {
+			out = append(out, fmt.Sprintf("flight %d (%s) both %s and %s", f.ID, f.Call, prev, where))
+			return
+		}
+		seen[f.ID] = where
+		phase, ok := rm.phases[f.ID]
+		if !ok {
+			out = append(out, fmt.Sprintf("flight %d (%s) %s has no phase", f.ID, f.Call, where))
+			return
+		}
+		for _, p := range phases {
+			if p == phase {
+				return
+			}
+		}
+		out = append(out, fmt.Sprintf("flight %d (%s) %s in phase %s", f.ID, f.Call, where, phase))
+	}
+	for _, name := range rm.order {
+		for _, f := range rm.assigned[name] {
+			place(f, "sequenced to "+name, PhaseSequenced, PhaseFinal)
+			if _, ok := rm.dueAt[f.ID]; !ok {
+				out = append(out, fmt.Sprintf("flight %d (%s) sequenced to %s has no landing due", f.ID, f.Call, name))
+			}
+		}
+	}
+	for _, f := range rm.holding {
+		place(f, "holding", PhaseHolding)
+	}
+	for _, f := range rm.goingAround {
+		place(f, "going around", PhaseHolding)
+	}
+	counts := make(map[FlightPhase]int)
+	for _, phase := range rm.phases {
+		counts[phase]++
+	}
+	for _, phase := range FlightPhases {
+		if !phase.Terminal() && counts[phase] != rm.phaseCounts[phase] {
+			out = append(out, fmt.Sprintf("%d flights %s but counted %d", counts[phase], phase, rm.phaseCounts[phase]))
+		}
+	}
+	return out
+}
+
+// HandleChaos reports (GET), configures (PUT) or stops (DELETE) chaos mode.
+func (s *Server) HandleChaos(w http.ResponseWriter, r *http.Request) {
+	if s.Chaos == nil {
+		http.Error(w, "chaos mode disabled", http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var cfg ChaosConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, "invalid chaos config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Chaos.Configure(cfg); err != nil {
+			http.Error(w, "invalid chaos config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		s.Chaos.Configure(ChaosConfig{})
+	}
+	writeJSON(w, http.StatusOK, s.Chaos.Status())
+}
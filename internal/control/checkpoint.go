@@ -0,0 +1,309 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventBranched is published when the simulation is branched from a
+// checkpoint.
+const EventBranched = "branched"
+
+// maxCheckpoints bounds the checkpoints kept at once.
+const maxCheckpoints = 32
+
+// Checkpoint is the state of a live run at TakenAt: the operating
+// conditions and every arrival sequenced or holding.
+type Checkpoint struct {
+	Name       string         `json:"name"`
+	TakenAt    time.Time      `json:"takenAt"`
+	Rate       int64          `json:"rate"`
+	Wind       WindState      `json:"wind"`
+	Visibility int64          `json:"visibility"`
+	Ceiling    int64          `json:"ceiling,omitempty"`
+	Closed     []string       `json:"closed"`
+	Incidents  []Incident     `json:"incidents"`
+	Flights    RecoveredState `json:"flights"`
+}
+
+// CheckpointRequest names a checkpoint to take.
+type CheckpointRequest struct {
+	Name string `json:"name"`
+}
+
+// BranchResult reports a branch from a checkpoint.
+type BranchResult struct {
+	Checkpoint string    `json:"checkpoint"`
+	BranchedAt time.Time `json:"branchedAt"`
+	// Discarded counts the arrivals of the abandoned timeline; Restored
+	// those of the checkpoint put back in their place.
+	Discarded int `json:"discarded"`
+	Restored  int `json:"restored"`
+}
+
+// checkpointStore holds the named checkpoints.
+type checkpointStore struct {
+	mu     sync.Mutex
+	byName map[string]Checkpoint
+}
+
+// TakeCheckpoint records the current state of the run under name.
+func (s *Server) TakeCheckpoint(name string) (Checkpoint, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Checkpoint{}, errors.New("name missing")
+	}
+	if s.Runways == nil {
+		return Checkpoint{}, errors.New("scheduler unavailable")
+	}
+	rm := s.Runways
+	cp := Checkpoint{
+		Name:       name,
+		TakenAt:    rm.Clock().Now(),
+		Rate:       s.Generator.Rate(),
+		Wind:       rm.Wind(),
+		Visibility: rm.Visibility(),
+		Ceiling:    rm.Ceiling(),
+		Closed:     []string{},
+		Incidents:  rm.Incidents(),
+		Flights:    rm.Outstanding(),
+	}
+	cp.Flights.LastID = s.Generator.LastID()
+	for _, runway := range rm.RunwayNames() {
+		if rm.IsClosed(runway) {
+			cp.Closed = append(cp.Closed, runway)
+		}
+	}
+
+	s.checkpoints.mu.Lock()
+	defer s.checkpoints.mu.Unlock()
+	if s.checkpoints.byName == nil {
+		s.checkpoints.byName = make(map[string]Checkpoint)
+	}
+	if _, ok := s.checkpoints.byName[name]; ok {
+		return Checkpoint{}, fmt.Errorf("checkpoint %q exists", name)
+	}
+	if len(s.checkpoints.byName) >= maxCheckpoints {
+		return Checkpoint{}, fmt.Errorf("at most %d checkpoints; delete one first", maxCheckpoints)
+	}
+	s.checkpoints.byName[name] = cp
+	log.Printf("checkpoint %s taken with %d sequenced and %d holding", name, len(cp.Flights.InProgress), len(cp.Flights.Holding))
+	return cp, nil
+}
+
+// Checkpoints lists the checkpoints, oldest first.
+func (s *Server) Checkpoints() []Checkpoint {
+	s.checkpoints.mu.Lock()
+	out := make([]Checkpoint, 0, len(s.checkpoints.byName))
+	for _, cp := range s.checkpoints.byName {
+		out = append(out, cp)
+	}
+	s.checkpoints.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].TakenAt.Before(out[j].TakenAt) })
+	return out
+}
+
+// Checkpoint returns the checkpoint called name.
+func (s *Server) Checkpoint(name string) (Checkpoint, bool) {
+	s.checkpoints.mu.Lock()
+	defer s.checkpoints.mu.Unlock()
+	cp, ok := s.checkpoints.byName[name]
+	return cp, ok
+}
+
+// DeleteCheckpoint removes a checkpoint, reporting whether it existed.
+func (s *Server) DeleteCheckpoint(name string) bool {
+	s.checkpoints.mu.Lock()
+	defer s.checkpoints.mu.Unlock()
+	_, ok := s.checkpoints.byName[name]
+	delete(s.checkpoints.byName, name)
+	return ok
+}
+
+// Branch abandons the current timeline and continues the run from the
+// checkpoint called name: its arrivals replace the current ones and its
+// conditions are restored. Simulation time does not go back; the
+// checkpoint's flights resume where they were, shifted to now. Metrics,
+// flight numbering and scheduled commands carry on across the branch.
+func (s *Server) Branch(name string) (BranchResult, error) {
+	cp, ok := s.Checkpoint(name)
+	if !ok {
+		return BranchResult{}, fmt.Errorf("unknown checkpoint %q", name)
+	}
+	if s.Runways == nil {
+		return BranchResult{}, errors.New("scheduler unavailable")
+	}
+	rm := s.Runways
+	now := rm.Clock().Now()
+	shift := now.Sub(cp.TakenAt)
+	result := BranchResult{Checkpoint: name, BranchedAt: now, Discarded: rm.discardArrivals()}
+
+	s.Generator.SetRate(cp.Rate)
+	rm.SetWindState(cp.Wind)
+	rm.SetVisibility(cp.Visibility)
+	if cp.Ceiling > 0 {
+		rm.SetCeiling(cp.Ceiling)
+	}
+	for _, inc := range rm.Incidents() {
+		rm.ResolveIncident(inc.ID)
+	}
+	for _, inc := range cp.Incidents {
+		// A zero remaining time would fall back to the default repair time.
+		remaining := max(inc.RepairBy.Sub(cp.TakenAt), time.Millisecond)
+		if _, err := rm.InjectIncident(inc.Type, inc.Runway, remaining); err != nil {
+			log.Printf("branch %s: incident %s: %v", name, inc.ID, err)
+		}
+	}
+	for _, runway := range rm.RunwayNames() {
+		if closed := slices.Contains(cp.Closed, runway); closed != rm.IsClosed(runway) {
+			rm.SetRunwayClosed(runway, closed)
+		}
+	}
+
+	flights := RecoveredState{InProgress: []Decision{}, Holding: append([]Flight{}, cp.Flights.Holding...), Deferred: []Decision{}}
+	for _, d := range cp.Flights.InProgress {
+		d.At = d.At.Add(shift)
+		flights.InProgress = append(flights.InProgress, d)
+	}
+	for _, d := range cp.Flights.Deferred {
+		d.At = d.At.Add(shift)
+		flights.Deferred = append(flights.Deferred, d)
+	}
+	rm.restoreBranch(flights)
+	rm.releaseHolding()
+	result.Restored = len(flights.InProgress) + len(flights.Holding) + len(flights.Deferred)
+
+	log.Printf("branched from checkpoint %s: %d arrivals discarded, %d restored", name, result.Discarded, result.Restored)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventBranched, Detail: fmt.Sprintf("%s, taken %s ago: %d arrivals restored", name, shift.Round(time.Second), result.Restored)})
+	}
+	return result, nil
+}
+
+// discardArrivals drops every arrival sequenced, holding, going around or
+// deferred by the acceptance gate and returns how many there were. Their landing timers are canceled;
+// pending go-around timers find them gone and do nothing.
+func (rm *RunwayManager) discardArrivals() int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	var dropped []Flight
+	for _, name := range rm.order {
+		dropped = append(dropped, rm.assigned[name]...)
+		rm.assigned[name] = nil
+		rm.publishQueuesLocked(name)
+	}
+	dropped = append(dropped, rm.holding...)
+	for _, f := range rm.goingAround {
+		dropped = append(dropped, f)
+	}
+	rm.holding = nil
+	rm.goingAround = make(map[int64]Flight)
+	for _, f := range dropped {
+		delete(rm.assignedAt, f.ID)
+		delete(rm.dueAt, f.ID)
+		rm.cancelLandingLocked(f.ID)
+		rm.setPhaseLocked(f, PhaseCancelled, "")
+		delete(rm.estimatedAt, f.ID)
+		delete(rm.vectors, f.ID)
+		delete(rm.delays, f.ID)
+		delete(rm.deferred, f.ID)
+		delete(rm.emissions, f.ID)
+		rm.logDecisionLocked(DecisionDivert, f, "")
+	}
+	rm.publishHoldingLocked()
+	for _, d := range rm.acceptance.deferred {
+		rm.logDecisionLocked(DecisionDivert, d.flight, "")
+	}
+	discarded := len(dropped) + len(rm.acceptance.deferred)
+	rm.acceptance.deferred = nil
+	return discarded
+}
+
+// restoreBranch restores a checkpoint's arrivals, recording them in the
+// decision log as if newly decided. Deferred arrivals are recorded first,
+// since Restore may let them enter at once.
+func (rm *RunwayManager) restoreBranch(state RecoveredState) {
+	rm.mu.Lock()
+	for _, d := range state.Deferred {
+		rm.logDecisionLocked(DecisionDefer, d.Flight, "")
+	}
+	rm.mu.Unlock()
+	rm.Restore(state)
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	for _, d := range state.InProgress {
+		rm.logDecisionLocked(DecisionAssign, d.Flight, d.Runway)
+	}
+	for _, f := range state.Holding {
+		rm.logDecisionLocked(DecisionHold, f, "")
+	}
+}
+
+// HandleCheckpoints lists the checkpoints (GET) or takes one (POST).
+func (s *Server) HandleCheckpoints(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Checkpoints())
+		return
+	}
+	var req CheckpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid checkpoint: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	cp, err := s.TakeCheckpoint(req.Name)
+	if err != nil {
+		http.Error(w, "invalid checkpoint: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, cp)
+}
+
+// HandleCheckpoint returns (GET) or deletes (DELETE) the checkpoint named
+// in the path.
+func (s *Server) HandleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if r.Method == http.MethodDelete {
+		if !s.DeleteCheckpoint(name) {
+			http.Error(w, "checkpoint not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	cp, ok := s.Checkpoint(name)
+	if !ok {
+		http.Error(w, "checkpoint not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, cp)
+}
+
+// HandleBranch branches the run from the checkpoint named in the path.
+func (s *Server) HandleBranch(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if _, ok := s.Checkpoint(r.PathValue("name")); !ok {
+		http.Error(w, "checkpoint not found", http.StatusNotFound)
+		return
+	}
+	result, err := s.Branch(r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,129 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Websocket client kinds.
+const (
+	ClientControl  = "control"
+	ClientPlayback = "playback"
+	ClientVoice    = "voice"
+)
+
+// clientRateWindow is the period over which a client's message rate is
+// measured.
+const clientRateWindow = 5 * time.Second
+
+// clientRegistry tracks the connected websocket clients.
+type clientRegistry struct {
+	mu      sync.Mutex
+	nextID  int64
+	clients map[int64]*wsClient
+}
+
+// connect registers a client of the given kind for conn.
+func (s *Server) connect(kind string, conn *websocket.Conn, r *http.Request) *wsClient {
+	c := &wsClient{conn: conn, validate: s.ValidateMessages, chaos: s.Chaos, kind: kind, remote: r.RemoteAddr, connected: time.Now()}
+	c.windowStart = c.connected
+	if s.Confirmations != nil {
+		c.controller, _ = s.Confirmations.Authenticate(r)
+	}
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	if s.clients.clients == nil {
+		s.clients.clients = make(map[int64]*wsClient)
+	}
+	s.clients.nextID++
+	c.id = s.clients.nextID
+	s.clients.clients[c.id] = c
+	return c
+}
+
+// clientLimitReached reports whether MaxClients are already connected.
+func (s *Server) clientLimitReached() bool {
+	if s.MaxClients <= 0 {
+		return false
+	}
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	return len(s.clients.clients) >= s.MaxClients
+}
+
+// disconnect forgets a client.
+func (s *Server) disconnect(c *wsClient) {
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	delete(s.clients.clients, c.id)
+}
+
+// recordSentLocked counts a message written to the client, rolling the
+// rate window over when it has elapsed.
+func (c *wsClient) recordSentLocked(now time.Time) {
+	c.sent++
+	c.windowSent++
+	if elapsed := now.Sub(c.windowStart); elapsed >= clientRateWindow {
+		c.rate = float64(c.windowSent) / elapsed.Seconds()
+		c.windowStart, c.windowSent = now, 0
+	}
+}
+
+// ClientStats describes one connected websocket client, to find slow
+// dashboards degrading the hub.
+type ClientStats struct {
+	ID     int64  `json:"id"`
+	Kind   string `json:"kind"`
+	Remote string `json:"remote"`
+	// Controller is the authenticated controller, if any.
+	Controller  string    `json:"controller,omitempty"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	// MessagesSent counts messages written; MessagesPerSecond is the rate
+	// over the last complete window.
+	MessagesSent      int64   `json:"messagesSent"`
+	MessagesPerSecond float64 `json:"messagesPerSecond"`
+	// QueueDepth is the events buffered for the client out of
+	// QueueCapacity; DroppedFrames counts events it lost to a full buffer.
+	QueueDepth    int   `json:"queueDepth"`
+	QueueCapacity int   `json:"queueCapacity"`
+	DroppedFrames int64 `json:"droppedFrames"`
+}
+
+// Clients reports the connected websocket clients, oldest first.
+func (s *Server) Clients() []ClientStats {
+	s.clients.mu.Lock()
+	clients := make([]*wsClient, 0, len(s.clients.clients))
+	for _, c := range s.clients.clients {
+		clients = append(clients, c)
+	}
+	s.clients.mu.Unlock()
+	sort.Slice(clients, func(i, j int) bool { return clients[i].id < clients[j].id })
+
+	out := make([]ClientStats, 0, len(clients))
+	for _, c := range clients {
+		c.mu.Lock()
+		stats := ClientStats{ID: c.id, Kind: c.kind, Remote: c.remote, Controller: c.controller, ConnectedAt: c.connected, MessagesSent: c.sent, MessagesPerSecond: c.rate}
+		if c.windowSent > 0 && c.sent == c.windowSent {
+			// Still in the first window.
+			stats.MessagesPerSecond = float64(c.windowSent) / max(time.Since(c.windowStart).Seconds(), 1)
+		}
+		sub := c.sub
+		c.mu.Unlock()
+		if sub != nil {
+			stats.QueueDepth, stats.QueueCapacity = sub.Pending()
+			stats.DroppedFrames = sub.Dropped()
+		}
+		out = append(out, stats)
+	}
+	return out
+}
+
+// HandleClients lists connected websocket clients with their message
+// statistics.
+func (s *Server) HandleClients(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Clients())
+}
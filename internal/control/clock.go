@@ -0,0 +1,155 @@
+package control
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so the scheduler can run against wall-clock time or a
+// virtual clock stepped by an embedding program.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc runs f once d has elapsed. The returned function cancels the
+	// call and reports whether it was still pending.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// RealClock is the wall-clock implementation of Clock.
+type RealClock struct{}
+
+// Now implements Clock.
+func (RealClock) Now() time.Time { return time.Now() }
+
+// AfterFunc implements Clock.
+func (RealClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// SimClock runs simulation time from an epoch at a fixed speed factor. It
+// measures elapsed time on the monotonic clock, so stepping the wall clock,
+// as NTP may, never moves simulation time.
+type SimClock struct {
+	epoch time.Time
+	start time.Time
+	speed float64
+}
+
+// NewSimClock returns a clock reading epoch now and advancing speed
+// simulated seconds per real second; a non-positive speed means 1.
+func NewSimClock(epoch time.Time, speed float64) *SimClock {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &SimClock{epoch: epoch, start: time.Now(), speed: speed}
+}
+
+// Now implements Clock.
+func (c *SimClock) Now() time.Time {
+	elapsed := time.Since(c.start)
+	return c.epoch.Add(time.Duration(float64(elapsed) * c.speed))
+}
+
+// AfterFunc implements Clock, running f once d of simulation time has
+// elapsed.
+func (c *SimClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(time.Duration(float64(d)/c.speed), f).Stop
+}
+
+// Epoch returns the simulation time the clock started at.
+func (c *SimClock) Epoch() time.Time { return c.epoch }
+
+// Speed returns the simulated seconds per real second.
+func (c *SimClock) Speed() float64 { return c.speed }
+
+// VirtualClock only moves when Advance is called. Timers fire synchronously
+// inside Advance, in deadline order, so a run is fully deterministic.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	nextID int64
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	id       int64
+	deadline time.Time
+	f        func()
+}
+
+// NewVirtualClock returns a virtual clock starting at start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now implements Clock.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc implements Clock.
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	t := &virtualTimer{id: c.nextID, deadline: c.now.Add(max(d, 0)), f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, candidate := range c.timers {
+			if candidate == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Advance moves the clock forward by d, running every timer that falls due
+// along the way. Timers scheduled by those callbacks fire too if they are due
+// before the new time.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		next := c.popDueLocked(target)
+		if next == nil {
+			break
+		}
+		c.now = next.deadline
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// Pending reports how many timers are waiting to fire.
+func (c *VirtualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *VirtualClock) popDueLocked(target time.Time) *virtualTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		if c.timers[i].deadline.Equal(c.timers[j].deadline) {
+			return c.timers[i].id < c.timers[j].id
+		}
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	first := c.timers[0]
+	if first.deadline.After(target) {
+		return nil
+	}
+	c.timers = c.timers[1:]
+	return first
+}
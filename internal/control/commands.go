@@ -0,0 +1,322 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types for scheduled commands.
+const (
+	EventCommandScheduled = "commandScheduled"
+	EventCommandExecuted  = "commandExecuted"
+	EventCommandCancelled = "commandCancelled"
+)
+
+// Command is a control command that can be scheduled: a rate, runway,
+// wind or visibility change, a wildlife report, or a burst of arrivals,
+// carrying the same fields as the websocket message of that type.
+type Command struct {
+	Type       string           `json:"type"`
+	Rate       int64            `json:"rate,omitempty"`
+	Runway     string           `json:"runway,omitempty"`
+	Closed     bool             `json:"closed,omitempty"`
+	Wind       *WindState       `json:"wind,omitempty"`
+	Visibility *VisibilityState `json:"visibility,omitempty"`
+	// Wildlife replaces the wildlife hazard; with Runway set, a wildlife
+	// command also closes that runway for dispersal.
+	Wildlife *WildlifeHazard `json:"wildlife,omitempty"`
+	// RampSeconds spreads a rate change over that many seconds.
+	RampSeconds int64 `json:"rampSeconds,omitempty"`
+	// Count is the number of flights a burst spawns.
+	Count int `json:"count,omitempty"`
+}
+
+func (c Command) String() string {
+	switch c.Type {
+	case "rate":
+		if c.RampSeconds > 0 {
+			return fmt.Sprintf("rate %d/min over %ds", c.Rate, c.RampSeconds)
+		}
+		return fmt.Sprintf("rate %d/min", c.Rate)
+	case "runway":
+		if c.Closed {
+			return "close " + c.Runway
+		}
+		return "open " + c.Runway
+	case "wind":
+		return "wind " + windDetail(normalizeWind(*c.Wind))
+	case "visibility":
+		return fmt.Sprintf("visibility %dm", c.Visibility.Meters)
+	case "wildlife":
+		var parts []string
+		if c.Wildlife != nil {
+			parts = append(parts, "wildlife hazard "+c.Wildlife.summary())
+		}
+		if c.Runway != "" {
+			parts = append(parts, "disperse wildlife on "+c.Runway)
+		}
+		return strings.Join(parts, ", ")
+	case "burst":
+		return fmt.Sprintf("burst of %d flights", c.Count)
+	}
+	return c.Type
+}
+
+// ScheduledCommand is a command queued to run at ExecuteAt on the
+// simulation clock, as in closing a runway at 14:00 sim time.
+type ScheduledCommand struct {
+	ID        string    `json:"id"`
+	ExecuteAt time.Time `json:"executeAt"`
+	Command   Command   `json:"command"`
+	// RequestedBy is the authenticated controller who queued it, if any.
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+type queuedCommand struct {
+	cmd  ScheduledCommand
+	stop func() bool
+}
+
+// commandQueue holds the commands waiting for their execution time.
+type commandQueue struct {
+	mu      sync.Mutex
+	pending map[string]*queuedCommand
+	nextID  int
+}
+
+// validateCommand checks cmd can be applied.
+func (s *Server) validateCommand(cmd Command) error {
+	switch cmd.Type {
+	case "rate":
+		if cmd.Rate <= 0 {
+			return errors.New("rate must be positive")
+		}
+		if cmd.RampSeconds < 0 {
+			return errors.New("ramp must not be negative")
+		}
+		return nil
+	case "runway", "wind", "visibility", "wildlife", "burst":
+	default:
+		return fmt.Errorf("unknown command type %q", cmd.Type)
+	}
+	if s.Runways == nil {
+		return errors.New("scheduler unavailable")
+	}
+	switch {
+	case cmd.Type == "runway" && !slices.Contains(s.Runways.RunwayNames(), s.Runways.ResolveRunway(cmd.Runway)):
+		return fmt.Errorf("unknown runway %q", cmd.Runway)
+	case cmd.Type == "wind" && cmd.Wind == nil:
+		return errors.New("wind missing")
+	case cmd.Type == "visibility" && cmd.Visibility == nil:
+		return errors.New("visibility missing")
+	case cmd.Type == "wildlife" && cmd.Wildlife == nil && cmd.Runway == "":
+		return errors.New("wildlife hazard or runway missing")
+	case cmd.Type == "wildlife" && cmd.Runway != "" && !slices.Contains(s.Runways.RunwayNames(), s.Runways.ResolveRunway(cmd.Runway)):
+		return fmt.Errorf("unknown runway %q", cmd.Runway)
+	case cmd.Type == "wildlife" && cmd.Wildlife != nil:
+		return validateWildlife(*cmd.Wildlife)
+	case cmd.Type == "burst":
+		return validateBurst(cmd.Count)
+	}
+	return nil
+}
+
+// Schedule queues cmd to run at at on the scheduler clock.
+func (s *Server) Schedule(cmd Command, at time.Time, controller string) (ScheduledCommand, error) {
+	if err := s.validateCommand(cmd); err != nil {
+		return ScheduledCommand{}, err
+	}
+	clock := s.Runways.Clock()
+	now := clock.Now()
+	if at.Before(now) {
+		return ScheduledCommand{}, fmt.Errorf("executeAt %s is in the past", at.Format(time.RFC3339))
+	}
+	s.commands.mu.Lock()
+	if s.commands.pending == nil {
+		s.commands.pending = make(map[string]*queuedCommand)
+	}
+	s.commands.nextID++
+	q := &queuedCommand{cmd: ScheduledCommand{ID: "cmd-" + strconv.Itoa(s.commands.nextID), ExecuteAt: at, Command: cmd, RequestedBy: controller}}
+	s.commands.pending[q.cmd.ID] = q
+	q.stop = clock.AfterFunc(at.Sub(now), func() { s.executeScheduled(q.cmd.ID) })
+	s.commands.mu.Unlock()
+
+	log.Printf("%s scheduled for %s as %s", cmd, at.Format(time.TimeOnly), q.cmd.ID)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventCommandScheduled, Detail: fmt.Sprintf("%s: %s at %s", q.cmd.ID, cmd, at.Format(time.TimeOnly))})
+	}
+	return q.cmd, nil
+}
+
+// CancelCommand removes a queued command, reporting whether it was still
+// waiting.
+func (s *Server) CancelCommand(id string) bool {
+	s.commands.mu.Lock()
+	q, ok := s.commands.pending[id]
+	if ok {
+		delete(s.commands.pending, id)
+		q.stop()
+	}
+	s.commands.mu.Unlock()
+	if !ok {
+		return false
+	}
+	log.Printf("scheduled %s (%s) cancelled", id, q.cmd.Command)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventCommandCancelled, Detail: fmt.Sprintf("%s: %s", id, q.cmd.Command)})
+	}
+	return true
+}
+
+// ScheduledCommands lists the queued commands, soonest first.
+func (s *Server) ScheduledCommands() []ScheduledCommand {
+	s.commands.mu.Lock()
+	out := make([]ScheduledCommand, 0, len(s.commands.pending))
+	for _, q := range s.commands.pending {
+		out = append(out, q.cmd)
+	}
+	s.commands.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ExecuteAt.Equal(out[j].ExecuteAt) {
+			return out[i].ExecuteAt.Before(out[j].ExecuteAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// executeScheduled runs queued command id, unless it was cancelled. Under
+// the two-person policy a command needing confirmation is held for it as
+// if its requester had just sent it.
+func (s *Server) executeScheduled(id string) {
+	s.commands.mu.Lock()
+	q, ok := s.commands.pending[id]
+	delete(s.commands.pending, id)
+	s.commands.mu.Unlock()
+	if !ok {
+		return
+	}
+	cmd := q.cmd.Command
+	apply := func() { s.applyCommand(cmd) }
+	if s.Confirmations != nil {
+		p := s.previewCommand(cmd)
+		if reason := s.Confirmations.Check(p); reason != "" {
+			requester := q.cmd.RequestedBy
+			if requester == "" {
+				requester = "scheduler"
+			}
+			s.Confirmations.Request(requester, p, reason, apply)
+			return
+		}
+	}
+	log.Printf("executing scheduled %s (%s)", id, cmd)
+	apply()
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventCommandExecuted, Detail: fmt.Sprintf("%s: %s", id, cmd)})
+	}
+}
+
+// applyCommand applies a validated command.
+func (s *Server) applyCommand(cmd Command) {
+	switch cmd.Type {
+	case "rate":
+		s.setRate(cmd.Rate, time.Duration(cmd.RampSeconds)*time.Second)
+	case "runway":
+		s.Runways.SetRunwayClosed(cmd.Runway, cmd.Closed)
+	case "wind":
+		s.Runways.SetWindState(*cmd.Wind)
+	case "visibility":
+		s.Runways.SetVisibility(cmd.Visibility.Meters)
+		if cmd.Visibility.CeilingFeet != nil {
+			s.Runways.SetCeiling(*cmd.Visibility.CeilingFeet)
+		}
+	case "wildlife":
+		s.applyWildlife(cmd)
+	case "burst":
+		s.Burst(cmd.Count)
+	}
+}
+
+// applyWildlife applies a validated wildlife command: the hazard first, so
+// a dispersal it asks for is logged at the new level.
+func (s *Server) applyWildlife(cmd Command) {
+	if cmd.Wildlife != nil {
+		if err := s.Runways.SetWildlifeHazard(*cmd.Wildlife); err != nil {
+			log.Printf("wildlife command ignored: %v", err)
+		}
+	}
+	if cmd.Runway != "" {
+		if err := s.Runways.DisperseWildlife(cmd.Runway); err != nil {
+			log.Printf("wildlife dispersal ignored: %v", err)
+		}
+	}
+}
+
+// previewCommand predicts the effect of a validated command.
+func (s *Server) previewCommand(cmd Command) Preview {
+	if cmd.Type == "visibility" {
+		return s.Runways.PreviewVisibility(cmd.Visibility.Meters, cmd.Visibility.CeilingFeet)
+	}
+	if cmd.Type == "wildlife" {
+		p := Preview{Command: "wildlife"}
+		if cmd.Runway != "" {
+			p = s.Runways.PreviewRunwayClosed(cmd.Runway, true)
+			p.Command = "wildlife"
+		}
+		if cmd.Wildlife != nil {
+			p.Effects = append([]string{"wildlife hazard will be " + cmd.Wildlife.summary()}, p.Effects...)
+		}
+		return p
+	}
+	p, _ := s.preview(Message{Type: cmd.Type, Rate: cmd.Rate, Runway: cmd.Runway, Closed: cmd.Closed, Wind: cmd.Wind, Count: cmd.Count})
+	return p
+}
+
+// HandleCommands lists the scheduled commands (GET) or queues one (POST).
+func (s *Server) HandleCommands(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.ScheduledCommands())
+		return
+	}
+	var req ScheduledCommand
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid command: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var controller string
+	if s.Confirmations != nil {
+		controller, _ = s.Confirmations.Authenticate(r)
+	}
+	scheduled, err := s.Schedule(req.Command, req.ExecuteAt, controller)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, scheduled)
+}
+
+// HandleCommand cancels the scheduled command named in the path.
+func (s *Server) HandleCommand(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.CancelCommand(r.PathValue("id")) {
+		http.Error(w, "command not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
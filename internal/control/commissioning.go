@@ -0,0 +1,245 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Event types for runways added and decommissioned at runtime.
+const (
+	EventRunwayAdded   = "runwayAdded"
+	EventRunwayRemoved = "runwayRemoved"
+)
+
+// RunwayDefinitions lists the runways in scheduling order.
+func (rm *RunwayManager) RunwayDefinitions() []RunwayDefinition {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayDefinition, 0, len(rm.order))
+	for _, name := range rm.order {
+		out = append(out, rm.runways[name].definition)
+	}
+	return out
+}
+
+// AddRunway commissions a runway, open and last in the assignment order.
+// Holding arrivals and waiting departures may use it at once.
+func (rm *RunwayManager) AddRunway(def RunwayDefinition) error {
+	if def.Name == "" {
+		return fmt.Errorf("runway name missing")
+	}
+	if def.Heading < 0 || def.Heading > 360 {
+		return fmt.Errorf("heading %.0f out of range", def.Heading)
+	}
+	if def.Length < 0 {
+		return fmt.Errorf("length must not be negative")
+	}
+	for _, e := range def.Exits {
+		if e.Distance <= 0 {
+			return fmt.Errorf("exit %q: distance must be positive", e.Name)
+		}
+	}
+	if !ValidApproach(def.Approach) {
+		return fmt.Errorf("unknown approach %q", def.Approach)
+	}
+	for _, item := range def.Requires {
+		if !ValidEquipage(item) {
+			return fmt.Errorf("unknown equipage %q", item)
+		}
+	}
+	if def.Reciprocal != "" && def.Reciprocal == def.Name {
+		return fmt.Errorf("runway %q cannot be its own reciprocal", def.Name)
+	}
+	rm.mu.Lock()
+	if _, ok := rm.runways[rm.resolveLocked(def.Name)]; ok {
+		rm.mu.Unlock()
+		return fmt.Errorf("runway %q already exists", def.Name)
+	}
+	if def.Reciprocal != "" {
+		if _, ok := rm.runways[rm.resolveLocked(def.Reciprocal)]; ok {
+			rm.mu.Unlock()
+			return fmt.Errorf("reciprocal %q already names a runway", def.Reciprocal)
+		}
+	}
+	r := &runwayState{definition: def, open: true, stats: runwayStats{since: rm.clock.Now()}}
+	r.activeHeading = rm.bestHeading(def)
+	rm.runways[def.Name] = r
+	rm.order = append(rm.order, def.Name)
+	rm.addAliasLocked(def.Name)
+	if rm.friction.IntervalSeconds > 0 {
+		interval := time.Duration(rm.friction.IntervalSeconds) * time.Second
+		rm.frictionRuns[def.Name] = &frictionRun{due: rm.clock.Now().Add(interval)}
+		rm.afterFrictionLocked(interval, def.Name, rm.planFrictionTest)
+	}
+	if rm.metrics != nil {
+		rm.metrics.AddRunway(def.Name)
+	}
+	rm.version++
+	rm.publishEventLocked(Event{Type: EventRunwayAdded, Runway: def.Name, Detail: fmt.Sprintf("heading %03.0f", r.activeHeading)})
+	log.Printf("runway %s commissioned", rm.runwayEndsLocked(def.Name))
+	rm.mu.Unlock()
+	rm.releaseHolding()
+	return nil
+}
+
+// RemoveRunway decommissions a runway. Arrivals sequenced to it go to
+// holding and departures waiting for it move to another runway; its
+// equipment failures and ground traffic go with it. The last runway cannot
+// be removed.
+func (rm *RunwayManager) RemoveRunway(name string) error {
+	rm.mu.Lock()
+	runway := rm.resolveLocked(name)
+	if _, ok := rm.runways[runway]; !ok {
+		rm.mu.Unlock()
+		return fmt.Errorf("unknown runway %q", name)
+	}
+	if len(rm.order) == 1 {
+		rm.mu.Unlock()
+		return fmt.Errorf("cannot remove the last runway")
+	}
+	for _, f := range rm.assigned[runway] {
+		rm.cancelLandingLocked(f.ID)
+		delete(rm.dueAt, f.ID)
+	}
+	diverted := rm.divertLocked(runway, SourceOperator, "diverted by runway decommissioning")
+	waiting := rm.departures[runway]
+	ends := rm.runwayEndsLocked(runway)
+
+	delete(rm.runways, runway)
+	rm.order = slices.DeleteFunc(rm.order, func(n string) bool { return n == runway })
+	for alias, target := range rm.aliases {
+		if target == runway {
+			delete(rm.aliases, alias)
+		}
+	}
+	for id, inc := range rm.incidents {
+		if inc.Runway == runway {
+			delete(rm.incidents, id)
+		}
+	}
+	for id, m := range rm.ground {
+		if m.Runway == runway {
+			delete(rm.ground, id)
+		}
+	}
+	delete(rm.incursions, runway)
+	delete(rm.assigned, runway)
+	delete(rm.lastUse, runway)
+	delete(rm.lastAssigned, runway)
+	delete(rm.departures, runway)
+	delete(rm.takingOff, runway)
+	delete(rm.transitions, runway)
+	delete(rm.frictionRuns, runway)
+	delete(rm.dispersals, runway)
+	delete(rm.stormBlocked, runway)
+	delete(rm.preferences, runway)
+	delete(rm.timing, runway)
+	if rm.metrics != nil {
+		rm.metrics.RemoveRunway(runway)
+	}
+
+	for _, d := range waiting {
+		next := rm.departureRunwayLocked(d.flight)
+		rm.departures[next] = append(rm.departures[next], d)
+	}
+	rm.publishDeparturesLocked()
+	rm.version++
+	rm.publishEventLocked(Event{Type: EventRunwayRemoved, Runway: runway, Detail: fmt.Sprintf("%d arrivals to holding, %d departures moved", diverted, len(waiting))})
+	log.Printf("runway %s decommissioned; %d arrivals to holding, %d departures moved", ends, diverted, len(waiting))
+	rm.mu.Unlock()
+	rm.releaseHolding()
+	return nil
+}
+
+// PreviewRemoveRunway predicts decommissioning runway.
+func (rm *RunwayManager) PreviewRemoveRunway(name string) Preview {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	p := Preview{Command: "removeRunway"}
+	runway := rm.resolveLocked(name)
+	if _, ok := rm.runways[runway]; !ok {
+		p.Error = fmt.Sprintf("unknown runway %q", name)
+		return p
+	}
+	if len(rm.order) == 1 {
+		p.Error = "cannot remove the last runway"
+		return p
+	}
+	for _, f := range rm.assigned[runway] {
+		p.Diverted = append(p.Diverted, f.Call)
+	}
+	p.Effects = append(p.Effects, fmt.Sprintf("removing %s will divert %d flights to holding", runway, len(p.Diverted)))
+	if waiting := len(rm.departures[runway]); waiting > 0 {
+		p.Effects = append(p.Effects, fmt.Sprintf("%d departures will move to another runway", waiting))
+	}
+	remaining := 0
+	for _, other := range rm.order {
+		if other != runway && rm.runways[other].available() {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		p.ClosesLastRunway = true
+		p.Effects = append(p.Effects, "no runway will remain open; all arrivals will hold")
+	}
+	return p
+}
+
+// HandleRunways lists the runways (GET) or commissions one (POST).
+func (s *Server) HandleRunways(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Runways.RunwayDefinitions())
+		return
+	}
+	var def RunwayDefinition
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		http.Error(w, "invalid runway: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Runways.AddRunway(def); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, def)
+}
+
+// HandleRunway reports (GET) or decommissions (DELETE) a runway.
+// Decommissioning that diverts many flights or leaves no runway open waits
+// for a second controller when confirmation is enabled.
+func (s *Server) HandleRunway(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.handleRunwayDetail(w, r)
+		return
+	}
+	runway := r.PathValue("runway")
+	preview := s.Runways.PreviewRemoveRunway(runway)
+	if dryRun(r) {
+		writeJSON(w, http.StatusOK, preview)
+		return
+	}
+	apply := func() {
+		if err := s.Runways.RemoveRunway(runway); err != nil {
+			log.Printf("confirmed removal of runway %s: %v", runway, err)
+		}
+	}
+	if s.Confirmations != nil && s.requestConfirmation(w, r, preview, apply) {
+		return
+	}
+	if err := s.Runways.RemoveRunway(runway); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
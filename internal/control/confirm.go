@@ -0,0 +1,304 @@
+package control
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types for two-person confirmation.
+const (
+	EventConfirmationRequired = "confirmationRequired"
+	EventActionConfirmed      = "actionConfirmed"
+	EventActionRejected       = "actionRejected"
+	EventConfirmationExpired  = "confirmationExpired"
+)
+
+const (
+	defaultConfirmationTimeout = time.Minute
+	defaultMassDiversion       = 5
+)
+
+var (
+	errConfirmationNotFound = errors.New("no such pending action")
+	errSelfConfirmation     = errors.New("an action must be confirmed by a second controller")
+)
+
+// ConfirmationPolicy requires a second controller to confirm high-impact
+// actions: closing the last open runway, or any command diverting at least
+// MassDiversion flights (5 by default). Controllers maps each controller's
+// bearer token to their name. An action not confirmed within TimeoutSeconds,
+// 60 by default, is dropped.
+type ConfirmationPolicy struct {
+	Controllers    map[string]string `json:"controllers"`
+	TimeoutSeconds float64           `json:"timeoutSeconds,omitempty"`
+	MassDiversion  int               `json:"massDiversion,omitempty"`
+}
+
+// PendingAction is a high-impact action waiting for a second controller.
+type PendingAction struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	// Effects are the command's predicted effects; Reason why it needs
+	// confirmation.
+	Effects     []string  `json:"effects"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requestedBy"`
+	RequestedAt time.Time `json:"requestedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	// ConfirmedBy is set once the action has been confirmed and applied.
+	ConfirmedBy string `json:"confirmedBy,omitempty"`
+}
+
+type pendingAction struct {
+	action PendingAction
+	apply  func()
+}
+
+// Confirmations holds high-impact actions until a second controller
+// confirms them.
+type Confirmations struct {
+	policy  ConfirmationPolicy
+	timeout time.Duration
+	events  *EventBus
+
+	mu      sync.Mutex
+	pending map[string]*pendingAction
+	order   []string
+	nextID  int
+}
+
+// NewConfirmations enforces policy, which must name at least two
+// controllers. The event bus is optional.
+func NewConfirmations(policy ConfirmationPolicy, events *EventBus) (*Confirmations, error) {
+	names := make(map[string]bool, len(policy.Controllers))
+	for token, name := range policy.Controllers {
+		if token == "" || name == "" {
+			return nil, errors.New("controllers need a token and a name")
+		}
+		names[name] = true
+	}
+	if len(names) < 2 {
+		return nil, errors.New("two-person confirmation needs at least two controllers")
+	}
+	if policy.TimeoutSeconds < 0 || policy.MassDiversion < 0 {
+		return nil, errors.New("timeoutSeconds and massDiversion must not be negative")
+	}
+	if policy.MassDiversion == 0 {
+		policy.MassDiversion = defaultMassDiversion
+	}
+	timeout := defaultConfirmationTimeout
+	if policy.TimeoutSeconds > 0 {
+		timeout = time.Duration(policy.TimeoutSeconds * float64(time.Second))
+	}
+	return &Confirmations{policy: policy, timeout: timeout, events: events, pending: make(map[string]*pendingAction)}, nil
+}
+
+// Authenticate names the controller a request carries the token of, from
+// an Authorization bearer header or, for websockets, the token query
+// parameter.
+func (c *Confirmations) Authenticate(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	name, ok := c.policy.Controllers[token]
+	return name, ok && token != ""
+}
+
+// Check reports why a command with predicted effect p needs confirmation,
+// or "" if it does not.
+func (c *Confirmations) Check(p Preview) string {
+	switch {
+	case p.Error != "":
+		return ""
+	case p.ClosesLastRunway:
+		return "closes the last open runway"
+	case len(p.Diverted) >= c.policy.MassDiversion:
+		return fmt.Sprintf("diverts %d flights", len(p.Diverted))
+	}
+	return ""
+}
+
+// Request holds apply, the command previewed by p, until a controller
+// other than controller confirms it.
+func (c *Confirmations) Request(controller string, p Preview, reason string, apply func()) PendingAction {
+	now := time.Now()
+	c.mu.Lock()
+	c.nextID++
+	action := PendingAction{
+		ID:          "action-" + strconv.Itoa(c.nextID),
+		Command:     p.Command,
+		Effects:     p.Effects,
+		Reason:      reason,
+		RequestedBy: controller,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(c.timeout),
+	}
+	c.pending[action.ID] = &pendingAction{action: action, apply: apply}
+	c.order = append(c.order, action.ID)
+	c.mu.Unlock()
+
+	log.Printf("%s by %s awaits confirmation: %s", action.Command, controller, reason)
+	c.publish(Event{Type: EventConfirmationRequired, Time: now, Detail: fmt.Sprintf("%s: %s by %s %s", action.ID, action.Command, controller, reason)})
+	time.AfterFunc(c.timeout, func() {
+		if _, ok := c.take(action.ID); ok {
+			log.Printf("%s by %s expired unconfirmed", action.Command, controller)
+			c.publish(Event{Type: EventConfirmationExpired, Detail: action.ID})
+		}
+	})
+	return action
+}
+
+// Confirm applies pending action id on behalf of controller, who must not
+// be the one who requested it.
+func (c *Confirmations) Confirm(id, controller string) (PendingAction, error) {
+	c.mu.Lock()
+	p, ok := c.pending[id]
+	if ok && p.action.RequestedBy == controller {
+		c.mu.Unlock()
+		return PendingAction{}, errSelfConfirmation
+	}
+	c.mu.Unlock()
+	p, ok = c.take(id)
+	if !ok {
+		return PendingAction{}, errConfirmationNotFound
+	}
+	p.action.ConfirmedBy = controller
+	log.Printf("%s by %s confirmed by %s", p.action.Command, p.action.RequestedBy, controller)
+	p.apply()
+	c.publish(Event{Type: EventActionConfirmed, Detail: fmt.Sprintf("%s: %s confirmed by %s", id, p.action.Command, controller)})
+	return p.action, nil
+}
+
+// Reject drops pending action id on behalf of controller.
+func (c *Confirmations) Reject(id, controller string) error {
+	p, ok := c.take(id)
+	if !ok {
+		return errConfirmationNotFound
+	}
+	log.Printf("%s by %s rejected by %s", p.action.Command, p.action.RequestedBy, controller)
+	c.publish(Event{Type: EventActionRejected, Detail: fmt.Sprintf("%s: %s rejected by %s", id, p.action.Command, controller)})
+	return nil
+}
+
+// Pending lists the actions awaiting confirmation, oldest first.
+func (c *Confirmations) Pending() []PendingAction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]PendingAction, 0, len(c.order))
+	for _, id := range c.order {
+		out = append(out, c.pending[id].action)
+	}
+	return out
+}
+
+// take removes pending action id, reporting whether it was still pending.
+func (c *Confirmations) take(id string) (*pendingAction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[id]
+	if !ok {
+		return nil, false
+	}
+	delete(c.pending, id)
+	for i, candidate := range c.order {
+		if candidate == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return p, true
+}
+
+func (c *Confirmations) publish(e Event) {
+	if c.events != nil {
+		c.events.Publish(e)
+	}
+}
+
+// holdForConfirmation asks the websocket client's controller to have a
+// second controller confirm the command previewed by preview, reporting
+// whether it was held rather than to be applied now. Commands needing
+// confirmation from clients that did not authenticate are refused.
+func (s *Server) holdForConfirmation(client *wsClient, preview func() Preview, apply func()) (bool, error) {
+	if s.Confirmations == nil {
+		return false, nil
+	}
+	p := preview()
+	reason := s.Confirmations.Check(p)
+	if reason == "" {
+		return false, nil
+	}
+	if client.controller == "" {
+		log.Printf("%s command refused: %s and the client is not authenticated", p.Command, reason)
+		return true, nil
+	}
+	action := s.Confirmations.Request(client.controller, p, reason, apply)
+	return true, client.send(Message{Type: "confirmation", Confirmation: &action})
+}
+
+// requestConfirmation holds a REST command needing confirmation, answering
+// 202 with the pending action. It reports whether the request was handled.
+func (s *Server) requestConfirmation(w http.ResponseWriter, r *http.Request, p Preview, apply func()) bool {
+	if s.Confirmations == nil {
+		return false
+	}
+	reason := s.Confirmations.Check(p)
+	if reason == "" {
+		return false
+	}
+	controller, ok := s.Confirmations.Authenticate(r)
+	if !ok {
+		http.Error(w, "controller authentication required: "+reason, http.StatusUnauthorized)
+		return true
+	}
+	writeJSON(w, http.StatusAccepted, s.Confirmations.Request(controller, p, reason, apply))
+	return true
+}
+
+// HandleConfirmations lists the actions awaiting a second controller.
+func (s *Server) HandleConfirmations(w http.ResponseWriter, r *http.Request) {
+	if s.Confirmations == nil {
+		http.Error(w, "two-person confirmation disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Confirmations.Pending())
+}
+
+// HandleConfirmation confirms (POST) or rejects (DELETE) the pending action
+// named in the path on behalf of the authenticated controller.
+func (s *Server) HandleConfirmation(w http.ResponseWriter, r *http.Request) {
+	if s.Confirmations == nil {
+		http.Error(w, "two-person confirmation disabled", http.StatusServiceUnavailable)
+		return
+	}
+	controller, ok := s.Confirmations.Authenticate(r)
+	if !ok {
+		http.Error(w, "controller authentication required", http.StatusUnauthorized)
+		return
+	}
+	id := r.PathValue("id")
+	if r.Method == http.MethodDelete {
+		if err := s.Confirmations.Reject(id, controller); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	action, err := s.Confirmations.Confirm(id, controller)
+	switch {
+	case errors.Is(err, errSelfConfirmation):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeJSON(w, http.StatusOK, action)
+	}
+}
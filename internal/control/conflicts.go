@@ -0,0 +1,96 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxConflicts is how many recent conflicts are kept for inspection.
+const maxConflicts = 200
+
+// Conflict is an arrival assigned to a runway sooner after the one before
+// it than the runway's spacing allows.
+type Conflict struct {
+	Time   time.Time `json:"time"`
+	Runway string    `json:"runway"`
+	// Leader is the arrival assigned before, Follower the one in conflict
+	// with it.
+	LeaderID     int64  `json:"leaderId"`
+	LeaderCall   string `json:"leaderCall"`
+	FollowerID   int64  `json:"followerId"`
+	FollowerCall string `json:"followerCall"`
+	// SpacingSeconds is the spacing achieved, RequiredSeconds the runway's
+	// spacing for the follower.
+	SpacingSeconds  float64 `json:"spacingSeconds"`
+	RequiredSeconds float64 `json:"requiredSeconds"`
+}
+
+// detectConflictLocked records a conflict if f, just assigned to runway,
+// follows the runway's previous arrival closer than its spacing.
+func (rm *RunwayManager) detectConflictLocked(runway string, f Flight) {
+	last, ok := rm.lastUse[runway]
+	if !ok {
+		return
+	}
+	now := rm.clock.Now()
+	delta := now.Sub(last)
+	required := rm.runwaySpacingLocked(runway, f)
+	if delta >= required {
+		return
+	}
+	leader := rm.lastAssigned[runway]
+	c := Conflict{
+		Time:            now,
+		Runway:          runway,
+		LeaderID:        leader.ID,
+		LeaderCall:      leader.Call,
+		FollowerID:      f.ID,
+		FollowerCall:    f.Call,
+		SpacingSeconds:  delta.Seconds(),
+		RequiredSeconds: required.Seconds(),
+	}
+	rm.conflicts = append(rm.conflicts, c)
+	if len(rm.conflicts) > maxConflicts {
+		rm.conflicts = rm.conflicts[len(rm.conflicts)-maxConflicts:]
+	}
+	if rm.metrics != nil {
+		rm.metrics.RecordConflict()
+	}
+	detail := fmt.Sprintf("%.1fs behind %s, %.1fs required", delta.Seconds(), leader.Call, required.Seconds())
+	rm.publishEventLocked(Event{Type: EventConflict, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: detail})
+	log.Printf("spacing conflict detected on %s: flight %d (%s) %s", runway, f.ID, f.Call, detail)
+}
+
+// Conflicts lists the most recent conflicts, oldest first, at most limit
+// of them when limit is positive.
+func (rm *RunwayManager) Conflicts(limit int) []Conflict {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	conflicts := rm.conflicts
+	if limit > 0 && len(conflicts) > limit {
+		conflicts = conflicts[len(conflicts)-limit:]
+	}
+	return append([]Conflict{}, conflicts...)
+}
+
+// HandleConflicts lists recent spacing conflicts; ?limit= keeps the last
+// few.
+func (s *Server) HandleConflicts(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Conflicts(limit))
+}
@@ -0,0 +1,115 @@
+package control
+
+import (
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultCSVInterval = time.Second
+	minCSVInterval     = 100 * time.Millisecond
+)
+
+// csvColumns are the metrics written to each CSV row, after the time.
+var csvColumns = []struct {
+	name  string
+	value func(MetricsSnapshot) float64
+}{
+	{"totalArrivals", func(s MetricsSnapshot) float64 { return float64(s.TotalArrivals) }},
+	{"averageWaitSeconds", func(s MetricsSnapshot) float64 { return s.AverageWaitSeconds }},
+	{"averageLandingSeconds", func(s MetricsSnapshot) float64 { return s.AverageLandingTime }},
+	{"averageOccupancySeconds", func(s MetricsSnapshot) float64 { return s.AverageOccupancy }},
+	{"holdingCurrent", func(s MetricsSnapshot) float64 { return float64(s.HoldingCurrent) }},
+	{"holdingPatterns", func(s MetricsSnapshot) float64 { return float64(s.HoldingPatterns) }},
+	{"conflicts", func(s MetricsSnapshot) float64 { return float64(s.ConflictDetections) }},
+	{"incursions", func(s MetricsSnapshot) float64 { return float64(s.Incursions) }},
+	{"goArounds", func(s MetricsSnapshot) float64 { return float64(s.GoArounds) }},
+	{"diversions", func(s MetricsSnapshot) float64 { return float64(s.Diversions) }},
+	{"fuelBurnedKg", func(s MetricsSnapshot) float64 { return s.FuelBurnedKg }},
+	{"departures", func(s MetricsSnapshot) float64 { return float64(s.Departures) }},
+	{"departureQueue", func(s MetricsSnapshot) float64 { return float64(s.DepartureQueue) }},
+	{"averageDepartureDelaySeconds", func(s MetricsSnapshot) float64 { return s.AverageDepartureDelaySeconds }},
+}
+
+// HandleMetricsCSV streams the metrics as CSV, a header then one row per
+// interval ("1s" by default), so an experiment can be piped straight into
+// a spreadsheet or pandas. With rows set the stream ends after that many.
+func (s *Server) HandleMetricsCSV(w http.ResponseWriter, r *http.Request) {
+	if s.Metrics == nil {
+		http.Error(w, "metrics unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	interval := defaultCSVInterval
+	if raw := r.URL.Query().Get("interval"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < minCSVInterval {
+			http.Error(w, "invalid interval (at least 100ms expected)", http.StatusBadRequest)
+			return
+		}
+		interval = d
+	}
+	rows := 0
+	if raw := r.URL.Query().Get("rows"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid rows", http.StatusBadRequest)
+			return
+		}
+		rows = n
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// The runway queue columns are fixed by the runways known at the start.
+	snap := s.Metrics.Snapshot()
+	runways := make([]string, 0, len(snap.QueueLengths))
+	for name := range snap.QueueLengths {
+		runways = append(runways, name)
+	}
+	sort.Strings(runways)
+
+	out := csv.NewWriter(w)
+	header := []string{"time"}
+	for _, c := range csvColumns {
+		header = append(header, c.name)
+	}
+	for _, name := range runways {
+		header = append(header, "queue_"+name)
+	}
+	out.Write(header)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for written := 1; ; written++ {
+		row := []string{s.ServerTime().Format(time.RFC3339Nano)}
+		for _, c := range csvColumns {
+			row = append(row, strconv.FormatFloat(c.value(snap), 'f', -1, 64))
+		}
+		for _, name := range runways {
+			row = append(row, strconv.FormatInt(snap.QueueLengths[name], 10))
+		}
+		out.Write(row)
+		out.Flush()
+		if out.Error() != nil {
+			return
+		}
+		flusher.Flush()
+		if written == rows {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		snap = s.Metrics.Snapshot()
+	}
+}
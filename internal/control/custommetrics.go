@@ -0,0 +1,197 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Custom metric kinds.
+const (
+	CustomCounter = "counter"
+	CustomGauge   = "gauge"
+)
+
+// customMetricName keeps custom metric names usable as Prometheus, StatsD
+// and InfluxDB field names.
+var customMetricName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// CustomMetric is an operator-defined counter or gauge. A counter counts
+// published events of type Event, optionally only those on Runway or with a
+// callsign starting CallPrefix; a gauge follows Source, any metric a rule
+// condition may reference. While, when set, limits counting or sampling to
+// the times the condition holds, as in "arrivals on 27 during LVP". Either
+// kind may also be driven by the incrementMetric and setMetric rule actions.
+type CustomMetric struct {
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Help       string     `json:"help,omitempty"`
+	Event      string     `json:"event,omitempty"`
+	Runway     string     `json:"runway,omitempty"`
+	CallPrefix string     `json:"callPrefix,omitempty"`
+	Source     string     `json:"source,omitempty"`
+	While      *Condition `json:"while,omitempty"`
+}
+
+func (m CustomMetric) validate() error {
+	if !customMetricName.MatchString(m.Name) {
+		return fmt.Errorf("invalid metric name %q", m.Name)
+	}
+	switch m.Kind {
+	case CustomCounter:
+		if m.Source != "" {
+			return errors.New("counters do not take a source")
+		}
+	case CustomGauge:
+		if m.Event != "" || m.Runway != "" || m.CallPrefix != "" {
+			return errors.New("gauges do not count events")
+		}
+	default:
+		return fmt.Errorf("unknown metric kind %q", m.Kind)
+	}
+	if m.While != nil {
+		return m.While.validate()
+	}
+	return nil
+}
+
+// counts reports whether e is one of the events counter m counts.
+func (m CustomMetric) counts(e Event) bool {
+	return m.Kind == CustomCounter && m.Event != "" && e.Type == m.Event &&
+		(m.Runway == "" || e.Runway == m.Runway) &&
+		(m.CallPrefix == "" || strings.HasPrefix(e.Call, m.CallPrefix))
+}
+
+// AddMetric registers a custom metric, replacing one of the same name. A
+// replaced metric keeps its value if the kind is unchanged.
+func (e *RulesEngine) AddMetric(m CustomMetric) (CustomMetric, error) {
+	if err := m.validate(); err != nil {
+		return CustomMetric{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if previous, ok := e.custom[m.Name]; !ok {
+		e.customOrder = append(e.customOrder, m.Name)
+	} else if previous.Kind != m.Kind && e.metrics != nil {
+		e.metrics.RemoveCustom(m.Name)
+	}
+	e.custom[m.Name] = m
+	if e.metrics != nil {
+		e.metrics.AddCustom(m.Name, 0)
+	}
+	return m, nil
+}
+
+// RemoveMetric deletes a custom metric and its value, reporting whether it
+// existed.
+func (e *RulesEngine) RemoveMetric(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.custom[name]; !ok {
+		return false
+	}
+	delete(e.custom, name)
+	for i, candidate := range e.customOrder {
+		if candidate == name {
+			e.customOrder = append(e.customOrder[:i], e.customOrder[i+1:]...)
+			break
+		}
+	}
+	if e.metrics != nil {
+		e.metrics.RemoveCustom(name)
+	}
+	return true
+}
+
+// Metrics lists the custom metrics in creation order.
+func (e *RulesEngine) Metrics() []CustomMetric {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]CustomMetric, 0, len(e.customOrder))
+	for _, name := range e.customOrder {
+		out = append(out, e.custom[name])
+	}
+	return out
+}
+
+// countEvent increments the counters e matches. Their While conditions are
+// checked against the values of the latest evaluation.
+func (e *RulesEngine) countEvent(ev Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.metrics == nil {
+		return
+	}
+	for _, name := range e.customOrder {
+		m := e.custom[name]
+		if m.counts(ev) && e.holdsLocked(m.While) {
+			e.metrics.AddCustom(name, 1)
+		}
+	}
+}
+
+// sampleGaugesLocked sets the gauges that follow a source to its value in
+// values.
+func (e *RulesEngine) sampleGaugesLocked(values map[string]float64) {
+	if e.metrics == nil {
+		return
+	}
+	for _, name := range e.customOrder {
+		m := e.custom[name]
+		if m.Kind != CustomGauge || m.Source == "" || !e.holdsLocked(m.While) {
+			continue
+		}
+		if v, ok := values[m.Source]; ok {
+			e.metrics.SetCustom(name, v)
+		}
+	}
+}
+
+// holdsLocked reports whether c, if any, holds at the latest evaluation. A
+// condition on a metric not sampled yet does not hold.
+func (e *RulesEngine) holdsLocked(c *Condition) bool {
+	if c == nil {
+		return true
+	}
+	v, ok := e.values[c.Metric]
+	return ok && c.matches(v)
+}
+
+// HandleCustomMetrics lists custom metrics (GET) or registers one (POST).
+func (s *Server) HandleCustomMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.Rules == nil {
+		http.Error(w, "rules engine disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Rules.Metrics())
+		return
+	}
+	var req CustomMetric
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid metric: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := s.Rules.AddMetric(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// HandleCustomMetric removes the custom metric named in the path.
+func (s *Server) HandleCustomMetric(w http.ResponseWriter, r *http.Request) {
+	if s.Rules == nil {
+		http.Error(w, "rules engine disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.Rules.RemoveMetric(r.PathValue("name")) {
+		http.Error(w, "metric not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,164 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// EventDeiced is published when a departure leaves a de-icing pad.
+const EventDeiced = "deiced"
+
+// Precipitation types.
+const (
+	PrecipitationNone            = "none"
+	PrecipitationRain            = "rain"
+	PrecipitationSnow            = "snow"
+	PrecipitationFreezingRain    = "freezingRain"
+	PrecipitationFreezingDrizzle = "freezingDrizzle"
+)
+
+// freezingPrecipitation lists the precipitation that requires de-icing.
+var freezingPrecipitation = []string{PrecipitationSnow, PrecipitationFreezingRain, PrecipitationFreezingDrizzle}
+
+var precipitationTypes = []string{PrecipitationNone, PrecipitationRain, PrecipitationSnow, PrecipitationFreezingRain, PrecipitationFreezingDrizzle}
+
+// baseDeicingTime is how long a medium aircraft occupies a de-icing pad;
+// other weight categories scale it like stand time.
+const baseDeicingTime = 2 * time.Minute
+
+// WinterOps configures winter operations. While enabled and the
+// precipitation is freezing, every departure is de-iced on one of Pads pads
+// between pushback and taxi-out.
+type WinterOps struct {
+	Enabled       bool   `json:"enabled"`
+	Pads          int    `json:"pads"`
+	Precipitation string `json:"precipitation"`
+}
+
+// deiceRequest is a departure waiting for a de-icing pad.
+type deiceRequest struct {
+	flight   Flight
+	distance float64
+	queuedAt time.Time
+}
+
+// SetWinterOps updates the winter operations mode. Departures already
+// waiting for de-icing are released to taxi when it is no longer needed.
+func (rm *RunwayManager) SetWinterOps(w WinterOps) error {
+	if w.Precipitation == "" {
+		w.Precipitation = PrecipitationNone
+	}
+	if !slices.Contains(precipitationTypes, w.Precipitation) {
+		return fmt.Errorf("unknown precipitation %q", w.Precipitation)
+	}
+	if w.Pads < 0 {
+		return fmt.Errorf("pads must not be negative")
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.winter = w
+	log.Printf("winter ops: enabled=%t pads=%d precipitation=%s", w.Enabled, w.Pads, w.Precipitation)
+	if !rm.needsDeicingLocked() {
+		for _, req := range rm.deiceQueue {
+			if rm.metrics != nil {
+				rm.metrics.RecordDelay(DelayDeicing, rm.clock.Now().Sub(req.queuedAt))
+			}
+			rm.beginTaxiLocked(req.flight, req.distance)
+		}
+		rm.deiceQueue = nil
+		rm.publishDeicingLocked()
+		return nil
+	}
+	rm.startDeicingLocked()
+	return nil
+}
+
+func (rm *RunwayManager) needsDeicingLocked() bool {
+	return rm.winter.Enabled && slices.Contains(freezingPrecipitation, rm.winter.Precipitation)
+}
+
+// departLocked sends a departure that just left its stand, distance meters
+// from the runways, through de-icing when required and then to taxi out.
+func (rm *RunwayManager) departLocked(f Flight, distance float64) {
+	if !rm.needsDeicingLocked() {
+		rm.beginTaxiLocked(f, distance)
+		return
+	}
+	rm.deiceQueue = append(rm.deiceQueue, deiceRequest{flight: f, distance: distance, queuedAt: rm.clock.Now()})
+	rm.startDeicingLocked()
+}
+
+// startDeicingLocked moves waiting departures onto free pads. The wait for
+// a pad counts as de-icing delay.
+func (rm *RunwayManager) startDeicingLocked() {
+	for len(rm.deiceQueue) > 0 && rm.deicing < rm.winter.Pads {
+		req := rm.deiceQueue[0]
+		rm.deiceQueue = rm.deiceQueue[1:]
+		rm.deicing++
+		wait := rm.clock.Now().Sub(req.queuedAt)
+		if rm.metrics != nil {
+			rm.metrics.RecordDeicingWait(wait)
+			rm.metrics.RecordDelay(DelayDeicing, wait)
+		}
+		rm.clock.AfterFunc(rm.deicingTime(req.flight), func() {
+			rm.mu.Lock()
+			defer rm.mu.Unlock()
+			rm.deicing--
+			rm.publishEventLocked(Event{Type: EventDeiced, FlightID: req.flight.ID, Call: req.flight.Call})
+			log.Printf("flight %d (%s) de-iced", req.flight.ID, req.flight.Call)
+			rm.beginTaxiLocked(req.flight, req.distance)
+			rm.startDeicingLocked()
+		})
+	}
+	rm.publishDeicingLocked()
+}
+
+func (rm *RunwayManager) deicingTime(f Flight) time.Duration {
+	return scaleByWeight(f, baseDeicingTime)
+}
+
+func (rm *RunwayManager) publishDeicingLocked() {
+	if rm.metrics == nil {
+		return
+	}
+	rm.metrics.SetDeicing(len(rm.deiceQueue), rm.deicing)
+}
+
+// WinterOpsState is the winter operations mode with the de-icing picture.
+type WinterOpsState struct {
+	Mode WinterOps `json:"mode"`
+	// Deicing and Queued count departures on pads and waiting for one.
+	Deicing int `json:"deicing"`
+	Queued  int `json:"queued"`
+}
+
+// HandleWinterOps reports (GET) or updates (PUT) winter operations.
+func (s *Server) HandleWinterOps(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req WinterOps
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid winter ops: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetWinterOps(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.WinterOpsState())
+}
+
+// WinterOpsState returns the winter operations mode and pad usage.
+func (rm *RunwayManager) WinterOpsState() WinterOpsState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return WinterOpsState{Mode: rm.winter, Deicing: rm.deicing, Queued: len(rm.deiceQueue)}
+}
@@ -0,0 +1,141 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Delay causes. Every second a flight spends holding or going around, or
+// sequenced behind other arrivals, is attributed to exactly one of them.
+const (
+	// DelayRunwayClosure covers runways closed by operators or equipment
+	// failures, occupied by ground traffic, or unusable for the flight's
+	// equipage.
+	DelayRunwayClosure = "runwayClosure"
+	// DelayWeather covers visibility below the flight's approach minima.
+	DelayWeather = "weather"
+	// DelayVolume is the spacing delay from queueing behind other arrivals.
+	DelayVolume = "volume"
+	// DelayTaxi is departure delay from taxi-out congestion and queueing at
+	// the runway holding point.
+	DelayTaxi = "taxi"
+	// DelayDeicing is departure delay waiting for a de-icing pad.
+	DelayDeicing = "deicing"
+	// DelayFrequency is arrival delay waiting for a vector to get airtime
+	// on a congested frequency.
+	DelayFrequency = "frequency"
+)
+
+// DelayCauses lists the delay causes in reporting order.
+var DelayCauses = []string{DelayRunwayClosure, DelayWeather, DelayVolume, DelayTaxi, DelayDeicing, DelayFrequency}
+
+// delayEntry is a delay in progress for one flight.
+type delayEntry struct {
+	call  string
+	cause string
+	since time.Time
+}
+
+// startDelayLocked marks f as delayed by cause from now. A delay already in
+// progress is attributed up to now to its own cause first.
+func (rm *RunwayManager) startDelayLocked(f Flight, cause string) {
+	rm.endDelayLocked(f)
+	rm.delays[f.ID] = delayEntry{call: f.Call, cause: cause, since: rm.clock.Now()}
+}
+
+// endDelayLocked attributes f's delay in progress, if any, to its cause and
+// charges the fuel burned holding meanwhile.
+func (rm *RunwayManager) endDelayLocked(f Flight) {
+	entry, ok := rm.delays[f.ID]
+	if !ok {
+		return
+	}
+	delete(rm.delays, f.ID)
+	held := rm.clock.Now().Sub(entry.since)
+	rm.burnLocked(f, held, false)
+	if rm.metrics != nil {
+		rm.metrics.RecordDelay(entry.cause, held)
+	}
+}
+
+// recordSpacingDelayLocked attributes the sequencing delay of a flight just
+// assigned to runway to traffic volume; the flight absorbs it by vectoring
+// before its landing is scheduled at the sequenced time.
+func (rm *RunwayManager) recordSpacingDelayLocked(runway string, f Flight) {
+	for _, e := range rm.ladderLocked(runway) {
+		if e.FlightID != f.ID || e.DelaySeconds <= 0 {
+			continue
+		}
+		d := time.Duration(e.DelaySeconds * float64(time.Second))
+		rm.burnLocked(f, d, true)
+		if rm.metrics != nil {
+			rm.metrics.RecordDelay(DelayVolume, d)
+		}
+		return
+	}
+}
+
+// DelayCause is the delay attributed to one cause.
+type DelayCause struct {
+	Cause   string  `json:"cause"`
+	Seconds float64 `json:"seconds"`
+	// Share is the fraction of all delay attributed to this cause.
+	Share float64 `json:"share"`
+}
+
+// DelayReport breaks total delay down by cause, largest first. It includes
+// delays still in progress.
+type DelayReport struct {
+	TotalSeconds float64      `json:"totalSeconds"`
+	Causes       []DelayCause `json:"causes"`
+	// PrimarySeconds and ReactionarySeconds split how late departures left
+	// their stands into delay arising here and delay propagated from the
+	// airframe's late inbound leg.
+	PrimarySeconds     float64 `json:"primarySeconds"`
+	ReactionarySeconds float64 `json:"reactionarySeconds"`
+}
+
+// DelayReport attributes all delay so far, including flights still holding,
+// to its causes.
+func (rm *RunwayManager) DelayReport() DelayReport {
+	seconds := make(map[string]float64, len(DelayCauses))
+	var report DelayReport
+	if rm.metrics != nil {
+		s := rm.metrics.Snapshot()
+		for cause, d := range s.DelaySeconds {
+			seconds[cause] = d
+		}
+		report.PrimarySeconds, report.ReactionarySeconds = s.PrimaryDelaySeconds, s.ReactionaryDelaySeconds
+	}
+	rm.mu.Lock()
+	now := rm.clock.Now()
+	for _, entry := range rm.delays {
+		seconds[entry.cause] += now.Sub(entry.since).Seconds()
+	}
+	rm.mu.Unlock()
+
+	report.Causes = make([]DelayCause, 0, len(DelayCauses))
+	for _, cause := range DelayCauses {
+		report.TotalSeconds += seconds[cause]
+		report.Causes = append(report.Causes, DelayCause{Cause: cause, Seconds: seconds[cause]})
+	}
+	for i := range report.Causes {
+		if report.TotalSeconds > 0 {
+			report.Causes[i].Share = report.Causes[i].Seconds / report.TotalSeconds
+		}
+	}
+	sort.SliceStable(report.Causes, func(i, j int) bool {
+		return report.Causes[i].Seconds > report.Causes[j].Seconds
+	})
+	return report
+}
+
+// HandleDelays serves the delay attribution report.
+func (s *Server) HandleDelays(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.DelayReport())
+}
@@ -0,0 +1,226 @@
+package control
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// EventTakeoff is published when a departure leaves the runway.
+const EventTakeoff = "takeoff"
+
+const (
+	// taxiSpeed is the unimpeded taxi speed in meters per second.
+	taxiSpeed = 10
+	// taxiCongestion is how much each other aircraft moving on the ground
+	// stretches a taxi-out, as a fraction of the unimpeded time.
+	taxiCongestion = 0.15
+	// defaultTaxiDistance applies to aircraft leaving without a stand.
+	defaultTaxiDistance = 1000
+	// takeoffDuration is the runway occupancy of one departure.
+	takeoffDuration = 4 * time.Second
+)
+
+// taxiOut is an aircraft taxiing from its stand to the holding point.
+type taxiOut struct {
+	flight    Flight
+	since     time.Time
+	unimpeded time.Duration
+	arrival   time.Time
+}
+
+// departure is an aircraft waiting at a runway holding point.
+type departure struct {
+	flight   Flight
+	queuedAt time.Time
+}
+
+// beginTaxiLocked starts f's taxi-out from a stand distance meters from the
+// runways. The more aircraft already moving on the ground, the longer it
+// takes; the excess over the unimpeded time counts as taxi delay. Under
+// low-visibility procedures it may first have to wait at its stand.
+func (rm *RunwayManager) beginTaxiLocked(f Flight, distance float64) {
+	if rm.holdTaxiLocked(f, distance) {
+		rm.publishDeparturesLocked()
+		return
+	}
+	if distance <= 0 {
+		distance = defaultTaxiDistance
+	}
+	unimpeded := time.Duration(distance / taxiSpeed * float64(time.Second))
+	actual := time.Duration(float64(unimpeded) * (1 + taxiCongestion*float64(len(rm.taxiing))))
+	now := rm.clock.Now()
+	rm.taxiing[f.ID] = taxiOut{flight: f, since: now, unimpeded: unimpeded, arrival: now.Add(actual)}
+	rm.publishDeparturesLocked()
+	rm.clock.AfterFunc(actual, func() { rm.reachHoldingPoint(f.ID) })
+}
+
+// reachHoldingPoint queues a taxiing aircraft at the holding point of the
+// runway with the shortest departure queue.
+func (rm *RunwayManager) reachHoldingPoint(id int64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	t, ok := rm.taxiing[id]
+	if !ok {
+		return
+	}
+	delete(rm.taxiing, id)
+	rm.releaseHeldTaxiLocked()
+	now := rm.clock.Now()
+	if rm.metrics != nil {
+		rm.metrics.RecordTaxiOut(now.Sub(t.since))
+		rm.metrics.RecordDelay(DelayTaxi, now.Sub(t.since)-t.unimpeded)
+	}
+	if rm.slas != nil {
+		rm.slas.Observe(SLATaxiOut, now.Sub(t.since), now)
+	}
+	runway := rm.departureRunwayLocked(t.flight)
+	if runway == "" {
+		log.Printf("flight %d (%s) has no runway to depart from", t.flight.ID, t.flight.Call)
+		rm.publishDeparturesLocked()
+		return
+	}
+	rm.departures[runway] = append(rm.departures[runway], departure{flight: t.flight, queuedAt: now})
+	rm.publishDeparturesLocked()
+	log.Printf("flight %d (%s) at holding point %s (%d queued)", t.flight.ID, t.flight.Call, runway, len(rm.departures[runway]))
+	rm.releaseDepartureLocked(runway)
+}
+
+// departureRunwayLocked picks the runway with the shortest departure queue
+// among those long enough for f, preferring available runways. When none is
+// long enough f waits at the longest runway for conditions to improve.
+func (rm *RunwayManager) departureRunwayLocked(f Flight) string {
+	best, longest := "", ""
+	for _, name := range rm.order {
+		if longest == "" || rm.landingDistanceLocked(name) > rm.landingDistanceLocked(longest) {
+			longest = name
+		}
+		if !rm.canDepartLocked(name, f) {
+			continue
+		}
+		if best == "" {
+			best = name
+			continue
+		}
+		avail, bestAvail := rm.runways[name].available(), rm.runways[best].available()
+		if avail && !bestAvail || avail == bestAvail && len(rm.departures[name]) < len(rm.departures[best]) {
+			best = name
+		}
+	}
+	if best == "" {
+		return longest
+	}
+	return best
+}
+
+// releaseDepartureLocked lines up the next departure at runway's holding
+// point if the runway is available, clear of ground traffic and no takeoff
+// is in progress. Departures the runway is too short for at the current
+// density altitude are passed over. The wait at the holding point counts as
+// taxi delay.
+func (rm *RunwayManager) releaseDepartureLocked(runway string) {
+	queue := rm.departures[runway]
+	if len(queue) == 0 || rm.takingOff[runway] || !rm.runways[runway].available() || rm.occupiedLocked(runway) {
+		return
+	}
+	next := -1
+	for i, d := range queue {
+		if rm.canDepartLocked(runway, d.flight) {
+			next = i
+			break
+		}
+	}
+	if next < 0 {
+		return
+	}
+	d := queue[next]
+	rm.departures[runway] = append(queue[:next:next], queue[next+1:]...)
+	rm.takingOff[runway] = true
+	if rm.metrics != nil {
+		rm.metrics.RecordDelay(DelayTaxi, rm.clock.Now().Sub(d.queuedAt))
+	}
+	rm.publishDeparturesLocked()
+	takeoff := rm.takeoffTimeLocked()
+	rm.recordTakeoffStatsLocked(runway, takeoff)
+	rm.clock.AfterFunc(takeoff, func() {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+		rm.takingOff[runway] = false
+		if rm.metrics != nil {
+			rm.metrics.RecordDeparture()
+		}
+		rm.recordLegLocked(d.flight)
+		rm.rotateLocked(d.flight)
+		rm.publishEventLocked(Event{Type: EventTakeoff, FlightID: d.flight.ID, Call: d.flight.Call, Runway: runway})
+		log.Printf("flight %d (%s) departed from %s", d.flight.ID, d.flight.Call, runway)
+		rm.releaseDepartureLocked(runway)
+	})
+}
+
+func (rm *RunwayManager) publishDeparturesLocked() {
+	if rm.metrics == nil {
+		return
+	}
+	queued := 0
+	for _, q := range rm.departures {
+		queued += len(q)
+	}
+	rm.metrics.SetGroundTraffic(len(rm.taxiing), queued)
+}
+
+// TaxiingFlight is an aircraft taxiing out.
+type TaxiingFlight struct {
+	FlightID  int64     `json:"flightId"`
+	Call      string    `json:"call"`
+	Since     time.Time `json:"since"`
+	Arrival   time.Time `json:"holdingPointAt"`
+	Unimpeded float64   `json:"unimpededSeconds"`
+}
+
+// HoldingPointQueue is the departure queue at one runway's holding point.
+type HoldingPointQueue struct {
+	Runway  string            `json:"runway"`
+	Flights []DepartingFlight `json:"flights"`
+}
+
+// DepartingFlight is an aircraft waiting at a holding point.
+type DepartingFlight struct {
+	FlightID int64     `json:"flightId"`
+	Call     string    `json:"call"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// DepartureState is the ground picture for departures.
+type DepartureState struct {
+	Taxiing       []TaxiingFlight     `json:"taxiing"`
+	HoldingPoints []HoldingPointQueue `json:"holdingPoints"`
+}
+
+// Departures reports aircraft taxiing out and queued at holding points.
+func (rm *RunwayManager) Departures() DepartureState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	state := DepartureState{Taxiing: make([]TaxiingFlight, 0, len(rm.taxiing)), HoldingPoints: make([]HoldingPointQueue, 0, len(rm.order))}
+	for _, t := range rm.taxiing {
+		state.Taxiing = append(state.Taxiing, TaxiingFlight{FlightID: t.flight.ID, Call: t.flight.Call, Since: t.since, Arrival: t.arrival, Unimpeded: t.unimpeded.Seconds()})
+	}
+	sort.Slice(state.Taxiing, func(i, j int) bool { return state.Taxiing[i].Arrival.Before(state.Taxiing[j].Arrival) })
+	for _, name := range rm.order {
+		q := HoldingPointQueue{Runway: name, Flights: []DepartingFlight{}}
+		for _, d := range rm.departures[name] {
+			q.Flights = append(q.Flights, DepartingFlight{FlightID: d.flight.ID, Call: d.flight.Call, QueuedAt: d.queuedAt})
+		}
+		state.HoldingPoints = append(state.HoldingPoints, q)
+	}
+	return state
+}
+
+// HandleDepartures serves the taxi-out and holding point picture.
+func (s *Server) HandleDepartures(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Departures())
+}
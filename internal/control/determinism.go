@@ -0,0 +1,204 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// determinismEpoch is the virtual start time used when a check does not
+// pin one, so repeated runs produce identical timestamps and callsigns.
+var determinismEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const collectorBuffer = 1 << 16
+
+// SimInput is a control change applied at an offset into a verification run.
+// Exactly one of Rate, Runway, Wind, Wildlife, Atmosphere or Step is set.
+type SimInput struct {
+	At   time.Duration
+	Rate int64
+	// RampOver ramps the rate change over the duration when positive.
+	RampOver   time.Duration
+	Runway     string
+	Closed     bool
+	Wind       *WindState
+	Wildlife   *WildlifeHazard
+	Atmosphere *Atmosphere
+	// Step is a scenario step.
+	Step *ScenarioStep
+}
+
+// DeterminismCheck describes a simulation that must produce the same event
+// stream every time it runs.
+type DeterminismCheck struct {
+	Engine   EngineConfig
+	Duration time.Duration
+	// Step is the virtual time advanced per iteration; one second when zero.
+	Step   time.Duration
+	Inputs []SimInput
+}
+
+// Run simulates the check once and returns every event it produced.
+func (c DeterminismCheck) Run() ([]Event, error) {
+	_, events, err := c.Simulate()
+	return events, err
+}
+
+// Simulate runs the check once and returns the engine, stopped at the end
+// of the run so its metrics and state can be inspected, with every event it
+// produced.
+func (c DeterminismCheck) Simulate() (*Engine, []Event, error) {
+	cfg := c.Engine
+	if cfg.Start.IsZero() {
+		cfg.Start = determinismEpoch
+	}
+	step := c.Step
+	if step <= 0 {
+		step = time.Second
+	}
+	engine := NewEngine(cfg)
+	events, unsubscribe := engine.Events.Subscribe(collectorBuffer)
+	defer unsubscribe()
+	for _, in := range c.Inputs {
+		in := in
+		engine.Clock.AfterFunc(in.At, func() { applyInput(engine, in) })
+	}
+
+	var out []Event
+	for elapsed := time.Duration(0); elapsed < c.Duration; elapsed += step {
+		engine.Step(step)
+		for drained := false; !drained; {
+			select {
+			case e := <-events:
+				out = append(out, e)
+			default:
+				drained = true
+			}
+		}
+		if engine.Events.Dropped() > 0 {
+			return nil, nil, fmt.Errorf("event collector overflowed at %s; use a smaller step", elapsed+step)
+		}
+	}
+	engine.Stop()
+	return engine, out, nil
+}
+
+// Verify runs the check twice and reports the first point where the two
+// event streams diverge. It returns the number of events compared.
+func (c DeterminismCheck) Verify() (int, error) {
+	first, err := c.Run()
+	if err != nil {
+		return 0, err
+	}
+	second, err := c.Run()
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < min(len(first), len(second)); i++ {
+		a, b := first[i], second[i]
+		if a.Seq != b.Seq || !a.Time.Equal(b.Time) || a.Type != b.Type || a.FlightID != b.FlightID ||
+			a.Call != b.Call || a.Runway != b.Runway || a.Detail != b.Detail {
+			return i, fmt.Errorf("event %d diverged:\n  run 1: %s\n  run 2: %s", i, describeEvent(a), describeEvent(b))
+		}
+	}
+	if len(first) != len(second) {
+		return min(len(first), len(second)), fmt.Errorf("run 1 produced %d events, run 2 produced %d", len(first), len(second))
+	}
+	return len(first), nil
+}
+
+// InputsFromRecording extracts the rate, runway and wind changes from a
+// recorded session so the same operator actions can be replayed in a check.
+// A change it cannot read back is an error rather than a silently different
+// replay.
+func InputsFromRecording(path string) ([]SimInput, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var inputs []SimInput
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var frame RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		e := frame.Message.Event
+		if e == nil {
+			continue
+		}
+		in := SimInput{At: time.Duration(frame.OffsetMillis) * time.Millisecond}
+		switch e.Type {
+		case EventRateChanged:
+			if in.Rate, in.RampOver, err = parseRateDetail(e.Detail); err != nil {
+				return nil, fmt.Errorf("%s: event %d: %w", path, e.Seq, err)
+			}
+		case EventRunwayClosed, EventRunwayOpened:
+			in.Runway, in.Closed = e.Runway, e.Type == EventRunwayClosed
+		case EventWindChanged:
+			wind, err := parseWindDetail(e.Detail)
+			if err != nil {
+				return nil, fmt.Errorf("%s: event %d: %w", path, e.Seq, err)
+			}
+			in.Wind = &wind
+		default:
+			continue
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, scanner.Err()
+}
+
+// parseRateDetail reads the rate, and the ramp if any, back from a rate
+// change event's detail, as "12/min" or "ramping to 12/min over 5m0s".
+func parseRateDetail(detail string) (int64, time.Duration, error) {
+	var rate int64
+	if rest, ok := strings.CutPrefix(detail, "ramping to "); ok {
+		var over string
+		if _, err := fmt.Sscanf(rest, "%d/min over %s", &rate, &over); err != nil {
+			return 0, 0, fmt.Errorf("unrecognized rate change %q", detail)
+		}
+		ramp, err := time.ParseDuration(over)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unrecognized rate ramp %q", detail)
+		}
+		return rate, ramp, nil
+	}
+	if _, err := fmt.Sscanf(detail, "%d/min", &rate); err != nil {
+		return 0, 0, fmt.Errorf("unrecognized rate change %q", detail)
+	}
+	return rate, 0, nil
+}
+
+func applyInput(e *Engine, in SimInput) {
+	switch {
+	case in.Rate > 0 && in.RampOver > 0:
+		e.Generator.RampRate(in.Rate, in.RampOver)
+	case in.Rate > 0:
+		e.Generator.SetRate(in.Rate)
+	case in.Runway != "":
+		e.Runways.SetRunwayClosed(in.Runway, in.Closed)
+	case in.Wind != nil:
+		e.Runways.SetWindState(*in.Wind)
+	case in.Wildlife != nil:
+		if err := e.Runways.SetWildlifeHazard(*in.Wildlife); err != nil {
+			log.Printf("wildlife input ignored: %v", err)
+		}
+	case in.Atmosphere != nil:
+		if err := e.Runways.SetAtmosphere(*in.Atmosphere); err != nil {
+			log.Printf("atmosphere input ignored: %v", err)
+		}
+	case in.Step != nil:
+		e.applyScenarioStep(*in.Step)
+	}
+}
+
+func describeEvent(e Event) string {
+	return fmt.Sprintf("#%d %s %s flight=%d call=%s runway=%s detail=%q", e.Seq, e.Time.Format(time.RFC3339Nano), e.Type, e.FlightID, e.Call, e.Runway, e.Detail)
+}
@@ -0,0 +1,172 @@
+package control
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Preview is the predicted effect of a control command sent with dryRun,
+// which is validated but not applied.
+type Preview struct {
+	Command string `json:"command"`
+	// Error explains why the command would be rejected or ignored.
+	Error string `json:"error,omitempty"`
+	// Effects describe what applying the command would do, as in "closing
+	// 27L will divert 4 flights to holding".
+	Effects []string `json:"effects,omitempty"`
+	// Diverted lists the callsigns that would be sent to holding; Released
+	// counts holding flights that could be cleared to land.
+	Diverted []string `json:"diverted,omitempty"`
+	Released int      `json:"released,omitempty"`
+	// ClosesLastRunway is set when no runway would be left open.
+	ClosesLastRunway bool `json:"closesLastRunway,omitempty"`
+}
+
+// dryRun reports whether a REST request asks only for a preview.
+func dryRun(r *http.Request) bool {
+	v, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	return v
+}
+
+// previewRate predicts a change of the arrival rate.
+func (s *Server) previewRate(rate int64) Preview {
+	p := Preview{Command: "rate"}
+	current := s.Generator.Rate()
+	next := max(rate, 1)
+	if next == current {
+		p.Effects = append(p.Effects, fmt.Sprintf("arrival rate stays at %d/min", current))
+	} else {
+		p.Effects = append(p.Effects, fmt.Sprintf("arrival rate will change from %d/min to %d/min", current, next))
+	}
+	return p
+}
+
+// PreviewRunwayClosed predicts closing or reopening runway.
+func (rm *RunwayManager) PreviewRunwayClosed(runway string, closed bool) Preview {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	p := Preview{Command: "runway"}
+	runway = rm.resolveLocked(runway)
+	r, ok := rm.runways[runway]
+	switch {
+	case !ok:
+		p.Error = "unknown runway " + runway
+		return p
+	case closed && !r.open:
+		p.Effects = append(p.Effects, "runway "+runway+" is already closed")
+		return p
+	case !closed && r.open:
+		p.Effects = append(p.Effects, "runway "+runway+" is already open")
+		return p
+	}
+
+	if !closed {
+		p.Effects = append(p.Effects, "runway "+runway+" will reopen")
+		if r.incidentClosed {
+			p.Effects = append(p.Effects, "runway "+runway+" stays unusable until its equipment failures are cleared")
+			return p
+		}
+		p.Released = len(rm.holding)
+		if p.Released > 0 {
+			p.Effects = append(p.Effects, fmt.Sprintf("reopening %s will release %d holding flights", runway, p.Released))
+		}
+		return p
+	}
+
+	for _, f := range rm.assigned[runway] {
+		p.Diverted = append(p.Diverted, f.Call)
+	}
+	p.Effects = append(p.Effects, fmt.Sprintf("closing %s will divert %d flights to holding", runway, len(p.Diverted)))
+	remaining := 0
+	for _, name := range rm.order {
+		if name != runway && rm.runways[name].available() {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		p.ClosesLastRunway = true
+		p.Effects = append(p.Effects, "no runway will remain open; all arrivals will hold")
+	}
+	return p
+}
+
+// PreviewWind predicts a change of the surface wind: which runways would
+// turn into it and how many queued arrivals land before each does.
+func (rm *RunwayManager) PreviewWind(w WindState) Preview {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	p := Preview{Command: "wind"}
+	wind := normalizeWind(w)
+	changes := 0
+	for _, name := range rm.order {
+		r := rm.runways[name]
+		heading := headingForWind(r.definition, magneticWind(wind, rm.geography.MagneticVariation))
+		if heading == r.activeHeading {
+			continue
+		}
+		changes++
+		ahead, queued, _ := rm.directionChangeCutLocked(name)
+		effect := fmt.Sprintf("runway %s will turn from %03.0f° to %03.0f°", name, r.activeHeading, heading)
+		switch {
+		case len(ahead) == 0:
+			effect += " at once"
+		case len(ahead) == queued:
+			effect += fmt.Sprintf(" after all %d queued arrivals, losing %s of landing time", queued, max(directionChangeGap-rm.arrivalSpacingLocked(), 0))
+		default:
+			effect += fmt.Sprintf(" after %d of %d queued arrivals", len(ahead), queued)
+		}
+		p.Effects = append(p.Effects, effect)
+	}
+	if changes == 0 {
+		p.Effects = append(p.Effects, "no runway changes direction")
+	}
+	return p
+}
+
+// PreviewVisibility predicts a change of visibility and, when given, the
+// ceiling: flights sent around below their minima, runways closed by
+// failed equipment and low-visibility procedures starting or ending.
+func (rm *RunwayManager) PreviewVisibility(meters int64, ceiling *int64) Preview {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	p := Preview{Command: "visibility"}
+	visibility := maxInt64(meters, 0)
+	feet := rm.ceiling
+	if ceiling != nil {
+		feet = maxInt64(*ceiling, 0)
+	}
+	for _, name := range rm.order {
+		r := rm.runways[name]
+		if failed := rm.failedEquipmentLocked(name); visibility < lowVisibilityMeters && len(failed) > 0 && !r.incidentClosed {
+			for _, f := range rm.assigned[name] {
+				p.Diverted = append(p.Diverted, f.Call)
+			}
+			p.Effects = append(p.Effects, fmt.Sprintf("runway %s will close with %d flights diverted", name, len(rm.assigned[name])))
+			continue
+		}
+		missed := 0
+		for _, f := range rm.assigned[name] {
+			if visibility < rm.minimumLocked(name, f) {
+				p.Diverted = append(p.Diverted, f.Call)
+				missed++
+			}
+		}
+		if missed > 0 {
+			p.Effects = append(p.Effects, fmt.Sprintf("%d flights on %s will miss their approach below minima", missed, name))
+		}
+	}
+	if visibility > rm.visibility {
+		p.Released = len(rm.holding)
+	}
+	switch {
+	case !rm.lvp.active && (visibility < lvpEntryVisibility || feet < lvpEntryCeiling):
+		p.Effects = append(p.Effects, "low-visibility procedures will start")
+	case rm.lvp.active && visibility >= lvpExitVisibility && feet >= lvpExitCeiling:
+		p.Effects = append(p.Effects, "low-visibility procedures will end")
+	}
+	if len(p.Effects) == 0 {
+		p.Effects = append(p.Effects, fmt.Sprintf("visibility will change from %dm to %dm", rm.visibility, visibility))
+	}
+	return p
+}
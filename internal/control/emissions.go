@@ -0,0 +1,124 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// holdingFuelKgPerMinute is a typical narrowbody burn in a holding
+	// pattern; vectoringFuelKgPerMinute is the higher burn of being vectored
+	// at lower altitude to absorb sequencing delay.
+	holdingFuelKgPerMinute   = 40
+	vectoringFuelKgPerMinute = 50
+	// co2PerKgFuel is the kilograms of CO2 emitted per kilogram of jet fuel.
+	co2PerKgFuel = 3.16
+	// recentEmissionsLimit bounds how many completed flights the emissions
+	// report keeps.
+	recentEmissionsLimit = 100
+)
+
+// FlightEmissions is the extra fuel a flight burned because of scheduling
+// decisions, split between holding and extended vectoring.
+type FlightEmissions struct {
+	FlightID         int64   `json:"flightId"`
+	Call             string  `json:"call"`
+	HoldingSeconds   float64 `json:"holdingSeconds"`
+	VectoringSeconds float64 `json:"vectoringSeconds"`
+	FuelKg           float64 `json:"fuelKg"`
+	CO2Kg            float64 `json:"co2Kg"`
+}
+
+// burnLocked charges f for d spent holding or, when vectoring is set, being
+// vectored for spacing.
+func (rm *RunwayManager) burnLocked(f Flight, d time.Duration, vectoring bool) {
+	if d <= 0 {
+		return
+	}
+	e, ok := rm.emissions[f.ID]
+	if !ok {
+		e = &FlightEmissions{FlightID: f.ID, Call: f.Call}
+		rm.emissions[f.ID] = e
+	}
+	rate := float64(holdingFuelKgPerMinute)
+	if vectoring {
+		rate = vectoringFuelKgPerMinute
+		e.VectoringSeconds += d.Seconds()
+	} else {
+		e.HoldingSeconds += d.Seconds()
+	}
+	fuel := d.Minutes() * rate
+	e.FuelKg += fuel
+	e.CO2Kg = e.FuelKg * co2PerKgFuel
+	if rm.metrics != nil {
+		rm.metrics.RecordFuelBurn(fuel)
+	}
+}
+
+// closeEmissionsLocked files f's emissions with the recently completed
+// flights once it has landed or left.
+func (rm *RunwayManager) closeEmissionsLocked(f Flight) {
+	e, ok := rm.emissions[f.ID]
+	if !ok {
+		return
+	}
+	delete(rm.emissions, f.ID)
+	rm.recentEmissions = append(rm.recentEmissions, *e)
+	if n := len(rm.recentEmissions); n > recentEmissionsLimit {
+		rm.recentEmissions = rm.recentEmissions[n-recentEmissionsLimit:]
+	}
+}
+
+// EmissionsReport totals the fuel and CO2 cost of holding and vectoring,
+// with a per-flight breakdown of airborne and recently completed flights.
+type EmissionsReport struct {
+	FuelKg  float64           `json:"fuelKg"`
+	CO2Kg   float64           `json:"co2Kg"`
+	Flights []FlightEmissions `json:"flights"`
+}
+
+// EmissionsReport estimates the environmental cost of delays so far,
+// including flights still holding.
+func (rm *RunwayManager) EmissionsReport() EmissionsReport {
+	var report EmissionsReport
+	if rm.metrics != nil {
+		report.FuelKg = rm.metrics.Snapshot().FuelBurnedKg
+	}
+	rm.mu.Lock()
+	now := rm.clock.Now()
+	flights := make(map[int64]FlightEmissions, len(rm.emissions)+len(rm.delays))
+	for id, e := range rm.emissions {
+		flights[id] = *e
+	}
+	for id, entry := range rm.delays {
+		// Holding still in progress is not yet charged; estimate it.
+		held := now.Sub(entry.since)
+		fuel := held.Minutes() * holdingFuelKgPerMinute
+		e := flights[id]
+		e.FlightID, e.Call = id, entry.call
+		e.HoldingSeconds += held.Seconds()
+		e.FuelKg += fuel
+		flights[id] = e
+		report.FuelKg += fuel
+	}
+	report.Flights = append(make([]FlightEmissions, 0, len(flights)+len(rm.recentEmissions)), rm.recentEmissions...)
+	rm.mu.Unlock()
+
+	for _, e := range flights {
+		e.CO2Kg = e.FuelKg * co2PerKgFuel
+		report.Flights = append(report.Flights, e)
+	}
+	sort.Slice(report.Flights, func(i, j int) bool { return report.Flights[i].FlightID < report.Flights[j].FlightID })
+	report.CO2Kg = report.FuelKg * co2PerKgFuel
+	return report
+}
+
+// HandleEmissions serves the fuel burn and CO2 report.
+func (s *Server) HandleEmissions(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.EmissionsReport())
+}
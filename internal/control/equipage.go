@@ -0,0 +1,106 @@
+package control
+
+import (
+	"log"
+	"slices"
+	"strings"
+)
+
+// Aircraft equipage a runway may require of arrivals.
+const (
+	EquipageRNAV        = "RNAV"
+	EquipageILSCATIII   = "ILS CAT III"
+	EquipageReducedWake = "reducedWake"
+)
+
+// EventDiverted is published when a flight cannot land at the airport at
+// all and leaves the simulation.
+const EventDiverted = "diverted"
+
+var knownEquipage = []string{EquipageRNAV, EquipageILSCATIII, EquipageReducedWake}
+
+// ValidEquipage reports whether item is a known equipage item.
+func ValidEquipage(item string) bool {
+	return slices.Contains(knownEquipage, item)
+}
+
+// flightApproach is the best approach category f can fly. Aircraft equipped
+// for ILS CAT III are CAT III capable whatever their declared approach.
+func flightApproach(f Flight) string {
+	if slices.Contains(f.Equipage, EquipageILSCATIII) {
+		return ApproachCATIII
+	}
+	return f.Approach
+}
+
+// missingEquipageLocked lists what runway requires that f does not carry.
+func (rm *RunwayManager) missingEquipageLocked(runway string, f Flight) []string {
+	var missing []string
+	for _, req := range rm.runways[runway].definition.Requires {
+		if !slices.Contains(f.Equipage, req) {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
+
+// equippedForLocked reports whether f carries everything runway requires.
+func (rm *RunwayManager) equippedForLocked(runway string, f Flight) bool {
+	return len(rm.missingEquipageLocked(runway, f)) == 0
+}
+
+// divertIfIncompatibleLocked diverts f away from the airport when no runway,
+// open or closed, accepts its equipage, since waiting could never help. It
+// reports whether the flight was diverted.
+func (rm *RunwayManager) divertIfIncompatibleLocked(f Flight) bool {
+	var missing []string
+	for _, name := range rm.order {
+		m := rm.missingEquipageLocked(name, f)
+		if len(m) == 0 {
+			return false
+		}
+		for _, req := range m {
+			if !slices.Contains(missing, req) {
+				missing = append(missing, req)
+			}
+		}
+	}
+	if len(rm.order) == 0 {
+		return false
+	}
+	rm.divertFlightLocked(f, "lacks "+strings.Join(missing, ", "))
+	return true
+}
+
+// divertFlightLocked sends a flight not yet sequenced to another airport.
+func (rm *RunwayManager) divertFlightLocked(f Flight, reason string) {
+	rm.transmitLocked(divertInstruction(f, reason))
+	rm.logDecisionLocked(DecisionDivert, f, "")
+	rm.endDelayLocked(f)
+	rm.closeEmissionsLocked(f)
+	rm.cancelGateLocked(f.ID)
+	if rm.metrics != nil {
+		rm.metrics.RecordDiversion()
+	}
+	rm.setPhaseLocked(f, PhaseDiverted, "")
+	delete(rm.estimatedAt, f.ID)
+	rm.publishEventLocked(Event{Type: EventDiverted, FlightID: f.ID, Call: f.Call, Detail: reason})
+	log.Printf("flight %d (%s) diverted: %s", f.ID, f.Call, reason)
+}
+
+// defaultEquipage gives generated flights a deterministic equipage mix: most
+// are RNAV capable, a third are certified for reduced wake separation and
+// CAT III capable flights carry the matching ILS equipment.
+func defaultEquipage(id int64) []string {
+	var equipage []string
+	if id%5 != 0 {
+		equipage = append(equipage, EquipageRNAV)
+	}
+	if defaultApproach(id) == ApproachCATIII {
+		equipage = append(equipage, EquipageILSCATIII)
+	}
+	if id%3 == 0 {
+		equipage = append(equipage, EquipageReducedWake)
+	}
+	return equipage
+}
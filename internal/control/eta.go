@@ -0,0 +1,146 @@
+package control
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// etaWindow is how many recent landings the ETA error percentiles cover.
+const etaWindow = 500
+
+// etaBucketBounds are the upper bounds, in seconds, of the ETA error
+// buckets; errors beyond the last fall in a final unbounded bucket.
+var etaBucketBounds = []float64{5, 30, 60, 300}
+
+// ETAAccuracy compares the touchdown time estimated when each flight was
+// first sequenced with when it actually touched down. Errors are positive
+// for flights landing later than estimated.
+type ETAAccuracy struct {
+	Landings int64 `json:"landings"`
+	Early    int64 `json:"early"`
+	Late     int64 `json:"late"`
+	// MeanErrorSeconds is the signed mean error, showing any bias;
+	// MeanAbsErrorSeconds how far off estimates are either way.
+	MeanErrorSeconds    float64 `json:"meanErrorSeconds"`
+	MeanAbsErrorSeconds float64 `json:"meanAbsErrorSeconds"`
+	// The percentiles of the absolute error cover the last etaWindow
+	// landings.
+	P50AbsErrorSeconds float64 `json:"p50AbsErrorSeconds"`
+	P90AbsErrorSeconds float64 `json:"p90AbsErrorSeconds"`
+	P99AbsErrorSeconds float64 `json:"p99AbsErrorSeconds"`
+	// Buckets counts landings by absolute error.
+	Buckets []ETABucket `json:"buckets"`
+}
+
+// ETABucket counts the landings whose absolute ETA error was at most
+// MaxSeconds and above the previous bucket's; the last bucket has no
+// MaxSeconds and holds the rest.
+type ETABucket struct {
+	MaxSeconds float64 `json:"maxSeconds,omitempty"`
+	Count      int64   `json:"count"`
+}
+
+// etaStats accumulates ETA errors, under SchedulerMetrics.etaMu.
+type etaStats struct {
+	landings  int64
+	early     int64
+	late      int64
+	sumMicros int64
+	absMicros int64
+	buckets   []int64
+	// recent holds the last etaWindow absolute errors in seconds, as a
+	// ring starting at next once full.
+	recent []float64
+	next   int
+}
+
+// RecordETAError registers a landing that touched down err after its
+// estimate, before it when negative.
+func (m *SchedulerMetrics) RecordETAError(err time.Duration) {
+	m.etaMu.Lock()
+	defer m.etaMu.Unlock()
+	s := &m.eta
+	if s.buckets == nil {
+		s.buckets = make([]int64, len(etaBucketBounds)+1)
+	}
+	s.landings++
+	switch {
+	case err < 0:
+		s.early++
+	case err > 0:
+		s.late++
+	}
+	abs := err.Abs()
+	s.sumMicros += err.Microseconds()
+	s.absMicros += abs.Microseconds()
+	i := sort.SearchFloat64s(etaBucketBounds, abs.Seconds())
+	s.buckets[i]++
+	if len(s.recent) < etaWindow {
+		s.recent = append(s.recent, abs.Seconds())
+	} else {
+		s.recent[s.next] = abs.Seconds()
+		s.next = (s.next + 1) % etaWindow
+	}
+}
+
+// readETA summarizes the ETA errors recorded so far.
+func (m *SchedulerMetrics) readETA() ETAAccuracy {
+	m.etaMu.Lock()
+	defer m.etaMu.Unlock()
+	s := m.eta
+	out := ETAAccuracy{Landings: s.landings, Early: s.early, Late: s.late, Buckets: make([]ETABucket, len(etaBucketBounds)+1)}
+	for i := range out.Buckets {
+		if i < len(etaBucketBounds) {
+			out.Buckets[i].MaxSeconds = etaBucketBounds[i]
+		}
+		if s.buckets != nil {
+			out.Buckets[i].Count = s.buckets[i]
+		}
+	}
+	if s.landings == 0 {
+		return out
+	}
+	out.MeanErrorSeconds = float64(s.sumMicros) / float64(s.landings) / 1_000_000
+	out.MeanAbsErrorSeconds = float64(s.absMicros) / float64(s.landings) / 1_000_000
+	recent := append([]float64(nil), s.recent...)
+	sort.Float64s(recent)
+	out.P50AbsErrorSeconds = nearestRank(recent, 0.5)
+	out.P90AbsErrorSeconds = nearestRank(recent, 0.9)
+	out.P99AbsErrorSeconds = nearestRank(recent, 0.99)
+	return out
+}
+
+// estimateTouchdownLocked records when f, just sequenced to runway, is
+// estimated to touch down, unless it was sequenced before: its ETA error
+// is measured against the first estimate, so go-arounds and spells in
+// holding count against it.
+func (rm *RunwayManager) estimateTouchdownLocked(runway string, f Flight) {
+	if _, ok := rm.estimatedAt[f.ID]; ok {
+		return
+	}
+	rm.estimatedAt[f.ID] = rm.estimateLocked(runway, f)
+}
+
+// touchdownErrorLocked returns how much later than first estimated f
+// touched down at landedAt, and forgets the estimate.
+func (rm *RunwayManager) touchdownErrorLocked(f Flight, landedAt time.Time) (time.Duration, bool) {
+	est, ok := rm.estimatedAt[f.ID]
+	if !ok {
+		return 0, false
+	}
+	delete(rm.estimatedAt, f.ID)
+	// Round to tenths of a second so timer jitter does not read as error.
+	return landedAt.Sub(est).Round(100 * time.Millisecond), true
+}
+
+// etaDetail describes a touchdown error for the landed event.
+func etaDetail(err time.Duration) string {
+	switch {
+	case err > 0:
+		return fmt.Sprintf("%.1fs later than estimated", err.Seconds())
+	case err < 0:
+		return fmt.Sprintf("%.1fs earlier than estimated", -err.Seconds())
+	}
+	return "as estimated"
+}
@@ -0,0 +1,240 @@
+package control
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published on the EventBus.
+const (
+	EventSpawned      = "spawned"
+	EventAssigned     = "assigned"
+	EventHolding      = "holding"
+	EventLanded       = "landed"
+	EventConflict     = "conflict"
+	EventRunwayClosed = "runwayClosed"
+	EventRunwayOpened = "runwayOpened"
+	EventWindChanged  = "windChanged"
+	EventRateChanged  = "rateChanged"
+	// EventIncursion is raised when an arrival reaches an occupied runway;
+	// EventIncursionResolved follows once the runway is vacated.
+	EventIncursion         = "incursion"
+	EventIncursionResolved = "incursionResolved"
+	EventGoAround          = "goAround"
+)
+
+// Event describes a notable scheduler occurrence.
+type Event struct {
+	Seq      int64     `json:"seq"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	FlightID int64     `json:"flightId,omitempty"`
+	Call     string    `json:"call,omitempty"`
+	Runway   string    `json:"runway,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// EventBus fans events out to subscribers without ever blocking publishers.
+// Subscribers that fall behind lose events rather than stalling the scheduler.
+type EventBus struct {
+	mu      sync.Mutex
+	nextSeq int64
+	nextSub int
+	subs    map[int]*Subscription
+	// backlogs queue the events each SubscribeAll subscriber has yet to
+	// read.
+	backlogs map[int]*eventBacklog
+	dropped  atomicInt64
+	// clock stamps events published without a time; nil means wall time.
+	clock Clock
+}
+
+// eventBacklog holds the events an unbounded subscriber has yet to read.
+type eventBacklog struct {
+	mu     sync.Mutex
+	events []Event
+	wake   chan struct{}
+}
+
+func (q *eventBacklog) push(e Event) {
+	q.mu.Lock()
+	q.events = append(q.events, e)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take empties the backlog, returning the events it held in order.
+func (q *eventBacklog) take() []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// Subscription is one subscriber's event channel and delivery statistics.
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	dropped atomicInt64
+}
+
+// Dropped reports how many events this subscriber lost to a full buffer.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Pending reports how many events are buffered awaiting the subscriber, and
+// the buffer's capacity.
+func (s *Subscription) Pending() (queued, capacity int) {
+	return len(s.ch), cap(s.ch)
+}
+
+// NewEventBus constructs an empty event bus.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]*Subscription), backlogs: make(map[int]*eventBacklog)}
+}
+
+// SetClock stamps events published without a time with clock, so every
+// event carries simulation time. It must be called before events are
+// published.
+func (b *EventBus) SetClock(clock Clock) {
+	b.mu.Lock()
+	b.clock = clock
+	b.mu.Unlock()
+}
+
+// Publish stamps the event with a sequence number and timestamp and delivers
+// it to every subscriber with room in its buffer.
+func (b *EventBus) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextSeq++
+	e.Seq = b.nextSeq
+	if e.Time.IsZero() && b.clock != nil {
+		e.Time = b.clock.Now()
+	} else if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.deliverLocked(e)
+	return e
+}
+
+// deliverLocked hands e to every subscriber: SubscribeAll backlogs queue
+// it, other subscribers drop it when their buffer is full.
+func (b *EventBus) deliverLocked(e Event) {
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+			b.dropped.Add(1)
+		}
+	}
+	for _, backlog := range b.backlogs {
+		backlog.push(e)
+	}
+}
+
+// Relay delivers an event published on another bus, keeping its sequence
+// number and time; later events published here number on from it.
+func (b *EventBus) Relay(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextSeq = max(b.nextSeq, e.Seq)
+	b.deliverLocked(e)
+}
+
+// Subscribe registers a new subscriber. The returned function unsubscribes
+// and closes the channel.
+func (b *EventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	sub, unsubscribe := b.Open(buffer)
+	return sub.C, unsubscribe
+}
+
+// Open registers a new subscriber like Subscribe, returning the
+// subscription so its backlog and losses can be monitored.
+func (b *EventBus) Open(buffer int) (*Subscription, func()) {
+	if buffer <= 0 {
+		buffer = 1
+	}
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch}
+
+	b.mu.Lock()
+	id := b.nextSub
+	b.nextSub++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// SubscribeAll registers a subscriber that receives every event: those it
+// has yet to read queue without bound instead of being dropped, so a slow
+// subscriber costs memory rather than history, and publishers still never
+// block. The returned function unsubscribes, discarding any backlog, and
+// closes the channel.
+func (b *EventBus) SubscribeAll() (<-chan Event, func()) {
+	ch := make(chan Event)
+	backlog := &eventBacklog{wake: make(chan struct{}, 1)}
+
+	b.mu.Lock()
+	id := b.nextSub
+	b.nextSub++
+	b.backlogs[id] = backlog
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for {
+			for _, e := range backlog.take() {
+				select {
+				case ch <- e:
+				case <-done:
+					return
+				}
+			}
+			select {
+			case <-backlog.wake:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.backlogs, id)
+			b.mu.Unlock()
+			close(done)
+		})
+	}
+}
+
+// subscribers reports how many subscribers are registered.
+func (b *EventBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs) + len(b.backlogs)
+}
+
+// Dropped reports how many deliveries were skipped because a subscriber was full.
+func (b *EventBus) Dropped() int64 {
+	return b.dropped.Load()
+}
@@ -0,0 +1,186 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// RunwayExit is a taxiway leaving the runway Distance meters past the
+// landing threshold. High-speed turnoffs can be taken at a higher speed, so
+// arrivals brake less and vacate the runway sooner.
+type RunwayExit struct {
+	Name      string  `json:"name"`
+	Distance  float64 `json:"distance"`
+	HighSpeed bool    `json:"highSpeed,omitempty"`
+}
+
+const (
+	// touchdownDistance is where arrivals touch down past the threshold.
+	touchdownDistance = 450.0
+	// landingDeceleration is the braking on the landing roll in m/s².
+	landingDeceleration = 2.5
+	// standardExitSpeed and highSpeedExitSpeed are the speeds, in m/s, at
+	// which the two kinds of exit are taken.
+	standardExitSpeed  = 10.0
+	highSpeedExitSpeed = 25.0
+	// exitClearance is how far an aircraft travels along the exit to clear
+	// the runway.
+	exitClearance = 100.0
+	// referenceOccupancy is the real runway occupancy the simulation's
+	// landingDuration stands for; modeled occupancies scale it.
+	referenceOccupancy = 55 * time.Second
+)
+
+// touchdownSpeeds is the touchdown speed in m/s per weight category.
+var touchdownSpeeds = map[string]float64{
+	WeightLight:  40,
+	WeightMedium: 65,
+	WeightHeavy:  72,
+	WeightSuper:  76,
+}
+
+// exitOccupancy models how long an aircraft of weight occupies the runway
+// when vacating by the quickest exit it can make, braking late enough to
+// reach it at exit speed. Without exits the reference occupancy applies.
+func exitOccupancy(exits []RunwayExit, weight string) time.Duration {
+	if len(exits) == 0 {
+		return referenceOccupancy
+	}
+	v0, ok := touchdownSpeeds[weight]
+	if !ok {
+		v0 = touchdownSpeeds[WeightMedium]
+	}
+	best := math.Inf(1)
+	farthest := exits[0]
+	for _, e := range exits {
+		if e.Distance > farthest.Distance {
+			farthest = e
+		}
+		ve := exitSpeed(e)
+		braking := touchdownDistance + (v0*v0-ve*ve)/(2*landingDeceleration)
+		if e.Distance < braking {
+			continue
+		}
+		best = min(best, rollTime(v0, ve)+(e.Distance-braking)/v0+exitClearance/ve)
+	}
+	if math.IsInf(best, 1) {
+		// Nothing can be made at speed: brake harder and take the last exit.
+		best = rollTime(v0, exitSpeed(farthest)) + exitClearance/exitSpeed(farthest)
+	}
+	return time.Duration(best * float64(time.Second))
+}
+
+// rollTime is the time from crossing the touchdown point at v0 to having
+// braked to exit speed ve, in seconds.
+func rollTime(v0, ve float64) float64 {
+	return touchdownDistance/v0 + (v0-ve)/landingDeceleration
+}
+
+func exitSpeed(e RunwayExit) float64 {
+	if e.HighSpeed {
+		return highSpeedExitSpeed
+	}
+	return standardExitSpeed
+}
+
+// exitFactorLocked scales the landing time on runway for f's weight by how
+// quickly it can vacate.
+func (rm *RunwayManager) exitFactorLocked(runway string, f Flight) float64 {
+	if _, ok := rm.runways[runway]; !ok {
+		return 1
+	}
+	return float64(exitOccupancy(rm.exitsLocked(runway), f.Weight)) / float64(referenceOccupancy)
+}
+
+// SetExits replaces the exits of runway; an empty list removes them.
+func (rm *RunwayManager) SetExits(runway string, exits []RunwayExit) error {
+	for _, e := range exits {
+		if e.Distance <= 0 {
+			return fmt.Errorf("exit %q: distance must be positive", e.Name)
+		}
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	runway = rm.resolveLocked(runway)
+	r, ok := rm.runways[runway]
+	if !ok {
+		return fmt.Errorf("unknown runway %q", runway)
+	}
+	r.definition.Exits = append([]RunwayExit(nil), exits...)
+	sort.Slice(r.definition.Exits, func(i, j int) bool { return r.definition.Exits[i].Distance < r.definition.Exits[j].Distance })
+	log.Printf("runway %s exits updated: %d", runway, len(exits))
+	return nil
+}
+
+// RunwayExits is one runway's usable exits, measured from the threshold in
+// use, with the modeled occupancy and the arrival capacity it allows per
+// weight category.
+type RunwayExits struct {
+	Runway          string       `json:"runway"`
+	Exits           []RunwayExit `json:"exits"`
+	LandingDistance float64      `json:"landingDistance,omitempty"`
+	// OccupancySeconds covers the weight categories the runway is long
+	// enough for.
+	OccupancySeconds map[string]float64 `json:"occupancySeconds"`
+	// ArrivalsPerHour is the capacity if every arrival were of the category.
+	ArrivalsPerHour map[string]float64 `json:"arrivalsPerHour"`
+}
+
+// Exits reports every runway's exits and their effect on occupancy.
+func (rm *RunwayManager) Exits() []RunwayExits {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayExits, 0, len(rm.order))
+	for _, name := range rm.order {
+		exits := rm.exitsLocked(name)
+		report := RunwayExits{
+			Runway:           name,
+			Exits:            exits,
+			LandingDistance:  rm.landingDistanceLocked(name),
+			OccupancySeconds: make(map[string]float64, len(touchdownSpeeds)),
+			ArrivalsPerHour:  make(map[string]float64, len(touchdownSpeeds)),
+		}
+		for weight := range touchdownSpeeds {
+			if !rm.longEnoughLocked(name, Flight{Weight: weight}) {
+				continue
+			}
+			occupancy := exitOccupancy(exits, weight)
+			report.OccupancySeconds[weight] = occupancy.Seconds()
+			report.ArrivalsPerHour[weight] = float64(time.Hour) / float64(occupancy)
+		}
+		out = append(out, report)
+	}
+	return out
+}
+
+// HandleExits reports runway exits.
+func (s *Server) HandleExits(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Exits())
+}
+
+// HandleRunwayExits replaces one runway's exits.
+func (s *Server) HandleRunwayExits(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var exits []RunwayExit
+	if err := json.NewDecoder(r.Body).Decode(&exits); err != nil {
+		http.Error(w, "invalid exits: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Runways.SetExits(r.PathValue("runway"), exits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Exits())
+}
@@ -0,0 +1,307 @@
+package control
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFlightSearchLimit = 100
+	maxFlightSearchLimit     = 1000
+)
+
+// FlightSearch finds archived flights by callsign pattern within a time
+// range. Callsign may use * and ? wildcards, as in "FLT*".
+type FlightSearch struct {
+	Callsign string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// FlightHistory is what happened to one archived flight.
+type FlightHistory struct {
+	FlightID  int64      `json:"flightId"`
+	Call      string     `json:"call"`
+	SpawnedAt *time.Time `json:"spawnedAt,omitempty"`
+	LandedAt  *time.Time `json:"landedAt,omitempty"`
+	// Runway is the last runway the flight was assigned to.
+	Runway string `json:"runway,omitempty"`
+	// Route is the flight's path through the sequence: each runway it was
+	// assigned to, with "holding", "goAround" and "diverted" steps between.
+	Route []string `json:"route,omitempty"`
+	// Outcome is "landed", "diverted" or, while neither is archived,
+	// "inProgress".
+	Outcome      string `json:"outcome"`
+	HoldingCount int    `json:"holdingCount"`
+	// HoldingSeconds is the total time spent holding.
+	HoldingSeconds float64 `json:"holdingSeconds"`
+	GoArounds      int     `json:"goArounds"`
+	// WaitSeconds is the time from spawn to touchdown of a landed flight.
+	WaitSeconds *float64 `json:"waitSeconds,omitempty"`
+	Events      []Event  `json:"events"`
+
+	firstSeen    time.Time
+	holdingSince *time.Time
+}
+
+// FlightSearchResult lists the matching flights with summary statistics.
+type FlightSearchResult struct {
+	Flights            []FlightHistory `json:"flights"`
+	Count              int             `json:"count"`
+	Landed             int             `json:"landed"`
+	Diverted           int             `json:"diverted"`
+	HeldFlights        int             `json:"heldFlights"`
+	GoArounds          int             `json:"goArounds"`
+	AverageWaitSeconds float64         `json:"averageWaitSeconds"`
+}
+
+// likePattern turns a callsign glob into a LIKE pattern escaped with \.
+func likePattern(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '%', '_', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '*':
+			b.WriteRune('%')
+		case '?':
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SearchFlights returns the archived flights whose callsign matches q, each
+// with its events in the range, in order of first appearance.
+func (a *EventArchive) SearchFlights(q FlightSearch) (FlightSearchResult, error) {
+	var inRange []string
+	var rangeArgs []any
+	if !q.From.IsZero() {
+		inRange = append(inRange, "at_ms >= ?")
+		rangeArgs = append(rangeArgs, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		inRange = append(inRange, "at_ms <= ?")
+		rangeArgs = append(rangeArgs, q.To.UnixMilli())
+	}
+	where := append([]string{"flight_id <> 0"}, inRange...)
+	args := append([]any{}, rangeArgs...)
+	if q.Callsign != "" {
+		where = append(where, `callsign LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(q.Callsign))
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultFlightSearchLimit
+	}
+	limit = min(limit, maxFlightSearchLimit)
+
+	// Find the matching flights first, then every event of theirs in the
+	// range, which includes those archived without a callsign.
+	matching := "SELECT flight_id FROM events WHERE " + strings.Join(where, " AND ") +
+		" GROUP BY flight_id ORDER BY MIN(at_ms) LIMIT " + strconv.Itoa(limit)
+	query := "SELECT seq, type, at_ms, flight_id, callsign, runway, detail FROM events WHERE " +
+		strings.Join(append([]string{"flight_id IN (" + matching + ")"}, inRange...), " AND ") + " ORDER BY at_ms, seq"
+
+	flights, err := a.foldFlights(query, append(args, rangeArgs...), true)
+	if err != nil {
+		return FlightSearchResult{}, err
+	}
+
+	result := FlightSearchResult{Flights: flights}
+	var waited float64
+	for _, f := range flights {
+		switch f.Outcome {
+		case "landed":
+			result.Landed++
+			if f.WaitSeconds != nil {
+				waited += *f.WaitSeconds
+			}
+		case "diverted":
+			result.Diverted++
+		}
+		if f.HoldingCount > 0 {
+			result.HeldFlights++
+		}
+		result.GoArounds += f.GoArounds
+	}
+	result.Count = len(result.Flights)
+	if result.Landed > 0 {
+		result.AverageWaitSeconds = waited / float64(result.Landed)
+	}
+	return result, nil
+}
+
+// Flights returns every archived flight with an event in the range
+// (zero means open), folded from those events.
+func (a *EventArchive) Flights(from, to time.Time) ([]FlightHistory, error) {
+	where := []string{"flight_id <> 0"}
+	var args []any
+	if !from.IsZero() {
+		where = append(where, "at_ms >= ?")
+		args = append(args, from.UnixMilli())
+	}
+	if !to.IsZero() {
+		where = append(where, "at_ms <= ?")
+		args = append(args, to.UnixMilli())
+	}
+	query := "SELECT seq, type, at_ms, flight_id, callsign, runway, detail FROM events WHERE " +
+		strings.Join(where, " AND ") + " ORDER BY at_ms, seq"
+	return a.foldFlights(query, args, false)
+}
+
+// foldFlights runs an event query and folds the rows into one history per
+// flight, in order of first appearance, keeping the events when asked.
+func (a *EventArchive) foldFlights(query string, args []any, keepEvents bool) ([]FlightHistory, error) {
+	rows, err := a.db.Query(a.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*FlightHistory)
+	var order []int64
+	for rows.Next() {
+		var e Event
+		var atMillis int64
+		if err := rows.Scan(&e.Seq, &e.Type, &atMillis, &e.FlightID, &e.Call, &e.Runway, &e.Detail); err != nil {
+			return nil, err
+		}
+		e.Time = time.UnixMilli(atMillis)
+		f, ok := byID[e.FlightID]
+		if !ok {
+			f = &FlightHistory{FlightID: e.FlightID, Outcome: "inProgress"}
+			if keepEvents {
+				f.Events = []Event{}
+			}
+			byID[e.FlightID] = f
+			order = append(order, e.FlightID)
+		}
+		if keepEvents {
+			f.Events = append(f.Events, e)
+		}
+		f.record(e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flights := make([]FlightHistory, 0, len(order))
+	for _, id := range order {
+		flights = append(flights, *byID[id])
+	}
+	return flights, nil
+}
+
+// FlightRecords folds a simulation's events into one record per flight, in
+// order of first appearance.
+func FlightRecords(events []Event) []FlightHistory {
+	byID := make(map[int64]*FlightHistory)
+	var order []int64
+	for _, e := range events {
+		if e.FlightID == 0 {
+			continue
+		}
+		f, ok := byID[e.FlightID]
+		if !ok {
+			f = &FlightHistory{FlightID: e.FlightID, Outcome: "inProgress"}
+			byID[e.FlightID] = f
+			order = append(order, e.FlightID)
+		}
+		f.record(e)
+	}
+	out := make([]FlightHistory, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byID[id])
+	}
+	return out
+}
+
+// record folds one of the flight's events into its history.
+func (f *FlightHistory) record(e Event) {
+	if e.Call != "" {
+		f.Call = e.Call
+	}
+	at := e.Time
+	if f.firstSeen.IsZero() {
+		f.firstSeen = at
+	}
+	if f.holdingSince != nil && (e.Type == EventAssigned || e.Type == EventDiverted || e.Type == EventLanded) {
+		f.HoldingSeconds += at.Sub(*f.holdingSince).Seconds()
+		f.holdingSince = nil
+	}
+	switch e.Type {
+	case EventSpawned:
+		f.SpawnedAt = &at
+	case EventAssigned:
+		f.Runway = e.Runway
+		f.Route = append(f.Route, e.Runway)
+	case EventHolding:
+		f.HoldingCount++
+		// A flight re-held while holding stays in the same stack.
+		if f.holdingSince == nil {
+			f.holdingSince = &at
+			f.Route = append(f.Route, "holding")
+		}
+	case EventGoAround:
+		f.GoArounds++
+		f.Route = append(f.Route, "goAround")
+	case EventDiverted:
+		f.Outcome = "diverted"
+		f.Route = append(f.Route, "diverted")
+	case EventLanded:
+		f.Outcome = "landed"
+		f.LandedAt = &at
+		if e.Runway != "" {
+			f.Runway = e.Runway
+		}
+		if f.SpawnedAt != nil {
+			wait := at.Sub(*f.SpawnedAt).Seconds()
+			f.WaitSeconds = &wait
+		}
+	}
+}
+
+// HandleFlightHistory serves archived flights matching a callsign pattern,
+// e.g. /api/v1/history/flights?callsign=FLT*&from=...&to=...
+func (s *Server) HandleFlightHistory(w http.ResponseWriter, r *http.Request) {
+	if s.Archive == nil {
+		http.Error(w, "event archive disabled", http.StatusServiceUnavailable)
+		return
+	}
+	params := r.URL.Query()
+	q := FlightSearch{Callsign: params.Get("callsign")}
+	var err error
+	if raw := params.Get("from"); raw != "" {
+		if q.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid from (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("to"); raw != "" {
+		if q.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid to (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("limit"); raw != "" {
+		if q.Limit, err = strconv.Atoi(raw); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := s.Archive.SearchFlights(q)
+	if err != nil {
+		log.Printf("flight history query: %v", err)
+		http.Error(w, "flight history query failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
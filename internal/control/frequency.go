@@ -0,0 +1,190 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxInstructionsPerMinute bounds the configured frequency throughput.
+const maxInstructionsPerMinute = 600
+
+// Frequency models the shared radio frequency every instruction goes out
+// on. Each instruction (a vector to a runway, a holding clearance, a
+// go-around, a re-vector or a diversion) takes a minute's share of
+// airtime; once the frequency is saturated, instructions queue and the
+// flights they are meant for wait for them. Zero InstructionsPerMinute
+// leaves the frequency unlimited.
+type Frequency struct {
+	InstructionsPerMinute int `json:"instructionsPerMinute"`
+}
+
+// FrequencyStatus reports the frequency's load.
+type FrequencyStatus struct {
+	Frequency
+	// BacklogSeconds is how long an instruction issued now waits for
+	// airtime.
+	BacklogSeconds float64 `json:"backlogSeconds"`
+	Instructions   int64   `json:"instructions"`
+	// Queued counts instructions that waited for airtime.
+	Queued                 int64   `json:"queued"`
+	AverageQueueSeconds    float64 `json:"averageQueueSeconds"`
+	LongestQueueSeconds    float64 `json:"longestQueueSeconds"`
+	InstructionsLastMinute int     `json:"instructionsLastMinute"`
+	UtilizationLastMinute  float64 `json:"utilizationLastMinute"`
+}
+
+// frequencyState is the frequency's load, under RunwayManager.mu.
+type frequencyState struct {
+	cfg Frequency
+	// freeAt is when the instructions queued so far have all gone out.
+	freeAt       time.Time
+	instructions int64
+	queued       int64
+	queueTotal   time.Duration
+	longest      time.Duration
+	// recent holds when the instructions of the last minute went out, and
+	// when those queued will.
+	recent []time.Time
+	// waits is how long each flight's last vector waited for airtime.
+	waits map[int64]time.Duration
+}
+
+// SetFrequency replaces the frequency's throughput. Instructions already
+// queued keep their airtime.
+func (rm *RunwayManager) SetFrequency(f Frequency) error {
+	if f.InstructionsPerMinute < 0 || f.InstructionsPerMinute > maxInstructionsPerMinute {
+		return fmt.Errorf("instructions per minute must be between 0 and %d", maxInstructionsPerMinute)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.radio.cfg = f
+	if f.InstructionsPerMinute > 0 {
+		log.Printf("frequency limited to %d instructions/min", f.InstructionsPerMinute)
+	} else {
+		log.Printf("frequency unlimited")
+	}
+	return nil
+}
+
+// FrequencyStatus reports the frequency's throughput and load.
+func (rm *RunwayManager) FrequencyStatus() FrequencyStatus {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	r := &rm.radio
+	now := rm.clock.Now()
+	rm.trimRecentLocked(now)
+	sent := 0
+	for _, at := range r.recent {
+		if !at.After(now) {
+			sent++
+		}
+	}
+	status := FrequencyStatus{
+		Frequency:              r.cfg,
+		BacklogSeconds:         max(r.freeAt.Sub(now), 0).Seconds(),
+		Instructions:           r.instructions,
+		Queued:                 r.queued,
+		LongestQueueSeconds:    r.longest.Seconds(),
+		InstructionsLastMinute: sent,
+	}
+	if r.queued > 0 {
+		status.AverageQueueSeconds = r.queueTotal.Seconds() / float64(r.queued)
+	}
+	if r.cfg.InstructionsPerMinute > 0 {
+		status.UtilizationLastMinute = min(float64(sent)/float64(r.cfg.InstructionsPerMinute), 1)
+	}
+	return status
+}
+
+// transmitLocked puts an instruction on the frequency and in the
+// transcript, returning how long it waits for airtime.
+func (rm *RunwayManager) transmitLocked(in Instruction) time.Duration {
+	r := &rm.radio
+	now := rm.clock.Now()
+	r.instructions++
+	rm.trimRecentLocked(now)
+	if r.cfg.InstructionsPerMinute <= 0 {
+		r.recent = append(r.recent, now)
+		rm.recordInstructionLocked(in, now)
+		return 0
+	}
+	start := now
+	if r.freeAt.After(now) {
+		start = r.freeAt
+	}
+	r.freeAt = start.Add(time.Minute / time.Duration(r.cfg.InstructionsPerMinute))
+	r.recent = append(r.recent, start)
+	rm.recordInstructionLocked(in, start)
+	wait := start.Sub(now)
+	if wait > 0 {
+		r.queued++
+		r.queueTotal += wait
+		r.longest = max(r.longest, wait)
+	}
+	return wait
+}
+
+// trimRecentLocked forgets instructions sent over a minute before now.
+// Those still queued stay, in the order they will go out.
+func (rm *RunwayManager) trimRecentLocked(now time.Time) {
+	r := &rm.radio
+	i := 0
+	for i < len(r.recent) && now.Sub(r.recent[i]) >= time.Minute {
+		i++
+	}
+	r.recent = r.recent[i:]
+}
+
+// vectorLocked transmits the vector sending f to runway, remembering the
+// wait for airtime so its approach allows for it.
+func (rm *RunwayManager) vectorLocked(f Flight, runway string) {
+	wait := rm.transmitLocked(rm.approachInstructionLocked(f, runway, rm.vectors[f.ID]))
+	if wait <= 0 {
+		delete(rm.radio.waits, f.ID)
+		return
+	}
+	if rm.radio.waits == nil {
+		rm.radio.waits = make(map[int64]time.Duration)
+	}
+	rm.radio.waits[f.ID] = wait
+	if rm.metrics != nil {
+		rm.metrics.RecordDelay(DelayFrequency, wait)
+	}
+	log.Printf("flight %d (%s) vector queued %.1fs for frequency airtime", f.ID, f.Call, wait.Seconds())
+}
+
+// frequencyWaitLocked is how long f's vector waited for airtime, or for a
+// flight not yet vectored, how long one would wait now.
+func (rm *RunwayManager) frequencyWaitLocked(f Flight) time.Duration {
+	if wait, ok := rm.radio.waits[f.ID]; ok {
+		return wait
+	}
+	if _, sequenced := rm.assignedAt[f.ID]; sequenced {
+		return 0
+	}
+	return max(rm.radio.freeAt.Sub(rm.clock.Now()), 0)
+}
+
+// HandleFrequency reports (GET) or replaces (PUT) the frequency's
+// throughput.
+func (s *Server) HandleFrequency(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var f Frequency
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			http.Error(w, "invalid frequency: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetFrequency(f); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.FrequencyStatus())
+}
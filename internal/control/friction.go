@@ -0,0 +1,220 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// EventFrictionTest is published when a friction test vehicle has measured
+// a runway.
+const EventFrictionTest = "frictionTest"
+
+// GroundFrictionTest is the ground movement kind of friction test vehicles.
+const GroundFrictionTest = "frictionTest"
+
+const (
+	// frictionRetry is how long to wait before looking for a gap again when
+	// a runway cannot be tested.
+	frictionRetry = 5 * time.Second
+	// frictionMargin keeps a test clear of the landings either side of it.
+	frictionMargin = minArrivalSpacing
+)
+
+// frictionReadings is the braking coefficient measured by precipitation.
+var frictionReadings = map[string]float64{
+	PrecipitationNone:            0.82,
+	PrecipitationRain:            0.55,
+	PrecipitationSnow:            0.35,
+	PrecipitationFreezingRain:    0.2,
+	PrecipitationFreezingDrizzle: 0.25,
+}
+
+// FrictionTests schedules periodic friction test runs on every runway. A
+// test is due IntervalSeconds after the last and occupies the runway for
+// DurationSeconds; it is slotted into the first gap in the landing sequence
+// long enough for it. A zero interval disables testing.
+type FrictionTests struct {
+	IntervalSeconds int64 `json:"intervalSeconds"`
+	DurationSeconds int64 `json:"durationSeconds"`
+}
+
+// frictionRun tracks testing on one runway. slot is set once a gap has been
+// reserved for the next test.
+type frictionRun struct {
+	due     time.Time
+	slot    time.Time
+	last    time.Time
+	reading float64
+}
+
+// SetFrictionTests replaces the friction test schedule. The first tests are
+// due one interval from now.
+func (rm *RunwayManager) SetFrictionTests(cfg FrictionTests) error {
+	if cfg.IntervalSeconds < 0 || cfg.DurationSeconds < 0 {
+		return fmt.Errorf("interval and duration must not be negative")
+	}
+	if cfg.IntervalSeconds > 0 && cfg.DurationSeconds == 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.friction = cfg
+	rm.frictionGen++
+	if cfg.IntervalSeconds == 0 {
+		log.Printf("friction testing disabled")
+		return nil
+	}
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	now := rm.clock.Now()
+	for _, name := range rm.order {
+		run := rm.frictionRuns[name]
+		if run == nil {
+			run = &frictionRun{}
+			rm.frictionRuns[name] = run
+		}
+		run.due, run.slot = now.Add(interval), time.Time{}
+		rm.afterFrictionLocked(interval, name, rm.planFrictionTest)
+	}
+	log.Printf("friction testing every %s for %ds", interval, cfg.DurationSeconds)
+	return nil
+}
+
+// afterFrictionLocked runs step for runway after d unless the schedule has
+// been replaced or the runway decommissioned in the meantime.
+func (rm *RunwayManager) afterFrictionLocked(d time.Duration, runway string, step func(string)) {
+	gen := rm.frictionGen
+	rm.clock.AfterFunc(d, func() {
+		rm.mu.Lock()
+		_, exists := rm.runways[runway]
+		current := gen == rm.frictionGen && exists
+		rm.mu.Unlock()
+		if current {
+			step(runway)
+		}
+	})
+}
+
+// planFrictionTest reserves the first gap in runway's landing sequence long
+// enough for a test.
+func (rm *RunwayManager) planFrictionTest(runway string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if !rm.runways[runway].available() {
+		rm.afterFrictionLocked(frictionRetry, runway, rm.planFrictionTest)
+		return
+	}
+	now := rm.clock.Now()
+	need := time.Duration(rm.friction.DurationSeconds)*time.Second + frictionMargin
+	start := now
+	for _, e := range rm.ladderLocked(runway) {
+		if !e.Target.Before(start.Add(need)) {
+			break
+		}
+		start = maxTime(start, e.Target.Add(frictionMargin))
+	}
+	rm.frictionRuns[runway].slot = start
+	log.Printf("friction test on %s slotted at %s", runway, start.Format(time.TimeOnly))
+	rm.afterFrictionLocked(start.Sub(now), runway, rm.startFrictionTest)
+}
+
+// startFrictionTest puts the test vehicle on the runway for its reserved
+// slot, looking for another gap if the runway has become unusable.
+func (rm *RunwayManager) startFrictionTest(runway string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	run := rm.frictionRuns[runway]
+	if !rm.runways[runway].available() || rm.occupiedLocked(runway) || rm.takingOff[runway] {
+		run.slot = time.Time{}
+		rm.afterFrictionLocked(frictionRetry, runway, rm.planFrictionTest)
+		return
+	}
+	d := time.Duration(rm.friction.DurationSeconds) * time.Second
+	rm.occupyLocked(runway, GroundFrictionTest, d)
+	now := rm.clock.Now()
+	run.last = now
+	run.reading = frictionReadings[rm.winter.Precipitation]
+	run.due = now.Add(time.Duration(rm.friction.IntervalSeconds) * time.Second)
+	rm.publishEventLocked(Event{Type: EventFrictionTest, Runway: runway, Detail: fmt.Sprintf("mu %.2f", run.reading)})
+	rm.afterFrictionLocked(run.due.Sub(now), runway, rm.planFrictionTest)
+}
+
+// frictionSlotLocked pushes target past a friction test reserved on runway
+// if it would land during it.
+func (rm *RunwayManager) frictionSlotLocked(runway string, target time.Time) time.Time {
+	run, ok := rm.frictionRuns[runway]
+	if !ok || run.slot.IsZero() {
+		return target
+	}
+	end := run.slot.Add(time.Duration(rm.friction.DurationSeconds)*time.Second + frictionMargin)
+	if target.After(run.slot.Add(-frictionMargin)) && target.Before(end) {
+		return end
+	}
+	return target
+}
+
+// FrictionRunway is one runway's friction testing state.
+type FrictionRunway struct {
+	Runway string    `json:"runway"`
+	Due    time.Time `json:"due"`
+	// Slot is the start of the gap reserved for the next test, or of the
+	// test in progress.
+	Slot *time.Time `json:"slot,omitempty"`
+	// LastTest and Reading are the time and braking coefficient of the
+	// latest test.
+	LastTest *time.Time `json:"lastTest,omitempty"`
+	Reading  float64    `json:"reading,omitempty"`
+}
+
+// FrictionState is the friction test schedule and each runway's testing.
+type FrictionState struct {
+	Schedule FrictionTests    `json:"schedule"`
+	Runways  []FrictionRunway `json:"runways"`
+}
+
+// FrictionTesting reports the friction test schedule.
+func (rm *RunwayManager) FrictionTesting() FrictionState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	state := FrictionState{Schedule: rm.friction, Runways: []FrictionRunway{}}
+	if rm.friction.IntervalSeconds == 0 {
+		return state
+	}
+	now := rm.clock.Now()
+	for _, name := range rm.order {
+		run := rm.frictionRuns[name]
+		r := FrictionRunway{Runway: name, Due: run.due, Reading: run.reading}
+		if !run.slot.IsZero() && !rm.frictionSlotLocked(name, now).Equal(now) {
+			slot := run.slot
+			r.Slot = &slot
+		}
+		if !run.last.IsZero() {
+			last := run.last
+			r.LastTest = &last
+		}
+		state.Runways = append(state.Runways, r)
+	}
+	return state
+}
+
+// HandleFriction reports (GET) or replaces (PUT) the friction test schedule.
+func (s *Server) HandleFriction(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req FrictionTests
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid friction tests: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetFrictionTests(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.FrictionTesting())
+}
@@ -0,0 +1,372 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Event types for stand allocation.
+const (
+	EventParked       = "parked"
+	EventGateConflict = "gateConflict"
+	EventTowed        = "towed"
+	EventPushback     = "pushback"
+)
+
+// standTimeFactors scales the stand time by weight category.
+var standTimeFactors = map[string]float64{
+	WeightLight:  0.5,
+	WeightMedium: 1,
+	WeightHeavy:  1.5,
+	WeightSuper:  2,
+}
+
+// weightRanks orders weight categories for stand size checks.
+var weightRanks = map[string]int{WeightLight: 0, WeightMedium: 1, WeightHeavy: 2, WeightSuper: 3}
+
+// Gate is a parking stand. Contact gates are planned ahead of arrival;
+// remote stands take the aircraft that cannot be placed at one.
+type Gate struct {
+	Name string `json:"name"`
+	// Size is the largest weight category the stand accepts; empty accepts
+	// any aircraft.
+	Size string `json:"size,omitempty"`
+	// Airline is the operator the stand is preferably assigned to.
+	Airline string `json:"airline,omitempty"`
+	// Distance is the taxi distance from the runways in meters.
+	Distance float64 `json:"distance"`
+	Remote   bool    `json:"remote,omitempty"`
+}
+
+// fits reports whether f is small enough for the stand.
+func (g Gate) fits(f Flight) bool {
+	if g.Size == "" {
+		return true
+	}
+	weight := f.Weight
+	if weight == "" {
+		weight = WeightMedium
+	}
+	return weightRanks[weight] <= weightRanks[g.Size]
+}
+
+// GateCandidate is a contact gate offered to a GateStrategy, with the time
+// it is expected to be free.
+type GateCandidate struct {
+	Gate   Gate
+	FreeAt time.Time
+}
+
+// GateStrategy chooses a stand for a flight among the contact gates that fit
+// it and are expected to be free when it arrives. Returning an empty name
+// leaves the flight without a planned stand. Strategies are called with the
+// scheduler lock held and must not call back into the RunwayManager.
+type GateStrategy interface {
+	SelectGate(f Flight, candidates []GateCandidate) string
+}
+
+// NearestGateStrategy picks the free gate closest to the runways.
+type NearestGateStrategy struct{}
+
+// SelectGate implements GateStrategy.
+func (NearestGateStrategy) SelectGate(f Flight, candidates []GateCandidate) string {
+	best := ""
+	bestDistance := 0.0
+	for _, c := range candidates {
+		if best == "" || c.Gate.Distance < bestDistance {
+			best, bestDistance = c.Gate.Name, c.Gate.Distance
+		}
+	}
+	return best
+}
+
+// AirlineGateStrategy prefers the airline's own gates, nearest first, and
+// falls back to the nearest unassigned gate.
+type AirlineGateStrategy struct{}
+
+// SelectGate implements GateStrategy.
+func (AirlineGateStrategy) SelectGate(f Flight, candidates []GateCandidate) string {
+	airline := airlineOf(f.Call)
+	var own, shared []GateCandidate
+	for _, c := range candidates {
+		switch c.Gate.Airline {
+		case airline:
+			own = append(own, c)
+		case "":
+			shared = append(shared, c)
+		}
+	}
+	if gate := (NearestGateStrategy{}).SelectGate(f, own); gate != "" {
+		return gate
+	}
+	return NearestGateStrategy{}.SelectGate(f, shared)
+}
+
+// BestFitGateStrategy picks the smallest gate that fits, nearest first,
+// keeping large stands free for large aircraft.
+type BestFitGateStrategy struct{}
+
+// SelectGate implements GateStrategy.
+func (BestFitGateStrategy) SelectGate(f Flight, candidates []GateCandidate) string {
+	size := func(g Gate) int {
+		if g.Size == "" {
+			return len(weightRanks)
+		}
+		return weightRanks[g.Size]
+	}
+	var best *GateCandidate
+	for i := range candidates {
+		c := &candidates[i]
+		if best == nil || size(c.Gate) < size(best.Gate) ||
+			size(c.Gate) == size(best.Gate) && c.Gate.Distance < best.Gate.Distance {
+			best = c
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Gate.Name
+}
+
+type gateState struct {
+	gate        Gate
+	occupant    *standOccupant
+	reservedFor int64
+	busy        time.Duration
+	movements   int64
+}
+
+type standOccupant struct {
+	flight     Flight
+	since      time.Time
+	plannedOff time.Time
+}
+
+// SetGates replaces the parking stands. It must be called before any flights
+// are assigned.
+func (rm *RunwayManager) SetGates(gates []Gate) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.gates = make(map[string]*gateState, len(gates))
+	rm.gateOrder = rm.gateOrder[:0]
+	for _, g := range gates {
+		rm.gates[g.Name] = &gateState{gate: g}
+		rm.gateOrder = append(rm.gateOrder, g.Name)
+	}
+	rm.gatesSince = rm.clock.Now()
+}
+
+// SetGateStrategy replaces the stand allocation strategy.
+func (rm *RunwayManager) SetGateStrategy(strategy GateStrategy) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.gateStrategy = strategy
+}
+
+// planGateLocked reserves a contact gate for f, due to land at eta.
+func (rm *RunwayManager) planGateLocked(f Flight, eta time.Time) {
+	rm.cancelGateLocked(f.ID)
+	var candidates []GateCandidate
+	for _, name := range rm.gateOrder {
+		g := rm.gates[name]
+		if g.gate.Remote || g.reservedFor != 0 || !g.gate.fits(f) {
+			continue
+		}
+		freeAt := rm.clock.Now()
+		if g.occupant != nil {
+			if g.occupant.plannedOff.After(eta) {
+				continue
+			}
+			freeAt = g.occupant.plannedOff
+		}
+		candidates = append(candidates, GateCandidate{Gate: g.gate, FreeAt: freeAt})
+	}
+	if gate := rm.selectGateLocked(f, candidates); gate != "" {
+		rm.gates[gate].reservedFor = f.ID
+		rm.gatePlan[f.ID] = gate
+	}
+}
+
+// selectGateLocked asks the strategy for a stand and checks its answer.
+func (rm *RunwayManager) selectGateLocked(f Flight, candidates []GateCandidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	gate := rm.gateStrategy.SelectGate(f, candidates)
+	for _, c := range candidates {
+		if c.Gate.Name == gate {
+			return gate
+		}
+	}
+	if gate != "" {
+		log.Printf("gate strategy chose unavailable stand %q for flight %d", gate, f.ID)
+	}
+	return ""
+}
+
+// cancelGateLocked drops any stand reserved for the flight.
+func (rm *RunwayManager) cancelGateLocked(id int64) {
+	gate, ok := rm.gatePlan[id]
+	if !ok {
+		return
+	}
+	delete(rm.gatePlan, id)
+	if g := rm.gates[gate]; g.reservedFor == id {
+		g.reservedFor = 0
+	}
+}
+
+// parkLocked puts a flight that just landed on its stand. When the planned
+// gate is still occupied by an overrunning departure, or none was planned
+// and no contact gate is free, the aircraft is towed to a remote stand.
+func (rm *RunwayManager) parkLocked(f Flight) {
+	if len(rm.gates) == 0 {
+		return
+	}
+	gate := rm.gatePlan[f.ID]
+	rm.cancelGateLocked(f.ID)
+	if gate != "" && rm.gates[gate].occupant != nil {
+		if rm.metrics != nil {
+			rm.metrics.RecordGateConflict()
+		}
+		occupant := rm.gates[gate].occupant.flight
+		rm.publishEventLocked(Event{Type: EventGateConflict, FlightID: f.ID, Call: f.Call, Detail: fmt.Sprintf("stand %s still occupied by %s", gate, occupant.Call)})
+		log.Printf("stand %s planned for flight %d still occupied by flight %d", gate, f.ID, occupant.ID)
+		gate = ""
+	}
+	if gate == "" {
+		var candidates []GateCandidate
+		for _, name := range rm.gateOrder {
+			g := rm.gates[name]
+			if !g.gate.Remote && g.occupant == nil && g.reservedFor == 0 && g.gate.fits(f) {
+				candidates = append(candidates, GateCandidate{Gate: g.gate, FreeAt: rm.clock.Now()})
+			}
+		}
+		gate = rm.selectGateLocked(f, candidates)
+	}
+	if gate == "" {
+		for _, name := range rm.gateOrder {
+			if g := rm.gates[name]; g.gate.Remote && g.occupant == nil && g.gate.fits(f) {
+				gate = name
+				break
+			}
+		}
+		if gate == "" {
+			rm.publishEventLocked(Event{Type: EventParked, FlightID: f.ID, Call: f.Call, Detail: "apron: no stand free"})
+			log.Printf("flight %d (%s) parked on the apron: no stand free", f.ID, f.Call)
+			rm.startTurnaroundLocked(f, "")
+			return
+		}
+		if rm.metrics != nil {
+			rm.metrics.RecordTow()
+		}
+		rm.publishEventLocked(Event{Type: EventTowed, FlightID: f.ID, Call: f.Call, Detail: "to remote stand " + gate})
+	}
+
+	g := rm.gates[gate]
+	g.occupant = &standOccupant{flight: f, since: rm.clock.Now()}
+	g.movements++
+	rm.publishGatesLocked()
+	rm.publishEventLocked(Event{Type: EventParked, FlightID: f.ID, Call: f.Call, Detail: gate})
+	log.Printf("flight %d (%s) parked on stand %s", f.ID, f.Call, gate)
+	g.occupant.plannedOff = rm.startTurnaroundLocked(f, gate)
+}
+
+// vacateStandLocked pushes the flight back from its stand and sends it on to
+// depart.
+func (rm *RunwayManager) vacateStandLocked(gate string, id int64) {
+	g, ok := rm.gates[gate]
+	if !ok || g.occupant == nil || g.occupant.flight.ID != id {
+		return
+	}
+	f := g.occupant.flight
+	g.busy += rm.clock.Now().Sub(g.occupant.since)
+	g.occupant = nil
+	rm.publishGatesLocked()
+	rm.publishEventLocked(Event{Type: EventPushback, FlightID: f.ID, Call: f.Call, Detail: gate})
+	log.Printf("flight %d (%s) pushed back from stand %s", f.ID, f.Call, gate)
+	rm.departLocked(f, g.gate.Distance)
+}
+
+func (rm *RunwayManager) publishGatesLocked() {
+	if rm.metrics == nil {
+		return
+	}
+	occupied := 0
+	for _, g := range rm.gates {
+		if g.occupant != nil {
+			occupied++
+		}
+	}
+	rm.metrics.SetGatesOccupied(occupied)
+}
+
+// GateOccupant is the aircraft on a stand.
+type GateOccupant struct {
+	FlightID   int64     `json:"flightId"`
+	Call       string    `json:"call"`
+	Since      time.Time `json:"since"`
+	PlannedOff time.Time `json:"plannedOff"`
+}
+
+// GateStatus is a stand's current use and its utilization so far.
+type GateStatus struct {
+	Gate        Gate          `json:"gate"`
+	Occupant    *GateOccupant `json:"occupant,omitempty"`
+	ReservedFor int64         `json:"reservedFor,omitempty"`
+	Movements   int64         `json:"movements"`
+	// Utilization is the fraction of time the stand has been occupied.
+	Utilization float64 `json:"utilization"`
+}
+
+// GateReport lists every stand with the mean utilization of contact gates.
+type GateReport struct {
+	Gates       []GateStatus `json:"gates"`
+	Utilization float64      `json:"utilization"`
+}
+
+// Gates reports stand occupancy and utilization.
+func (rm *RunwayManager) Gates() GateReport {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := rm.clock.Now()
+	elapsed := now.Sub(rm.gatesSince)
+	report := GateReport{Gates: make([]GateStatus, 0, len(rm.gateOrder))}
+	contact := 0
+	for _, name := range rm.gateOrder {
+		g := rm.gates[name]
+		status := GateStatus{Gate: g.gate, ReservedFor: g.reservedFor, Movements: g.movements}
+		busy := g.busy
+		if o := g.occupant; o != nil {
+			busy += now.Sub(o.since)
+			status.Occupant = &GateOccupant{FlightID: o.flight.ID, Call: o.flight.Call, Since: o.since, PlannedOff: o.plannedOff}
+		}
+		if elapsed > 0 {
+			status.Utilization = float64(busy) / float64(elapsed)
+		}
+		if !g.gate.Remote {
+			report.Utilization += status.Utilization
+			contact++
+		}
+		report.Gates = append(report.Gates, status)
+	}
+	if contact > 0 {
+		report.Utilization /= float64(contact)
+	}
+	sort.SliceStable(report.Gates, func(i, j int) bool {
+		return !report.Gates[i].Gate.Remote && report.Gates[j].Gate.Remote
+	})
+	return report
+}
+
+// HandleGates serves stand occupancy and utilization.
+func (s *Server) HandleGates(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Gates())
+}
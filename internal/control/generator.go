@@ -4,23 +4,69 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"sync/atomic"
 	"time"
 )
 
+// Spawning degrades predictably at extreme rates: no rate exceeds
+// rateCeiling, and ticks are never shorter than minSpawnInterval, faster
+// rates spawning several flights a tick instead.
+const (
+	rateCeiling      = 60000
+	minSpawnInterval = 10 * time.Millisecond
+)
+
 // Generator simulates flight arrivals at a configurable rate.
 type Generator struct {
 	ratePerMinute atomic.Int64
-	nextID        atomic.Int64
+	// rateChanges counts the changes of rate, for the state version.
+	rateChanges atomic.Int64
+	nextID      atomic.Int64
+	// nextAirframe numbers the airframes of generated flights. A flight
+	// gets at most one new airframe, so it never passes nextID.
+	nextAirframe atomic.Int64
+	// maxRate caps the rate when positive.
+	maxRate atomic.Int64
+	spawner atomic.Pointer[FlightSpawner]
+	// identity issues callsigns and IDs in a configured format; nil keeps
+	// the built-in one.
+	identity atomic.Pointer[callsignIssuer]
+	// ramp is the rate change in progress, if any.
+	ramp  atomic.Pointer[rateRamp]
+	clock Clock
+	// pipe accounts for flights delayed on their way to the scheduler.
+	pipe spawnPipe
+	// carry is the fraction of a flight owed to the next batched tick. Only
+	// the spawning loop touches it.
+	carry float64
+}
+
+// rateRamp moves the rate linearly from from to to over over.
+type rateRamp struct {
+	from  float64
+	to    int64
+	start time.Time
+	over  time.Duration
+}
+
+// RateRamp is a gradual change of the arrival rate in progress.
+type RateRamp struct {
+	From            float64   `json:"from"`
+	To              int64     `json:"to"`
+	StartedAt       time.Time `json:"startedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	// Progress is the fraction of the change made so far.
+	Progress float64 `json:"progress"`
 }
 
 // NewGenerator constructs a generator with a default rate.
 func NewGenerator(defaultRate int64) *Generator {
-	g := &Generator{}
+	g := &Generator{clock: RealClock{}}
 	if defaultRate <= 0 {
 		defaultRate = 1
 	}
-	g.ratePerMinute.Store(defaultRate)
+	g.ratePerMinute.Store(g.capRate(defaultRate))
 	return g
 }
 
@@ -29,17 +75,155 @@ func (g *Generator) SetRate(rate int64) {
 	if rate <= 0 {
 		rate = 1
 	}
-	g.ratePerMinute.Store(rate)
+	rate = g.clampRate(rate)
+	ramping := g.ramp.Swap(nil) != nil
+	if g.ratePerMinute.Swap(rate) != rate || ramping {
+		g.rateChanges.Add(1)
+	}
 	log.Printf("arrival rate updated: %d planes/min", rate)
 }
 
+// RampRate moves the rate to target linearly over the given duration, as in
+// reaching 30 planes per minute over five minutes, so demand changes
+// smoothly. A ramp replaces any in progress, starting from the rate
+// reached; a non-positive duration changes the rate at once.
+func (g *Generator) RampRate(target int64, over time.Duration) {
+	if target <= 0 {
+		target = 1
+	}
+	target = g.clampRate(target)
+	if over <= 0 {
+		g.SetRate(target)
+		return
+	}
+	now := g.clock.Now()
+	from := g.currentRate(now)
+	g.ramp.Store(&rateRamp{from: from, to: target, start: now, over: over})
+	g.rateChanges.Add(1)
+	log.Printf("arrival rate ramping from %.1f to %d planes/min over %s", from, target, over)
+}
+
+// Ramp reports the rate change in progress, or nil when the rate is
+// steady.
+func (g *Generator) Ramp() *RateRamp {
+	r := g.ramp.Load()
+	if r == nil {
+		return nil
+	}
+	progress := float64(g.clock.Now().Sub(r.start)) / float64(r.over)
+	if progress >= 1 {
+		return nil
+	}
+	return &RateRamp{From: r.from, To: r.to, StartedAt: r.start, DurationSeconds: r.over.Seconds(), Progress: max(progress, 0)}
+}
+
+// currentRate is the rate at now, part way along any ramp. A finished
+// ramp settles on its target.
+func (g *Generator) currentRate(now time.Time) float64 {
+	r := g.ramp.Load()
+	if r == nil {
+		return float64(max(g.ratePerMinute.Load(), 1))
+	}
+	progress := float64(now.Sub(r.start)) / float64(r.over)
+	if progress >= 1 {
+		if g.ramp.CompareAndSwap(r, nil) {
+			g.ratePerMinute.Store(r.to)
+			log.Printf("arrival rate ramp complete: %d planes/min", r.to)
+		}
+		return float64(r.to)
+	}
+	return max(r.from+(float64(r.to)-r.from)*max(progress, 0), 1)
+}
+
+// SetClock replaces the clock used to timestamp flights. It must be called
+// before the generator starts.
+func (g *Generator) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// SetSpawner installs a custom FlightSpawner; nil restores the default.
+func (g *Generator) SetSpawner(s FlightSpawner) {
+	if s == nil {
+		g.spawner.Store(nil)
+		return
+	}
+	g.spawner.Store(&s)
+}
+
+// SetMaxRate caps the rate at max planes per minute, lowering it at once
+// if above; zero removes the cap.
+func (g *Generator) SetMaxRate(max int64) {
+	g.maxRate.Store(max)
+	if max <= 0 {
+		return
+	}
+	if r := g.ramp.Load(); g.Rate() > max || r != nil && r.to > max {
+		g.SetRate(min(g.Rate(), max))
+	}
+}
+
+// MaxRate returns the rate cap, zero when uncapped.
+func (g *Generator) MaxRate() int64 {
+	return g.maxRate.Load()
+}
+
+// capRate limits rate to the configured maximum and rateCeiling.
+func (g *Generator) capRate(rate int64) int64 {
+	if max := g.maxRate.Load(); max > 0 && rate > max {
+		return max
+	}
+	return min(rate, rateCeiling)
+}
+
+// clampRate is capRate, logging when it lowers rate.
+func (g *Generator) clampRate(rate int64) int64 {
+	capped := g.capRate(rate)
+	if capped < rate {
+		log.Printf("arrival rate %d planes/min clamped to %d", rate, capped)
+	}
+	return capped
+}
+
 // Rate returns the current rate in planes per minute.
 func (g *Generator) Rate() int64 {
-	rate := g.ratePerMinute.Load()
-	if rate <= 0 {
-		return 1
+	return int64(math.Round(g.currentRate(g.clock.Now())))
+}
+
+// ResumeFrom ensures newly generated IDs and airframes continue after
+// lastID, so flights recovered from a previous run never collide with new
+// ones.
+func (g *Generator) ResumeFrom(lastID int64) {
+	advanceTo(&g.nextID, lastID)
+	advanceTo(&g.nextAirframe, lastID)
+}
+
+func advanceTo(counter *atomic.Int64, n int64) {
+	for {
+		current := counter.Load()
+		if current >= n || counter.CompareAndSwap(current, n) {
+			return
+		}
 	}
-	return rate
+}
+
+// NextID issues a fresh flight ID.
+func (g *Generator) NextID() int64 {
+	if issuer := g.identity.Load(); issuer != nil && issuer.cfg.IDs == IDSnowflake {
+		base := snowflakeID(g.clock.Now(), issuer.cfg.Node)
+		for {
+			current := g.nextID.Load()
+			next := max(base, current+1)
+			if g.nextID.CompareAndSwap(current, next) {
+				return next
+			}
+		}
+	}
+	return g.nextID.Add(1)
+}
+
+// LastID returns the most recently issued flight ID.
+func (g *Generator) LastID() int64 {
+	return g.nextID.Load()
 }
 
 // Flight represents a generated flight payload.
@@ -47,37 +231,144 @@ type Flight struct {
 	ID        int64     `json:"id"`
 	Call      string    `json:"call"`
 	CreatedAt time.Time `json:"createdAt"`
+	Tags      []string  `json:"tags,omitempty"`
+	// Approach is the best approach category the aircraft is certified for;
+	// CAT I when empty.
+	Approach string `json:"approach,omitempty"`
+	// Equipage lists the aircraft's navigation and separation capabilities.
+	Equipage []string `json:"equipage,omitempty"`
+	// Weight is the aircraft's weight category, which sets its landing fee.
+	Weight string `json:"weight,omitempty"`
+	// Airframe is the aircraft's registration. Flights without one do not
+	// rotate back for another leg.
+	Airframe string `json:"airframe,omitempty"`
+	// ScheduledAt is the scheduled landing time, by default as soon as the
+	// flight can land after being spawned.
+	ScheduledAt time.Time `json:"scheduledAt"`
+	// UUID identifies the flight to downstream systems when flight IDs are
+	// configured as UUIDs.
+	UUID string `json:"uuid,omitempty"`
 }
 
-// Run starts generating flights until the context is canceled.
+// Run starts generating flights until the context is canceled. Spawning
+// keeps to the rate however slowly out is drained: flights the consumer
+// has no room for wait in a backlog, in order, and are counted as delayed
+// by backpressure (see Backpressure). Flights still in the backlog at
+// shutdown are dropped.
 func (g *Generator) Run(ctx context.Context, out chan<- Flight) {
+	defer close(out)
+	var backlog []backlogFlight
+	wait, batch := g.nextTick()
+	tick := g.after(wait)
 	for {
+		// Offer the oldest backlogged flight only while there is one.
+		var send chan<- Flight
+		var next Flight
+		if len(backlog) > 0 {
+			send, next = out, backlog[0].flight
+		}
 		select {
 		case <-ctx.Done():
-			close(out)
+			g.pipe.shutdown(len(backlog))
 			return
-		case <-time.After(g.interval()):
-			flight := g.spawn()
-			select {
-			case <-ctx.Done():
-				close(out)
-				return
-			case out <- flight:
+		case <-tick:
+			spawning := batch
+			wait, batch = g.nextTick()
+			tick = g.after(wait)
+			for range spawning {
+				backlog = g.offer(out, backlog, g.spawn())
 			}
+		case send <- next:
+			g.pipe.delivered(g.clock.Now().Sub(backlog[0].since), len(backlog)-1)
+			backlog = backlog[1:]
 		}
 	}
 }
 
-func (g *Generator) interval() time.Duration {
-	rate := g.Rate()
-	return time.Minute / time.Duration(rate)
+// offer sends flight to out if nothing is backlogged ahead of it and out
+// has room, and otherwise backlogs it, dropping it when the backlog is
+// full.
+func (g *Generator) offer(out chan<- Flight, backlog []backlogFlight, flight Flight) []backlogFlight {
+	if len(backlog) == 0 {
+		select {
+		case out <- flight:
+			g.pipe.onTime()
+			return backlog
+		default:
+		}
+	}
+	if len(backlog) >= maxSpawnBacklog {
+		g.pipe.overflow()
+		log.Printf("flight %d (%s) dropped: spawn backlog full", flight.ID, flight.Call)
+		return backlog
+	}
+	backlog = append(backlog, backlogFlight{flight: flight, since: g.clock.Now()})
+	g.pipe.delayed(len(backlog))
+	return backlog
+}
+
+// after is time.After on the generator's clock.
+func (g *Generator) after(d time.Duration) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	g.clock.AfterFunc(d, func() { ch <- struct{}{} })
+	return ch
+}
+
+// nextTick is the wait until the next spawn and how many flights it
+// spawns: one, unless the rate needs ticks shorter than minSpawnInterval,
+// when the tick is floored and spawns the flights due over it, the
+// fraction left over carried to the next.
+func (g *Generator) nextTick() (time.Duration, int) {
+	interval := float64(time.Minute) / g.currentRate(g.clock.Now())
+	if interval >= float64(minSpawnInterval) {
+		g.carry = 0
+		return time.Duration(interval), 1
+	}
+	g.carry += float64(minSpawnInterval) / interval
+	n := int(g.carry)
+	g.carry -= float64(n)
+	return minSpawnInterval, n
 }
 
 func (g *Generator) spawn() Flight {
-	id := g.nextID.Add(1)
-	return Flight{
-		ID:        id,
-		Call:      "FLT" + time.Now().Format("150405") + "-" + fmt.Sprintf("%04d", id%10000),
-		CreatedAt: time.Now(),
+	id := g.NextID()
+	now := g.clock.Now()
+	issuer := g.identity.Load()
+	var f Flight
+	if custom := g.spawner.Load(); custom != nil {
+		f = (*custom).Spawn(id, now)
+		f.ID = id
+		if f.CreatedAt.IsZero() {
+			f.CreatedAt = now
+		}
+		if f.ScheduledAt.IsZero() {
+			f.ScheduledAt = f.CreatedAt.Add(landingDuration)
+		}
+		if f.Call == "" && issuer != nil {
+			f.Call = issuer.issue(id, now)
+		}
+	} else {
+		call := defaultCallsign(id, now)
+		if issuer != nil {
+			call = issuer.issue(id, now)
+		}
+		f = Flight{
+			ID:          id,
+			Call:        call,
+			CreatedAt:   now,
+			ScheduledAt: now.Add(landingDuration),
+			Approach:    defaultApproach(id),
+			Equipage:    defaultEquipage(id),
+			Weight:      defaultWeight(id),
+			Airframe:    fmt.Sprintf("N%04dA", g.nextAirframe.Add(1)),
+		}
+	}
+	if issuer != nil && issuer.cfg.IDs == IDUUID && f.UUID == "" {
+		f.UUID = newUUID()
 	}
+	return f
+}
+
+func defaultCallsign(id int64, now time.Time) string {
+	return "FLT" + now.Format("150405") + "-" + fmt.Sprintf("%04d", id%10000)
 }
@@ -0,0 +1,197 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// LatLon is a WGS84 position in decimal degrees.
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func (p LatLon) validate() error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("position %.6f,%.6f out of range", p.Lat, p.Lon)
+	}
+	return nil
+}
+
+// RunwayThresholds locates both ends of a runway: Threshold is the end
+// arrivals on the definition's heading land from, Reciprocal the other.
+type RunwayThresholds struct {
+	Threshold  LatLon `json:"threshold"`
+	Reciprocal LatLon `json:"reciprocal"`
+}
+
+// MapTiles tells map clients where to fetch base map tiles. URL is a
+// template with {z}, {x} and {y} placeholders.
+type MapTiles struct {
+	URL         string `json:"url"`
+	Attribution string `json:"attribution,omitempty"`
+	MinZoom     int    `json:"minZoom,omitempty"`
+	MaxZoom     int    `json:"maxZoom,omitempty"`
+}
+
+// Geography places the airport in the real world for map-based clients:
+// its reference point, the magnetic variation in degrees (east positive),
+// the runway thresholds keyed by runway name and the base map tiles. The
+// zero value puts the airport at 0,0 with no variation.
+type Geography struct {
+	Reference         LatLon                      `json:"reference"`
+	MagneticVariation float64                     `json:"magneticVariation"`
+	Thresholds        map[string]RunwayThresholds `json:"thresholds,omitempty"`
+	Tiles             *MapTiles                   `json:"tiles,omitempty"`
+}
+
+// RunwayPlacement is a runway as a map draws it, from its threshold to the
+// reciprocal threshold. Bearings are in degrees and Length in meters.
+type RunwayPlacement struct {
+	Runway              string  `json:"runway"`
+	Reciprocal          string  `json:"reciprocal"`
+	Threshold           LatLon  `json:"threshold"`
+	ReciprocalThreshold LatLon  `json:"reciprocalThreshold"`
+	TrueBearing         float64 `json:"trueBearing"`
+	MagneticBearing     float64 `json:"magneticBearing"`
+	Length              float64 `json:"length"`
+}
+
+// GeographyState is the configured geography with every located runway
+// placed on the map.
+type GeographyState struct {
+	Geography Geography         `json:"geography"`
+	Runways   []RunwayPlacement `json:"runways"`
+}
+
+// SetGeography replaces the airport's real-world placement. Thresholds
+// must name known runways. A new magnetic variation turns runways to the
+// wind as it now falls on their magnetic headings.
+func (rm *RunwayManager) SetGeography(g Geography) error {
+	if err := g.Reference.validate(); err != nil {
+		return fmt.Errorf("reference: %w", err)
+	}
+	if g.MagneticVariation < -180 || g.MagneticVariation > 180 {
+		return fmt.Errorf("magnetic variation %.1f out of range", g.MagneticVariation)
+	}
+	if t := g.Tiles; t != nil {
+		for _, placeholder := range []string{"{z}", "{x}", "{y}"} {
+			if !strings.Contains(t.URL, placeholder) {
+				return fmt.Errorf("tile url lacks %s", placeholder)
+			}
+		}
+		if t.MinZoom < 0 || t.MaxZoom < t.MinZoom {
+			return fmt.Errorf("tile zoom range %d-%d invalid", t.MinZoom, t.MaxZoom)
+		}
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	for name, t := range g.Thresholds {
+		if _, ok := rm.runways[name]; !ok {
+			return fmt.Errorf("unknown runway %q", name)
+		}
+		if err := t.Threshold.validate(); err != nil {
+			return fmt.Errorf("runway %s threshold: %w", name, err)
+		}
+		if err := t.Reciprocal.validate(); err != nil {
+			return fmt.Errorf("runway %s reciprocal: %w", name, err)
+		}
+		if t.Threshold == t.Reciprocal {
+			return fmt.Errorf("runway %s thresholds coincide", name)
+		}
+	}
+	varied := g.MagneticVariation != rm.geography.MagneticVariation
+	rm.geography = g
+	if varied {
+		rm.version++
+		rm.updateActiveHeadingsLocked()
+		rm.revectorLocked()
+	}
+	log.Printf("airport reference %.6f,%.6f, magnetic variation %+.1f°, %d runways located", g.Reference.Lat, g.Reference.Lon, g.MagneticVariation, len(g.Thresholds))
+	return nil
+}
+
+// Geography reports the airport's placement and its located runways in
+// assignment order.
+func (rm *RunwayManager) Geography() GeographyState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	g := rm.geography
+	state := GeographyState{Geography: g, Runways: []RunwayPlacement{}}
+	for _, name := range rm.order {
+		t, ok := g.Thresholds[name]
+		if !ok {
+			continue
+		}
+		bearing := initialBearing(t.Threshold, t.Reciprocal)
+		state.Runways = append(state.Runways, RunwayPlacement{
+			Runway:              name,
+			Reciprocal:          rm.reciprocalLocked(name),
+			Threshold:           t.Threshold,
+			ReciprocalThreshold: t.Reciprocal,
+			TrueBearing:         bearing,
+			MagneticBearing:     normalizeHeading(bearing - g.MagneticVariation),
+			Length:              greatCircleDistance(t.Threshold, t.Reciprocal),
+		})
+	}
+	return state
+}
+
+// initialBearing is the true bearing in degrees setting out from a along
+// the great circle to b.
+func initialBearing(a, b LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return normalizeHeading(math.Atan2(y, x) * 180 / math.Pi)
+}
+
+// magneticWind is wind with its direction converted from true to magnetic
+// under variation, east positive, for comparison with runway headings.
+func magneticWind(wind WindState, variation float64) WindState {
+	magnetic := func(dir int64) int64 {
+		return normalizeDirection(int64(math.Round(float64(dir) - variation)))
+	}
+	wind.Direction = magnetic(wind.Direction)
+	if v := wind.Variable; v != nil {
+		wind.Variable = &WindVariation{From: magnetic(v.From), To: magnetic(v.To)}
+	}
+	return wind
+}
+
+// magneticWindLocked is the current wind in degrees magnetic.
+func (rm *RunwayManager) magneticWindLocked() WindState {
+	return magneticWind(rm.wind, rm.geography.MagneticVariation)
+}
+
+// trueHeadingLocked is the true heading arrivals land runway on, for
+// geometry laid out on true north.
+func (rm *RunwayManager) trueHeadingLocked(runway string) float64 {
+	return normalizeHeading(rm.runways[runway].activeHeading + rm.geography.MagneticVariation)
+}
+
+// greatCircleDistance is the distance in meters from a to b.
+func greatCircleDistance(a, b LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// HandleGeography reports the airport reference point, magnetic variation,
+// map tiles and runway placement.
+func (s *Server) HandleGeography(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Geography())
+}
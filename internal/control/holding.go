@@ -0,0 +1,129 @@
+package control
+
+import "time"
+
+// HoldingFlight is the flight that has been holding longest of those
+// holding now.
+type HoldingFlight struct {
+	FlightID int64     `json:"flightId"`
+	Call     string    `json:"call"`
+	Since    time.Time `json:"since"`
+	Minutes  float64   `json:"minutes"`
+}
+
+// holdingStats accumulates time spent holding, under
+// SchedulerMetrics.holdMu.
+type holdingStats struct {
+	flights     int64
+	totalMicros int64
+	longest     *HoldingFlight
+}
+
+// RecordHoldingTime registers a spell of holding that just ended. first is
+// set for the flight's first spell, so the average is per flight held
+// rather than per spell.
+func (m *SchedulerMetrics) RecordHoldingTime(d time.Duration, first bool) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	if first {
+		m.hold.flights++
+	}
+	m.hold.totalMicros += d.Microseconds()
+}
+
+// SetLongestHolding stores the flight holding longest now, nil when none is.
+func (m *SchedulerMetrics) SetLongestHolding(f *HoldingFlight) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	m.hold.longest = f
+}
+
+// SetClock sets the clock the longest holding time is measured on. It must
+// be called before metrics are read.
+func (m *SchedulerMetrics) SetClock(clock Clock) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	m.clock = clock
+}
+
+// readHolding returns the total and average minutes held and the flight
+// holding longest now.
+func (m *SchedulerMetrics) readHolding() (total, average float64, longest *HoldingFlight) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	total = float64(m.hold.totalMicros) / 60_000_000
+	if m.hold.flights > 0 {
+		average = total / float64(m.hold.flights)
+	}
+	if m.hold.longest != nil {
+		f := *m.hold.longest
+		clock := m.clock
+		if clock == nil {
+			clock = RealClock{}
+		}
+		f.Minutes = max(clock.Now().Sub(f.Since).Minutes(), 0)
+		longest = &f
+	}
+	return total, average, longest
+}
+
+// holdingSpell is a flight's spell of holding in progress, and fix when it
+// reached the holding fix.
+type holdingSpell struct {
+	call  string
+	since time.Time
+	fix   time.Time
+}
+
+// longestHoldingMinutes is how long the longest-holding flight has held,
+// zero when none is.
+func longestHoldingMinutes(s MetricsSnapshot) float64 {
+	if s.LongestHolding == nil {
+		return 0
+	}
+	return s.LongestHolding.Minutes
+}
+
+// enterHoldingLocked starts timing a spell of holding for f, which joins
+// the pattern at the fix.
+func (rm *RunwayManager) enterHoldingLocked(f Flight) {
+	now := rm.clock.Now()
+	rm.holdingSpells[f.ID] = holdingSpell{call: f.Call, since: now, fix: now}
+	delete(rm.fixWaits, f.ID)
+	rm.publishLongestHoldingLocked()
+}
+
+// leaveHoldingLocked ends f's spell of holding, if it is in one. ended is
+// set when f's lifecycle is over, and its holding time forgotten.
+func (rm *RunwayManager) leaveHoldingLocked(f Flight, ended bool) {
+	if spell, ok := rm.holdingSpells[f.ID]; ok {
+		delete(rm.holdingSpells, f.ID)
+		held := rm.clock.Now().Sub(spell.since)
+		_, seen := rm.heldFor[f.ID]
+		first := !seen
+		rm.heldFor[f.ID] += held
+		if rm.metrics != nil {
+			rm.metrics.RecordHoldingTime(held, first)
+		}
+		rm.publishLongestHoldingLocked()
+	}
+	if ended {
+		delete(rm.heldFor, f.ID)
+		delete(rm.fixWaits, f.ID)
+	}
+}
+
+// publishLongestHoldingLocked updates the longest-holding gauge, ties going
+// to the lower flight ID so it is deterministic.
+func (rm *RunwayManager) publishLongestHoldingLocked() {
+	if rm.metrics == nil {
+		return
+	}
+	var longest *HoldingFlight
+	for id, spell := range rm.holdingSpells {
+		if longest == nil || spell.since.Before(longest.Since) || spell.since.Equal(longest.Since) && id < longest.FlightID {
+			longest = &HoldingFlight{FlightID: id, Call: spell.call, Since: spell.since}
+		}
+	}
+	rm.metrics.SetLongestHolding(longest)
+}
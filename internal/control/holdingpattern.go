@@ -0,0 +1,174 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// maxHoldingLeg bounds the configured leg time; racetracks are flown with
+// legs of a minute or a minute and a half, or a few miles.
+const maxHoldingLeg = 5 * time.Minute
+
+// HoldingPattern is the racetrack flown at the holding fix: an outbound and
+// an inbound leg of LegSeconds each, joined by 180° turns at TurnRate
+// degrees per second. The standard pattern below FL140, one-minute legs and
+// rate-one turns at 3° per second, makes each orbit four minutes. Flights
+// join the pattern at the fix and can only leave it there, so a holding
+// flight cleared to approach first completes the orbit it is on. The zero
+// value lets holding flights leave the moment they are cleared.
+type HoldingPattern struct {
+	LegSeconds float64 `json:"legSeconds"`
+	TurnRate   float64 `json:"turnRate"`
+}
+
+func (p HoldingPattern) validate() error {
+	switch {
+	case p == HoldingPattern{}:
+		return nil
+	case p.LegSeconds < 0 || p.LegSeconds > maxHoldingLeg.Seconds():
+		return fmt.Errorf("legSeconds must be between 0 and %.0f", maxHoldingLeg.Seconds())
+	case p.TurnRate < 1 || p.TurnRate > 6:
+		return fmt.Errorf("turnRate must be between 1 and 6 degrees per second")
+	}
+	return nil
+}
+
+// orbit is the time one circuit of the pattern takes, zero when holding
+// flights leave at once.
+func (p HoldingPattern) orbit() time.Duration {
+	if p.TurnRate <= 0 {
+		return 0
+	}
+	return time.Duration((2*p.LegSeconds + 360/p.TurnRate) * float64(time.Second))
+}
+
+// nextFix is the first time at or after t a flight that reached the fix at
+// fix is back over it.
+func (p HoldingPattern) nextFix(fix, t time.Time) time.Time {
+	if !t.After(fix) {
+		return fix
+	}
+	orbit := p.orbit()
+	if orbit <= 0 {
+		return t
+	}
+	orbits := (t.Sub(fix) + orbit - 1) / orbit
+	return fix.Add(orbits * orbit)
+}
+
+// SetHoldingPattern replaces the holding pattern. Flights already cleared
+// out of the hold keep their approach times.
+func (rm *RunwayManager) SetHoldingPattern(p HoldingPattern) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.pattern = p
+	log.Printf("holding pattern: %.0fs legs, %.1f°/s turns, %s orbits", p.LegSeconds, p.TurnRate, p.orbit())
+	return nil
+}
+
+// HoldingOrbit is a holding flight's progress around the pattern.
+type HoldingOrbit struct {
+	FlightID int64  `json:"flightId"`
+	Call     string `json:"call"`
+	// FixAt is when the flight reached the fix, Orbits the circuits it has
+	// completed since and NextFixAt when it is next over the fix, the
+	// earliest it can leave.
+	FixAt     time.Time `json:"fixAt"`
+	Orbits    int64     `json:"orbits"`
+	NextFixAt time.Time `json:"nextFixAt"`
+}
+
+// HoldingPatternStatus is the holding pattern, the time and fuel each orbit
+// takes and where the holding flights are in it.
+type HoldingPatternStatus struct {
+	Pattern      HoldingPattern `json:"pattern"`
+	OrbitSeconds float64        `json:"orbitSeconds"`
+	OrbitFuelKg  float64        `json:"orbitFuelKg"`
+	Flights      []HoldingOrbit `json:"flights"`
+}
+
+// HoldingPattern reports the pattern and the flights flying it.
+func (rm *RunwayManager) HoldingPattern() HoldingPatternStatus {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	orbit := rm.pattern.orbit()
+	status := HoldingPatternStatus{
+		Pattern:      rm.pattern,
+		OrbitSeconds: orbit.Seconds(),
+		OrbitFuelKg:  math.Round(orbit.Minutes()*holdingFuelKgPerMinute*10) / 10,
+		Flights:      []HoldingOrbit{},
+	}
+	now := rm.clock.Now()
+	for id, spell := range rm.holdingSpells {
+		o := HoldingOrbit{FlightID: id, Call: spell.call, FixAt: spell.fix, NextFixAt: rm.pattern.nextFix(spell.fix, now)}
+		if orbit > 0 && now.After(spell.fix) {
+			o.Orbits = int64(now.Sub(spell.fix) / orbit)
+		}
+		status.Flights = append(status.Flights, o)
+	}
+	sort.Slice(status.Flights, func(i, j int) bool { return status.Flights[i].FlightID < status.Flights[j].FlightID })
+	return status
+}
+
+// joinPatternLocked records that holding f reaches the fix at at, later
+// than it began holding when it first flies a missed approach.
+func (rm *RunwayManager) joinPatternLocked(f Flight, at time.Time) {
+	if spell, ok := rm.holdingSpells[f.ID]; ok {
+		spell.fix = at
+		rm.holdingSpells[f.ID] = spell
+	}
+}
+
+// leaveFixLocked is how long holding f, cleared now, flies on to the fix
+// before it can leave the hold. The time is charged as holding, to the
+// cause of f's delay, and remembered so its approach allows for it.
+func (rm *RunwayManager) leaveFixLocked(f Flight) {
+	delete(rm.fixWaits, f.ID)
+	spell, ok := rm.holdingSpells[f.ID]
+	if !ok {
+		return
+	}
+	now := rm.clock.Now()
+	wait := rm.pattern.nextFix(spell.fix, now).Sub(now)
+	if wait <= 0 {
+		return
+	}
+	rm.fixWaits[f.ID] = wait
+	rm.burnLocked(f, wait, false)
+	if entry, ok := rm.delays[f.ID]; ok && rm.metrics != nil {
+		rm.metrics.RecordDelay(entry.cause, wait)
+	}
+}
+
+// fixWaitLocked is how long f flew on to the holding fix after clearance.
+func (rm *RunwayManager) fixWaitLocked(f Flight) time.Duration {
+	return rm.fixWaits[f.ID]
+}
+
+// HandleHoldingPattern reports (GET) or replaces (PUT) the holding pattern.
+func (s *Server) HandleHoldingPattern(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var p HoldingPattern
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "invalid holding pattern: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetHoldingPattern(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.HoldingPattern())
+}
@@ -0,0 +1,292 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+const scriptReloadInterval = 2 * time.Second
+
+// Scripts run on the scheduler's path, on_spawn while a flight enters the
+// sequence, so a run that exceeds either budget is cancelled and skipped.
+const (
+	scriptMaxSteps = 1_000_000
+	scriptTimeout  = 100 * time.Millisecond
+)
+
+// Hook function names a script may define.
+const (
+	hookOnSpawn      = "on_spawn"
+	hookOnAssign     = "on_assign"
+	hookOnWindChange = "on_wind_change"
+)
+
+// ScriptHooks runs user-supplied Starlark scripts at key points of the
+// simulation. Every *.star file in the directory may define:
+//
+//	on_spawn(flight)              -> optional dict {"call": str, "tags": [str], "approach": str}
+//	on_assign(flight, runway)
+//	on_wind_change(speed, direction)
+//
+// Scripts can act on the simulation through the builtins set_rate(n),
+// close_runway(name), open_runway(name) and log(msg). Files are reloaded when
+// they change on disk.
+type ScriptHooks struct {
+	ctx     context.Context
+	dir     string
+	gen     *Generator
+	runways *RunwayManager
+
+	mu      sync.RWMutex
+	scripts []*script
+	mtimes  map[string]time.Time
+}
+
+type script struct {
+	name    string
+	globals starlark.StringDict
+}
+
+// scriptAction is a side effect requested by a script, applied once the hook
+// returns so scripts never run while the scheduler lock is held.
+type scriptAction func()
+
+// NewScriptHooks loads every script in dir. Scripts still running when ctx
+// is done are cancelled.
+func NewScriptHooks(ctx context.Context, dir string, gen *Generator, runways *RunwayManager) (*ScriptHooks, error) {
+	h := &ScriptHooks{ctx: ctx, dir: dir, gen: gen, runways: runways}
+	if err := h.reload(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Run dispatches bus events to the assignment and wind hooks and hot-reloads
+// changed scripts until the context is canceled.
+func (h *ScriptHooks) Run(ctx context.Context, bus *EventBus) {
+	events, unsubscribe := bus.Subscribe(eventBufferSize)
+	defer unsubscribe()
+	ticker := time.NewTicker(scriptReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if h.changed() {
+				if err := h.reload(); err != nil {
+					log.Printf("script reload: %v (keeping previous scripts)", err)
+				}
+			}
+		case e := <-events:
+			switch e.Type {
+			case EventAssigned:
+				flight := flightValue(Flight{ID: e.FlightID, Call: e.Call})
+				h.call(hookOnAssign, flight, starlark.String(e.Runway))
+			case EventWindChanged:
+				wind := h.runways.Wind()
+				h.call(hookOnWindChange, starlark.MakeInt64(wind.Speed), starlark.MakeInt64(wind.Direction))
+			}
+		}
+	}
+}
+
+// OnSpawn runs the on_spawn hooks and applies any callsign or tag changes
+// they return. It is installed as the RunwayManager arrival hook.
+func (h *ScriptHooks) OnSpawn(f Flight) Flight {
+	results := h.call(hookOnSpawn, flightValue(f))
+	for _, res := range results {
+		dict, ok := res.(*starlark.Dict)
+		if !ok {
+			continue
+		}
+		if v, found, _ := dict.Get(starlark.String("call")); found {
+			if call, ok := starlark.AsString(v); ok && call != "" {
+				f.Call = call
+			}
+		}
+		if v, found, _ := dict.Get(starlark.String("approach")); found {
+			if approach, ok := starlark.AsString(v); ok && ValidApproach(approach) {
+				f.Approach = approach
+			}
+		}
+		if v, found, _ := dict.Get(starlark.String("tags")); found {
+			if list, ok := v.(*starlark.List); ok {
+				for i := 0; i < list.Len(); i++ {
+					if tag, ok := starlark.AsString(list.Index(i)); ok {
+						f.Tags = append(f.Tags, tag)
+					}
+				}
+			}
+		}
+	}
+	return f
+}
+
+// call invokes the named hook in every script that defines it and returns
+// their results. Errors are logged and do not stop the simulation.
+func (h *ScriptHooks) call(hook string, args ...starlark.Value) []starlark.Value {
+	h.mu.RLock()
+	scripts := h.scripts
+	h.mu.RUnlock()
+
+	var results []starlark.Value
+	for _, s := range scripts {
+		fn, ok := s.globals[hook].(starlark.Callable)
+		if !ok {
+			continue
+		}
+		var actions []scriptAction
+		thread, done := h.thread(s.name + ":" + hook)
+		thread.SetLocal("actions", &actions)
+		res, err := starlark.Call(thread, fn, args, nil)
+		done()
+		if err != nil {
+			log.Printf("script %s %s: %v (skipped)", s.name, hook, err)
+			continue
+		}
+		for _, apply := range actions {
+			apply()
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// thread returns a thread for one script run, cancelled once it exceeds
+// scriptMaxSteps or scriptTimeout or the simulation stops. done releases
+// the timer.
+func (h *ScriptHooks) thread(name string) (thread *starlark.Thread, done func()) {
+	thread = &starlark.Thread{Name: name}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	ctx, cancel := context.WithTimeout(h.ctx, scriptTimeout)
+	stop := context.AfterFunc(ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			thread.Cancel(fmt.Sprintf("ran longer than %v", scriptTimeout))
+		} else {
+			thread.Cancel("simulation stopped")
+		}
+	})
+	return thread, func() {
+		stop()
+		cancel()
+	}
+}
+
+func (h *ScriptHooks) reload() error {
+	paths, err := filepath.Glob(filepath.Join(h.dir, "*.star"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	builtins := h.builtins()
+	scripts := make([]*script, 0, len(paths))
+	mtimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		mtimes[path] = info.ModTime()
+		thread, done := h.thread(filepath.Base(path))
+		globals, err := starlark.ExecFile(thread, path, nil, builtins)
+		done()
+		if err != nil {
+			return fmt.Errorf("load %s: %w", path, err)
+		}
+		globals.Freeze()
+		scripts = append(scripts, &script{name: filepath.Base(path), globals: globals})
+	}
+
+	h.mu.Lock()
+	h.scripts = scripts
+	h.mtimes = mtimes
+	h.mu.Unlock()
+	log.Printf("loaded %d hook scripts from %s", len(scripts), h.dir)
+	return nil
+}
+
+func (h *ScriptHooks) changed() bool {
+	paths, err := filepath.Glob(filepath.Join(h.dir, "*.star"))
+	if err != nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(paths) != len(h.mtimes) {
+		return true
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(h.mtimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *ScriptHooks) builtins() starlark.StringDict {
+	enqueue := func(thread *starlark.Thread, a scriptAction) {
+		if actions, ok := thread.Local("actions").(*[]scriptAction); ok {
+			*actions = append(*actions, a)
+		}
+	}
+	return starlark.StringDict{
+		"set_rate": starlark.NewBuiltin("set_rate", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var rate int64
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &rate); err != nil {
+				return nil, err
+			}
+			enqueue(thread, func() { h.gen.SetRate(rate) })
+			return starlark.None, nil
+		}),
+		"close_runway": starlark.NewBuiltin("close_runway", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var name string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+				return nil, err
+			}
+			enqueue(thread, func() { h.runways.SetRunwayClosed(name, true) })
+			return starlark.None, nil
+		}),
+		"open_runway": starlark.NewBuiltin("open_runway", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var name string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+				return nil, err
+			}
+			enqueue(thread, func() { h.runways.SetRunwayClosed(name, false) })
+			return starlark.None, nil
+		}),
+		"log": starlark.NewBuiltin("log", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &msg); err != nil {
+				return nil, err
+			}
+			log.Printf("[%s] %s", thread.Name, msg)
+			return starlark.None, nil
+		}),
+	}
+}
+
+func flightValue(f Flight) *starlark.Dict {
+	d := starlark.NewDict(4)
+	d.SetKey(starlark.String("id"), starlark.MakeInt64(f.ID))
+	d.SetKey(starlark.String("call"), starlark.String(f.Call))
+	d.SetKey(starlark.String("approach"), starlark.String(f.Approach))
+	tags := make([]starlark.Value, len(f.Tags))
+	for i, t := range f.Tags {
+		tags[i] = starlark.String(t)
+	}
+	d.SetKey(starlark.String("tags"), starlark.NewList(tags))
+	return d
+}
@@ -0,0 +1,210 @@
+package control
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyWindow is how long a command's result is kept for replay
+	// under its idempotency key.
+	idempotencyWindow = 10 * time.Minute
+	// maxIdempotencyKeys bounds the remembered results; the oldest are
+	// forgotten first.
+	maxIdempotencyKeys = 10000
+	idempotencyHeader  = "Idempotency-Key"
+)
+
+// idempotentResult is the outcome of a command sent with an idempotency
+// key. done is closed once the first attempt has finished.
+type idempotentResult struct {
+	fingerprint string
+	at          time.Time
+	done        chan struct{}
+	status      int
+	header      http.Header
+	body        []byte
+	reply       *Message
+}
+
+// idempotencyCache remembers command results by idempotency key over a
+// rolling window, so retried commands are not applied twice.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	results map[string]*idempotentResult
+	order   []string
+}
+
+// claim returns the result stored under key, or stores an unfinished one
+// and reports true when this is the first attempt. A key reused with a
+// different fingerprint is reported as a mismatch.
+func (c *idempotencyCache) claim(key, fingerprint string, now time.Time) (result *idempotentResult, first, mismatch bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked(now)
+	if res, ok := c.results[key]; ok {
+		return res, false, res.fingerprint != fingerprint
+	}
+	if c.results == nil {
+		c.results = make(map[string]*idempotentResult)
+	}
+	res := &idempotentResult{fingerprint: fingerprint, at: now, done: make(chan struct{})}
+	c.results[key] = res
+	c.order = append(c.order, key)
+	return res, true, false
+}
+
+// store remembers a finished result under key unless one is already kept.
+func (c *idempotencyCache) store(key string, res *idempotentResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked(res.at)
+	if _, ok := c.results[key]; ok {
+		return
+	}
+	if c.results == nil {
+		c.results = make(map[string]*idempotentResult)
+	}
+	c.results[key] = res
+	c.order = append(c.order, key)
+}
+
+// release forgets an unfinished result, so a failed attempt may be retried.
+func (c *idempotencyCache) release(key string, res *idempotentResult) {
+	c.mu.Lock()
+	if c.results[key] == res {
+		delete(c.results, key)
+	}
+	c.mu.Unlock()
+	close(res.done)
+}
+
+func (c *idempotencyCache) expireLocked(now time.Time) {
+	drop := 0
+	for _, key := range c.order {
+		res, ok := c.results[key]
+		if ok && now.Sub(res.at) < idempotencyWindow && len(c.order)-drop <= maxIdempotencyKeys {
+			break
+		}
+		if ok {
+			delete(c.results, key)
+		}
+		drop++
+	}
+	c.order = c.order[drop:]
+}
+
+// idempotencyFingerprint identifies a request so a key reused for a
+// different one is caught.
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	sum := sha256.Sum256(body)
+	return r.Method + " " + r.URL.RequestURI() + " " + hex.EncodeToString(sum[:])
+}
+
+// recordingWriter passes a response through while keeping a copy.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// idempotent deduplicates mutating requests carrying an Idempotency-Key
+// header: a repeat within the window gets the original response, marked
+// with an Idempotent-Replayed header, instead of being applied again.
+// Server errors are not remembered, so the command may be retried.
+func (s *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		res, first, mismatch := s.idempotency.claim("http:"+key, idempotencyFingerprint(r, body), time.Now())
+		if mismatch {
+			http.Error(w, "idempotency key reused for a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		if !first {
+			select {
+			case <-res.done:
+			case <-r.Context().Done():
+				return
+			}
+			if res.status == 0 {
+				http.Error(w, "original request failed; retry", http.StatusConflict)
+				return
+			}
+			for name, values := range res.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(res.status)
+			w.Write(res.body)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w}
+		next(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if rec.status >= http.StatusInternalServerError {
+			s.idempotency.release("http:"+key, res)
+			return
+		}
+		res.status, res.header, res.body = rec.status, w.Header().Clone(), rec.body.Bytes()
+		close(res.done)
+	}
+}
+
+// replayCommand reports the reply already sent for a websocket command
+// carrying an idempotency key, if it was applied before.
+func (s *Server) replayCommand(msg Message) (*Message, bool) {
+	if msg.IdempotencyKey == "" {
+		return nil, false
+	}
+	s.idempotency.mu.Lock()
+	defer s.idempotency.mu.Unlock()
+	s.idempotency.expireLocked(time.Now())
+	res, ok := s.idempotency.results["ws:"+msg.IdempotencyKey]
+	if !ok || res.reply == nil {
+		return nil, false
+	}
+	return res.reply, true
+}
+
+// ack sends reply for the websocket command msg, remembering it under the
+// command's idempotency key, if any, for replay.
+func (s *Server) ack(client *wsClient, msg, reply Message) error {
+	if msg.IdempotencyKey != "" {
+		s.idempotency.store("ws:"+msg.IdempotencyKey, &idempotentResult{at: time.Now(), reply: &reply})
+	}
+	return client.send(reply)
+}
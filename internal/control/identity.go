@@ -0,0 +1,215 @@
+package control
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Callsign numbering schemes.
+const (
+	NumberingTime       = "time"
+	NumberingSequential = "sequential"
+	NumberingRandom     = "random"
+)
+
+// Flight ID strategies.
+const (
+	IDSequential = "sequential"
+	IDSnowflake  = "snowflake"
+	IDUUID       = "uuid"
+)
+
+const (
+	defaultCallsignPrefix = "FLT"
+	defaultCallsignDigits = 4
+	maxCallsignDigits     = 6
+	maxSnowflakeNode      = 1023
+)
+
+// snowflakeEpoch is the time snowflake IDs count milliseconds from.
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FlightIdentity sets how generated flights are named and numbered, so
+// exported data matches what downstream systems expect. The zero value keeps
+// the built-in format: FLT callsigns stamped with the spawn time and
+// sequential IDs.
+type FlightIdentity struct {
+	// Prefixes is the pool of callsign prefixes, such as airline
+	// designators, taken in turn; "FLT" when empty.
+	Prefixes []string `json:"prefixes,omitempty"`
+	// Numbering is "time" (the default: prefix, spawn time and flight
+	// number, as FLT142503-0042), "sequential" (each prefix numbered from 1,
+	// as BAW17) or "random".
+	Numbering string `json:"numbering,omitempty"`
+	// Digits bounds the flight number, 4 by default and at most 6.
+	Digits int `json:"digits,omitempty"`
+	// UniqueWindowSeconds keeps a callsign from being issued again within
+	// that many simulated seconds; zero lets one recur at once.
+	UniqueWindowSeconds float64 `json:"uniqueWindowSeconds,omitempty"`
+	// IDs is "sequential" (the default), "snowflake" (time-ordered 64-bit
+	// IDs: milliseconds since 2020, Node and a sequence) or "uuid"
+	// (sequential IDs, and a random UUID in each flight's uuid). Snowflake
+	// IDs exceed the integers JavaScript holds exactly. Event history and
+	// its exports stay keyed by the numeric ID.
+	IDs string `json:"ids,omitempty"`
+	// Node is the snowflake worker ID, 0 to 1023, for generators issuing
+	// IDs side by side.
+	Node int64 `json:"node,omitempty"`
+}
+
+func (c FlightIdentity) validate() error {
+	for _, p := range c.Prefixes {
+		if p == "" {
+			return fmt.Errorf("empty callsign prefix")
+		}
+	}
+	switch c.Numbering {
+	case "", NumberingTime, NumberingSequential, NumberingRandom:
+	default:
+		return fmt.Errorf("unknown numbering %q", c.Numbering)
+	}
+	if c.Digits < 0 || c.Digits > maxCallsignDigits {
+		return fmt.Errorf("digits must be between 0 and %d; 0 means %d", maxCallsignDigits, defaultCallsignDigits)
+	}
+	if c.UniqueWindowSeconds < 0 {
+		return fmt.Errorf("uniqueness window must not be negative")
+	}
+	switch c.IDs {
+	case "", IDSequential, IDSnowflake, IDUUID:
+	default:
+		return fmt.Errorf("unknown ID strategy %q", c.IDs)
+	}
+	if c.Node < 0 || c.Node > maxSnowflakeNode {
+		return fmt.Errorf("node must be between 0 and %d", maxSnowflakeNode)
+	}
+	return nil
+}
+
+// callsignIssuer hands out callsigns in a FlightIdentity's format, keeping
+// them unique over its window.
+type callsignIssuer struct {
+	cfg    FlightIdentity
+	window time.Duration
+	modulo int64
+
+	mu   sync.Mutex
+	turn int
+	// next is the last sequential number issued per prefix.
+	next map[string]int64
+	// issued is when each callsign still within the window was issued.
+	issued map[string]time.Time
+}
+
+func newCallsignIssuer(cfg FlightIdentity) *callsignIssuer {
+	if len(cfg.Prefixes) == 0 {
+		cfg.Prefixes = []string{defaultCallsignPrefix}
+	}
+	if cfg.Numbering == "" {
+		cfg.Numbering = NumberingTime
+	}
+	if cfg.Digits == 0 {
+		cfg.Digits = defaultCallsignDigits
+	}
+	if cfg.IDs == "" {
+		cfg.IDs = IDSequential
+	}
+	modulo := int64(1)
+	for range cfg.Digits {
+		modulo *= 10
+	}
+	return &callsignIssuer{
+		cfg:    cfg,
+		window: time.Duration(cfg.UniqueWindowSeconds * float64(time.Second)),
+		modulo: modulo,
+		next:   make(map[string]int64),
+		issued: make(map[string]time.Time),
+	}
+}
+
+// issue returns the callsign for flight id spawned at now. Should every
+// number be taken within the window, the last one tried is reused.
+func (c *callsignIssuer) issue(id int64, now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := c.cfg.Prefixes[c.turn%len(c.cfg.Prefixes)]
+	c.turn++
+	for call, at := range c.issued {
+		if now.Sub(at) >= c.window {
+			delete(c.issued, call)
+		}
+	}
+	var call string
+	for attempt := int64(0); attempt < c.modulo; attempt++ {
+		call = c.candidateLocked(prefix, id, attempt, now)
+		if _, taken := c.issued[call]; !taken {
+			break
+		}
+		if attempt == c.modulo-1 {
+			log.Printf("callsign %s reissued within %s: every %s number is in use", call, c.window, prefix)
+		}
+	}
+	if c.window > 0 {
+		c.issued[call] = now
+	}
+	return call
+}
+
+// candidateLocked is the attempt-th callsign tried for flight id.
+func (c *callsignIssuer) candidateLocked(prefix string, id, attempt int64, now time.Time) string {
+	switch c.cfg.Numbering {
+	case NumberingSequential:
+		n := c.next[prefix]%(c.modulo-1) + 1
+		c.next[prefix] = n
+		return prefix + strconv.FormatInt(n, 10)
+	case NumberingRandom:
+		n := int64(splitmix64(uint64(id)<<20^uint64(attempt))%uint64(c.modulo-1)) + 1
+		return prefix + strconv.FormatInt(n, 10)
+	}
+	return fmt.Sprintf("%s%s-%0*d", prefix, now.Format("150405"), c.cfg.Digits, (id+attempt)%c.modulo)
+}
+
+// splitmix64 scrambles x, so random flight numbers are reproducible from the
+// flight ID.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// snowflakeID is the smallest snowflake ID for node at now.
+func snowflakeID(now time.Time, node int64) int64 {
+	return max(now.Sub(snowflakeEpoch).Milliseconds(), 0)<<22 | node<<12
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// SetIdentity sets how flights spawned from now on are named and numbered.
+func (g *Generator) SetIdentity(cfg FlightIdentity) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	issuer := newCallsignIssuer(cfg)
+	g.identity.Store(issuer)
+	log.Printf("flight identity: %s callsigns from %d prefixes, %s IDs", issuer.cfg.Numbering, len(issuer.cfg.Prefixes), issuer.cfg.IDs)
+	return nil
+}
+
+// Identity reports how flights are named and numbered.
+func (g *Generator) Identity() FlightIdentity {
+	if issuer := g.identity.Load(); issuer != nil {
+		return issuer.cfg
+	}
+	return newCallsignIssuer(FlightIdentity{}).cfg
+}
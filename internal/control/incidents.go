@@ -0,0 +1,293 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Equipment failure types.
+const (
+	IncidentLightingFailure = "lightingFailure"
+	IncidentILSOutage       = "ilsOutage"
+)
+
+// Event types for equipment failures and weather.
+const (
+	EventIncident          = "incident"
+	EventIncidentResolved  = "incidentResolved"
+	EventVisibilityChanged = "visibilityChanged"
+)
+
+const (
+	// lowVisibilityMeters is the visibility below which a runway with failed
+	// lighting or ILS cannot accept arrivals.
+	lowVisibilityMeters = 800
+	defaultVisibility   = 10000
+)
+
+// incidentProfile is how a failure degrades a runway and how long it
+// usually takes to repair.
+type incidentProfile struct {
+	// occupancyFactor stretches runway occupancy per landing, reducing the
+	// runway's capacity while the failure lasts.
+	occupancyFactor float64
+	repair          time.Duration
+}
+
+var incidentProfiles = map[string]incidentProfile{
+	IncidentLightingFailure: {occupancyFactor: 1.5, repair: 10 * time.Minute},
+	IncidentILSOutage:       {occupancyFactor: 1.25, repair: 15 * time.Minute},
+}
+
+// Incident is an equipment failure affecting one runway.
+type Incident struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Runway   string    `json:"runway"`
+	Started  time.Time `json:"started"`
+	RepairBy time.Time `json:"repairBy"`
+}
+
+// InjectIncident starts an equipment failure on a runway. It is repaired
+// automatically after repair, or after the type's usual repair time when
+// repair is zero. In low visibility the runway closes until the repair.
+func (rm *RunwayManager) InjectIncident(kind, runway string, repair time.Duration) (Incident, error) {
+	profile, ok := incidentProfiles[kind]
+	if !ok {
+		return Incident{}, fmt.Errorf("unknown incident type %q", kind)
+	}
+	if repair <= 0 {
+		repair = profile.repair
+	}
+	rm.mu.Lock()
+	runway = rm.resolveLocked(runway)
+	if _, ok := rm.runways[runway]; !ok {
+		rm.mu.Unlock()
+		return Incident{}, fmt.Errorf("unknown runway %q", runway)
+	}
+	rm.nextIncidentID++
+	now := rm.clock.Now()
+	inc := Incident{ID: "inc-" + strconv.FormatInt(rm.nextIncidentID, 10), Type: kind, Runway: runway, Started: now, RepairBy: now.Add(repair)}
+	rm.incidents[inc.ID] = inc
+	rm.publishEventLocked(Event{Type: EventIncident, Runway: runway, Detail: fmt.Sprintf("%s, repair in %s", kind, repair)})
+	log.Printf("incident %s: %s on runway %s (repair in %s)", inc.ID, kind, runway, repair)
+	rm.clock.AfterFunc(repair, func() { rm.ResolveIncident(inc.ID) })
+	released := rm.reconcileAvailabilityLocked()
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+	return inc, nil
+}
+
+// ResolveIncident ends an equipment failure, reporting whether it was active.
+func (rm *RunwayManager) ResolveIncident(id string) bool {
+	rm.mu.Lock()
+	inc, ok := rm.incidents[id]
+	if !ok {
+		rm.mu.Unlock()
+		return false
+	}
+	delete(rm.incidents, id)
+	rm.publishEventLocked(Event{Type: EventIncidentResolved, Runway: inc.Runway, Detail: inc.Type})
+	log.Printf("incident %s resolved: %s on runway %s", id, inc.Type, inc.Runway)
+	released := rm.reconcileAvailabilityLocked()
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+	return true
+}
+
+// Incidents lists the active equipment failures, oldest first.
+func (rm *RunwayManager) Incidents() []Incident {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]Incident, 0, len(rm.incidents))
+	for _, inc := range rm.incidents {
+		out = append(out, inc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Started.Equal(out[j].Started) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Started.Before(out[j].Started)
+	})
+	return out
+}
+
+// SetVisibility updates the prevailing visibility in meters, closing or
+// reopening runways with equipment failures as it crosses the low
+// visibility threshold, sending flights below their approach minima to
+// holding and entering or leaving low-visibility procedures.
+func (rm *RunwayManager) SetVisibility(meters int64) {
+	rm.mu.Lock()
+	released := rm.setVisibilityLocked(meters)
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+}
+
+// setVisibilityLocked changes the visibility, reporting whether the caller
+// should release the holding stack once the lock is dropped.
+func (rm *RunwayManager) setVisibilityLocked(meters int64) bool {
+	previous := rm.visibility
+	rm.visibility = maxInt64(meters, 0)
+	rm.version++
+	rm.publishEventLocked(Event{Type: EventVisibilityChanged, Detail: fmt.Sprintf("%dm", rm.visibility)})
+	released := rm.reconcileAvailabilityLocked()
+	rm.enforceMinimaLocked()
+	rm.updateLVPLocked()
+	// Better visibility may bring holding flights back within their minima.
+	return released || rm.visibility > previous
+}
+
+// Visibility returns the prevailing visibility in meters.
+func (rm *RunwayManager) Visibility() int64 {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.visibility
+}
+
+// reconcileAvailabilityLocked closes runways whose failures make them
+// unusable in the current visibility and reopens those that recovered. It
+// reports whether any runway became available, in which case the caller
+// should release the holding stack once the lock is dropped.
+func (rm *RunwayManager) reconcileAvailabilityLocked() bool {
+	released := false
+	for _, name := range rm.order {
+		r := rm.runways[name]
+		failed := rm.failedEquipmentLocked(name)
+		closed := rm.visibility < lowVisibilityMeters && len(failed) > 0
+		switch {
+		case closed && !r.incidentClosed:
+			r.setIncidentClosed(true, rm.clock.Now())
+			reason := strings.Join(failed, ", ") + " in low visibility"
+			rm.publishEventLocked(Event{Type: EventRunwayClosed, Runway: name, Detail: reason})
+			n := rm.divertLocked(name, SourceScheduler, "diverted: "+reason)
+			log.Printf("runway %s unusable (%s); diverted %d flights", name, reason, n)
+		case !closed && r.incidentClosed:
+			r.setIncidentClosed(false, rm.clock.Now())
+			rm.publishEventLocked(Event{Type: EventRunwayOpened, Runway: name, Detail: "equipment restored or visibility improved"})
+			log.Printf("runway %s usable again", name)
+			released = released || r.open
+		}
+	}
+	return released
+}
+
+// failedEquipmentLocked lists the distinct failure types active on a runway.
+func (rm *RunwayManager) failedEquipmentLocked(runway string) []string {
+	var failed []string
+	for _, inc := range rm.incidents {
+		if inc.Runway == runway && !slices.Contains(failed, inc.Type) {
+			failed = append(failed, inc.Type)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// landingTimeLocked is f's runway occupancy, set by how quickly it can
+// vacate, shortened into a headwind or stretched by a tailwind, and
+// stretched by the worst active equipment failure.
+func (rm *RunwayManager) landingTimeLocked(runway string, f Flight) time.Duration {
+	factor := 1.0
+	for _, inc := range rm.incidents {
+		if inc.Runway == runway {
+			factor = max(factor, incidentProfiles[inc.Type].occupancyFactor)
+		}
+	}
+	return time.Duration(float64(rm.runwayLandingLocked(runway, f)) * factor * rm.exitFactorLocked(runway, f) * rm.windFactorLocked(runway))
+}
+
+// IncidentRequest injects an equipment failure. RepairSeconds defaults to
+// the usual repair time for the type.
+type IncidentRequest struct {
+	Type          string `json:"type"`
+	Runway        string `json:"runway"`
+	RepairSeconds int64  `json:"repairSeconds,omitempty"`
+}
+
+// HandleIncidents lists active incidents (GET) or injects one (POST).
+func (s *Server) HandleIncidents(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Runways.Incidents())
+		return
+	}
+	var req IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid incident: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	inc, err := s.Runways.InjectIncident(req.Type, strings.TrimSpace(req.Runway), time.Duration(req.RepairSeconds)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inc)
+}
+
+// HandleIncident resolves the incident named in the path ahead of its repair
+// time.
+func (s *Server) HandleIncident(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.Runways.ResolveIncident(r.PathValue("id")) {
+		http.Error(w, "incident not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VisibilityState is the prevailing visibility and cloud ceiling. A PUT
+// without CeilingFeet leaves the ceiling unchanged.
+type VisibilityState struct {
+	Meters      int64  `json:"meters"`
+	CeilingFeet *int64 `json:"ceilingFeet,omitempty"`
+}
+
+// HandleVisibility reports (GET) or sets (PUT) the prevailing visibility.
+func (s *Server) HandleVisibility(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req VisibilityState
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid visibility: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if dryRun(r) {
+			writeJSON(w, http.StatusOK, s.Runways.PreviewVisibility(req.Meters, req.CeilingFeet))
+			return
+		}
+		apply := func() {
+			s.Runways.SetVisibility(req.Meters)
+			if req.CeilingFeet != nil {
+				s.Runways.SetCeiling(*req.CeilingFeet)
+			}
+		}
+		if s.Confirmations != nil && s.requestConfirmation(w, r, s.Runways.PreviewVisibility(req.Meters, req.CeilingFeet), apply) {
+			return
+		}
+		apply()
+	}
+	ceiling := s.Runways.Ceiling()
+	writeJSON(w, http.StatusOK, VisibilityState{Meters: s.Runways.Visibility(), CeilingFeet: &ceiling})
+}
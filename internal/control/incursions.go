@@ -0,0 +1,208 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// goAroundDelay is how long a flight that aborted its landing takes to fly
+// the missed approach before it is sequenced again.
+const goAroundDelay = 3 * landingDuration
+
+// Ground movement kinds.
+const (
+	GroundVehicle  = "vehicle"
+	GroundCrossing = "crossing"
+)
+
+// GroundMovement is a vehicle or crossing aircraft occupying a runway.
+type GroundMovement struct {
+	ID     string    `json:"id"`
+	Kind   string    `json:"kind"`
+	Runway string    `json:"runway"`
+	Since  time.Time `json:"since"`
+	// Until is when the movement clears by itself; nil means it stays until
+	// cleared explicitly.
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// OccupyRunway places ground traffic on a runway. When d is positive the
+// runway is vacated automatically after d. Arrivals reaching the threshold
+// while it is occupied are incursions and go around.
+func (rm *RunwayManager) OccupyRunway(runway, kind string, d time.Duration) (GroundMovement, error) {
+	if kind != GroundVehicle && kind != GroundCrossing {
+		return GroundMovement{}, fmt.Errorf("unknown ground movement kind %q", kind)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	runway = rm.resolveLocked(runway)
+	if _, ok := rm.runways[runway]; !ok {
+		return GroundMovement{}, fmt.Errorf("unknown runway %q", runway)
+	}
+	return rm.occupyLocked(runway, kind, d), nil
+}
+
+func (rm *RunwayManager) occupyLocked(runway, kind string, d time.Duration) GroundMovement {
+	rm.nextGroundID++
+	now := rm.clock.Now()
+	m := GroundMovement{ID: "gnd-" + strconv.FormatInt(rm.nextGroundID, 10), Kind: kind, Runway: runway, Since: now}
+	if d > 0 {
+		until := now.Add(d)
+		m.Until = &until
+		id := m.ID
+		rm.clock.AfterFunc(d, func() { rm.ClearGroundMovement(id) })
+	}
+	rm.ground[m.ID] = m
+	log.Printf("%s %s entered runway %s", kind, m.ID, runway)
+	return m
+}
+
+// ClearGroundMovement removes ground traffic from its runway, reporting
+// whether it was present. Clearing the last movement on a runway resolves
+// any open incursion there and lets departures line up again.
+func (rm *RunwayManager) ClearGroundMovement(id string) bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	m, ok := rm.ground[id]
+	if !ok {
+		return false
+	}
+	delete(rm.ground, id)
+	log.Printf("%s %s vacated runway %s", m.Kind, id, m.Runway)
+	if rm.occupiedLocked(m.Runway) {
+		return true
+	}
+	rm.releaseDepartureLocked(m.Runway)
+	if since, open := rm.incursions[m.Runway]; open {
+		delete(rm.incursions, m.Runway)
+		took := rm.clock.Now().Sub(since)
+		if rm.metrics != nil {
+			rm.metrics.RecordIncursionResolved(took)
+		}
+		rm.publishEventLocked(Event{Type: EventIncursionResolved, Runway: m.Runway, Detail: fmt.Sprintf("cleared after %.0fs", took.Seconds())})
+		log.Printf("incursion on %s resolved after %s", m.Runway, took)
+	}
+	return true
+}
+
+// GroundMovements lists the traffic currently occupying runways.
+func (rm *RunwayManager) GroundMovements() []GroundMovement {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]GroundMovement, 0, len(rm.ground))
+	for _, m := range rm.ground {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Since.Equal(out[j].Since) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Since.Before(out[j].Since)
+	})
+	return out
+}
+
+func (rm *RunwayManager) occupiedLocked(runway string) bool {
+	for _, m := range rm.ground {
+		if m.Runway == runway {
+			return true
+		}
+	}
+	return false
+}
+
+// openIncursionLocked opens an incursion on runway, occupied as f reaches
+// it, unless one is already open.
+func (rm *RunwayManager) openIncursionLocked(runway string, f Flight) {
+	if _, open := rm.incursions[runway]; open {
+		return
+	}
+	rm.incursions[runway] = rm.clock.Now()
+	if rm.metrics != nil {
+		rm.metrics.RecordIncursion()
+	}
+	rm.publishEventLocked(Event{Type: EventIncursion, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: "runway occupied at touchdown"})
+	log.Printf("RUNWAY INCURSION on %s: flight %d (%s) on short final", runway, f.ID, f.Call)
+}
+
+// goAroundLocked aborts a landing for reason: the flight leaves the runway
+// queue and is sequenced again once the missed approach is flown.
+func (rm *RunwayManager) goAroundLocked(runway string, f Flight, queueIdx int, reason string) {
+	queue := rm.assigned[runway]
+	rm.assigned[runway] = append(queue[:queueIdx], queue[queueIdx+1:]...)
+	delete(rm.assignedAt, f.ID)
+	rm.publishQueuesLocked(runway)
+
+	if rm.metrics != nil {
+		rm.metrics.RecordGoAround()
+	}
+	rm.logDecisionLocked(DecisionHold, f, "")
+	rm.startDelayLocked(f, DelayRunwayClosure)
+	rm.cancelGateLocked(f.ID)
+	rm.goingAround[f.ID] = f
+	rm.transmitLocked(goAroundInstruction(f, runway, reason))
+	delete(rm.radio.waits, f.ID)
+	rm.setPhaseLocked(f, PhaseHolding, runway)
+	rm.joinPatternLocked(f, rm.clock.Now().Add(rm.goAroundLatencyLocked()+goAroundDelay))
+	rm.publishEventLocked(Event{Type: EventGoAround, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: reason})
+	log.Printf("flight %d (%s) going around from %s: %s", f.ID, f.Call, runway, reason)
+	rm.clock.AfterFunc(rm.goAroundLatencyLocked()+goAroundDelay, func() {
+		rm.mu.Lock()
+		_, pending := rm.goingAround[f.ID]
+		delete(rm.goingAround, f.ID)
+		rm.mu.Unlock()
+		if pending {
+			rm.AssignFlight(f)
+		}
+	})
+}
+
+// GroundMovementRequest places ground traffic on a runway for Seconds, or
+// until cleared when Seconds is zero.
+type GroundMovementRequest struct {
+	Kind    string `json:"kind"`
+	Runway  string `json:"runway"`
+	Seconds int64  `json:"seconds,omitempty"`
+}
+
+// HandleGround lists runway ground traffic (GET) or adds a movement (POST).
+func (s *Server) HandleGround(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Runways.GroundMovements())
+		return
+	}
+	var req GroundMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid ground movement: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := s.Runways.OccupyRunway(strings.TrimSpace(req.Runway), req.Kind, time.Duration(req.Seconds)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// HandleGroundMovement clears the ground movement named in the path.
+func (s *Server) HandleGroundMovement(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.Runways.ClearGroundMovement(r.PathValue("id")) {
+		http.Error(w, "ground movement not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxPilotLatency bounds configured pilot response delays; anything longer
+// is taken for a unit mistake.
+const maxPilotLatency = 2 * time.Minute
+
+// PilotLatency models the time pilots take to act on instructions. A flight
+// vectored to a runway turns onto its approach after a delay drawn between
+// VectorMinSeconds and VectorMaxSeconds, the same for a flight every time so
+// runs stay reproducible; the arrival manager sequences it accordingly. A
+// flight instructed to go around keeps descending for GoAroundSeconds before
+// climbing away, lengthening its missed approach. The zero value applies
+// instructions at once.
+type PilotLatency struct {
+	VectorMinSeconds float64 `json:"vectorMinSeconds,omitempty"`
+	VectorMaxSeconds float64 `json:"vectorMaxSeconds,omitempty"`
+	GoAroundSeconds  float64 `json:"goAroundSeconds,omitempty"`
+}
+
+func (p PilotLatency) validate() error {
+	limit := maxPilotLatency.Seconds()
+	switch {
+	case p.VectorMinSeconds < 0, p.VectorMaxSeconds < 0, p.GoAroundSeconds < 0:
+		return fmt.Errorf("latencies must not be negative")
+	case p.VectorMaxSeconds < p.VectorMinSeconds:
+		return fmt.Errorf("vectorMaxSeconds below vectorMinSeconds")
+	case p.VectorMaxSeconds > limit, p.GoAroundSeconds > limit:
+		return fmt.Errorf("latencies above the maximum of %s", maxPilotLatency)
+	}
+	return nil
+}
+
+// SetPilotLatency replaces the pilot response delays. Flights already
+// sequenced keep their landing times.
+func (rm *RunwayManager) SetPilotLatency(p PilotLatency) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.latency = p
+	log.Printf("pilot latency: vectors %.0f-%.0fs, go-around %.0fs", p.VectorMinSeconds, p.VectorMaxSeconds, p.GoAroundSeconds)
+	return nil
+}
+
+// PilotLatency reports the pilot response delays.
+func (rm *RunwayManager) PilotLatency() PilotLatency {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.latency
+}
+
+// vectorLatencyLocked is how long f takes to comply with a vector.
+func (rm *RunwayManager) vectorLatencyLocked(f Flight) time.Duration {
+	p := rm.latency
+	spread := p.VectorMaxSeconds - p.VectorMinSeconds
+	seconds := p.VectorMinSeconds
+	if spread > 0 {
+		seconds += spread * float64(splitmix64(uint64(f.ID))>>11) / (1 << 53)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// goAroundLatencyLocked is how long a flight instructed to go around takes
+// to begin the missed approach.
+func (rm *RunwayManager) goAroundLatencyLocked() time.Duration {
+	return time.Duration(rm.latency.GoAroundSeconds * float64(time.Second))
+}
+
+// approachTimeLocked is the time f takes from being vectored to runway to
+// touchdown, allowing for the vector's wait for airtime, the pilot's
+// response and, cleared out of the hold, the flight on to the fix.
+func (rm *RunwayManager) approachTimeLocked(runway string, f Flight) time.Duration {
+	return rm.frequencyWaitLocked(f) + rm.fixWaitLocked(f) + rm.vectorLatencyLocked(f) + rm.landingTimeLocked(runway, f)
+}
+
+// HandlePilotLatency reports (GET) or replaces (PUT) the pilot response
+// delays.
+func (s *Server) HandlePilotLatency(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var p PilotLatency
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "invalid pilot latency: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetPilotLatency(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.PilotLatency())
+}
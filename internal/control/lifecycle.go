@@ -0,0 +1,130 @@
+package control
+
+import "log"
+
+// EventPhaseChanged is published each time a flight moves from one phase of
+// its lifecycle to the next; Detail reads "from>to".
+const EventPhaseChanged = "phaseChanged"
+
+// FlightPhase is a stage of an arrival's lifecycle.
+type FlightPhase string
+
+// Flight phases. An arrival is Spawned, then Sequenced to a runway or put in
+// Holding, possibly several times over, until the next arrival due on its
+// runway is cleared to Final. Landed, Diverted and Cancelled end it.
+const (
+	PhaseSpawned   FlightPhase = "spawned"
+	PhaseSequenced FlightPhase = "sequenced"
+	PhaseHolding   FlightPhase = "holding"
+	PhaseFinal     FlightPhase = "final"
+	PhaseLanded    FlightPhase = "landed"
+	PhaseDiverted  FlightPhase = "diverted"
+	PhaseCancelled FlightPhase = "cancelled"
+)
+
+// FlightPhases lists the phases in lifecycle order.
+var FlightPhases = []FlightPhase{PhaseSpawned, PhaseSequenced, PhaseHolding, PhaseFinal, PhaseLanded, PhaseDiverted, PhaseCancelled}
+
+// phaseTransitions lists the phases each phase may move to. Terminal phases
+// have none.
+var phaseTransitions = map[FlightPhase][]FlightPhase{
+	PhaseSpawned:   {PhaseSequenced, PhaseHolding, PhaseDiverted, PhaseCancelled},
+	PhaseSequenced: {PhaseFinal, PhaseHolding, PhaseCancelled},
+	PhaseHolding:   {PhaseSequenced, PhaseDiverted, PhaseCancelled},
+	// A flight on final leaves it by landing, or by going around or
+	// missing the approach into holding.
+	PhaseFinal: {PhaseLanded, PhaseHolding, PhaseCancelled},
+}
+
+// Terminal reports whether the phase ends the lifecycle.
+func (p FlightPhase) Terminal() bool {
+	return p == PhaseLanded || p == PhaseDiverted || p == PhaseCancelled
+}
+
+// canTransition reports whether a flight may move from one phase to another.
+func canTransition(from, to FlightPhase) bool {
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// setPhaseLocked moves flight f to phase to, publishing the transition and
+// updating the phase gauges. Moving to the phase it is already in does
+// nothing. A flight with no recorded phase, as one restored after a
+// restart, enters any phase but a terminal one directly. Invalid transitions
+// are logged and refused, leaving the phase unchanged; the result reports
+// whether the flight is now in phase to.
+func (rm *RunwayManager) setPhaseLocked(f Flight, to FlightPhase, runway string) bool {
+	from, tracked := rm.phases[f.ID]
+	switch {
+	case tracked && from == to:
+		return true
+	case tracked && !canTransition(from, to), !tracked && to.Terminal():
+		if !tracked {
+			from = "untracked"
+		}
+		log.Printf("flight %d (%s): invalid phase transition %s>%s", f.ID, f.Call, from, to)
+		return false
+	}
+	if tracked {
+		rm.phaseCounts[from]--
+	}
+	if from == PhaseHolding || to.Terminal() {
+		rm.leaveHoldingLocked(f, to.Terminal())
+	}
+	if to == PhaseHolding {
+		rm.enterHoldingLocked(f)
+	}
+	rm.phaseCounts[to]++
+	if to.Terminal() {
+		delete(rm.phases, f.ID)
+		delete(rm.radio.waits, f.ID)
+	} else {
+		rm.phases[f.ID] = to
+	}
+	if tracked {
+		rm.publishEventLocked(Event{Type: EventPhaseChanged, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: string(from) + ">" + string(to)})
+	}
+	rm.publishPhasesLocked()
+	return true
+}
+
+// spawnLocked starts the lifecycle of a newly announced flight.
+func (rm *RunwayManager) spawnLocked(f Flight) {
+	if _, tracked := rm.phases[f.ID]; tracked {
+		return
+	}
+	rm.phases[f.ID] = PhaseSpawned
+	rm.phaseCounts[PhaseSpawned]++
+	rm.publishPhasesLocked()
+}
+
+// clearToFinalLocked moves the arrival next due on runway to final. Flights
+// resequenced behind it once on final stay there.
+func (rm *RunwayManager) clearToFinalLocked(runway string) {
+	var next *Flight
+	for i, f := range rm.assigned[runway] {
+		due, ok := rm.dueAt[f.ID]
+		if !ok {
+			continue
+		}
+		if next == nil || due.Before(rm.dueAt[next.ID]) {
+			next = &rm.assigned[runway][i]
+		}
+	}
+	if next != nil && rm.phases[next.ID] == PhaseSequenced {
+		rm.setPhaseLocked(*next, PhaseFinal, runway)
+	}
+}
+
+func (rm *RunwayManager) publishPhasesLocked() {
+	if rm.metrics == nil {
+		return
+	}
+	for _, phase := range FlightPhases {
+		rm.metrics.SetFlightPhase(phase, rm.phaseCounts[phase])
+	}
+}
@@ -0,0 +1,85 @@
+package control
+
+import "time"
+
+// Severities of the operational log events sent to websocket clients.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// LogEvent is a significant simulation event summarized for an operator
+// event feed.
+type LogEvent struct {
+	Seq      int64     `json:"seq"`
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"`
+	// Event is the type of the event the entry summarizes.
+	Event    string `json:"event"`
+	Runway   string `json:"runway,omitempty"`
+	FlightID int64  `json:"flightId,omitempty"`
+	Call     string `json:"call,omitempty"`
+	Message  string `json:"message"`
+}
+
+// logEventKind is how an event type appears in the operational feed.
+type logEventKind struct {
+	severity string
+	summary  string
+}
+
+// logEventKinds lists the event types significant enough for the
+// operational feed. Routine flight progress is left out.
+var logEventKinds = map[string]logEventKind{
+	EventConflict:          {SeverityCritical, "spacing conflict"},
+	EventIncursion:         {SeverityCritical, "runway incursion"},
+	EventIncident:          {SeverityCritical, "incident"},
+	EventRunwayClosed:      {SeverityWarning, "runway closed"},
+	EventRunwayRemoved:     {SeverityWarning, "runway decommissioned"},
+	EventDiverted:          {SeverityWarning, "flight diverted"},
+	EventGoAround:          {SeverityWarning, "go-around"},
+	EventLVPEntered:        {SeverityWarning, "low-visibility procedures in force"},
+	EventStormBlocked:      {SeverityWarning, "runway blocked by storm"},
+	EventSLABreached:       {SeverityWarning, "SLA breached"},
+	EventAnomaly:           {SeverityWarning, "anomaly"},
+	EventFlowRestricted:    {SeverityWarning, "flow restricted"},
+	EventRunwayOpened:      {SeverityInfo, "runway opened"},
+	EventRunwayAdded:       {SeverityInfo, "runway commissioned"},
+	EventIncursionResolved: {SeverityInfo, "incursion resolved"},
+	EventIncidentResolved:  {SeverityInfo, "incident resolved"},
+	EventLVPExited:         {SeverityInfo, "low-visibility procedures ended"},
+	EventStormCleared:      {SeverityInfo, "storm cleared"},
+	EventSLARestored:       {SeverityInfo, "SLA restored"},
+	EventFlowRestored:      {SeverityInfo, "flow restored"},
+}
+
+// logEventFor summarizes e for the operational feed, if it is significant.
+func logEventFor(e Event) (LogEvent, bool) {
+	kind, ok := logEventKinds[e.Type]
+	if !ok {
+		return LogEvent{}, false
+	}
+	msg := kind.summary
+	switch {
+	case e.Call != "" && e.Runway != "":
+		msg += " on " + e.Runway + ": " + e.Call
+	case e.Runway != "":
+		msg += " on " + e.Runway
+	case e.Call != "":
+		msg += ": " + e.Call
+	}
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return LogEvent{
+		Seq:      e.Seq,
+		Time:     e.Time,
+		Severity: kind.severity,
+		Event:    e.Type,
+		Runway:   e.Runway,
+		FlightID: e.FlightID,
+		Call:     e.Call,
+		Message:  msg,
+	}, true
+}
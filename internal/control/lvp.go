@@ -0,0 +1,167 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Event types for low-visibility procedures.
+const (
+	EventLVPEntered = "lvpEntered"
+	EventLVPExited  = "lvpExited"
+)
+
+const (
+	// LVP are entered when the visibility drops below lvpEntryVisibility
+	// meters or the ceiling below lvpEntryCeiling feet, and left once both
+	// are back at or above the exit thresholds, so conditions hovering
+	// around the entry values do not toggle them.
+	lvpEntryVisibility = 550
+	lvpEntryCeiling    = 200
+	lvpExitVisibility  = 800
+	lvpExitCeiling     = 300
+	defaultCeiling     = 5000
+	// lvpSpacing is the arrival spacing while LVP protect the ILS critical
+	// areas.
+	lvpSpacing = minArrivalSpacing * 3 / 2
+	// lvpMaxTaxiing is how many departures may taxi at once under LVP;
+	// others wait at their stand.
+	lvpMaxTaxiing = 2
+)
+
+// lowVisibility tracks low-visibility procedures. total is the time spent
+// in LVP before the current spell, which began at since.
+type lowVisibility struct {
+	active  bool
+	since   time.Time
+	total   time.Duration
+	entries int
+}
+
+// heldTaxi is a departure kept at its stand while LVP limit ground movement.
+type heldTaxi struct {
+	flight   Flight
+	distance float64
+	queuedAt time.Time
+}
+
+// SetCeiling updates the cloud ceiling in feet above the airfield.
+func (rm *RunwayManager) SetCeiling(feet int64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.setCeilingLocked(feet)
+}
+
+func (rm *RunwayManager) setCeilingLocked(feet int64) {
+	rm.ceiling = maxInt64(feet, 0)
+	rm.version++
+	log.Printf("ceiling %dft", rm.ceiling)
+	rm.updateLVPLocked()
+}
+
+// Ceiling returns the cloud ceiling in feet.
+func (rm *RunwayManager) Ceiling() int64 {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.ceiling
+}
+
+// updateLVPLocked enters or leaves low-visibility procedures as the
+// visibility and ceiling cross their thresholds. Leaving them lets the
+// departures held at their stands taxi.
+func (rm *RunwayManager) updateLVPLocked() {
+	now := rm.clock.Now()
+	conditions := fmt.Sprintf("visibility %dm, ceiling %dft", rm.visibility, rm.ceiling)
+	switch {
+	case !rm.lvp.active && (rm.visibility < lvpEntryVisibility || rm.ceiling < lvpEntryCeiling):
+		rm.lvp.active, rm.lvp.since = true, now
+		rm.lvp.entries++
+		rm.publishEventLocked(Event{Type: EventLVPEntered, Detail: conditions})
+		log.Printf("low-visibility procedures in force (%s)", conditions)
+	case rm.lvp.active && rm.visibility >= lvpExitVisibility && rm.ceiling >= lvpExitCeiling:
+		spell := now.Sub(rm.lvp.since)
+		rm.lvp.active = false
+		rm.lvp.total += spell
+		rm.publishEventLocked(Event{Type: EventLVPExited, Detail: fmt.Sprintf("%s after %s", conditions, spell)})
+		log.Printf("low-visibility procedures cancelled after %s (%s)", spell, conditions)
+		rm.releaseHeldTaxiLocked()
+	}
+}
+
+// holdTaxiLocked reports whether f must wait at its stand rather than taxi,
+// queueing it if so.
+func (rm *RunwayManager) holdTaxiLocked(f Flight, distance float64) bool {
+	if !rm.lvp.active || len(rm.taxiing) < lvpMaxTaxiing {
+		return false
+	}
+	rm.taxiHeld = append(rm.taxiHeld, heldTaxi{flight: f, distance: distance, queuedAt: rm.clock.Now()})
+	return true
+}
+
+// releaseHeldTaxiLocked starts the taxi of departures held at their stands
+// while ground movement allows. The wait counts as taxi delay.
+func (rm *RunwayManager) releaseHeldTaxiLocked() {
+	for len(rm.taxiHeld) > 0 && (!rm.lvp.active || len(rm.taxiing) < lvpMaxTaxiing) {
+		h := rm.taxiHeld[0]
+		rm.taxiHeld = rm.taxiHeld[1:]
+		if rm.metrics != nil {
+			rm.metrics.RecordDelay(DelayTaxi, rm.clock.Now().Sub(h.queuedAt))
+		}
+		rm.beginTaxiLocked(h.flight, h.distance)
+	}
+}
+
+// LVPState reports low-visibility procedures and their effect on capacity.
+type LVPState struct {
+	Active      bool       `json:"active"`
+	Since       *time.Time `json:"since,omitempty"`
+	Visibility  int64      `json:"visibility"`
+	CeilingFeet int64      `json:"ceilingFeet"`
+	// Entries counts the times LVP were entered; SecondsInLVP is the total
+	// time spent in them, including the current spell.
+	Entries      int     `json:"entries"`
+	SecondsInLVP float64 `json:"secondsInLvp"`
+	// SpacingSeconds and DeclaredArrivalsPerHour are the arrival spacing
+	// and the open runways' landing capacity in the current procedures.
+	SpacingSeconds          float64 `json:"spacingSeconds"`
+	DeclaredArrivalsPerHour float64 `json:"declaredArrivalsPerHour"`
+	// MaxTaxiing limits simultaneous taxi-outs under LVP; HeldAtStand
+	// counts departures waiting for it.
+	MaxTaxiing  int `json:"maxTaxiing,omitempty"`
+	HeldAtStand int `json:"heldAtStand"`
+}
+
+// LowVisibility reports the low-visibility procedures state.
+func (rm *RunwayManager) LowVisibility() LVPState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	spacing := rm.arrivalSpacingLocked()
+	state := LVPState{
+		Active:                  rm.lvp.active,
+		Visibility:              rm.visibility,
+		CeilingFeet:             rm.ceiling,
+		Entries:                 rm.lvp.entries,
+		SecondsInLVP:            rm.lvp.total.Seconds(),
+		SpacingSeconds:          spacing.Seconds(),
+		DeclaredArrivalsPerHour: float64(len(rm.openRunways())) * float64(time.Hour) / float64(spacing),
+		HeldAtStand:             len(rm.taxiHeld),
+	}
+	if rm.lvp.active {
+		since := rm.lvp.since
+		state.Since = &since
+		state.SecondsInLVP += rm.clock.Now().Sub(since).Seconds()
+		state.MaxTaxiing = lvpMaxTaxiing
+	}
+	return state
+}
+
+// HandleLVP reports the low-visibility procedures state.
+func (s *Server) HandleLVP(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.LowVisibility())
+}
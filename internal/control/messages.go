@@ -0,0 +1,161 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// messageKind documents one websocket message type and which Message fields
+// it carries.
+type messageKind struct {
+	Type       string
+	Summary    string
+	Fields     []string
+	Required   []string
+	FromClient bool
+	FromServer bool
+	// Voice marks a message sent only on the voice channel.
+	Voice bool
+}
+
+var messageKinds = []messageKind{
+	{Type: "rate", Summary: "Arrival rate in planes per minute. Clients send it to change the rate; the server echoes the applied value, marked clamped with the requestedRate when lowered to the maximum.", Fields: []string{"rate", "rampSeconds", "ramp", "clamped", "requestedRate", "dryRun", "idempotencyKey", "executeAt", "version"}, FromClient: true, FromServer: true},
+	{Type: "runway", Summary: "Runway open/closed state. Clients send it to toggle a runway; the server echoes the applied state.", Fields: []string{"runway", "closed", "dryRun", "idempotencyKey", "executeAt", "version"}, Required: []string{"runway"}, FromClient: true, FromServer: true},
+	{Type: "wind", Summary: "Surface wind, its direction in degrees true, with the gust speed and variable direction range when present. Clients send it to change the wind; the server echoes the applied value.", Fields: []string{"wind", "dryRun", "idempotencyKey", "executeAt", "version"}, Required: []string{"wind"}, FromClient: true, FromServer: true},
+	{Type: "burst", Summary: "Spawns count flights at once, routed through normal assignment. The server echoes the number spawned.", Fields: []string{"count", "dryRun", "idempotencyKey", "executeAt", "version"}, Required: []string{"count"}, FromClient: true, FromServer: true},
+	{Type: "preview", Summary: "The predicted effect of a command sent with dryRun, which is not applied.", Fields: []string{"preview"}, Required: []string{"preview"}, FromServer: true},
+	{Type: "resync", Summary: "Asks for the full state; the server answers with a snapshot message.", FromClient: true},
+	{Type: "snapshot", Summary: "The full state taken under one consistent view of the scheduler, the same as GET /api/v1/state.", Fields: []string{"snapshot"}, Required: []string{"snapshot"}, FromServer: true},
+	{Type: "conflict", Summary: "A command sent with a version the state has since moved on from; it was not applied. Carries the current version.", Fields: []string{"version"}, Required: []string{"version"}, FromServer: true},
+	{Type: "scheduled", Summary: "A command sent with executeAt, queued to run at that simulation time.", Fields: []string{"scheduled"}, Required: []string{"scheduled"}, FromServer: true},
+	{Type: "confirmation", Summary: "A command held until a second controller confirms it under the two-person policy.", Fields: []string{"confirmation"}, Required: []string{"confirmation"}, FromServer: true},
+	{Type: "time", Summary: "Server time sync. Sent on connect; a client sending one with its clientTime gets it back with the serverTime, to estimate its clock offset.", Fields: []string{"serverTime", "clientTime"}, FromClient: true, FromServer: true},
+	{Type: "timers", Summary: "Every flight's countdown to touchdown, time in holding and expected approach time, sent each second.", Fields: []string{"timers"}, Required: []string{"timers"}, FromServer: true},
+	{Type: "event", Summary: "A simulation event published on the event bus.", Fields: []string{"event"}, Required: []string{"event"}, FromServer: true},
+	{Type: "logEvent", Summary: "A significant event (closures, conflicts, diversions and the like) summarized with a severity of info, warning or critical, sent after the event message.", Fields: []string{"logEvent"}, Required: []string{"logEvent"}, FromServer: true},
+	{Type: "voice", Summary: "An instruction or readback as spoken on the frequency, with the speaker (tower or pilot), for a text-to-speech frontend.", Fields: []string{"voice"}, Required: []string{"voice"}, Voice: true},
+	{Type: "playbackComplete", Summary: "Sent once a recorded session has been fully replayed.", FromServer: true},
+}
+
+// AsyncAPIDocument is an AsyncAPI 2.6 description of the websocket channels.
+type AsyncAPIDocument struct {
+	AsyncAPI   string                     `json:"asyncapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Channels   map[string]AsyncAPIChannel `json:"channels"`
+	Components AsyncAPIComponents         `json:"components"`
+}
+
+// AsyncAPIChannel lists what clients may publish to a channel and what they
+// receive when subscribed.
+type AsyncAPIChannel struct {
+	Description string             `json:"description,omitempty"`
+	Publish     *AsyncAPIOperation `json:"publish,omitempty"`
+	Subscribe   *AsyncAPIOperation `json:"subscribe,omitempty"`
+}
+
+// AsyncAPIOperation references the messages exchanged in one direction.
+type AsyncAPIOperation struct {
+	Message AsyncAPIOneOf `json:"message"`
+}
+
+// AsyncAPIOneOf is a choice between message references.
+type AsyncAPIOneOf struct {
+	OneOf []*Schema `json:"oneOf"`
+}
+
+// AsyncAPIMessage describes one message type.
+type AsyncAPIMessage struct {
+	Name    string  `json:"name"`
+	Summary string  `json:"summary"`
+	Payload *Schema `json:"payload"`
+}
+
+// AsyncAPIComponents holds the message definitions and the schemas they use.
+type AsyncAPIComponents struct {
+	Messages map[string]AsyncAPIMessage `json:"messages"`
+	Schemas  map[string]*Schema         `json:"schemas"`
+}
+
+// messageContract is the websocket contract, derived from the Message type.
+var messageContract = buildMessageContract()
+
+func buildMessageContract() *AsyncAPIDocument {
+	schemas := newSchemaSet("#/components/schemas/")
+	full := schemas.object(reflect.TypeOf(Message{}))
+	doc := &AsyncAPIDocument{
+		AsyncAPI: "2.6.0",
+		Info:     OpenAPIInfo{Title: "AirCommand websocket messages", Version: APIVersion},
+		Components: AsyncAPIComponents{
+			Messages: make(map[string]AsyncAPIMessage),
+		},
+	}
+	var fromClient, fromServer, voice []*Schema
+	for _, kind := range messageKinds {
+		payload := &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"type": {Type: "string", Enum: []string{kind.Type}}},
+			Required:   append([]string{"type"}, kind.Required...),
+		}
+		for _, field := range kind.Fields {
+			payload.Properties[field] = full.Properties[field]
+		}
+		doc.Components.Messages[kind.Type] = AsyncAPIMessage{Name: kind.Type, Summary: kind.Summary, Payload: payload}
+		ref := &Schema{Ref: "#/components/messages/" + kind.Type}
+		if kind.FromClient {
+			fromClient = append(fromClient, ref)
+		}
+		if kind.FromServer {
+			fromServer = append(fromServer, ref)
+		}
+		if kind.Voice {
+			voice = append(voice, ref)
+		}
+	}
+	doc.Components.Schemas = schemas.defs
+	doc.Channels = map[string]AsyncAPIChannel{
+		"/control": {
+			Description: "Bidirectional control channel. The server first sends the current rate, runway states and wind.",
+			Publish:     &AsyncAPIOperation{Message: AsyncAPIOneOf{OneOf: fromClient}},
+			Subscribe:   &AsyncAPIOperation{Message: AsyncAPIOneOf{OneOf: fromServer}},
+		},
+		"/playback": {
+			Description: "Replays a recorded /control session.",
+			Subscribe:   &AsyncAPIOperation{Message: AsyncAPIOneOf{OneOf: fromServer}},
+		},
+		"/voice": {
+			Description: "Instructions and pilot readbacks in phraseology as they go out on the frequency, for a text-to-speech frontend.",
+			Subscribe:   &AsyncAPIOperation{Message: AsyncAPIOneOf{OneOf: voice}},
+		},
+	}
+	return doc
+}
+
+// validateMessage checks an outgoing message against its published schema.
+func validateMessage(msg Message) error {
+	kind, ok := messageContract.Components.Messages[msg.Type]
+	if !ok {
+		return fmt.Errorf("undocumented message type %q", msg.Type)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	for name := range decoded {
+		if _, ok := kind.Payload.Properties[name]; !ok {
+			return fmt.Errorf("%s message carries undocumented property %q", msg.Type, name)
+		}
+	}
+	return kind.Payload.Validate(decoded, messageContract.Components.Schemas)
+}
+
+// HandleMessageSchema serves the AsyncAPI description of the websocket
+// messages.
+func (s *Server) HandleMessageSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, messageContract)
+}
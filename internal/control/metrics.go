@@ -1,12 +1,17 @@
 package control
 
 import (
+	"math"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // SchedulerMetrics captures live scheduler telemetry in a goroutine-safe manner.
 type SchedulerMetrics struct {
+	// runwayMu guards the per-runway maps, queues and runwayAssignments,
+	// against runways added and removed at runtime.
+	runwayMu           sync.RWMutex
 	queues             map[string]*atomicInt64
 	holdingCurrent     atomicInt64
 	holdingTotal       atomicInt64
@@ -14,27 +19,152 @@ type SchedulerMetrics struct {
 	totalWaitMicros    atomicInt64
 	landings           atomicInt64
 	totalLandingMicros atomicInt64
+	occupancyMicros    atomicInt64
 	conflicts          atomicInt64
+	incursions         atomicInt64
+	incursionsResolved atomicInt64
+	incursionMicros    atomicInt64
+	goArounds          atomicInt64
+	diversions         atomicInt64
+	fuelGrams          atomicInt64
+	revenueCents       atomicInt64
+	gateConflicts      atomicInt64
+	tows               atomicInt64
+	gatesOccupied      atomicInt64
+	departures         atomicInt64
+	taxiOuts           atomicInt64
+	taxiOutMicros      atomicInt64
+	taxiing            atomicInt64
+	departureQueue     atomicInt64
+	deiceQueue         atomicInt64
+	deicing            atomicInt64
+	deicingWaits       atomicInt64
+	deicingWaitMicros  atomicInt64
+	offBlocks          atomicInt64
+	offBlockLateMicros atomicInt64
+	reactionaryMicros  atomicInt64
+	transitions        atomicInt64
+	transitionMicros   atomicInt64
+	// delayMicros accumulates attributed delay per cause.
+	delayMicros map[string]*atomicInt64
+	// custom holds the values of operator-defined metrics by name, and
+	// customKinds whether each was first counted or set.
+	customMu    sync.Mutex
+	custom      map[string]float64
+	customKinds map[string]string
+	// slas holds each SLA's attainment over its window, under customMu.
+	slas map[string]float64
+	// phases counts flights per lifecycle phase.
+	phases map[FlightPhase]*atomicInt64
+	// runwayAssignments and preferenceAssignments count assignments per
+	// runway and per runway preference.
+	runwayAssignments     map[string]*atomicInt64
+	preferenceAssignments map[string]*atomicInt64
+	etaMu                 sync.Mutex
+	eta                   etaStats
+	holdMu                sync.Mutex
+	hold                  holdingStats
+	clock                 Clock
 }
 
 // MetricsSnapshot is a read-only view of the current metrics.
 type MetricsSnapshot struct {
-	TotalArrivals      int64            `json:"totalArrivals"`
-	AverageWaitSeconds float64          `json:"averageWaitSeconds"`
-	AverageLandingTime float64          `json:"averageLandingSeconds"`
-	HoldingCurrent     int64            `json:"holdingCurrent"`
-	HoldingPatterns    int64            `json:"holdingPatterns"`
-	QueueLengths       map[string]int64 `json:"queueLengths"`
-	ConflictDetections int64            `json:"conflicts"`
+	TotalArrivals      int64   `json:"totalArrivals"`
+	AverageWaitSeconds float64 `json:"averageWaitSeconds"`
+	AverageLandingTime float64 `json:"averageLandingSeconds"`
+	// AverageOccupancy is the mean time, in seconds, arrivals occupy the
+	// runway, which runway exits shorten.
+	AverageOccupancy float64 `json:"averageOccupancySeconds"`
+	HoldingCurrent   int64   `json:"holdingCurrent"`
+	HoldingPatterns  int64   `json:"holdingPatterns"`
+	// HoldingMinutes is the total time flights have spent holding, and
+	// AverageHoldingMinutes that per flight held; both count spells once
+	// they end. LongestHolding is the flight holding longest now.
+	HoldingMinutes        float64          `json:"holdingMinutes"`
+	AverageHoldingMinutes float64          `json:"averageHoldingMinutes"`
+	LongestHolding        *HoldingFlight   `json:"longestHolding,omitempty"`
+	QueueLengths          map[string]int64 `json:"queueLengths"`
+	ConflictDetections    int64            `json:"conflicts"`
+	Incursions            int64            `json:"incursions"`
+	// AverageIncursionResolution is the mean time, in seconds, from an
+	// incursion being detected to the runway being vacated.
+	AverageIncursionResolution float64 `json:"averageIncursionResolutionSeconds"`
+	GoArounds                  int64   `json:"goArounds"`
+	Diversions                 int64   `json:"diversions"`
+	// FuelBurnedKg and CO2Kg estimate the extra fuel burned, and CO2 emitted,
+	// holding and vectoring because of scheduling delays.
+	FuelBurnedKg float64 `json:"fuelBurnedKg"`
+	CO2Kg        float64 `json:"co2Kg"`
+	// Revenue is the landing fees billed so far.
+	Revenue float64 `json:"landingFeeRevenue"`
+	// GateConflicts counts arrivals whose planned stand was still occupied;
+	// Tows counts aircraft towed to a remote stand.
+	GateConflicts int64 `json:"gateConflicts"`
+	Tows          int64 `json:"tows"`
+	GatesOccupied int64 `json:"gatesOccupied"`
+	Departures    int64 `json:"departures"`
+	// Taxiing and DepartureQueue count aircraft taxiing out and waiting at
+	// runway holding points.
+	Taxiing               int64   `json:"taxiing"`
+	DepartureQueue        int64   `json:"departureQueue"`
+	AverageTaxiOutSeconds float64 `json:"averageTaxiOutSeconds"`
+	// DeicingQueue and Deicing count departures waiting for and on de-icing
+	// pads.
+	DeicingQueue              int64   `json:"deicingQueue"`
+	Deicing                   int64   `json:"deicing"`
+	AverageDeicingWaitSeconds float64 `json:"averageDeicingWaitSeconds"`
+	// AverageDepartureDelaySeconds is how late departures leave their
+	// stands against schedule. Of the total, ReactionaryDelaySeconds was
+	// propagated from late inbound legs and PrimaryDelaySeconds arose here.
+	AverageDepartureDelaySeconds float64 `json:"averageDepartureDelaySeconds"`
+	PrimaryDelaySeconds          float64 `json:"primaryDelaySeconds"`
+	ReactionaryDelaySeconds      float64 `json:"reactionaryDelaySeconds"`
+	// DelaySeconds is the delay attributed to each cause so far.
+	DelaySeconds map[string]float64 `json:"delaySecondsByCause"`
+	// RunwayTransitions counts runway direction changes and
+	// TransitionLostSeconds the landing time they cost.
+	RunwayTransitions     int64   `json:"runwayTransitions"`
+	TransitionLostSeconds float64 `json:"transitionLostSeconds"`
+	// Custom holds operator-defined counters and gauges by name.
+	Custom map[string]float64 `json:"custom,omitempty"`
+	// SLAAttainment is the fraction of recent flights meeting each SLA.
+	SLAAttainment map[string]float64 `json:"slaAttainment,omitempty"`
+	// FlightPhases counts the flights in each lifecycle phase: those in
+	// progress now, and for the terminal phases every flight that reached
+	// them.
+	FlightPhases map[FlightPhase]int64 `json:"flightPhases"`
+	// ETA is how accurately touchdown times were estimated.
+	ETA ETAAccuracy `json:"eta"`
+	// AssignmentsByRunway and AssignmentsByPreference show how arrivals
+	// were distributed across runways and their preferences.
+	AssignmentsByRunway     map[string]int64 `json:"assignmentsByRunway"`
+	AssignmentsByPreference map[string]int64 `json:"assignmentsByPreference"`
 }
 
 // NewSchedulerMetrics builds a metrics collector for the supplied runway names.
+// Runways not named are registered when first seen.
 func NewSchedulerMetrics(runways []string) *SchedulerMetrics {
 	queues := make(map[string]*atomicInt64, len(runways))
 	for _, r := range runways {
 		queues[r] = &atomicInt64{}
 	}
-	return &SchedulerMetrics{queues: queues}
+	delays := make(map[string]*atomicInt64, len(DelayCauses))
+	for _, cause := range DelayCauses {
+		delays[cause] = &atomicInt64{}
+	}
+	phases := make(map[FlightPhase]*atomicInt64, len(FlightPhases))
+	for _, phase := range FlightPhases {
+		phases[phase] = &atomicInt64{}
+	}
+	assignments := make(map[string]*atomicInt64, len(runways))
+	for _, r := range runways {
+		assignments[r] = &atomicInt64{}
+	}
+	preferences := make(map[string]*atomicInt64, len(preferenceWeights))
+	for pref := range preferenceWeights {
+		preferences[pref] = &atomicInt64{}
+	}
+	return &SchedulerMetrics{queues: queues, delayMicros: delays, custom: make(map[string]float64), customKinds: make(map[string]string), slas: make(map[string]float64), phases: phases, runwayAssignments: assignments, preferenceAssignments: preferences}
 }
 
 // RecordAssignment registers an arrival assigned to a runway.
@@ -49,6 +179,11 @@ func (m *SchedulerMetrics) RecordLanding(duration time.Duration) {
 	m.totalLandingMicros.Add(duration.Microseconds())
 }
 
+// RecordOccupancy captures how long a landing occupied the runway.
+func (m *SchedulerMetrics) RecordOccupancy(d time.Duration) {
+	m.occupancyMicros.Add(d.Microseconds())
+}
+
 // RecordHoldingPattern increments the count of flights sent to holding.
 func (m *SchedulerMetrics) RecordHoldingPattern() {
 	m.holdingTotal.Add(1)
@@ -59,23 +194,227 @@ func (m *SchedulerMetrics) RecordConflict() {
 	m.conflicts.Add(1)
 }
 
+// RecordIncursion increments the runway incursion counter.
+func (m *SchedulerMetrics) RecordIncursion() {
+	m.incursions.Add(1)
+}
+
+// RecordIncursionResolved captures how long an incursion took to clear.
+func (m *SchedulerMetrics) RecordIncursionResolved(took time.Duration) {
+	m.incursionsResolved.Add(1)
+	m.incursionMicros.Add(took.Microseconds())
+}
+
+// RecordGoAround increments the count of aborted landings.
+func (m *SchedulerMetrics) RecordGoAround() {
+	m.goArounds.Add(1)
+}
+
+// RecordDiversion increments the count of flights diverted elsewhere.
+func (m *SchedulerMetrics) RecordDiversion() {
+	m.diversions.Add(1)
+}
+
+// RecordFuelBurn adds fuel, in kilograms, burned because of delays.
+func (m *SchedulerMetrics) RecordFuelBurn(kg float64) {
+	m.fuelGrams.Add(int64(kg * 1000))
+}
+
+// RecordRevenue adds a billed landing fee.
+func (m *SchedulerMetrics) RecordRevenue(fee float64) {
+	m.revenueCents.Add(int64(math.Round(fee * 100)))
+}
+
+// RecordGateConflict increments the count of arrivals whose planned stand
+// was still occupied.
+func (m *SchedulerMetrics) RecordGateConflict() {
+	m.gateConflicts.Add(1)
+}
+
+// RecordTow increments the count of aircraft towed to a remote stand.
+func (m *SchedulerMetrics) RecordTow() {
+	m.tows.Add(1)
+}
+
+// SetGatesOccupied updates the number of occupied stands.
+func (m *SchedulerMetrics) SetGatesOccupied(count int) {
+	m.gatesOccupied.Store(int64(count))
+}
+
+// RecordDeparture increments the count of takeoffs.
+func (m *SchedulerMetrics) RecordDeparture() {
+	m.departures.Add(1)
+}
+
+// RecordTaxiOut captures the time from pushback to the holding point.
+func (m *SchedulerMetrics) RecordTaxiOut(d time.Duration) {
+	m.taxiOuts.Add(1)
+	m.taxiOutMicros.Add(d.Microseconds())
+}
+
+// SetGroundTraffic updates the number of aircraft taxiing out and queued at
+// holding points.
+func (m *SchedulerMetrics) SetGroundTraffic(taxiing, queued int) {
+	m.taxiing.Store(int64(taxiing))
+	m.departureQueue.Store(int64(queued))
+}
+
+// RecordDeicingWait captures how long a departure waited for a de-icing pad.
+func (m *SchedulerMetrics) RecordDeicingWait(d time.Duration) {
+	m.deicingWaits.Add(1)
+	m.deicingWaitMicros.Add(d.Microseconds())
+}
+
+// SetDeicing updates the number of departures waiting for and on de-icing
+// pads.
+func (m *SchedulerMetrics) SetDeicing(queued, active int) {
+	m.deiceQueue.Store(int64(queued))
+	m.deicing.Store(int64(active))
+}
+
+// RecordDepartureDelay captures how late a departure left its stand, of
+// which reactionary was inherited from its late inbound leg.
+func (m *SchedulerMetrics) RecordDepartureDelay(d, reactionary time.Duration) {
+	m.offBlocks.Add(1)
+	m.offBlockLateMicros.Add(d.Microseconds())
+	m.reactionaryMicros.Add(reactionary.Microseconds())
+}
+
+// RecordRunwayTransition captures a runway direction change and the
+// landing time lost to it.
+func (m *SchedulerMetrics) RecordRunwayTransition(lost time.Duration) {
+	m.transitions.Add(1)
+	m.transitionMicros.Add(lost.Microseconds())
+}
+
+// RecordDelay attributes delay to a cause.
+func (m *SchedulerMetrics) RecordDelay(cause string, d time.Duration) {
+	counter, ok := m.delayMicros[cause]
+	if !ok || d <= 0 {
+		return
+	}
+	counter.Add(d.Microseconds())
+}
+
+// AddCustom adds delta to a custom metric, creating it if needed.
+func (m *SchedulerMetrics) AddCustom(name string, delta float64) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	m.custom[name] += delta
+	if _, ok := m.customKinds[name]; !ok {
+		m.customKinds[name] = CustomCounter
+	}
+}
+
+// SetCustom sets a custom metric's value.
+func (m *SchedulerMetrics) SetCustom(name string, v float64) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	m.custom[name] = v
+	if _, ok := m.customKinds[name]; !ok {
+		m.customKinds[name] = CustomGauge
+	}
+}
+
+// RemoveCustom drops a custom metric.
+func (m *SchedulerMetrics) RemoveCustom(name string) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	delete(m.custom, name)
+	delete(m.customKinds, name)
+}
+
+// readCustomKinds reports whether each custom metric is a counter or a
+// gauge.
+func (m *SchedulerMetrics) readCustomKinds() map[string]string {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	out := make(map[string]string, len(m.customKinds))
+	for name, kind := range m.customKinds {
+		out[name] = kind
+	}
+	return out
+}
+
+// SetSLAAttainment updates an SLA's attainment.
+func (m *SchedulerMetrics) SetSLAAttainment(id string, attainment float64) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	m.slas[id] = attainment
+}
+
+// RemoveSLAAttainment drops an SLA's attainment.
+func (m *SchedulerMetrics) RemoveSLAAttainment(id string) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	delete(m.slas, id)
+}
+
 // SetHolding updates the current number of flights in holding.
 func (m *SchedulerMetrics) SetHolding(count int) {
 	m.holdingCurrent.Store(int64(count))
 }
 
-// UpdateQueueLength stores the current queue length for a runway.
-func (m *SchedulerMetrics) UpdateQueueLength(runway string, count int) {
-	gauge, ok := m.queues[runway]
-	if !ok {
-		return
+// SetFlightPhase stores the number of flights in a lifecycle phase.
+func (m *SchedulerMetrics) SetFlightPhase(phase FlightPhase, count int) {
+	if gauge, ok := m.phases[phase]; ok {
+		gauge.Store(int64(count))
+	}
+}
+
+// RecordRunwayAssignment counts an arrival assigned to runway while it had
+// the given preference.
+func (m *SchedulerMetrics) RecordRunwayAssignment(runway, preference string) {
+	m.runwayGauge(m.runwayAssignments, runway).Add(1)
+	if counter, ok := m.preferenceAssignments[preference]; ok {
+		counter.Add(1)
+	}
+}
+
+// AddRunway starts collecting metrics for a runway added at runtime, so it
+// shows in snapshots before its first arrival. Runways first seen in a
+// queue update or assignment are registered then.
+func (m *SchedulerMetrics) AddRunway(runway string) {
+	m.runwayGauge(m.queues, runway)
+	m.runwayGauge(m.runwayAssignments, runway)
+}
+
+// runwayGauge returns runway's entry in one of the per-runway maps,
+// registering it on first use.
+func (m *SchedulerMetrics) runwayGauge(gauges map[string]*atomicInt64, runway string) *atomicInt64 {
+	m.runwayMu.RLock()
+	gauge, ok := gauges[runway]
+	m.runwayMu.RUnlock()
+	if ok {
+		return gauge
 	}
-	gauge.Store(int64(count))
+	m.runwayMu.Lock()
+	defer m.runwayMu.Unlock()
+	if gauge, ok = gauges[runway]; !ok {
+		gauge = &atomicInt64{}
+		gauges[runway] = gauge
+	}
+	return gauge
+}
+
+// RemoveRunway drops the queue gauge of a decommissioned runway. Its
+// assignments stay counted.
+func (m *SchedulerMetrics) RemoveRunway(runway string) {
+	m.runwayMu.Lock()
+	defer m.runwayMu.Unlock()
+	delete(m.queues, runway)
+}
+
+// UpdateQueueLength stores the current queue length for a runway,
+// registering runways not known at construction.
+func (m *SchedulerMetrics) UpdateQueueLength(runway string, count int) {
+	m.runwayGauge(m.queues, runway).Store(int64(count))
 }
 
 // Snapshot returns a copy of the current metrics values.
 func (m *SchedulerMetrics) Snapshot() MetricsSnapshot {
 	queues := m.readQueueLengths()
+	holdingMinutes, averageHolding, longestHolding := m.readHolding()
 
 	arrivals := m.arrivals.Load()
 	waitAvg := 0.0
@@ -84,23 +423,131 @@ func (m *SchedulerMetrics) Snapshot() MetricsSnapshot {
 	}
 
 	landings := m.landings.Load()
-	landingAvg := 0.0
+	landingAvg, occupancyAvg := 0.0, 0.0
 	if landings > 0 {
 		landingAvg = float64(m.totalLandingMicros.Load()) / float64(landings) / 1_000_000
+		occupancyAvg = float64(m.occupancyMicros.Load()) / float64(landings) / 1_000_000
+	}
+
+	resolved := m.incursionsResolved.Load()
+	incursionAvg := 0.0
+	if resolved > 0 {
+		incursionAvg = float64(m.incursionMicros.Load()) / float64(resolved) / 1_000_000
+	}
+
+	fuel := float64(m.fuelGrams.Load()) / 1000
+
+	taxiOuts := m.taxiOuts.Load()
+	taxiAvg := 0.0
+	if taxiOuts > 0 {
+		taxiAvg = float64(m.taxiOutMicros.Load()) / float64(taxiOuts) / 1_000_000
+	}
+
+	deicingWaits := m.deicingWaits.Load()
+	deicingAvg := 0.0
+	if deicingWaits > 0 {
+		deicingAvg = float64(m.deicingWaitMicros.Load()) / float64(deicingWaits) / 1_000_000
+	}
+
+	offBlocks := m.offBlocks.Load()
+	offBlockLate := float64(m.offBlockLateMicros.Load()) / 1_000_000
+	reactionary := float64(m.reactionaryMicros.Load()) / 1_000_000
+	departureDelayAvg := 0.0
+	if offBlocks > 0 {
+		departureDelayAvg = offBlockLate / float64(offBlocks)
 	}
 
 	return MetricsSnapshot{
-		TotalArrivals:      arrivals,
-		AverageWaitSeconds: waitAvg,
-		AverageLandingTime: landingAvg,
-		HoldingCurrent:     m.holdingCurrent.Load(),
-		HoldingPatterns:    m.holdingTotal.Load(),
-		QueueLengths:       queues,
-		ConflictDetections: m.conflicts.Load(),
+		TotalArrivals:                arrivals,
+		AverageWaitSeconds:           waitAvg,
+		AverageLandingTime:           landingAvg,
+		AverageOccupancy:             occupancyAvg,
+		HoldingCurrent:               m.holdingCurrent.Load(),
+		HoldingPatterns:              m.holdingTotal.Load(),
+		HoldingMinutes:               holdingMinutes,
+		AverageHoldingMinutes:        averageHolding,
+		LongestHolding:               longestHolding,
+		QueueLengths:                 queues,
+		ConflictDetections:           m.conflicts.Load(),
+		Incursions:                   m.incursions.Load(),
+		AverageIncursionResolution:   incursionAvg,
+		GoArounds:                    m.goArounds.Load(),
+		Diversions:                   m.diversions.Load(),
+		DelaySeconds:                 m.readDelays(),
+		FuelBurnedKg:                 fuel,
+		CO2Kg:                        fuel * co2PerKgFuel,
+		Revenue:                      float64(m.revenueCents.Load()) / 100,
+		GateConflicts:                m.gateConflicts.Load(),
+		Tows:                         m.tows.Load(),
+		GatesOccupied:                m.gatesOccupied.Load(),
+		Departures:                   m.departures.Load(),
+		Taxiing:                      m.taxiing.Load(),
+		DepartureQueue:               m.departureQueue.Load(),
+		AverageTaxiOutSeconds:        taxiAvg,
+		DeicingQueue:                 m.deiceQueue.Load(),
+		Deicing:                      m.deicing.Load(),
+		AverageDeicingWaitSeconds:    deicingAvg,
+		AverageDepartureDelaySeconds: departureDelayAvg,
+		PrimaryDelaySeconds:          offBlockLate - reactionary,
+		ReactionaryDelaySeconds:      reactionary,
+		RunwayTransitions:            m.transitions.Load(),
+		TransitionLostSeconds:        float64(m.transitionMicros.Load()) / 1_000_000,
+		Custom:                       m.readCustom(m.custom),
+		SLAAttainment:                m.readCustom(m.slas),
+		FlightPhases:                 m.readPhases(),
+		ETA:                          m.readETA(),
+		AssignmentsByRunway:          m.readRunwayAssignments(),
+		AssignmentsByPreference:      readCounts(m.preferenceAssignments),
 	}
 }
 
+// readCustom copies one of the maps guarded by customMu.
+func (m *SchedulerMetrics) readCustom(values map[string]float64) map[string]float64 {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]float64, len(values))
+	for name, v := range values {
+		out[name] = v
+	}
+	return out
+}
+
+func (m *SchedulerMetrics) readDelays() map[string]float64 {
+	out := make(map[string]float64, len(m.delayMicros))
+	for cause, counter := range m.delayMicros {
+		out[cause] = float64(counter.Load()) / 1_000_000
+	}
+	return out
+}
+
+func (m *SchedulerMetrics) readPhases() map[FlightPhase]int64 {
+	out := make(map[FlightPhase]int64, len(m.phases))
+	for phase, gauge := range m.phases {
+		out[phase] = gauge.Load()
+	}
+	return out
+}
+
+func readCounts(counters map[string]*atomicInt64) map[string]int64 {
+	out := make(map[string]int64, len(counters))
+	for name, counter := range counters {
+		out[name] = counter.Load()
+	}
+	return out
+}
+
+func (m *SchedulerMetrics) readRunwayAssignments() map[string]int64 {
+	m.runwayMu.RLock()
+	defer m.runwayMu.RUnlock()
+	return readCounts(m.runwayAssignments)
+}
+
 func (m *SchedulerMetrics) readQueueLengths() map[string]int64 {
+	m.runwayMu.RLock()
+	defer m.runwayMu.RUnlock()
 	out := make(map[string]int64, len(m.queues))
 	for runway, gauge := range m.queues {
 		out[runway] = gauge.Load()
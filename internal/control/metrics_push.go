@@ -0,0 +1,250 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported metrics push protocols.
+const (
+	PushStatsD   = "statsd"
+	PushDatadog  = "datadog"
+	PushInfluxDB = "influx"
+)
+
+// PushConfig configures the periodic metrics pusher.
+type PushConfig struct {
+	Protocol string
+	Address  string
+	Interval time.Duration
+	Prefix   string
+	Tags     map[string]string
+}
+
+// MetricsPusher periodically ships scheduler metrics to an external collector.
+type MetricsPusher struct {
+	cfg     PushConfig
+	metrics *SchedulerMetrics
+	conn    net.Conn
+	last    MetricsSnapshot
+}
+
+// NewMetricsPusher validates the configuration and dials the collector.
+func NewMetricsPusher(metrics *SchedulerMetrics, cfg PushConfig) (*MetricsPusher, error) {
+	switch cfg.Protocol {
+	case PushStatsD, PushDatadog, PushInfluxDB:
+	default:
+		return nil, fmt.Errorf("unknown push protocol %q", cfg.Protocol)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "aircommand"
+	}
+	conn, err := net.Dial("udp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", cfg.Address, err)
+	}
+	return &MetricsPusher{cfg: cfg, metrics: metrics, conn: conn}, nil
+}
+
+// Run pushes metrics on every interval until the context is canceled.
+func (p *MetricsPusher) Run(ctx context.Context) {
+	defer p.conn.Close()
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	log.Printf("pushing %s metrics to %s every %s", p.cfg.Protocol, p.cfg.Address, p.cfg.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.push()
+		}
+	}
+}
+
+func (p *MetricsPusher) push() {
+	snapshot := p.metrics.Snapshot()
+	var buf bytes.Buffer
+	if p.cfg.Protocol == PushInfluxDB {
+		p.writeInflux(&buf, snapshot)
+	} else {
+		p.writeStatsD(&buf, snapshot)
+	}
+	p.last = snapshot
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		log.Printf("metrics push: %v", err)
+	}
+}
+
+// writeStatsD emits counters as deltas since the previous push and gauges as
+// absolute values. Datadog tags are appended in the DogStatsD format.
+func (p *MetricsPusher) writeStatsD(buf *bytes.Buffer, s MetricsSnapshot) {
+	line := func(name string, value any, kind string, extra map[string]string) {
+		fmt.Fprintf(buf, "%s.%s:%v|%s", p.cfg.Prefix, name, value, kind)
+		if p.cfg.Protocol == PushDatadog {
+			if tags := joinTags(p.cfg.Tags, extra, ":", ","); tags != "" {
+				buf.WriteString("|#" + tags)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	line("arrivals", s.TotalArrivals-p.last.TotalArrivals, "c", nil)
+	line("holding_patterns", s.HoldingPatterns-p.last.HoldingPatterns, "c", nil)
+	line("conflicts", s.ConflictDetections-p.last.ConflictDetections, "c", nil)
+	line("incursions", s.Incursions-p.last.Incursions, "c", nil)
+	line("go_arounds", s.GoArounds-p.last.GoArounds, "c", nil)
+	line("diversions", s.Diversions-p.last.Diversions, "c", nil)
+	line("fuel_kg", s.FuelBurnedKg-p.last.FuelBurnedKg, "c", nil)
+	line("landing_fee_revenue", s.Revenue-p.last.Revenue, "c", nil)
+	line("gate_conflicts", s.GateConflicts-p.last.GateConflicts, "c", nil)
+	line("tows", s.Tows-p.last.Tows, "c", nil)
+	line("gates_occupied", s.GatesOccupied, "g", nil)
+	line("departures", s.Departures-p.last.Departures, "c", nil)
+	line("taxiing", s.Taxiing, "g", nil)
+	line("departure_queue", s.DepartureQueue, "g", nil)
+	line("taxi_out_seconds_avg", s.AverageTaxiOutSeconds, "g", nil)
+	line("deicing_queue", s.DeicingQueue, "g", nil)
+	line("deicing_wait_seconds_avg", s.AverageDeicingWaitSeconds, "g", nil)
+	line("departure_delay_seconds_avg", s.AverageDepartureDelaySeconds, "g", nil)
+	line("primary_delay_seconds", s.PrimaryDelaySeconds-p.last.PrimaryDelaySeconds, "c", nil)
+	line("reactionary_delay_seconds", s.ReactionaryDelaySeconds-p.last.ReactionaryDelaySeconds, "c", nil)
+	line("holding_current", s.HoldingCurrent, "g", nil)
+	line("holding_minutes", s.HoldingMinutes-p.last.HoldingMinutes, "c", nil)
+	line("holding_minutes_avg", s.AverageHoldingMinutes, "g", nil)
+	line("holding_longest_minutes", longestHoldingMinutes(s), "g", nil)
+	line("wait_seconds_avg", s.AverageWaitSeconds, "g", nil)
+	line("landing_seconds_avg", s.AverageLandingTime, "g", nil)
+	line("occupancy_seconds_avg", s.AverageOccupancy, "g", nil)
+	line("eta_error_seconds_avg", s.ETA.MeanErrorSeconds, "g", nil)
+	line("eta_abs_error_seconds_avg", s.ETA.MeanAbsErrorSeconds, "g", nil)
+	line("eta_abs_error_seconds_p90", s.ETA.P90AbsErrorSeconds, "g", nil)
+	line("runway_transitions", s.RunwayTransitions-p.last.RunwayTransitions, "c", nil)
+	line("transition_lost_seconds", s.TransitionLostSeconds-p.last.TransitionLostSeconds, "c", nil)
+	for _, cause := range sortedKeys(s.DelaySeconds) {
+		delta := s.DelaySeconds[cause] - p.last.DelaySeconds[cause]
+		if p.cfg.Protocol == PushDatadog {
+			line("delay_seconds", delta, "c", map[string]string{"cause": cause})
+		} else {
+			line("delay_seconds."+cause, delta, "c", nil)
+		}
+	}
+	for _, name := range sortedKeys(s.Custom) {
+		line("custom."+name, s.Custom[name], "g", nil)
+	}
+	for _, id := range sortedKeys(s.SLAAttainment) {
+		if p.cfg.Protocol == PushDatadog {
+			line("sla_attainment", s.SLAAttainment[id], "g", map[string]string{"sla": id})
+		} else {
+			line("sla_attainment."+id, s.SLAAttainment[id], "g", nil)
+		}
+	}
+	for _, phase := range FlightPhases {
+		if p.cfg.Protocol == PushDatadog {
+			line("flight_phase", s.FlightPhases[phase], "g", map[string]string{"phase": string(phase)})
+		} else {
+			line("flight_phase."+string(phase), s.FlightPhases[phase], "g", nil)
+		}
+	}
+	for _, runway := range sortedKeys(s.QueueLengths) {
+		if p.cfg.Protocol == PushDatadog {
+			line("queue_length", s.QueueLengths[runway], "g", map[string]string{"runway": runway})
+		} else {
+			line("queue_length."+runway, s.QueueLengths[runway], "g", nil)
+		}
+	}
+	for _, runway := range sortedKeys(s.AssignmentsByRunway) {
+		delta := s.AssignmentsByRunway[runway] - p.last.AssignmentsByRunway[runway]
+		if p.cfg.Protocol == PushDatadog {
+			line("assignments", delta, "c", map[string]string{"runway": runway})
+		} else {
+			line("assignments."+runway, delta, "c", nil)
+		}
+	}
+	for _, pref := range sortedKeys(s.AssignmentsByPreference) {
+		delta := s.AssignmentsByPreference[pref] - p.last.AssignmentsByPreference[pref]
+		if p.cfg.Protocol == PushDatadog {
+			line("preference_assignments", delta, "c", map[string]string{"preference": pref})
+		} else {
+			line("preference_assignments."+pref, delta, "c", nil)
+		}
+	}
+}
+
+// writeInflux emits absolute values using the InfluxDB line protocol.
+func (p *MetricsPusher) writeInflux(buf *bytes.Buffer, s MetricsSnapshot) {
+	ts := time.Now().UnixNano()
+	tags := joinTags(p.cfg.Tags, nil, "=", ",")
+	measurement := p.cfg.Prefix
+	if tags != "" {
+		measurement += "," + tags
+	}
+	fmt.Fprintf(buf, "%s arrivals=%di,holding_patterns=%di,conflicts=%di,incursions=%di,go_arounds=%di,diversions=%di,fuel_kg=%f,landing_fee_revenue=%f,gate_conflicts=%di,tows=%di,gates_occupied=%di,departures=%di,taxiing=%di,departure_queue=%di,taxi_out_seconds_avg=%f,deicing_queue=%di,deicing_wait_seconds_avg=%f,departure_delay_seconds_avg=%f,primary_delay_seconds=%f,reactionary_delay_seconds=%f,holding_current=%di,holding_minutes=%f,holding_minutes_avg=%f,holding_longest_minutes=%f,wait_seconds_avg=%f,landing_seconds_avg=%f,occupancy_seconds_avg=%f %d\n",
+		measurement, s.TotalArrivals, s.HoldingPatterns, s.ConflictDetections, s.Incursions, s.GoArounds, s.Diversions, s.FuelBurnedKg, s.Revenue, s.GateConflicts, s.Tows, s.GatesOccupied, s.Departures, s.Taxiing, s.DepartureQueue, s.AverageTaxiOutSeconds, s.DeicingQueue, s.AverageDeicingWaitSeconds, s.AverageDepartureDelaySeconds, s.PrimaryDelaySeconds, s.ReactionaryDelaySeconds, s.HoldingCurrent, s.HoldingMinutes, s.AverageHoldingMinutes, longestHoldingMinutes(s), s.AverageWaitSeconds, s.AverageLandingTime, s.AverageOccupancy, ts)
+	for _, runway := range sortedKeys(s.QueueLengths) {
+		fmt.Fprintf(buf, "%s_queue,%s queue_length=%di %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"runway": runway}, "=", ","), s.QueueLengths[runway], ts)
+	}
+	for _, runway := range sortedKeys(s.AssignmentsByRunway) {
+		fmt.Fprintf(buf, "%s_assignments,%s count=%di %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"runway": runway}, "=", ","), s.AssignmentsByRunway[runway], ts)
+	}
+	for _, pref := range sortedKeys(s.AssignmentsByPreference) {
+		fmt.Fprintf(buf, "%s_preference_assignments,%s count=%di %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"preference": pref}, "=", ","), s.AssignmentsByPreference[pref], ts)
+	}
+	for _, phase := range FlightPhases {
+		fmt.Fprintf(buf, "%s_flight_phase,%s count=%di %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"phase": string(phase)}, "=", ","), s.FlightPhases[phase], ts)
+	}
+	eta := p.cfg.Prefix + "_eta"
+	if tags != "" {
+		eta += "," + tags
+	}
+	fmt.Fprintf(buf, "%s landings=%di,error_seconds_avg=%f,abs_error_seconds_avg=%f,abs_error_seconds_p50=%f,abs_error_seconds_p90=%f,abs_error_seconds_p99=%f %d\n",
+		eta, s.ETA.Landings, s.ETA.MeanErrorSeconds, s.ETA.MeanAbsErrorSeconds, s.ETA.P50AbsErrorSeconds, s.ETA.P90AbsErrorSeconds, s.ETA.P99AbsErrorSeconds, ts)
+	for _, cause := range sortedKeys(s.DelaySeconds) {
+		fmt.Fprintf(buf, "%s_delay,%s seconds=%f %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"cause": cause}, "=", ","), s.DelaySeconds[cause], ts)
+	}
+	for _, name := range sortedKeys(s.Custom) {
+		fmt.Fprintf(buf, "%s_custom,%s value=%f %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"name": name}, "=", ","), s.Custom[name], ts)
+	}
+	for _, id := range sortedKeys(s.SLAAttainment) {
+		fmt.Fprintf(buf, "%s_sla,%s attainment=%f %d\n",
+			p.cfg.Prefix, joinTags(p.cfg.Tags, map[string]string{"sla": id}, "=", ","), s.SLAAttainment[id], ts)
+	}
+}
+
+func joinTags(base, extra map[string]string, kv, sep string) string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	parts := make([]string, 0, len(merged))
+	for _, k := range sortedKeys(merged) {
+		parts = append(parts, k+kv+merged[k])
+	}
+	return strings.Join(parts, sep)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,206 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTConfig configures the MQTT bridge for display boards.
+type MQTTConfig struct {
+	// Broker is the broker URL, as in tcp://localhost:1883.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// Prefix roots the topic tree, "aircommand" by default.
+	Prefix string
+	// Interval is how often the state is compared and changes published,
+	// 5s by default.
+	Interval time.Duration
+	// Arrivals is how many next arrivals are published, 5 by default.
+	Arrivals int
+	QoS      byte
+}
+
+// MQTTArrival is one of the next arrivals published by the MQTT bridge.
+type MQTTArrival struct {
+	Call   string    `json:"call"`
+	Runway string    `json:"runway"`
+	DueAt  time.Time `json:"dueAt"`
+}
+
+// MQTTBridge publishes key state to an MQTT broker as retained messages so
+// flip-dot boards and embedded displays can subscribe to just what they
+// show. Under the prefix it publishes:
+//
+//	delays/average       mean arrival wait in whole seconds
+//	delays/holding       flights holding
+//	runways/active       available runways, comma separated
+//	runways/{name}       "open", "closed" or "unavailable"
+//	runways/{name}/queue arrivals sequenced to the runway
+//	arrivals/next        the next arrivals as a JSON array of MQTTArrival
+//	arrivals/{n}         the nth next arrival as "CALL RUNWAY HH:MM", empty
+//	                     when there is none
+//
+// Only topics whose payload changed are published.
+type MQTTBridge struct {
+	cfg    MQTTConfig
+	server *Server
+	client mqtt.Client
+
+	// mu serializes publishing; last holds each topic's payload as last
+	// published.
+	mu   sync.Mutex
+	last map[string]string
+}
+
+// NewMQTTBridge validates the configuration; Run connects to the broker.
+func NewMQTTBridge(server *Server, cfg MQTTConfig) (*MQTTBridge, error) {
+	if cfg.Broker == "" {
+		return nil, errors.New("broker missing")
+	}
+	if cfg.QoS > 2 {
+		return nil, fmt.Errorf("qos %d not in 0-2", cfg.QoS)
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "aircommand"
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	if cfg.Prefix == "" {
+		cfg.Prefix = "aircommand"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Arrivals <= 0 {
+		cfg.Arrivals = 5
+	}
+	b := &MQTTBridge{cfg: cfg, server: server, last: make(map[string]string)}
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		// Republish everything after a reconnect in case the broker lost
+		// the retained messages.
+		SetOnConnectHandler(func(mqtt.Client) { b.reset() }).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) { log.Printf("mqtt: connection lost: %v", err) })
+	b.client = mqtt.NewClient(opts)
+	return b, nil
+}
+
+// Run connects and publishes on every interval until the context is
+// canceled.
+func (b *MQTTBridge) Run(ctx context.Context) {
+	// With ConnectRetry the token completes once connected or on the first
+	// failure, and the client keeps retrying in the background.
+	if token := b.client.Connect(); token.Wait() && token.Error() != nil {
+		log.Printf("mqtt: %v", token.Error())
+	}
+	defer b.client.Disconnect(250)
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	log.Printf("publishing state to %s under %s/ every %s", b.cfg.Broker, b.cfg.Prefix, b.cfg.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if b.client.IsConnectionOpen() {
+				b.publish()
+			}
+		}
+	}
+}
+
+// reset forgets what was published and publishes every topic afresh. It
+// publishes on its own goroutine: the client's callback goroutine would
+// block on a publish waiting for acknowledgement.
+func (b *MQTTBridge) reset() {
+	b.mu.Lock()
+	clear(b.last)
+	b.mu.Unlock()
+	go b.publish()
+}
+
+// topics returns the payload of every topic for the current state, keyed
+// by topic under the prefix.
+func (b *MQTTBridge) topics() map[string]string {
+	snap := b.server.Snapshot()
+	topics := make(map[string]string)
+	if snap.Metrics != nil {
+		topics["delays/average"] = strconv.FormatFloat(snap.Metrics.AverageWaitSeconds, 'f', 0, 64)
+	}
+	topics["delays/holding"] = strconv.Itoa(len(snap.Holding))
+
+	var active []string
+	var arrivals []MQTTArrival
+	for _, r := range snap.Runways {
+		status := "open"
+		switch {
+		case r.Closed:
+			status = "closed"
+		case !r.Available:
+			status = "unavailable"
+		default:
+			active = append(active, r.Name)
+		}
+		topics["runways/"+r.Name] = status
+		topics["runways/"+r.Name+"/queue"] = strconv.Itoa(len(r.Arrivals))
+		for _, a := range r.Arrivals {
+			if a.DueAt != nil {
+				arrivals = append(arrivals, MQTTArrival{Call: a.Flight.Call, Runway: r.Name, DueAt: *a.DueAt})
+			}
+		}
+	}
+	topics["runways/active"] = strings.Join(active, ",")
+
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i].DueAt.Before(arrivals[j].DueAt) })
+	arrivals = arrivals[:min(len(arrivals), b.cfg.Arrivals)]
+	next, _ := json.Marshal(append([]MQTTArrival{}, arrivals...))
+	topics["arrivals/next"] = string(next)
+	for i := range b.cfg.Arrivals {
+		line := ""
+		if i < len(arrivals) {
+			a := arrivals[i]
+			line = fmt.Sprintf("%s %s %s", a.Call, a.Runway, a.DueAt.Format("15:04"))
+		}
+		topics["arrivals/"+strconv.Itoa(i+1)] = line
+	}
+	return topics
+}
+
+// publish sends the topics whose payload changed since last published.
+func (b *MQTTBridge) publish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	topics := b.topics()
+	for _, topic := range sortedKeys(topics) {
+		payload := topics[topic]
+		if last, ok := b.last[topic]; ok && last == payload {
+			continue
+		}
+		token := b.client.Publish(b.cfg.Prefix+"/"+topic, b.cfg.QoS, true, payload)
+		if !token.WaitTimeout(b.cfg.Interval) {
+			log.Printf("mqtt: publish %s: timed out", topic)
+			continue
+		}
+		if err := token.Error(); err != nil {
+			log.Printf("mqtt: publish %s: %v", topic, err)
+			continue
+		}
+		b.last[topic] = payload
+	}
+}
@@ -0,0 +1,213 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+// Telemetry packet types.
+const (
+	TelemetryState   = "state"
+	TelemetryFlights = "flights"
+	TelemetryEvent   = "event"
+)
+
+// maxTelemetryPacket keeps each datagram within a typical Ethernet MTU so
+// it is never fragmented.
+const maxTelemetryPacket = 1400
+
+// MulticastConfig configures the LAN telemetry broadcaster.
+type MulticastConfig struct {
+	// Group is the multicast address and port, 239.255.42.42:4242 by
+	// default.
+	Group string
+	// Interval is how often the state and flights are sent, 1s by default.
+	Interval time.Duration
+	// TTL bounds the router hops, 1 by default to stay on the LAN.
+	TTL int
+	// Interface names the network interface to send on; empty lets the
+	// system choose.
+	Interface string
+}
+
+// TelemetryPacket is one JSON datagram of the multicast telemetry. A state
+// packet summarizes the runways; the flight countdowns follow in flights
+// packets numbered Part of Parts; an event packet carries each event as it
+// is published. Seq increases by one per packet so receivers can spot
+// losses.
+type TelemetryPacket struct {
+	Type       string            `json:"type"`
+	Seq        uint64            `json:"seq"`
+	Time       time.Time         `json:"time"`
+	Version    int64             `json:"version,omitempty"`
+	Rate       int64             `json:"rate,omitempty"`
+	Wind       *WindState        `json:"wind,omitempty"`
+	Visibility int64             `json:"visibility,omitempty"`
+	Runways    []TelemetryRunway `json:"runways,omitempty"`
+	Holding    int               `json:"holding,omitempty"`
+	Flights    []FlightTimer     `json:"flights,omitempty"`
+	Part       int               `json:"part,omitempty"`
+	Parts      int               `json:"parts,omitempty"`
+	Event      *Event            `json:"event,omitempty"`
+}
+
+// TelemetryRunway is one runway within a state packet.
+type TelemetryRunway struct {
+	Name      string  `json:"name"`
+	Available bool    `json:"available"`
+	Heading   float64 `json:"heading"`
+	ActiveEnd string  `json:"activeEnd"`
+	Arrivals  int     `json:"arrivals"`
+}
+
+// MulticastBroadcaster sends the simulation state to a UDP multicast group
+// so any number of display machines on a LAN can follow it without
+// connecting.
+type MulticastBroadcaster struct {
+	cfg    MulticastConfig
+	server *Server
+	conn   *ipv4.PacketConn
+	group  *net.UDPAddr
+	seq    uint64
+}
+
+// NewMulticastBroadcaster validates the configuration and opens the
+// sending socket.
+func NewMulticastBroadcaster(server *Server, cfg MulticastConfig) (*MulticastBroadcaster, error) {
+	if cfg.Group == "" {
+		cfg.Group = "239.255.42.42:4242"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 1
+	}
+	group, err := net.ResolveUDPAddr("udp4", cfg.Group)
+	if err != nil {
+		return nil, fmt.Errorf("group %s: %w", cfg.Group, err)
+	}
+	if !group.IP.IsMulticast() {
+		return nil, fmt.Errorf("group %s is not a multicast address", cfg.Group)
+	}
+	c, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	conn := ipv4.NewPacketConn(c)
+	if err := conn.SetMulticastTTL(cfg.TTL); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("ttl: %w", err)
+	}
+	if err := conn.SetMulticastLoopback(true); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("loopback: %w", err)
+	}
+	if cfg.Interface != "" {
+		ifi, err := net.InterfaceByName(cfg.Interface)
+		if err == nil {
+			err = conn.SetMulticastInterface(ifi)
+		}
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("interface %s: %w", cfg.Interface, err)
+		}
+	}
+	return &MulticastBroadcaster{cfg: cfg, server: server, conn: conn, group: group}, nil
+}
+
+// Run broadcasts the state on every interval and each event as it is
+// published until the context is canceled.
+func (b *MulticastBroadcaster) Run(ctx context.Context) {
+	defer b.conn.Close()
+	var events <-chan Event
+	if b.server.Events != nil {
+		ch, unsubscribe := b.server.Events.Subscribe(eventBufferSize)
+		defer unsubscribe()
+		events = ch
+	}
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	log.Printf("multicasting telemetry to %s every %s", b.group, b.cfg.Interval)
+	b.broadcastState()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.broadcastState()
+		case e := <-events:
+			b.send(TelemetryPacket{Type: TelemetryEvent, Time: e.Time, Event: &e})
+		}
+	}
+}
+
+// broadcastState sends a state packet followed by the flight countdowns,
+// split across as many flights packets as it takes.
+func (b *MulticastBroadcaster) broadcastState() {
+	snap := b.server.Snapshot()
+	state := TelemetryPacket{
+		Type:       TelemetryState,
+		Time:       snap.Time,
+		Version:    snap.Version,
+		Rate:       snap.Rate,
+		Wind:       &snap.Wind,
+		Visibility: snap.Visibility.Meters,
+		Runways:    make([]TelemetryRunway, 0, len(snap.Runways)),
+		Holding:    len(snap.Holding),
+	}
+	for _, r := range snap.Runways {
+		state.Runways = append(state.Runways, TelemetryRunway{Name: r.Name, Available: r.Available, Heading: r.Heading, ActiveEnd: r.ActiveEnd, Arrivals: len(r.Arrivals)})
+	}
+	b.send(state)
+	if b.server.Runways == nil {
+		return
+	}
+	timers := b.server.Runways.Timers()
+	parts := splitTelemetry(timers.Flights)
+	for i, flights := range parts {
+		b.send(TelemetryPacket{Type: TelemetryFlights, Time: timers.ServerTime, Flights: flights, Part: i + 1, Parts: len(parts)})
+	}
+}
+
+// splitTelemetry groups flights so each group fits a flights packet.
+func splitTelemetry(flights []FlightTimer) [][]FlightTimer {
+	// Leave room for the packet's other fields.
+	const budget = maxTelemetryPacket - 160
+	var parts [][]FlightTimer
+	start, size := 0, 0
+	for i, f := range flights {
+		raw, _ := json.Marshal(f)
+		if size+len(raw)+1 > budget && i > start {
+			parts = append(parts, flights[start:i])
+			start, size = i, 0
+		}
+		size += len(raw) + 1
+	}
+	if start < len(flights) {
+		parts = append(parts, flights[start:])
+	}
+	return parts
+}
+
+func (b *MulticastBroadcaster) send(p TelemetryPacket) {
+	b.seq++
+	p.Seq = b.seq
+	raw, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("multicast: %v", err)
+		return
+	}
+	if len(raw) > maxTelemetryPacket {
+		log.Printf("multicast: %s packet of %d bytes may be fragmented", p.Type, len(raw))
+	}
+	if _, err := b.conn.WriteTo(raw, nil, b.group); err != nil {
+		log.Printf("multicast: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package control
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// noiseSectors names the compass sectors noise exposure is reported for,
+// clockwise from north, each 45° wide.
+var noiseSectors = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// noiseSideWeight is the share of a movement's exposure felt in the sectors
+// either side of the one under the approach path.
+const noiseSideWeight = 0.25
+
+// RunwayUsage counts landings on a runway in one direction.
+type RunwayUsage struct {
+	Runway   string  `json:"runway"`
+	Heading  float64 `json:"heading"`
+	Landings int64   `json:"landings"`
+}
+
+// NoiseSector is the rough noise exposure of one compass sector. Movements
+// weighs each overflight by how directly it passes over the sector; Score
+// expresses that on a decibel-like log scale so configurations can be
+// compared.
+type NoiseSector struct {
+	Sector    string  `json:"sector"`
+	Bearing   float64 `json:"bearing"`
+	Movements float64 `json:"movements"`
+	Score     float64 `json:"score"`
+}
+
+// NoiseReport is runway usage and the resulting exposure per sector.
+type NoiseReport struct {
+	Usage   []RunwayUsage `json:"usage"`
+	Sectors []NoiseSector `json:"sectors"`
+}
+
+// NoiseFootprint estimates the exposure per compass sector for a runway
+// configuration. Arrivals landing on heading h fly their approach over the
+// sector at bearing h+180 from the airport, which takes most of the noise;
+// the neighbouring sectors take a smaller share.
+func NoiseFootprint(usage []RunwayUsage) []NoiseSector {
+	n := len(noiseSectors)
+	width := 360 / float64(n)
+	sectors := make([]NoiseSector, n)
+	for i, name := range noiseSectors {
+		sectors[i] = NoiseSector{Sector: name, Bearing: float64(i) * width}
+	}
+	for _, u := range usage {
+		approach := normalizeHeading(u.Heading + 180)
+		idx := int(math.Round(approach/width)) % n
+		landings := float64(u.Landings)
+		sectors[idx].Movements += landings
+		sectors[(idx+1)%n].Movements += landings * noiseSideWeight
+		sectors[(idx+n-1)%n].Movements += landings * noiseSideWeight
+	}
+	for i := range sectors {
+		sectors[i].Score = 10 * math.Log10(1+sectors[i].Movements)
+	}
+	return sectors
+}
+
+// recordUsageLocked counts a landing on runway in its current direction.
+func (rm *RunwayManager) recordUsageLocked(runway string) {
+	key := runwayDirection{runway: runway, heading: rm.runways[runway].activeHeading}
+	rm.usage[key]++
+}
+
+// runwayDirection identifies one landing direction of a runway.
+type runwayDirection struct {
+	runway  string
+	heading float64
+}
+
+// RunwayUsage lists landings so far by runway and direction.
+func (rm *RunwayManager) RunwayUsage() []RunwayUsage {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayUsage, 0, len(rm.usage))
+	for key, n := range rm.usage {
+		out = append(out, RunwayUsage{Runway: key.runway, Heading: key.heading, Landings: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Runway == out[j].Runway {
+			return out[i].Heading < out[j].Heading
+		}
+		return out[i].Runway < out[j].Runway
+	})
+	return out
+}
+
+// HandleNoise reports the noise footprint of the runway usage so far (GET),
+// or of a hypothetical usage posted as a list of RunwayUsage (POST) so
+// configurations can be compared.
+func (s *Server) HandleNoise(w http.ResponseWriter, r *http.Request) {
+	var usage []RunwayUsage
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&usage); err != nil {
+			http.Error(w, "invalid runway usage: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		if s.Runways == nil {
+			http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		usage = s.Runways.RunwayUsage()
+	}
+	if usage == nil {
+		usage = []RunwayUsage{}
+	}
+	writeJSON(w, http.StatusOK, NoiseReport{Usage: usage, Sectors: NoiseFootprint(usage)})
+}
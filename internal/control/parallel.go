@@ -0,0 +1,129 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Simultaneous approach modes for parallel runways.
+const (
+	// ParallelIndependent sequences each parallel runway on its own.
+	ParallelIndependent = "independent"
+	// ParallelDependent staggers arrivals on adjacent parallels by
+	// dependentStagger.
+	ParallelDependent = "dependent"
+	// ParallelSingleStream spaces arrivals across all parallels as one
+	// stream, as if they shared a runway.
+	ParallelSingleStream = "singleStream"
+)
+
+var parallelModes = []string{ParallelIndependent, ParallelDependent, ParallelSingleStream}
+
+const (
+	// parallelTolerance is how far apart, in degrees, runway headings may be
+	// for the runways to count as parallel.
+	parallelTolerance = 5
+	// dependentStagger is the diagonal spacing behind the last arrival on
+	// an adjacent parallel in dependent mode.
+	dependentStagger = minArrivalSpacing / 2
+)
+
+// SetParallelMode selects how arrivals to parallel runways are coupled.
+func (rm *RunwayManager) SetParallelMode(mode string) error {
+	if !slices.Contains(parallelModes, mode) {
+		return fmt.Errorf("unknown parallel approach mode %q", mode)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.parallelMode = mode
+	log.Printf("parallel approach mode: %s", mode)
+	return nil
+}
+
+// parallelStagger is the spacing behind the last arrival on another runway
+// of the same parallel group.
+func (rm *RunwayManager) parallelStagger() time.Duration {
+	switch rm.parallelMode {
+	case ParallelDependent:
+		return dependentStagger
+	case ParallelSingleStream:
+		return rm.arrivalSpacingLocked()
+	}
+	return 0
+}
+
+// parallelGroupLocked lists the runways sequenced together with runway. In
+// independent mode a runway is sequenced alone.
+func (rm *RunwayManager) parallelGroupLocked(runway string) []string {
+	if rm.parallelMode == ParallelIndependent {
+		return []string{runway}
+	}
+	return rm.parallelsLocked(runway)
+}
+
+// parallelsLocked lists runway and the runways parallel to it, in runway
+// order.
+func (rm *RunwayManager) parallelsLocked(runway string) []string {
+	heading := rm.runways[runway].definition.Heading
+	var group []string
+	for _, name := range rm.order {
+		if angularDiff(heading, rm.runways[name].definition.Heading) <= parallelTolerance {
+			group = append(group, name)
+		}
+	}
+	return group
+}
+
+// ParallelApproaches is the simultaneous approach mode with the runways it
+// couples.
+type ParallelApproaches struct {
+	Mode string `json:"mode"`
+	// Groups lists sets of parallel runways; it is ignored when setting the
+	// mode.
+	Groups [][]string `json:"groups,omitempty"`
+}
+
+// ParallelApproaches reports the parallel approach mode and runway groups.
+func (rm *RunwayManager) ParallelApproaches() ParallelApproaches {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	state := ParallelApproaches{Mode: rm.parallelMode}
+	seen := make(map[string]bool, len(rm.order))
+	for _, name := range rm.order {
+		if seen[name] {
+			continue
+		}
+		group := rm.parallelsLocked(name)
+		for _, other := range group {
+			seen[other] = true
+		}
+		if len(group) > 1 {
+			state.Groups = append(state.Groups, group)
+		}
+	}
+	return state
+}
+
+// HandleParallel reports (GET) or selects (PUT) the parallel approach mode.
+func (s *Server) HandleParallel(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req ParallelApproaches
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid parallel approaches: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetParallelMode(req.Mode); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.ParallelApproaches())
+}
@@ -0,0 +1,420 @@
+package control
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// This file writes Apache Parquet files: uncompressed, PLAIN-encoded, one
+// data page per column chunk, with the footer in the Thrift compact protocol.
+// It covers the flat schemas the exports need and no more.
+
+const parquetMagic = "PAR1"
+
+// parquetKind is the logical type of a column.
+type parquetKind int
+
+const (
+	parquetBool parquetKind = iota
+	parquetInt
+	parquetFloat
+	parquetString
+	parquetTimestamp
+)
+
+// physical returns the Parquet physical type of the kind and its converted
+// type, or -1 for none.
+func (k parquetKind) physical() (typ, converted int32) {
+	switch k {
+	case parquetBool:
+		return 0, -1 // BOOLEAN
+	case parquetInt:
+		return 2, -1 // INT64
+	case parquetFloat:
+		return 5, -1 // DOUBLE
+	case parquetString:
+		return 6, 0 // BYTE_ARRAY, UTF8
+	default:
+		return 2, 9 // INT64, TIMESTAMP_MILLIS
+	}
+}
+
+// Parquet enums used here.
+const (
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+)
+
+// parquetColumn describes one column of a flat schema. value returns the
+// column's value for a row as a bool, int64, float64, string or time.Time,
+// or nil for null, which only optional columns may hold.
+type parquetColumn[T any] struct {
+	name     string
+	kind     parquetKind
+	optional bool
+	value    func(T) any
+}
+
+// parquetChunk is where a written column chunk lies in the file.
+type parquetChunk struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+// parquetWriter writes rows of T in row groups, finishing the file on Close.
+type parquetWriter[T any] struct {
+	w       *bufio.Writer
+	offset  int64
+	columns []parquetColumn[T]
+	groups  [][]parquetChunk
+	counts  []int64
+	err     error
+}
+
+func newParquetWriter[T any](w io.Writer, columns []parquetColumn[T]) *parquetWriter[T] {
+	pw := &parquetWriter[T]{w: bufio.NewWriter(w), columns: columns}
+	pw.write([]byte(parquetMagic))
+	return pw
+}
+
+func (pw *parquetWriter[T]) write(p []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	pw.err = err
+}
+
+// WriteRowGroup writes rows as one row group.
+func (pw *parquetWriter[T]) WriteRowGroup(rows []T) error {
+	if len(rows) == 0 || pw.err != nil {
+		return pw.err
+	}
+	chunks := make([]parquetChunk, len(pw.columns))
+	for i, col := range pw.columns {
+		page, err := encodeParquetPage(col, rows)
+		if err != nil {
+			return err
+		}
+		header := parquetPageHeader(len(rows), len(page))
+		chunks[i] = parquetChunk{offset: pw.offset, size: int64(len(header) + len(page)), values: int64(len(rows))}
+		pw.write(header)
+		pw.write(page)
+	}
+	pw.groups = append(pw.groups, chunks)
+	pw.counts = append(pw.counts, int64(len(rows)))
+	return pw.err
+}
+
+// Close writes the footer. It does not close the underlying writer.
+func (pw *parquetWriter[T]) Close() error {
+	footer := pw.footer()
+	pw.write(footer)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	pw.write(size[:])
+	pw.write([]byte(parquetMagic))
+	if pw.err != nil {
+		return pw.err
+	}
+	return pw.w.Flush()
+}
+
+// encodeParquetPage encodes a column's values, preceded by definition levels
+// when the column is optional.
+func encodeParquetPage[T any](col parquetColumn[T], rows []T) ([]byte, error) {
+	var data bytes.Buffer
+	defined := make([]bool, len(rows))
+	var bits, nbits byte
+	for i, row := range rows {
+		v := col.value(row)
+		if v == nil {
+			if !col.optional {
+				return nil, errors.New("parquet: null in required column " + col.name)
+			}
+			continue
+		}
+		defined[i] = true
+		switch x := v.(type) {
+		case bool:
+			if x {
+				bits |= 1 << nbits
+			}
+			if nbits++; nbits == 8 {
+				data.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		case int64:
+			binary.Write(&data, binary.LittleEndian, x)
+		case float64:
+			binary.Write(&data, binary.LittleEndian, math.Float64bits(x))
+		case time.Time:
+			binary.Write(&data, binary.LittleEndian, x.UnixMilli())
+		case string:
+			binary.Write(&data, binary.LittleEndian, uint32(len(x)))
+			data.WriteString(x)
+		default:
+			return nil, errors.New("parquet: unsupported value in column " + col.name)
+		}
+	}
+	if nbits > 0 {
+		data.WriteByte(bits)
+	}
+	if !col.optional {
+		return data.Bytes(), nil
+	}
+	levels := parquetLevels(defined)
+	page := make([]byte, 4, 4+len(levels)+data.Len())
+	binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+	page = append(page, levels...)
+	return append(page, data.Bytes()...), nil
+}
+
+// parquetLevels encodes definition levels of bit width one as RLE runs.
+func parquetLevels(defined []bool) []byte {
+	var out []byte
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if defined[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+func parquetPageHeader(values, size int) []byte {
+	var t thriftWriter
+	t.i32(1, 0) // DATA_PAGE
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.beginStruct(5)
+	t.i32(1, int32(values))
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.endStruct()
+	t.stop()
+	return t.buf.Bytes()
+}
+
+func (pw *parquetWriter[T]) footer() []byte {
+	var rows int64
+	for _, n := range pw.counts {
+		rows += n
+	}
+	var t thriftWriter
+	t.i32(1, 1)
+	t.beginList(2, thriftStruct, len(pw.columns)+1)
+	t.string(4, "schema")
+	t.i32(5, int32(len(pw.columns)))
+	t.stop()
+	for _, col := range pw.columns {
+		typ, converted := col.kind.physical()
+		t.i32(1, typ)
+		repetition := int32(parquetRequired)
+		if col.optional {
+			repetition = parquetOptional
+		}
+		t.i32(3, repetition)
+		t.string(4, col.name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.stop()
+	}
+	t.endList()
+	t.i64(3, rows)
+	t.beginList(4, thriftStruct, len(pw.groups))
+	for g, chunks := range pw.groups {
+		t.beginList(1, thriftStruct, len(chunks))
+		var total int64
+		for i, chunk := range chunks {
+			col := pw.columns[i]
+			typ, _ := col.kind.physical()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, typ)
+			t.beginList(2, thriftI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.endList()
+			t.beginList(3, thriftBinary, 1)
+			t.listString(col.name)
+			t.endList()
+			t.i32(4, 0) // UNCOMPRESSED
+			t.i64(5, chunk.values)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.stop()
+			total += chunk.size
+		}
+		t.endList()
+		t.i64(2, total)
+		t.i64(3, pw.counts[g])
+		t.stop()
+	}
+	t.endList()
+	t.string(6, "aircommand")
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol. Fields must
+// be written in increasing id order; stop ends a struct, including each
+// struct element of a list.
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16
+	outer []int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(v<<1)^uint64(v>>63)))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) string(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listString(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.outer = append(t.outer, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.last, t.outer = t.outer[len(t.outer)-1], t.outer[:len(t.outer)-1]
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		t.buf.WriteByte(0xf0 | elem)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+	t.outer = append(t.outer, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endList() {
+	t.last, t.outer = t.outer[len(t.outer)-1], t.outer[:len(t.outer)-1]
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) listString(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+	t.last = 0
+}
+
+// flightRowGroup is the number of flights written per Parquet row group.
+const flightRowGroup = 64 * 1024
+
+// flightColumns are the columns of a flight export.
+var flightColumns = []parquetColumn[FlightHistory]{
+	{"flightId", parquetInt, false, func(f FlightHistory) any { return f.FlightID }},
+	{"call", parquetString, false, func(f FlightHistory) any { return f.Call }},
+	{"runway", parquetString, true, func(f FlightHistory) any { return optionalString(f.Runway) }},
+	{"route", parquetString, false, func(f FlightHistory) any { return strings.Join(f.Route, ">") }},
+	{"outcome", parquetString, false, func(f FlightHistory) any { return f.Outcome }},
+	{"spawnedAt", parquetTimestamp, true, func(f FlightHistory) any { return optionalTime(f.SpawnedAt) }},
+	{"landedAt", parquetTimestamp, true, func(f FlightHistory) any { return optionalTime(f.LandedAt) }},
+	{"waitSeconds", parquetFloat, true, func(f FlightHistory) any { return optionalFloat(f.WaitSeconds) }},
+	{"holdingSeconds", parquetFloat, false, func(f FlightHistory) any { return f.HoldingSeconds }},
+	{"holdingCount", parquetInt, false, func(f FlightHistory) any { return int64(f.HoldingCount) }},
+	{"goArounds", parquetInt, false, func(f FlightHistory) any { return int64(f.GoArounds) }},
+	{"diverted", parquetBool, false, func(f FlightHistory) any { return f.Outcome == "diverted" }},
+}
+
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func optionalFloat(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// WriteFlightsParquet writes one row per flight to w as a Parquet file, with
+// the route as runway and step names joined by ">".
+func WriteFlightsParquet(w io.Writer, flights []FlightHistory) error {
+	pw := newParquetWriter(w, flightColumns)
+	for start := 0; start < len(flights); start += flightRowGroup {
+		if err := pw.WriteRowGroup(flights[start:min(start+flightRowGroup, len(flights))]); err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
@@ -0,0 +1,249 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plugin"
+	"sync"
+	"time"
+)
+
+// FlightSpawner builds the flight for a newly issued ID.
+type FlightSpawner interface {
+	Spawn(id int64, now time.Time) Flight
+}
+
+// RunwayCandidate describes an open runway offered to an AssignmentStrategy.
+type RunwayCandidate struct {
+	Name        string
+	Heading     float64
+	QueueLength int
+	LastUse     time.Time
+	// Preference is how favored the runway is, and Weight the share of
+	// arrivals that preference draws. Strategies should keep last-resort
+	// runways for when no other candidate is offered.
+	Preference string
+	Weight     float64
+}
+
+// AssignmentStrategy chooses a runway for a flight among the open candidates.
+// Returning an empty name defers the flight to holding until the next
+// landing, when it is offered again. Strategies are called
+// with the scheduler lock held and must not call back into the RunwayManager.
+type AssignmentStrategy interface {
+	SelectRunway(f Flight, candidates []RunwayCandidate) string
+}
+
+// EventSink receives every event published on the bus.
+type EventSink interface {
+	HandleEvent(e Event)
+}
+
+// WeatherSource drives the wind until the context is canceled by calling
+// apply with each new observation.
+type WeatherSource interface {
+	Run(ctx context.Context, apply func(WindState))
+}
+
+// Registry holds named extensions contributed by plugins or by programs that
+// embed the simulator.
+type Registry struct {
+	mu         sync.Mutex
+	spawners   map[string]FlightSpawner
+	strategies map[string]AssignmentStrategy
+	gates      map[string]GateStrategy
+	sinks      map[string]EventSink
+	weather    map[string]WeatherSource
+	clearing   map[string]ClearingMechanism
+}
+
+// NewRegistry returns a registry pre-populated with the built-in extensions.
+func NewRegistry() *Registry {
+	r := &Registry{
+		spawners:   make(map[string]FlightSpawner),
+		strategies: make(map[string]AssignmentStrategy),
+		gates:      make(map[string]GateStrategy),
+		sinks:      make(map[string]EventSink),
+		weather:    make(map[string]WeatherSource),
+		clearing:   make(map[string]ClearingMechanism),
+	}
+	r.RegisterStrategy("round-robin", &RoundRobinStrategy{})
+	r.RegisterGateStrategy("nearest", NearestGateStrategy{})
+	r.RegisterGateStrategy("airline", AirlineGateStrategy{})
+	r.RegisterGateStrategy("best-fit", BestFitGateStrategy{})
+	r.RegisterClearing("first-price", FirstPriceClearing{})
+	r.RegisterClearing("second-price", SecondPriceClearing{})
+	return r
+}
+
+// RegisterSpawner adds a named flight spawner.
+func (r *Registry) RegisterSpawner(name string, s FlightSpawner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spawners[name] = s
+}
+
+// RegisterStrategy adds a named assignment strategy.
+func (r *Registry) RegisterStrategy(name string, s AssignmentStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies[name] = s
+}
+
+// RegisterGateStrategy adds a named stand allocation strategy.
+func (r *Registry) RegisterGateStrategy(name string, s GateStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gates[name] = s
+}
+
+// RegisterSink adds a named event sink.
+func (r *Registry) RegisterSink(name string, s EventSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks[name] = s
+}
+
+// RegisterWeather adds a named weather source.
+func (r *Registry) RegisterWeather(name string, s WeatherSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weather[name] = s
+}
+
+// RegisterClearing adds a named slot auction clearing mechanism.
+func (r *Registry) RegisterClearing(name string, c ClearingMechanism) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clearing[name] = c
+}
+
+// Spawner looks up a flight spawner by name.
+func (r *Registry) Spawner(name string) (FlightSpawner, error) {
+	return lookup(r, r.spawners, "spawner", name)
+}
+
+// Strategy looks up an assignment strategy by name.
+func (r *Registry) Strategy(name string) (AssignmentStrategy, error) {
+	return lookup(r, r.strategies, "strategy", name)
+}
+
+// GateStrategy looks up a stand allocation strategy by name.
+func (r *Registry) GateStrategy(name string) (GateStrategy, error) {
+	return lookup(r, r.gates, "gate strategy", name)
+}
+
+// Sink looks up an event sink by name.
+func (r *Registry) Sink(name string) (EventSink, error) {
+	return lookup(r, r.sinks, "event sink", name)
+}
+
+// Weather looks up a weather source by name.
+func (r *Registry) Weather(name string) (WeatherSource, error) {
+	return lookup(r, r.weather, "weather source", name)
+}
+
+// Clearing looks up a slot auction clearing mechanism by name.
+func (r *Registry) Clearing(name string) (ClearingMechanism, error) {
+	return lookup(r, r.clearing, "clearing mechanism", name)
+}
+
+// Strategies lists the registered strategy names.
+func (r *Registry) Strategies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sortedKeys(r.strategies)
+}
+
+func lookup[T any](r *Registry, m map[string]T, kind, name string) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := m[name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("unknown %s %q (registered: %v)", kind, name, sortedKeys(m))
+	}
+	return v, nil
+}
+
+// LoadPlugin opens a Go plugin built with -buildmode=plugin and calls its
+// exported Register(*control.Registry) function.
+func LoadPlugin(path string, r *Registry) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("open plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup("Register")
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	register, ok := sym.(func(*Registry))
+	if !ok {
+		return fmt.Errorf("plugin %s: Register has type %T, want func(*control.Registry)", path, sym)
+	}
+	register(r)
+	log.Printf("loaded plugin %s", path)
+	return nil
+}
+
+// RunEventSink delivers bus events to the sink until the context is canceled.
+func RunEventSink(ctx context.Context, bus *EventBus, sink EventSink) {
+	events, unsubscribe := bus.Subscribe(eventBufferSize)
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			sink.HandleEvent(e)
+		}
+	}
+}
+
+// RunWeather feeds a weather source into the runway manager's wind.
+func RunWeather(ctx context.Context, src WeatherSource, rm *RunwayManager) {
+	src.Run(ctx, func(w WindState) {
+		rm.SetWindState(w)
+	})
+}
+
+// RoundRobinStrategy rotates through the open runways in order. Runways of
+// unequal preference are rotated by smooth weighted round-robin, so each
+// draws arrivals in proportion to its weight without bunching them.
+type RoundRobinStrategy struct {
+	next    int
+	current map[string]float64
+}
+
+// SelectRunway implements AssignmentStrategy.
+func (s *RoundRobinStrategy) SelectRunway(f Flight, candidates []RunwayCandidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	candidates = viableCandidates(candidates)
+	if !uniformWeights(candidates) {
+		return s.selectWeighted(candidates)
+	}
+	runway := candidates[s.next%len(candidates)].Name
+	s.next++
+	return runway
+}
+
+func (s *RoundRobinStrategy) selectWeighted(candidates []RunwayCandidate) string {
+	if s.current == nil {
+		s.current = make(map[string]float64)
+	}
+	var total float64
+	best := -1
+	for i, c := range candidates {
+		s.current[c.Name] += c.Weight
+		total += c.Weight
+		if best < 0 || s.current[c.Name] > s.current[candidates[best].Name] {
+			best = i
+		}
+	}
+	runway := candidates[best].Name
+	s.current[runway] -= total
+	return runway
+}
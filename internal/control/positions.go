@@ -0,0 +1,102 @@
+package control
+
+import (
+	"math"
+	"net/http"
+	"time"
+)
+
+// positionInterval is how often position streams send a fresh snapshot,
+// fast enough for smooth map animation.
+const positionInterval = 250 * time.Millisecond
+
+// AircraftPosition is where a sequenced arrival is on its approach:
+// Position in nautical miles from the airfield, Distance the miles still to
+// fly to the threshold and Track the approach course in degrees true.
+type AircraftPosition struct {
+	FlightID int64   `json:"flightId"`
+	Call     string  `json:"call"`
+	Runway   string  `json:"runway"`
+	Position Point   `json:"position"`
+	Distance float64 `json:"distance"`
+	Track    float64 `json:"track"`
+}
+
+// PositionState is every sequenced arrival's position at Time.
+type PositionState struct {
+	Time     time.Time          `json:"time"`
+	Aircraft []AircraftPosition `json:"aircraft"`
+}
+
+// Positions places each sequenced arrival on the approach to its runway.
+// Arrivals join the approach intermediateNM out when assigned and fly it
+// at a steady speed to touch down when due. Holding flights are not
+// placed.
+func (rm *RunwayManager) Positions() PositionState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := rm.clock.Now()
+	state := PositionState{Time: now, Aircraft: []AircraftPosition{}}
+	for _, name := range rm.order {
+		track := rm.trueHeadingLocked(name)
+		for _, f := range rm.assigned[name] {
+			due, ok := rm.dueAt[f.ID]
+			if !ok {
+				continue
+			}
+			distance := 0.0
+			if total := due.Sub(rm.assignedAt[f.ID]); total > 0 {
+				distance = intermediateNM * min(max(float64(due.Sub(now))/float64(total), 0), 1)
+			}
+			state.Aircraft = append(state.Aircraft, AircraftPosition{
+				FlightID: f.ID,
+				Call:     f.Call,
+				Runway:   name,
+				Position: approachPoint(track, distance),
+				Distance: math.Round(distance*1000) / 1000,
+				Track:    track,
+			})
+		}
+	}
+	return state
+}
+
+// HandlePositions reports where the sequenced arrivals are now.
+func (s *Server) HandlePositions(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Positions())
+}
+
+// HandlePositionStream streams arrival positions as server-sent
+// "positions" events every positionInterval, for map display.
+func (s *Server) HandlePositionStream(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(positionInterval)
+	defer ticker.Stop()
+	for {
+		if err := writeSSE(w, "positions", s.Runways.Positions()); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
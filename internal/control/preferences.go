@@ -0,0 +1,123 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Runway preferences. Strategies favor preferred runways over secondary
+// ones when both are viable, and use last-resort runways only when nothing
+// else is.
+const (
+	PreferencePreferred  = "preferred"
+	PreferenceSecondary  = "secondary"
+	PreferenceLastResort = "lastResort"
+)
+
+// preferenceWeights is the share of arrivals each preference draws relative
+// to the others: a preferred runway takes three arrivals to a secondary
+// runway's one.
+var preferenceWeights = map[string]float64{
+	PreferencePreferred:  3,
+	PreferenceSecondary:  1,
+	PreferenceLastResort: 0,
+}
+
+// RunwayPreference is how favored a runway is for arrivals.
+type RunwayPreference struct {
+	Runway     string  `json:"runway"`
+	Preference string  `json:"preference"`
+	Weight     float64 `json:"weight"`
+}
+
+// SetRunwayPreference sets how favored runway is for arrivals.
+func (rm *RunwayManager) SetRunwayPreference(runway, preference string) error {
+	if _, ok := preferenceWeights[preference]; !ok {
+		return fmt.Errorf("unknown preference %q", preference)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	runway = rm.resolveLocked(runway)
+	if _, ok := rm.runways[runway]; !ok {
+		return fmt.Errorf("unknown runway %q", runway)
+	}
+	if rm.preferences == nil {
+		rm.preferences = make(map[string]string)
+	}
+	rm.preferences[runway] = preference
+	log.Printf("runway %s preference set to %s", runway, preference)
+	return nil
+}
+
+// RunwayPreferences reports every runway's preference in scheduling order.
+func (rm *RunwayManager) RunwayPreferences() []RunwayPreference {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayPreference, 0, len(rm.order))
+	for _, name := range rm.order {
+		pref := rm.preferenceLocked(name)
+		out = append(out, RunwayPreference{Runway: name, Preference: pref, Weight: preferenceWeights[pref]})
+	}
+	return out
+}
+
+// preferenceLocked returns runway's preference, preferred unless set.
+func (rm *RunwayManager) preferenceLocked(runway string) string {
+	if pref, ok := rm.preferences[runway]; ok {
+		return pref
+	}
+	return PreferencePreferred
+}
+
+// viableCandidates drops last-resort runways unless nothing else is viable.
+func viableCandidates(candidates []RunwayCandidate) []RunwayCandidate {
+	var out []RunwayCandidate
+	for _, c := range candidates {
+		if c.Preference != PreferenceLastResort {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return candidates
+	}
+	return out
+}
+
+// uniformWeights reports whether every candidate is equally favored.
+func uniformWeights(candidates []RunwayCandidate) bool {
+	for _, c := range candidates[1:] {
+		if c.Weight != candidates[0].Weight {
+			return false
+		}
+	}
+	return true
+}
+
+// HandlePreferences reports every runway's preference.
+func (s *Server) HandlePreferences(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.RunwayPreferences())
+}
+
+// HandleRunwayPreference sets one runway's preference.
+func (s *Server) HandleRunwayPreference(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var req RunwayPreference
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid preference: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Runways.SetRunwayPreference(r.PathValue("runway"), req.Preference); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.RunwayPreferences())
+}
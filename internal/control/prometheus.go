@@ -0,0 +1,102 @@
+package control
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// prometheusPrefix namespaces every exposed metric.
+const prometheusPrefix = "aircommand_"
+
+// writePrometheus writes s in the Prometheus text exposition format.
+// Operator-defined metrics follow as aircommand_custom_<name>, typed by
+// kinds and described by help where their definition gives it.
+func writePrometheus(buf *bytes.Buffer, s MetricsSnapshot, kinds, help map[string]string) {
+	family := func(name, kind, doc string) {
+		fmt.Fprintf(buf, "# HELP %s%s %s\n# TYPE %s%s %s\n", prometheusPrefix, name, doc, prometheusPrefix, name, kind)
+	}
+	sample := func(name string, value any, label, labelValue string) {
+		buf.WriteString(prometheusPrefix + name)
+		if label != "" {
+			fmt.Fprintf(buf, "{%s=%q}", label, labelValue)
+		}
+		fmt.Fprintf(buf, " %v\n", value)
+	}
+	single := func(name, kind, doc string, value any) {
+		family(name, kind, doc)
+		sample(name, value, "", "")
+	}
+	single("arrivals_total", "counter", "Arrivals generated.", s.TotalArrivals)
+	single("holding_patterns_total", "counter", "Flights sent to holding.", s.HoldingPatterns)
+	single("conflicts_total", "counter", "Spacing conflicts detected.", s.ConflictDetections)
+	single("incursions_total", "counter", "Runway incursions.", s.Incursions)
+	single("go_arounds_total", "counter", "Missed approaches.", s.GoArounds)
+	single("diversions_total", "counter", "Flights diverted.", s.Diversions)
+	single("departures_total", "counter", "Departures.", s.Departures)
+	single("fuel_kg_total", "counter", "Extra fuel burned holding and vectoring, in kilograms.", s.FuelBurnedKg)
+	single("landing_fee_revenue_total", "counter", "Landing fees charged.", s.Revenue)
+	single("holding_current", "gauge", "Flights holding now.", s.HoldingCurrent)
+	single("gates_occupied", "gauge", "Stands occupied now.", s.GatesOccupied)
+	single("departure_queue", "gauge", "Departures waiting at the holding points.", s.DepartureQueue)
+	single("wait_seconds_avg", "gauge", "Mean wait for a runway, in seconds.", s.AverageWaitSeconds)
+	single("landing_seconds_avg", "gauge", "Mean landing time, in seconds.", s.AverageLandingTime)
+	single("occupancy_seconds_avg", "gauge", "Mean runway occupancy of arrivals, in seconds.", s.AverageOccupancy)
+	single("runway_transitions_total", "counter", "Runway direction changes.", s.RunwayTransitions)
+
+	family("delay_seconds_total", "counter", "Delay attributed to each cause, in seconds.")
+	for _, cause := range sortedKeys(s.DelaySeconds) {
+		sample("delay_seconds_total", s.DelaySeconds[cause], "cause", cause)
+	}
+	family("queue_length", "gauge", "Arrivals queued for each runway.")
+	for _, runway := range sortedKeys(s.QueueLengths) {
+		sample("queue_length", s.QueueLengths[runway], "runway", runway)
+	}
+	family("flight_phase", "gauge", "Flights in each lifecycle phase.")
+	for _, phase := range FlightPhases {
+		sample("flight_phase", s.FlightPhases[phase], "phase", string(phase))
+	}
+	if len(s.SLAAttainment) > 0 {
+		family("sla_attainment", "gauge", "Fraction of recent flights meeting each SLA.")
+		for _, id := range sortedKeys(s.SLAAttainment) {
+			sample("sla_attainment", s.SLAAttainment[id], "sla", id)
+		}
+	}
+
+	for _, name := range sortedKeys(s.Custom) {
+		kind, metric := kinds[name], "custom_"+name
+		if kind == CustomCounter {
+			metric += "_total"
+		} else {
+			kind = CustomGauge
+		}
+		doc := help[name]
+		if doc == "" {
+			doc = "Operator-defined " + kind + " " + name + "."
+		}
+		single(metric, kind, strings.ReplaceAll(doc, "\n", " "), s.Custom[name])
+	}
+}
+
+// HandlePrometheus serves the metrics, including the operator-defined
+// counters and gauges, for a Prometheus scraper.
+func (s *Server) HandlePrometheus(w http.ResponseWriter, r *http.Request) {
+	if s.Metrics == nil {
+		http.Error(w, "metrics unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	help := make(map[string]string)
+	if s.Rules != nil {
+		for _, m := range s.Rules.Metrics() {
+			help[m.Name] = m.Help
+		}
+	}
+	var buf bytes.Buffer
+	writePrometheus(&buf, s.Metrics.Snapshot(), s.Metrics.readCustomKinds(), help)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("write prometheus metrics: %v", err)
+	}
+}
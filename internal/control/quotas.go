@@ -0,0 +1,191 @@
+package control
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// defaultQuotaWindow is how many recent peak slots quota adherence is
+// judged over when no window is configured.
+const defaultQuotaWindow = 10
+
+// AirlineQuota is a service agreement reserving Share of the arrival slots
+// for Airline while traffic peaks.
+type AirlineQuota struct {
+	Airline string  `json:"airline"`
+	Share   float64 `json:"share"`
+}
+
+// QuotaConfig configures airline quotas. Peak is the number of arrivals
+// queued across the open runways at which quotas start to apply; Window is
+// the number of recent peak slots the shares are judged over.
+type QuotaConfig struct {
+	Quotas []AirlineQuota `json:"quotas"`
+	Peak   int            `json:"peak"`
+	Window int            `json:"window,omitempty"`
+}
+
+// QuotaStrategy honors airline quotas during peaks and otherwise defers to
+// another strategy. A peak slot goes to an airline without a quota only if
+// every quota airline still has its share of the recent slots afterwards;
+// otherwise the flight is deferred until a landing frees a slot, so the
+// reserved slots stay available to the quota holders.
+type QuotaStrategy struct {
+	next AssignmentStrategy
+	cfg  QuotaConfig
+
+	mu     sync.Mutex
+	recent []string
+	slots  map[string]int64
+	// deferred counts flights deferred per airline; waiting holds those
+	// still to be admitted, so re-offers are not counted again.
+	deferred map[string]int64
+	waiting  map[int64]bool
+	total    int64
+}
+
+// NewQuotaStrategy wraps next with the configured airline quotas.
+func NewQuotaStrategy(cfg QuotaConfig, next AssignmentStrategy) (*QuotaStrategy, error) {
+	if next == nil {
+		next = &RoundRobinStrategy{}
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultQuotaWindow
+	}
+	if cfg.Peak <= 0 {
+		return nil, fmt.Errorf("quota peak must be positive")
+	}
+	total := 0.0
+	for _, q := range cfg.Quotas {
+		if q.Airline == "" || q.Share <= 0 {
+			return nil, fmt.Errorf("quota for %q needs an airline and a positive share", q.Airline)
+		}
+		total += q.Share
+	}
+	if total > 1 {
+		return nil, fmt.Errorf("quota shares add up to %.2f, more than all slots", total)
+	}
+	return &QuotaStrategy{next: next, cfg: cfg, slots: make(map[string]int64), deferred: make(map[string]int64), waiting: make(map[int64]bool)}, nil
+}
+
+// SelectRunway implements AssignmentStrategy.
+func (s *QuotaStrategy) SelectRunway(f Flight, candidates []RunwayCandidate) string {
+	queued := 0
+	for _, c := range candidates {
+		queued += c.QueueLength
+	}
+	airline := airlineOf(f.Call)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if queued < s.cfg.Peak {
+		delete(s.waiting, f.ID)
+		return s.next.SelectRunway(f, candidates)
+	}
+	if !s.admitsLocked(airline) {
+		if !s.waiting[f.ID] {
+			s.waiting[f.ID] = true
+			s.deferred[airline]++
+		}
+		return ""
+	}
+	runway := s.next.SelectRunway(f, candidates)
+	if runway != "" {
+		delete(s.waiting, f.ID)
+		s.recent = append(s.recent, airline)
+		if len(s.recent) > s.cfg.Window {
+			s.recent = s.recent[1:]
+		}
+		s.slots[airline]++
+		s.total++
+	}
+	return runway
+}
+
+// admitsLocked reports whether the next peak slot may go to airline.
+func (s *QuotaStrategy) admitsLocked(airline string) bool {
+	n := len(s.recent) + 1
+	for _, q := range s.cfg.Quotas {
+		if q.Airline == airline {
+			return true
+		}
+	}
+	for _, q := range s.cfg.Quotas {
+		held := 0
+		for _, a := range s.recent {
+			if a == q.Airline {
+				held++
+			}
+		}
+		if float64(held) < math.Floor(q.Share*float64(n)) {
+			return false
+		}
+	}
+	return true
+}
+
+// QuotaAdherence compares an airline's share of peak slots with its quota.
+type QuotaAdherence struct {
+	Airline string `json:"airline"`
+	// Quota is zero for airlines without an agreement.
+	Quota    float64 `json:"quota"`
+	Slots    int64   `json:"slots"`
+	Share    float64 `json:"share"`
+	Deferred int64   `json:"deferred"`
+	// Met reports whether the airline received at least its quota.
+	Met bool `json:"met"`
+}
+
+// QuotaReport lists quota adherence over all peak slots so far.
+type QuotaReport struct {
+	Peak      int              `json:"peak"`
+	PeakSlots int64            `json:"peakSlots"`
+	Airlines  []QuotaAdherence `json:"airlines"`
+}
+
+// Report summarizes quota adherence over all peak slots so far.
+func (s *QuotaStrategy) Report() QuotaReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	quotas := make(map[string]float64, len(s.cfg.Quotas))
+	for _, q := range s.cfg.Quotas {
+		quotas[q.Airline] = q.Share
+	}
+	airlines := make(map[string]bool)
+	for a := range quotas {
+		airlines[a] = true
+	}
+	for a := range s.slots {
+		airlines[a] = true
+	}
+	for a := range s.deferred {
+		airlines[a] = true
+	}
+	report := QuotaReport{Peak: s.cfg.Peak, PeakSlots: s.total, Airlines: make([]QuotaAdherence, 0, len(airlines))}
+	for a := range airlines {
+		entry := QuotaAdherence{Airline: a, Quota: quotas[a], Slots: s.slots[a], Deferred: s.deferred[a]}
+		if s.total > 0 {
+			entry.Share = float64(entry.Slots) / float64(s.total)
+		}
+		entry.Met = entry.Share >= entry.Quota || s.total == 0
+		report.Airlines = append(report.Airlines, entry)
+	}
+	sort.Slice(report.Airlines, func(i, j int) bool {
+		if report.Airlines[i].Quota != report.Airlines[j].Quota {
+			return report.Airlines[i].Quota > report.Airlines[j].Quota
+		}
+		return report.Airlines[i].Airline < report.Airlines[j].Airline
+	})
+	return report
+}
+
+// HandleQuotas serves airline quota adherence.
+func (s *Server) HandleQuotas(w http.ResponseWriter, r *http.Request) {
+	if s.Quotas == nil {
+		http.Error(w, "airline quotas disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Quotas.Report())
+}
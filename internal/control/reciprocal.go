@@ -0,0 +1,106 @@
+package control
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A runway strip has an identity at each end: 09/27, or 2L/20R for a left
+// runway whose reciprocal is on the right. The scheduler knows a runway by
+// the name it was defined with; the opposite end's name is an alias for it
+// wherever a runway is named, so closing "20R" closes "2L".
+
+// reciprocalName derives the opposite end's designator from a runway name:
+// the number plus 18, modulo 36, with left and right swapped. Names that are
+// not designators have no reciprocal.
+func reciprocalName(name string) string {
+	digits := len(name) - len(strings.TrimLeft(name, "0123456789"))
+	if digits == 0 || digits > 2 {
+		return ""
+	}
+	n, _ := strconv.Atoi(name[:digits])
+	if n < 1 || n > 36 {
+		return ""
+	}
+	var side string
+	switch suffix := name[digits:]; suffix {
+	case "":
+	case "L":
+		side = "R"
+	case "R":
+		side = "L"
+	case "C":
+		side = "C"
+	default:
+		return ""
+	}
+	n = (n+17)%36 + 1
+	if digits == 2 {
+		return fmt.Sprintf("%02d%s", n, side)
+	}
+	return strconv.Itoa(n) + side
+}
+
+// reciprocalLocked is the name of runway's opposite end, if it has one.
+func (rm *RunwayManager) reciprocalLocked(runway string) string {
+	r, ok := rm.runways[runway]
+	if !ok {
+		return ""
+	}
+	if r.definition.Reciprocal != "" {
+		return r.definition.Reciprocal
+	}
+	return reciprocalName(runway)
+}
+
+// addAliasLocked makes runway's opposite end name it too, unless another
+// runway already goes by that name.
+func (rm *RunwayManager) addAliasLocked(runway string) {
+	alias := rm.reciprocalLocked(runway)
+	if alias == "" {
+		return
+	}
+	if _, taken := rm.runways[alias]; taken {
+		return
+	}
+	if rm.aliases == nil {
+		rm.aliases = make(map[string]string)
+	}
+	rm.aliases[alias] = runway
+}
+
+// resolveLocked returns the runway name goes by, whichever end it names.
+// Names that are neither are returned unchanged.
+func (rm *RunwayManager) resolveLocked(name string) string {
+	if runway, ok := rm.aliases[name]; ok {
+		return runway
+	}
+	return name
+}
+
+// ResolveRunway returns the runway name goes by, whichever end it names.
+func (rm *RunwayManager) ResolveRunway(name string) string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.resolveLocked(name)
+}
+
+// activeEndLocked names the end of runway in use: the one arrivals fly
+// toward, as the wind decides.
+func (rm *RunwayManager) activeEndLocked(runway string) string {
+	r := rm.runways[runway]
+	reciprocal := rm.reciprocalLocked(runway)
+	if reciprocal == "" || angularDiff(r.activeHeading, normalizeHeading(r.definition.Heading)) <= 90 {
+		return runway
+	}
+	return reciprocal
+}
+
+// runwayEndsLocked describes runway by both its ends, as "2L/20R".
+func (rm *RunwayManager) runwayEndsLocked(runway string) string {
+	if reciprocal := rm.reciprocalLocked(runway); reciprocal != "" {
+		return runway + "/" + reciprocal
+	}
+	return runway
+}
@@ -0,0 +1,180 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxPlaybackSpeed = 100.0
+
+// RecordedFrame is a single broadcast message along with its offset from the
+// start of the recording session.
+type RecordedFrame struct {
+	OffsetMillis int64   `json:"offsetMs"`
+	Message      Message `json:"message"`
+}
+
+// Recorder persists the broadcast stream to a JSON-lines file.
+type Recorder struct {
+	path string
+}
+
+// StartRecorder creates a new session file in dir and records the broadcast
+// stream until the context is canceled: the state at the start, then every
+// event and log entry, none dropped however far the writer falls behind.
+func (s *Server) StartRecorder(ctx context.Context, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording dir: %w", err)
+	}
+	name := "session-" + time.Now().UTC().Format("20060102T150405Z") + ".jsonl"
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+
+	// Subscribe before taking the state so no event after it is missed.
+	events, unsubscribe := s.Events.SubscribeAll()
+	initial := stateMessages(s.Snapshot())
+	rec := &Recorder{path: path}
+	go func() {
+		defer file.Close()
+		defer unsubscribe()
+
+		start := time.Now()
+		writer := bufio.NewWriter(file)
+		enc := json.NewEncoder(writer)
+		write := func(msgs []Message) bool {
+			offset := time.Since(start).Milliseconds()
+			for _, msg := range msgs {
+				if err := enc.Encode(RecordedFrame{OffsetMillis: offset, Message: msg}); err != nil {
+					log.Printf("write recording: %v", err)
+					return false
+				}
+			}
+			return true
+		}
+		if !write(initial) {
+			return
+		}
+		flush := time.NewTicker(time.Second)
+		defer flush.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := writer.Flush(); err != nil {
+					log.Printf("flush recording: %v", err)
+				}
+				return
+			case <-flush.C:
+				if err := writer.Flush(); err != nil {
+					log.Printf("flush recording: %v", err)
+					return
+				}
+			case e := <-events:
+				if !write(eventMessages(e)) {
+					return
+				}
+			}
+		}
+	}()
+	log.Printf("recording broadcast stream to %s", path)
+	return rec, nil
+}
+
+// Path returns the file the recorder is writing to.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// HandlePlayback replays a recorded session over a websocket at the original
+// pace or accelerated by the speed query parameter.
+func (s *Server) HandlePlayback(w http.ResponseWriter, r *http.Request) {
+	if s.RecordingDir == "" {
+		http.Error(w, "recording disabled", http.StatusNotFound)
+		return
+	}
+	name := r.URL.Query().Get("session")
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".jsonl") {
+		http.Error(w, "invalid session", http.StatusBadRequest)
+		return
+	}
+	speed := 1.0
+	if raw := r.URL.Query().Get("speed"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid speed", http.StatusBadRequest)
+			return
+		}
+		speed = min(parsed, maxPlaybackSpeed)
+	}
+
+	file, err := os.Open(filepath.Join(s.RecordingDir, name))
+	if err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	if s.clientLimitReached() {
+		http.Error(w, "client limit reached", http.StatusServiceUnavailable)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("playback upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := s.connect(ClientPlayback, conn, r)
+	defer s.disconnect(client)
+
+	var lastOffset int64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var frame RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			log.Printf("playback %s: skipping bad frame: %v", name, err)
+			continue
+		}
+		if gap := frame.OffsetMillis - lastOffset; gap > 0 {
+			time.Sleep(time.Duration(float64(gap) * float64(time.Millisecond) / speed))
+		}
+		lastOffset = frame.OffsetMillis
+		if err := client.send(frame.Message); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("playback %s: %v", name, err)
+	}
+	client.send(Message{Type: "playbackComplete"})
+}
+
+// HandleRecordings lists the recorded sessions available for playback.
+func (s *Server) HandleRecordings(w http.ResponseWriter, r *http.Request) {
+	sessions := []string{}
+	if s.RecordingDir != "" {
+		matches, err := filepath.Glob(filepath.Join(s.RecordingDir, "*.jsonl"))
+		if err != nil {
+			http.Error(w, "list recordings", http.StatusInternalServerError)
+			return
+		}
+		for _, m := range matches {
+			sessions = append(sessions, filepath.Base(m))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sessions); err != nil {
+		log.Printf("encode recordings: %v", err)
+	}
+}
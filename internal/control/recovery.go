@@ -0,0 +1,194 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Event types for disruption recovery.
+const (
+	EventRecoveryProposed = "recoveryProposed"
+	EventRecoveryApplied  = "recoveryApplied"
+)
+
+const (
+	// recoverySpacing is the tightened arrival spacing a recovery plan
+	// boosts the landing rate with while the backlog clears.
+	recoverySpacing = minArrivalSpacing * 3 / 4
+	// recoveryBacklog is the backlog left by a reopened runway at which a
+	// recovery plan is proposed to the operator.
+	recoveryBacklog = 5
+)
+
+// RecoveryFlight is a backlogged flight and how late it is against its
+// scheduled arrival.
+type RecoveryFlight struct {
+	FlightID     int64   `json:"flightId"`
+	Call         string  `json:"call"`
+	Runway       string  `json:"runway,omitempty"`
+	DelaySeconds float64 `json:"delaySeconds"`
+}
+
+// RecoveryPlan is a catch-up plan for the backlog a disruption left behind:
+// arrivals are spaced at BoostSpacingSeconds instead of the minimum for
+// BoostSeconds, and the longest-delayed flights are sequenced first.
+type RecoveryPlan struct {
+	Time    time.Time `json:"time"`
+	Backlog int       `json:"backlog"`
+	// Priority lists the backlog, longest-delayed first.
+	Priority            []RecoveryFlight `json:"priority"`
+	AverageDelaySeconds float64          `json:"averageDelaySeconds"`
+	BoostSpacingSeconds float64          `json:"boostSpacingSeconds"`
+	BoostSeconds        float64          `json:"boostSeconds"`
+	// ArrivalsPerHour and BoostedArrivalsPerHour are the open runways'
+	// landing rate without and with the boost.
+	ArrivalsPerHour        float64 `json:"arrivalsPerHour"`
+	BoostedArrivalsPerHour float64 `json:"boostedArrivalsPerHour"`
+	// BoostedUntil is set while a plan's boost is in effect.
+	BoostedUntil *time.Time `json:"boostedUntil,omitempty"`
+}
+
+// RecoveryPlan computes a catch-up plan for the flights currently queued or
+// holding.
+func (rm *RunwayManager) RecoveryPlan() RecoveryPlan {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.recoveryPlanLocked()
+}
+
+func (rm *RunwayManager) recoveryPlanLocked() RecoveryPlan {
+	now := rm.clock.Now()
+	plan := RecoveryPlan{Time: now, Priority: []RecoveryFlight{}, BoostSpacingSeconds: recoverySpacing.Seconds()}
+	add := func(f Flight, runway string) {
+		plan.Priority = append(plan.Priority, RecoveryFlight{FlightID: f.ID, Call: f.Call, Runway: runway, DelaySeconds: max(now.Sub(scheduledArrival(f)), 0).Seconds()})
+	}
+	for _, name := range rm.order {
+		for _, f := range rm.assigned[name] {
+			add(f, name)
+		}
+	}
+	for _, f := range rm.holding {
+		add(f, "")
+	}
+	sort.SliceStable(plan.Priority, func(i, j int) bool { return plan.Priority[i].DelaySeconds > plan.Priority[j].DelaySeconds })
+	plan.Backlog = len(plan.Priority)
+	for _, f := range plan.Priority {
+		plan.AverageDelaySeconds += f.DelaySeconds / float64(plan.Backlog)
+	}
+	if open := len(rm.openRunways()); open > 0 {
+		plan.ArrivalsPerHour = float64(open) * float64(time.Hour) / float64(minArrivalSpacing)
+		plan.BoostedArrivalsPerHour = float64(open) * float64(time.Hour) / float64(recoverySpacing)
+		plan.BoostSeconds = (time.Duration(plan.Backlog) * recoverySpacing / time.Duration(open)).Seconds()
+	}
+	if now.Before(rm.boostUntil) {
+		until := rm.boostUntil
+		plan.BoostedUntil = &until
+	}
+	return plan
+}
+
+// ApplyRecovery puts a fresh recovery plan into effect: the arrival spacing
+// is tightened for the plan's boost, queued flights swap landing slots so
+// the longest-delayed land first on each runway, and the holding stack is
+// released longest-delayed first.
+func (rm *RunwayManager) ApplyRecovery() (RecoveryPlan, error) {
+	rm.mu.Lock()
+	plan := rm.recoveryPlanLocked()
+	if plan.Backlog == 0 {
+		rm.mu.Unlock()
+		return plan, fmt.Errorf("no backlog to recover")
+	}
+	if plan.BoostSeconds == 0 {
+		rm.mu.Unlock()
+		return plan, fmt.Errorf("no runway open")
+	}
+	now := plan.Time
+	rm.boostUntil = now.Add(time.Duration(plan.BoostSeconds * float64(time.Second)))
+	until := rm.boostUntil
+	plan.BoostedUntil = &until
+	rank := make(map[int64]int, plan.Backlog)
+	for i, f := range plan.Priority {
+		rank[f.FlightID] = i
+	}
+	for _, name := range rm.order {
+		rm.prioritizeQueueLocked(name, rank)
+	}
+	holding := rm.holding
+	rm.holding = nil
+	rm.publishHoldingLocked()
+	sort.SliceStable(holding, func(i, j int) bool { return rank[holding[i].ID] < rank[holding[j].ID] })
+	rm.publishEventLocked(Event{Type: EventRecoveryApplied, Detail: fmt.Sprintf("%d flights, boost for %.0fs", plan.Backlog, plan.BoostSeconds)})
+	rm.mu.Unlock()
+
+	log.Printf("recovery plan applied: %d flights backlogged, spacing %.1fs for %.0fs", plan.Backlog, plan.BoostSpacingSeconds, plan.BoostSeconds)
+	for _, f := range holding {
+		rm.AssignFlight(f)
+	}
+	return plan, nil
+}
+
+// prioritizeQueueLocked hands runway's landing slots, earliest first, to its
+// queued flights in rank order.
+func (rm *RunwayManager) prioritizeQueueLocked(runway string, rank map[int64]int) {
+	queue := append([]Flight(nil), rm.assigned[runway]...)
+	slots := make([]time.Time, 0, len(queue))
+	for _, f := range queue {
+		slots = append(slots, rm.estimateLocked(runway, f))
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Before(slots[j]) })
+	sort.SliceStable(queue, func(i, j int) bool { return rank[queue[i].ID] < rank[queue[j].ID] })
+	now := rm.clock.Now()
+	for i, f := range queue {
+		if rm.estimateLocked(runway, f).Equal(slots[i]) {
+			continue
+		}
+		rm.scheduleLandingLocked(runway, f, rm.assignedAt[f.ID], max(slots[i].Sub(now), 0))
+	}
+}
+
+// arrivalSpacingLocked is the spacing between arrivals to one runway, which
+// a recovery boost tightens and low-visibility procedures widen, overriding
+// any boost.
+func (rm *RunwayManager) arrivalSpacingLocked() time.Duration {
+	if rm.lvp.active {
+		return lvpSpacing
+	}
+	if rm.clock.Now().Before(rm.boostUntil) {
+		return recoverySpacing
+	}
+	return minArrivalSpacing
+}
+
+// proposeRecovery offers the operator a recovery plan when a disruption has
+// left a backlog behind.
+func (rm *RunwayManager) proposeRecovery() {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	plan := rm.recoveryPlanLocked()
+	if plan.Backlog < recoveryBacklog || plan.BoostedUntil != nil {
+		return
+	}
+	rm.publishEventLocked(Event{Type: EventRecoveryProposed, Detail: fmt.Sprintf("%d flights backlogged, average delay %.0fs", plan.Backlog, plan.AverageDelaySeconds)})
+}
+
+// HandleRecovery returns the current recovery plan (GET) or applies it
+// (POST).
+func (s *Server) HandleRecovery(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusOK, s.Runways.RecoveryPlan())
+		return
+	}
+	plan, err := s.Runways.ApplyRecovery()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,388 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// replicationSnapshotInterval is how often the primary sends replicas
+	// a full snapshot; replica state lags the primary by at most about
+	// this much.
+	replicationSnapshotInterval = time.Second
+	// replicationBuffer is the primary's per-replica event buffer.
+	replicationBuffer = 1024
+	// maxReplicaBackoff caps the wait between reconnection attempts.
+	maxReplicaBackoff = 30 * time.Second
+)
+
+// HandleReplication streams the state to a read replica as server-sent
+// events: a "snapshot" on connecting and every second after, and each
+// "event" as published. A snapshot follows at once when the replica falls
+// behind and loses events.
+func (s *Server) HandleReplication(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if s.Events == nil {
+		http.Error(w, "event bus unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Subscribe before the first snapshot so no event after it is missed.
+	sub, unsubscribe := s.Events.Open(replicationBuffer)
+	defer unsubscribe()
+	log.Printf("replica %s following", r.RemoteAddr)
+	defer log.Printf("replica %s gone", r.RemoteAddr)
+
+	send := func(kind string, payload any) bool {
+		if err := writeSSE(w, kind, payload); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	if !send("snapshot", s.Snapshot()) {
+		return
+	}
+	ticker := time.NewTicker(replicationSnapshotInterval)
+	defer ticker.Stop()
+	var dropped int64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !send("snapshot", s.Snapshot()) {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok || !send("event", e) {
+				return
+			}
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				if !send("snapshot", s.Snapshot()) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ReplicaStatus describes a replica's link to its primary.
+type ReplicaStatus struct {
+	Primary   string `json:"primary"`
+	Connected bool   `json:"connected"`
+	// LastSeq is the sequence number of the last event relayed.
+	LastSeq        int64      `json:"lastSeq"`
+	LastSnapshotAt *time.Time `json:"lastSnapshotAt,omitempty"`
+	// StateTime is the simulation time of the state served.
+	StateTime  *time.Time `json:"stateTime,omitempty"`
+	Reconnects int64      `json:"reconnects"`
+	Clients    int        `json:"clients"`
+}
+
+// Replica follows a primary's replication stream and serves its state and
+// events read-only, taking dashboard fan-out off the scheduler node. Its
+// state is eventually consistent: events are relayed as they arrive and
+// the state is refreshed from the primary's snapshots.
+type Replica struct {
+	primary *url.URL
+	events  *EventBus
+	client  *http.Client
+
+	mu         sync.Mutex
+	snap       *StateSnapshot
+	snapshotAt time.Time
+	connected  bool
+	lastSeq    int64
+	reconnects int64
+
+	upgrader websocket.Upgrader
+	clients  clientRegistry
+}
+
+// NewReplica returns a replica of the primary at the base URL primary,
+// as in http://scheduler:8080.
+func NewReplica(primary string) (*Replica, error) {
+	u, err := url.Parse(strings.TrimRight(primary, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("primary %q is not an http(s) URL", primary)
+	}
+	return &Replica{
+		primary: u,
+		events:  NewEventBus(),
+		client:  &http.Client{},
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}, nil
+}
+
+// Run follows the primary until the context is canceled, reconnecting
+// with backoff whenever the stream breaks.
+func (rp *Replica) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := rp.follow(ctx)
+		rp.mu.Lock()
+		rp.connected = false
+		rp.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > maxReplicaBackoff {
+			backoff = time.Second
+		}
+		log.Printf("replica: %v; reconnecting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReplicaBackoff)
+		rp.mu.Lock()
+		rp.reconnects++
+		rp.mu.Unlock()
+	}
+}
+
+// follow reads the replication stream until it ends.
+func (rp *Replica) follow(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rp.primary.String()+"/api/v1/replication", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := rp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("primary answered %s", resp.Status)
+	}
+	rp.mu.Lock()
+	rp.connected = true
+	rp.mu.Unlock()
+	log.Printf("replica: following %s", rp.primary)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var kind string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if k, ok := strings.CutPrefix(line, "event: "); ok {
+			kind = k
+			continue
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		if err := rp.apply(kind, []byte(data)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("primary closed the stream")
+}
+
+func (rp *Replica) apply(kind string, data []byte) error {
+	switch kind {
+	case "snapshot":
+		var snap StateSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return fmt.Errorf("decode snapshot: %w", err)
+		}
+		rp.mu.Lock()
+		rp.snap, rp.snapshotAt = &snap, time.Now()
+		rp.mu.Unlock()
+	case "event":
+		var e Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		rp.mu.Lock()
+		rp.lastSeq = e.Seq
+		rp.mu.Unlock()
+		rp.events.Relay(e)
+	}
+	return nil
+}
+
+// Snapshot returns the primary's state as last received, and false until
+// the first snapshot arrives.
+func (rp *Replica) Snapshot() (StateSnapshot, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.snap == nil {
+		return StateSnapshot{}, false
+	}
+	return *rp.snap, true
+}
+
+// Status describes the link to the primary.
+func (rp *Replica) Status() ReplicaStatus {
+	rp.mu.Lock()
+	status := ReplicaStatus{Primary: rp.primary.String(), Connected: rp.connected, LastSeq: rp.lastSeq, Reconnects: rp.reconnects}
+	if rp.snap != nil {
+		at, state := rp.snapshotAt, rp.snap.Time
+		status.LastSnapshotAt, status.StateTime = &at, &state
+	}
+	rp.mu.Unlock()
+	rp.clients.mu.Lock()
+	status.Clients = len(rp.clients.clients)
+	rp.clients.mu.Unlock()
+	return status
+}
+
+// Register installs the replica's read-only routes: the state, metrics,
+// spectator feed and control websocket as the primary serves them, plus
+// its replication status. Other reads are redirected to the primary and
+// writes refused.
+func (rp *Replica) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/state", rp.HandleState)
+	mux.HandleFunc("GET /metrics", rp.HandleMetrics)
+	mux.HandleFunc("GET /spectate", rp.HandleSpectate)
+	mux.HandleFunc("GET /control", rp.HandleControl)
+	mux.HandleFunc("GET /api/v1/replica", rp.HandleStatus)
+	mux.HandleFunc("/api/", rp.handleOther)
+}
+
+func (rp *Replica) handleOther(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "read-only replica; send commands to "+rp.primary.String(), http.StatusForbidden)
+		return
+	}
+	http.Redirect(w, r, rp.primary.String()+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+}
+
+// HandleState serves the primary's state as last received.
+func (rp *Replica) HandleState(w http.ResponseWriter, r *http.Request) {
+	snap, ok := rp.Snapshot()
+	if !ok {
+		http.Error(w, "replica not yet synchronized", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, snap)
+}
+
+// HandleMetrics serves the primary's metrics as last received.
+func (rp *Replica) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	snap, ok := rp.Snapshot()
+	if !ok || snap.Metrics == nil {
+		http.Error(w, "replica not yet synchronized", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, snap.Metrics)
+}
+
+// HandleStatus reports the link to the primary.
+func (rp *Replica) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, rp.Status())
+}
+
+// HandleSpectate serves the spectator feed from the replicated state and
+// events.
+func (rp *Replica) HandleSpectate(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	events, unsubscribe := rp.events.Subscribe(eventBufferSize)
+	defer unsubscribe()
+	streamSpectator(w, r, flusher, events, func() SpectatorState {
+		snap, _ := rp.Snapshot()
+		return spectatorStateOf(snap)
+	})
+}
+
+// HandleControl serves the control websocket read-only: the initial state
+// and events as the primary sends them, time sync and resync. Commands are
+// ignored; they belong on the primary.
+func (rp *Replica) HandleControl(w http.ResponseWriter, r *http.Request) {
+	snap, ok := rp.Snapshot()
+	if !ok {
+		http.Error(w, "replica not yet synchronized", http.StatusServiceUnavailable)
+		return
+	}
+	conn, err := rp.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := &wsClient{conn: conn, kind: ClientControl, remote: r.RemoteAddr, connected: time.Now()}
+	client.windowStart = client.connected
+	rp.clients.mu.Lock()
+	if rp.clients.clients == nil {
+		rp.clients.clients = make(map[int64]*wsClient)
+	}
+	rp.clients.nextID++
+	client.id = rp.clients.nextID
+	rp.clients.clients[client.id] = client
+	rp.clients.mu.Unlock()
+	defer func() {
+		rp.clients.mu.Lock()
+		delete(rp.clients.clients, client.id)
+		rp.clients.mu.Unlock()
+	}()
+
+	sub, unsubscribe := rp.events.Open(eventBufferSize)
+	defer unsubscribe()
+	initial := append(stateMessages(snap), Message{Type: "time", ServerTime: &snap.Time})
+	for _, msg := range initial {
+		if err := client.send(msg); err != nil {
+			return
+		}
+	}
+	go forwardEvents(client, sub.C)
+
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		snap, _ := rp.Snapshot()
+		var reply Message
+		switch msg.Type {
+		case "time":
+			reply = Message{Type: "time", ServerTime: &snap.Time, ClientTime: msg.ClientTime}
+		case "resync":
+			reply = Message{Type: "snapshot", Snapshot: &snap}
+		default:
+			log.Printf("replica: ignoring %s command from %s", msg.Type, r.RemoteAddr)
+			continue
+		}
+		if err := client.send(reply); err != nil {
+			return
+		}
+	}
+}
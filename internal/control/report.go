@@ -0,0 +1,396 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDailyReports bounds the past reports kept for the API.
+const maxDailyReports = 31
+
+// EventDailyReport is published when an end-of-day report is generated.
+const EventDailyReport = "dailyReport"
+
+// ReportConfig configures the end-of-day report.
+type ReportConfig struct {
+	// TimeZone names the location whose midnight ends the day, UTC by
+	// default.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Email sends each report when set.
+	Email *SMTPConfig `json:"email,omitempty"`
+}
+
+// SMTPConfig is the mail server and addresses reports are sent with. Port
+// defaults to 587; Username, when set, authenticates with PLAIN auth.
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// RunwayClosure is a period a runway was closed within a report's day.
+type RunwayClosure struct {
+	Runway string    `json:"runway"`
+	From   time.Time `json:"from"`
+	// To is unset while the runway remained closed at the end of the day.
+	To      *time.Time `json:"to,omitempty"`
+	Seconds float64    `json:"seconds"`
+}
+
+// DailyReport summarises one day of operations.
+type DailyReport struct {
+	Date string    `json:"date"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Partial is set for the day still in progress.
+	Partial            bool    `json:"partial,omitempty"`
+	Arrivals           int64   `json:"arrivals"`
+	Departures         int64   `json:"departures"`
+	AverageWaitSeconds float64 `json:"averageWaitSeconds"`
+	HoldingPatterns    int64   `json:"holdingPatterns"`
+	GoArounds          int64   `json:"goArounds"`
+	Diversions         int64   `json:"diversions"`
+	Conflicts          int64   `json:"conflicts"`
+	Incursions         int64   `json:"incursions"`
+	// DelaySeconds is the delay attributed to each cause during the day.
+	DelaySeconds      map[string]float64 `json:"delaySecondsByCause"`
+	TotalDelaySeconds float64            `json:"totalDelaySeconds"`
+	Closures          []RunwayClosure    `json:"closures"`
+	// Custom holds the operator-defined metrics: what each counter counted
+	// during the day, and each gauge's value at its end.
+	Custom map[string]float64 `json:"custom"`
+}
+
+// DailyReporter generates a report at the end of each simulated day from
+// the change in the metrics and the runway closures seen on the event bus,
+// optionally emailing it.
+type DailyReporter struct {
+	metrics  *SchedulerMetrics
+	events   *EventBus
+	clock    Clock
+	location *time.Location
+	email    *SMTPConfig
+
+	mu       sync.Mutex
+	dayStart time.Time
+	baseline MetricsSnapshot
+	closedAt map[string]time.Time
+	closures []RunwayClosure
+	reports  []DailyReport
+}
+
+// NewDailyReporter builds a reporter whose first day starts now.
+func NewDailyReporter(metrics *SchedulerMetrics, events *EventBus, clock Clock, cfg ReportConfig) (*DailyReporter, error) {
+	location := time.UTC
+	if cfg.TimeZone != "" {
+		loc, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("time zone: %w", err)
+		}
+		location = loc
+	}
+	if e := cfg.Email; e != nil {
+		if e.Host == "" || e.From == "" || len(e.To) == 0 {
+			return nil, errors.New("email needs a host, from and to addresses")
+		}
+	}
+	return &DailyReporter{
+		metrics:  metrics,
+		events:   events,
+		clock:    clock,
+		location: location,
+		email:    cfg.Email,
+		dayStart: clock.Now(),
+		baseline: metrics.Snapshot(),
+		closedAt: make(map[string]time.Time),
+	}, nil
+}
+
+// Run tracks closures and closes each day at midnight until the context is
+// canceled.
+func (d *DailyReporter) Run(ctx context.Context) {
+	var events <-chan Event
+	if d.events != nil {
+		ch, unsubscribe := d.events.Subscribe(eventBufferSize)
+		defer unsubscribe()
+		events = ch
+	}
+	midnight := make(chan time.Time, 1)
+	schedule := func() {
+		now := d.clock.Now()
+		next := d.nextMidnight(now)
+		d.clock.AfterFunc(next.Sub(now), func() { midnight <- next })
+	}
+	schedule()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			d.observe(e)
+		case end := <-midnight:
+			report := d.closeDay(end)
+			log.Printf("daily report %s: %d arrivals, %d departures, %d conflicts", report.Date, report.Arrivals, report.Departures, report.Conflicts)
+			if d.events != nil {
+				d.events.Publish(Event{Type: EventDailyReport, Detail: report.Date})
+			}
+			if d.email != nil {
+				go func() {
+					if err := d.send(report); err != nil {
+						log.Printf("email daily report %s: %v", report.Date, err)
+					}
+				}()
+			}
+			schedule()
+		}
+	}
+}
+
+func (d *DailyReporter) nextMidnight(now time.Time) time.Time {
+	local := now.In(d.location)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, d.location)
+}
+
+// observe records runway closures and reopenings.
+func (d *DailyReporter) observe(e Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch e.Type {
+	case EventRunwayClosed:
+		if _, closed := d.closedAt[e.Runway]; !closed {
+			d.closedAt[e.Runway] = e.Time
+		}
+	case EventRunwayOpened:
+		from, closed := d.closedAt[e.Runway]
+		if !closed {
+			return
+		}
+		delete(d.closedAt, e.Runway)
+		from = later(from, d.dayStart)
+		to := e.Time
+		d.closures = append(d.closures, RunwayClosure{Runway: e.Runway, From: from, To: &to, Seconds: to.Sub(from).Seconds()})
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// closeDay completes the report for the day ending at end and starts the
+// next.
+func (d *DailyReporter) closeDay(end time.Time) DailyReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	current := d.metrics.Snapshot()
+	report := d.reportLocked(end, current)
+	d.reports = append(d.reports, report)
+	if len(d.reports) > maxDailyReports {
+		d.reports = d.reports[len(d.reports)-maxDailyReports:]
+	}
+	d.dayStart, d.baseline, d.closures = end, current, nil
+	return report
+}
+
+// Today reports the day in progress so far.
+func (d *DailyReporter) Today() DailyReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	report := d.reportLocked(d.clock.Now(), d.metrics.Snapshot())
+	report.Partial = true
+	return report
+}
+
+// Reports returns the completed reports, oldest first.
+func (d *DailyReporter) Reports() []DailyReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DailyReport{}, d.reports...)
+}
+
+// Report returns the completed report for date, as in "2026-01-31".
+func (d *DailyReporter) Report(date string) (DailyReport, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := len(d.reports) - 1; i >= 0; i-- {
+		if d.reports[i].Date == date {
+			return d.reports[i], true
+		}
+	}
+	return DailyReport{}, false
+}
+
+func (d *DailyReporter) reportLocked(end time.Time, current MetricsSnapshot) DailyReport {
+	base := d.baseline
+	report := DailyReport{
+		Date:            d.dayStart.In(d.location).Format(time.DateOnly),
+		From:            d.dayStart,
+		To:              end,
+		Arrivals:        current.TotalArrivals - base.TotalArrivals,
+		Departures:      current.Departures - base.Departures,
+		HoldingPatterns: current.HoldingPatterns - base.HoldingPatterns,
+		GoArounds:       current.GoArounds - base.GoArounds,
+		Diversions:      current.Diversions - base.Diversions,
+		Conflicts:       current.ConflictDetections - base.ConflictDetections,
+		Incursions:      current.Incursions - base.Incursions,
+		DelaySeconds:    make(map[string]float64),
+		Closures:        append([]RunwayClosure{}, d.closures...),
+		Custom:          make(map[string]float64, len(current.Custom)),
+	}
+	kinds := d.metrics.readCustomKinds()
+	for name, v := range current.Custom {
+		if kinds[name] == CustomCounter {
+			v -= base.Custom[name]
+		}
+		report.Custom[name] = v
+	}
+	if report.Arrivals > 0 {
+		waited := current.AverageWaitSeconds*float64(current.TotalArrivals) - base.AverageWaitSeconds*float64(base.TotalArrivals)
+		report.AverageWaitSeconds = waited / float64(report.Arrivals)
+	}
+	for cause, secs := range current.DelaySeconds {
+		if delta := secs - base.DelaySeconds[cause]; delta > 0 {
+			report.DelaySeconds[cause] = delta
+			report.TotalDelaySeconds += delta
+		}
+	}
+	runways := make([]string, 0, len(d.closedAt))
+	for runway := range d.closedAt {
+		runways = append(runways, runway)
+	}
+	sort.Strings(runways)
+	for _, runway := range runways {
+		from := later(d.closedAt[runway], d.dayStart)
+		report.Closures = append(report.Closures, RunwayClosure{Runway: runway, From: from, Seconds: end.Sub(from).Seconds()})
+	}
+	return report
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"minutes": func(secs float64) string { return strconv.FormatFloat(secs/60, 'f', 1, 64) },
+	"clock":   func(t time.Time) string { return t.Format("15:04") },
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Daily report {{.Date}}</title></head>
+<body>
+<h1>Daily report {{.Date}}{{if .Partial}} (so far){{end}}</h1>
+<p>{{.From.Format "2006-01-02 15:04"}} to {{.To.Format "2006-01-02 15:04"}}</p>
+<h2>Movements</h2>
+<table>
+<tr><th>Arrivals</th><td>{{.Arrivals}}</td></tr>
+<tr><th>Departures</th><td>{{.Departures}}</td></tr>
+<tr><th>Go-arounds</th><td>{{.GoArounds}}</td></tr>
+<tr><th>Diversions</th><td>{{.Diversions}}</td></tr>
+</table>
+<h2>Delays</h2>
+<table>
+<tr><th>Average wait</th><td>{{minutes .AverageWaitSeconds}} min</td></tr>
+<tr><th>Holding patterns</th><td>{{.HoldingPatterns}}</td></tr>
+<tr><th>Total delay</th><td>{{minutes .TotalDelaySeconds}} min</td></tr>
+{{range $cause, $secs := .DelaySeconds}}<tr><th>{{$cause}}</th><td>{{minutes $secs}} min</td></tr>
+{{end}}</table>
+<h2>Safety</h2>
+<table>
+<tr><th>Conflicts</th><td>{{.Conflicts}}</td></tr>
+<tr><th>Incursions</th><td>{{.Incursions}}</td></tr>
+</table>
+<h2>Closures</h2>
+{{if .Closures}}<table>
+<tr><th>Runway</th><th>From</th><th>To</th><th>Minutes</th></tr>
+{{range .Closures}}<tr><td>{{.Runway}}</td><td>{{clock .From}}</td><td>{{if .To}}{{clock .To}}{{else}}still closed{{end}}</td><td>{{minutes .Seconds}}</td></tr>
+{{end}}</table>{{else}}<p>No runway closures.</p>{{end}}
+{{if .Custom}}<h2>Custom metrics</h2>
+<table>
+{{range $name, $value := .Custom}}<tr><th>{{$name}}</th><td>{{$value}}</td></tr>
+{{end}}</table>{{end}}
+</body></html>
+`))
+
+// RenderHTML renders the report as an HTML page.
+func (r DailyReport) RenderHTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// send emails the report as HTML.
+func (d *DailyReporter) send(report DailyReport) error {
+	body, err := report.RenderHTML()
+	if err != nil {
+		return err
+	}
+	cfg := d.email
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: AirCommand daily report %s\r\n", report.Date)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
+	msg.Write(body)
+	return smtp.SendMail(net.JoinHostPort(cfg.Host, strconv.Itoa(port)), auth, cfg.From, cfg.To, msg.Bytes())
+}
+
+// HandleDailyReports lists the completed daily reports.
+func (s *Server) HandleDailyReports(w http.ResponseWriter, r *http.Request) {
+	if s.Reports == nil {
+		http.Error(w, "daily reports disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Reports.Reports())
+}
+
+// HandleDailyReport serves the report for the date in the path, or the day
+// so far for "today", as JSON or, with format=html, as a page.
+func (s *Server) HandleDailyReport(w http.ResponseWriter, r *http.Request) {
+	if s.Reports == nil {
+		http.Error(w, "daily reports disabled", http.StatusServiceUnavailable)
+		return
+	}
+	date := r.PathValue("date")
+	report, ok := s.Reports.Report(date)
+	if date == "today" {
+		report, ok = s.Reports.Today(), true
+	}
+	if !ok {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("format") != "html" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	page, err := report.RenderHTML()
+	if err != nil {
+		log.Printf("render daily report: %v", err)
+		http.Error(w, "render failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
@@ -0,0 +1,165 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RetentionPolicy bounds how much raw history is kept. Archived events older
+// than Keep are folded into hourly aggregates; recordings older than Keep are
+// deleted.
+type RetentionPolicy struct {
+	Keep     time.Duration
+	Interval time.Duration
+}
+
+// HourlyAggregate counts events of one type on one runway within an hour.
+type HourlyAggregate struct {
+	Hour   time.Time `json:"hour"`
+	Type   string    `json:"type"`
+	Runway string    `json:"runway,omitempty"`
+	Count  int64     `json:"count"`
+}
+
+// RunRetention applies the policy on every interval until the context is
+// canceled. Either the archive or the recording directory may be absent.
+func RunRetention(ctx context.Context, policy RetentionPolicy, archive *EventArchive, recordingDir string) {
+	if policy.Keep <= 0 {
+		return
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = time.Hour
+	}
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+	for {
+		cutoff := time.Now().Add(-policy.Keep)
+		if archive != nil {
+			if n, err := archive.Compact(ctx, cutoff); err != nil {
+				log.Printf("archive compaction: %v", err)
+			} else if n > 0 {
+				log.Printf("archive compaction folded %d events older than %s", n, cutoff.Format(time.RFC3339))
+			}
+		}
+		if recordingDir != "" {
+			pruneRecordings(recordingDir, cutoff)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact folds raw events older than cutoff into hourly aggregates and
+// deletes them, returning how many raw events were removed.
+func (a *EventArchive) Compact(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cut := cutoff.Truncate(time.Hour).UnixMilli()
+	hour := time.Hour.Milliseconds()
+	aggregate := a.rebind(fmt.Sprintf(`INSERT INTO event_hourly (hour_ms, type, runway, count)
+		SELECT (at_ms / %d) * %d, type, runway, COUNT(*) FROM events WHERE at_ms < ?
+		GROUP BY (at_ms / %d) * %d, type, runway`, hour, hour, hour, hour))
+	if _, err := tx.ExecContext(ctx, aggregate, cut); err != nil {
+		return 0, fmt.Errorf("aggregate: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, a.rebind(`DELETE FROM events WHERE at_ms < ?`), cut)
+	if err != nil {
+		return 0, fmt.Errorf("delete: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Hourly returns compacted aggregates between from and to (zero means open).
+func (a *EventArchive) Hourly(from, to time.Time) ([]HourlyAggregate, error) {
+	query := `SELECT hour_ms, type, runway, SUM(count) FROM event_hourly WHERE hour_ms >= ?`
+	args := []any{from.UnixMilli()}
+	if !to.IsZero() {
+		query += ` AND hour_ms <= ?`
+		args = append(args, to.UnixMilli())
+	}
+	query += ` GROUP BY hour_ms, type, runway ORDER BY hour_ms, type, runway`
+	rows, err := a.db.Query(a.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []HourlyAggregate{}
+	for rows.Next() {
+		var agg HourlyAggregate
+		var hourMillis int64
+		if err := rows.Scan(&hourMillis, &agg.Type, &agg.Runway, &agg.Count); err != nil {
+			return nil, err
+		}
+		agg.Hour = time.UnixMilli(hourMillis).UTC()
+		out = append(out, agg)
+	}
+	return out, rows.Err()
+}
+
+// HandleHourlyHistory serves compacted hourly aggregates.
+func (s *Server) HandleHourlyHistory(w http.ResponseWriter, r *http.Request) {
+	if s.Archive == nil {
+		http.Error(w, "event archive disabled", http.StatusServiceUnavailable)
+		return
+	}
+	var from, to time.Time
+	var err error
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid from (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "invalid to (RFC 3339 expected)", http.StatusBadRequest)
+			return
+		}
+	}
+	aggregates, err := s.Archive.Hourly(from, to)
+	if err != nil {
+		log.Printf("hourly history query: %v", err)
+		http.Error(w, "history query failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(aggregates); err != nil {
+		log.Printf("encode hourly history: %v", err)
+	}
+}
+
+func pruneRecordings(dir string, cutoff time.Time) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		log.Printf("prune recordings: %v", err)
+		return
+	}
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("prune recording %s: %v", path, err)
+			continue
+		}
+		log.Printf("pruned recording %s", filepath.Base(path))
+	}
+}
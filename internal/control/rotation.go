@@ -0,0 +1,142 @@
+package control
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// rotationFlightTime is the airborne time of an airframe's out-and-back
+	// trip between taking off here and landing back.
+	rotationFlightTime = 18 * time.Minute
+	// taxiAllowance is the taxi-out time planned into rotation schedules.
+	taxiAllowance = 5 * time.Minute
+)
+
+// airframe follows one aircraft through its legs of the day.
+type airframe struct {
+	registration string
+	legs         int
+	flightID     int64
+	call         string
+	// lastDelay is how late the airframe last left its stand.
+	lastDelay time.Duration
+	// returnsAt is when the airframe is due to land back, zero while it is
+	// on the ground here.
+	returnsAt time.Time
+}
+
+// SetRotations makes departed airframes fly out and back: each one returns
+// as a new arrival rotationFlightTime after taking off, with flight IDs
+// drawn from ids. Its return is scheduled from its scheduled off-block time,
+// so the delay it leaves with carries over to the next leg. Rotations are
+// off until ids is set.
+func (rm *RunwayManager) SetRotations(ids func() int64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.flightIDs = ids
+}
+
+// scheduledArrival is when f is scheduled to land.
+func scheduledArrival(f Flight) time.Time {
+	if f.ScheduledAt.IsZero() {
+		return f.CreatedAt.Add(landingDuration)
+	}
+	return f.ScheduledAt
+}
+
+// airframeLocked returns the airframe flying f, tracking it from its first
+// leg. Flights without an airframe yield nil.
+func (rm *RunwayManager) airframeLocked(f Flight) *airframe {
+	if f.Airframe == "" {
+		return nil
+	}
+	a, ok := rm.airframes[f.Airframe]
+	if !ok {
+		a = &airframe{registration: f.Airframe}
+		rm.airframes[f.Airframe] = a
+	}
+	a.flightID, a.call = f.ID, f.Call
+	return a
+}
+
+// recordLegLocked counts a leg flown by f's airframe, landing or departing.
+func (rm *RunwayManager) recordLegLocked(f Flight) {
+	if a := rm.airframeLocked(f); a != nil {
+		a.legs++
+		a.returnsAt = time.Time{}
+	}
+}
+
+// rotateLocked sends f's airframe, which just took off, out and back as its
+// next inbound leg.
+func (rm *RunwayManager) rotateLocked(f Flight) {
+	a := rm.airframeLocked(f)
+	if a == nil || rm.flightIDs == nil {
+		return
+	}
+	ids := rm.flightIDs
+	scheduled := scheduledArrival(f).Add(rm.standTime(f)).Add(taxiAllowance + rotationFlightTime)
+	a.returnsAt = rm.clock.Now().Add(rotationFlightTime)
+	rm.clock.AfterFunc(rotationFlightTime-landingDuration, func() {
+		id := ids()
+		now := rm.clock.Now()
+		next := Flight{
+			ID:          id,
+			Call:        defaultCallsign(id, now),
+			CreatedAt:   now,
+			ScheduledAt: scheduled,
+			Approach:    f.Approach,
+			Equipage:    f.Equipage,
+			Weight:      f.Weight,
+			Airframe:    f.Airframe,
+		}
+		log.Printf("airframe %s returning as flight %d (%s)", f.Airframe, id, next.Call)
+		rm.Arrive(next)
+	})
+}
+
+// AirframeStatus is one airframe's progress through its legs of the day.
+type AirframeStatus struct {
+	Registration string `json:"registration"`
+	Legs         int    `json:"legs"`
+	// FlightID and Call identify the airframe's current or last flight.
+	FlightID         int64   `json:"flightId"`
+	Call             string  `json:"call"`
+	LastDelaySeconds float64 `json:"lastDelaySeconds"`
+	// ReturnsAt is when an airframe that has departed is due back.
+	ReturnsAt *time.Time `json:"returnsAt,omitempty"`
+}
+
+// Airframes lists the airframes seen so far, most legs first.
+func (rm *RunwayManager) Airframes() []AirframeStatus {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]AirframeStatus, 0, len(rm.airframes))
+	for _, a := range rm.airframes {
+		status := AirframeStatus{Registration: a.registration, Legs: a.legs, FlightID: a.flightID, Call: a.call, LastDelaySeconds: a.lastDelay.Seconds()}
+		if !a.returnsAt.IsZero() {
+			returnsAt := a.returnsAt
+			status.ReturnsAt = &returnsAt
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Legs != out[j].Legs {
+			return out[i].Legs > out[j].Legs
+		}
+		return out[i].Registration < out[j].Registration
+	})
+	return out
+}
+
+// HandleAirframes serves airframe rotations.
+func (s *Server) HandleAirframes(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Airframes())
+}
@@ -0,0 +1,434 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventRuleFired is published whenever a rule's action runs.
+const EventRuleFired = "ruleFired"
+
+const (
+	ruleEvalInterval = time.Second
+	maxRuleFirings   = 200
+	// ruleEventBuffer is the event backlog custom counters may fall behind.
+	ruleEventBuffer = 256
+)
+
+// Rule actions.
+const (
+	ActionSetRate     = "setRate"
+	ActionCloseRunway = "closeRunway"
+	ActionOpenRunway  = "openRunway"
+	// ActionIncrementMetric and ActionSetMetric drive a custom metric.
+	ActionIncrementMetric = "incrementMetric"
+	ActionSetMetric       = "setMetric"
+)
+
+// Condition compares a live metric against a threshold. Metrics are
+// "holding", "holdingMinutes", "averageHoldingMinutes",
+// "longestHoldingMinutes", "arrivals", "conflicts", "incursions", "goArounds",
+// "diversions", "fuelKg", "revenue", "gatesOccupied", "gateConflicts",
+// "taxiing", "departureQueue", "deicingQueue", "averageWait",
+// "averageOccupancy", "averageDepartureDelay", "reactionaryDelay", "rate",
+// "windSpeed", "windGust" (the steady speed when not gusting),
+// "windDirection", "visibility", "ceiling", "lvp" (1 while low-visibility
+// procedures are in force), "temperature", "densityAltitude",
+// "queue:<runway>", "delay:<cause>" (seconds), "custom:<name>" and
+// "sla:<id>" (the attainment fraction).
+type Condition struct {
+	Metric string  `json:"metric"`
+	Op     string  `json:"op"`
+	Value  float64 `json:"value"`
+}
+
+// Action is the effect applied when a rule fires.
+type Action struct {
+	Type   string  `json:"type"`
+	Rate   int64   `json:"rate,omitempty"`
+	Runway string  `json:"runway,omitempty"`
+	Metric string  `json:"metric,omitempty"`
+	Value  float64 `json:"value,omitempty"`
+}
+
+// Rule is an if-this-then-that automation. Rules fire on the transition from
+// false to true and then wait at least CooldownSeconds before firing again.
+type Rule struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name,omitempty"`
+	If              Condition `json:"if"`
+	Then            Action    `json:"then"`
+	CooldownSeconds int64     `json:"cooldownSeconds,omitempty"`
+	Disabled        bool      `json:"disabled,omitempty"`
+}
+
+// RuleFiring records a single execution of a rule.
+type RuleFiring struct {
+	RuleID string    `json:"ruleId"`
+	Time   time.Time `json:"time"`
+	Metric string    `json:"metric"`
+	Value  float64   `json:"value"`
+	Action Action    `json:"action"`
+}
+
+type ruleState struct {
+	rule      Rule
+	active    bool
+	lastFired time.Time
+}
+
+// RulesEngine evaluates rules continuously against live state.
+type RulesEngine struct {
+	gen     *Generator
+	runways *RunwayManager
+	metrics *SchedulerMetrics
+	events  *EventBus
+
+	mu      sync.Mutex
+	rules   map[string]*ruleState
+	order   []string
+	nextID  int
+	firings []RuleFiring
+	// custom holds operator-defined metrics by name; values is the latest
+	// sample, against which their conditions are checked.
+	custom      map[string]CustomMetric
+	customOrder []string
+	values      map[string]float64
+}
+
+// NewRulesEngine builds an engine with no rules.
+func NewRulesEngine(gen *Generator, runways *RunwayManager, metrics *SchedulerMetrics, events *EventBus) *RulesEngine {
+	return &RulesEngine{gen: gen, runways: runways, metrics: metrics, events: events, rules: make(map[string]*ruleState), custom: make(map[string]CustomMetric)}
+}
+
+// Add validates and stores a rule, assigning an ID when none is given.
+func (e *RulesEngine) Add(r Rule) (Rule, error) {
+	if err := r.validate(); err != nil {
+		return Rule{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if r.ID == "" {
+		e.nextID++
+		r.ID = "rule-" + strconv.Itoa(e.nextID)
+	}
+	if _, exists := e.rules[r.ID]; !exists {
+		e.order = append(e.order, r.ID)
+	}
+	e.rules[r.ID] = &ruleState{rule: r}
+	return r, nil
+}
+
+// Remove deletes a rule, reporting whether it existed.
+func (e *RulesEngine) Remove(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.rules[id]; !ok {
+		return false
+	}
+	delete(e.rules, id)
+	for i, candidate := range e.order {
+		if candidate == id {
+			e.order = append(e.order[:i], e.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Rules lists the configured rules in creation order.
+func (e *RulesEngine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Rule, 0, len(e.order))
+	for _, id := range e.order {
+		out = append(out, e.rules[id].rule)
+	}
+	return out
+}
+
+// Firings returns the most recent rule executions, oldest first.
+func (e *RulesEngine) Firings() []RuleFiring {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]RuleFiring, len(e.firings))
+	copy(out, e.firings)
+	return out
+}
+
+// Run evaluates the rules every second, and counts events for custom
+// counters, until the context is canceled.
+func (e *RulesEngine) Run(ctx context.Context) {
+	ticker := time.NewTicker(ruleEvalInterval)
+	defer ticker.Stop()
+	var events <-chan Event
+	if e.events != nil {
+		ch, unsubscribe := e.events.Subscribe(ruleEventBuffer)
+		defer unsubscribe()
+		events = ch
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate(e.runways.Clock().Now())
+		case ev := <-events:
+			e.countEvent(ev)
+		}
+	}
+}
+
+// Evaluate checks every rule once and applies the actions of those that fire.
+func (e *RulesEngine) Evaluate(now time.Time) {
+	values := e.sample()
+
+	e.mu.Lock()
+	e.values = values
+	e.sampleGaugesLocked(values)
+	var due []RuleFiring
+	for _, id := range e.order {
+		st := e.rules[id]
+		if st.rule.Disabled {
+			continue
+		}
+		value, ok := values[st.rule.If.Metric]
+		if !ok {
+			continue
+		}
+		matched := st.rule.If.matches(value)
+		wasActive := st.active
+		st.active = matched
+		if !matched || wasActive {
+			continue
+		}
+		cooldown := time.Duration(st.rule.CooldownSeconds) * time.Second
+		if !st.lastFired.IsZero() && now.Sub(st.lastFired) < cooldown {
+			continue
+		}
+		st.lastFired = now
+		firing := RuleFiring{RuleID: id, Time: now, Metric: st.rule.If.Metric, Value: value, Action: st.rule.Then}
+		due = append(due, firing)
+		e.firings = append(e.firings, firing)
+		if len(e.firings) > maxRuleFirings {
+			e.firings = e.firings[len(e.firings)-maxRuleFirings:]
+		}
+	}
+	e.mu.Unlock()
+
+	for _, f := range due {
+		log.Printf("rule %s fired (%s = %g): %s", f.RuleID, f.Metric, f.Value, f.Action)
+		e.apply(f.Action)
+		if e.events != nil {
+			e.events.Publish(Event{Type: EventRuleFired, Runway: f.Action.Runway, Detail: f.RuleID + ": " + f.Action.String()})
+		}
+	}
+}
+
+func (e *RulesEngine) apply(a Action) {
+	switch a.Type {
+	case ActionSetRate:
+		e.gen.SetRate(a.Rate)
+	case ActionCloseRunway:
+		e.runways.SetRunwayClosed(a.Runway, true)
+	case ActionOpenRunway:
+		e.runways.SetRunwayClosed(a.Runway, false)
+	case ActionIncrementMetric, ActionSetMetric:
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.custom[a.Metric]; !ok || e.metrics == nil {
+			log.Printf("rule action on unknown metric %q ignored", a.Metric)
+			return
+		}
+		if a.Type == ActionSetMetric {
+			e.metrics.SetCustom(a.Metric, a.Value)
+		} else {
+			e.metrics.AddCustom(a.Metric, max(a.Value, 1))
+		}
+	}
+}
+
+// sample gathers the current metric values the rules may reference.
+func (e *RulesEngine) sample() map[string]float64 {
+	values := map[string]float64{"rate": float64(e.gen.Rate())}
+	wind := e.runways.Wind()
+	values["windSpeed"] = float64(wind.Speed)
+	values["windGust"] = float64(max(wind.Gust, wind.Speed))
+	values["windDirection"] = float64(wind.Direction)
+	values["visibility"] = float64(e.runways.Visibility())
+	lvp := e.runways.LowVisibility()
+	values["ceiling"] = float64(lvp.CeilingFeet)
+	values["lvp"] = 0
+	if lvp.Active {
+		values["lvp"] = 1
+	}
+	atmosphere := e.runways.Atmosphere()
+	values["temperature"] = atmosphere.TemperatureC
+	values["densityAltitude"] = atmosphere.DensityAltitude()
+	if e.metrics != nil {
+		s := e.metrics.Snapshot()
+		values["holding"] = float64(s.HoldingCurrent)
+		values["holdingMinutes"] = s.HoldingMinutes
+		values["averageHoldingMinutes"] = s.AverageHoldingMinutes
+		values["longestHoldingMinutes"] = longestHoldingMinutes(s)
+		values["arrivals"] = float64(s.TotalArrivals)
+		values["conflicts"] = float64(s.ConflictDetections)
+		values["incursions"] = float64(s.Incursions)
+		values["goArounds"] = float64(s.GoArounds)
+		values["diversions"] = float64(s.Diversions)
+		values["fuelKg"] = s.FuelBurnedKg
+		values["revenue"] = s.Revenue
+		values["gatesOccupied"] = float64(s.GatesOccupied)
+		values["gateConflicts"] = float64(s.GateConflicts)
+		values["taxiing"] = float64(s.Taxiing)
+		values["departureQueue"] = float64(s.DepartureQueue)
+		values["deicingQueue"] = float64(s.DeicingQueue)
+		values["averageWait"] = s.AverageWaitSeconds
+		values["averageOccupancy"] = s.AverageOccupancy
+		values["averageDepartureDelay"] = s.AverageDepartureDelaySeconds
+		values["reactionaryDelay"] = s.ReactionaryDelaySeconds
+		for cause, seconds := range s.DelaySeconds {
+			values["delay:"+cause] = seconds
+		}
+		for runway, n := range s.QueueLengths {
+			values["queue:"+runway] = float64(n)
+		}
+		for name, v := range s.Custom {
+			values["custom:"+name] = v
+		}
+		for id, v := range s.SLAAttainment {
+			values["sla:"+id] = v
+		}
+	}
+	return values
+}
+
+func (c Condition) matches(v float64) bool {
+	switch c.Op {
+	case ">":
+		return v > c.Value
+	case ">=":
+		return v >= c.Value
+	case "<":
+		return v < c.Value
+	case "<=":
+		return v <= c.Value
+	case "==":
+		return v == c.Value
+	case "!=":
+		return v != c.Value
+	}
+	return false
+}
+
+func (a Action) String() string {
+	switch a.Type {
+	case ActionSetRate:
+		return fmt.Sprintf("set rate to %d/min", a.Rate)
+	case ActionCloseRunway:
+		return "close runway " + a.Runway
+	case ActionOpenRunway:
+		return "open runway " + a.Runway
+	case ActionIncrementMetric:
+		return "increment " + a.Metric
+	case ActionSetMetric:
+		return fmt.Sprintf("set %s to %g", a.Metric, a.Value)
+	}
+	return a.Type
+}
+
+func (c Condition) validate() error {
+	if c.Metric == "" {
+		return errors.New("condition needs a metric")
+	}
+	switch c.Op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return fmt.Errorf("unsupported operator %q", c.Op)
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	if err := r.If.validate(); err != nil {
+		return fmt.Errorf("rule %w", err)
+	}
+	switch r.Then.Type {
+	case ActionSetRate:
+		if r.Then.Rate <= 0 {
+			return errors.New("setRate action needs a positive rate")
+		}
+	case ActionCloseRunway, ActionOpenRunway:
+		if r.Then.Runway == "" {
+			return fmt.Errorf("%s action needs a runway", r.Then.Type)
+		}
+	case ActionIncrementMetric, ActionSetMetric:
+		if r.Then.Metric == "" {
+			return fmt.Errorf("%s action needs a metric", r.Then.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported action %q", r.Then.Type)
+	}
+	if r.CooldownSeconds < 0 {
+		return errors.New("cooldownSeconds must not be negative")
+	}
+	return nil
+}
+
+// HandleRules lists rules (GET) or creates one (POST).
+func (s *Server) HandleRules(w http.ResponseWriter, r *http.Request) {
+	if s.Rules == nil {
+		http.Error(w, "rules engine disabled", http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Rules.Rules())
+	case http.MethodPost:
+		var rule Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, "invalid rule: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		created, err := s.Rules.Add(rule)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRule deletes the rule named in the path.
+func (s *Server) HandleRule(w http.ResponseWriter, r *http.Request) {
+	if s.Rules == nil {
+		http.Error(w, "rules engine disabled", http.StatusServiceUnavailable)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("id"))
+	if !s.Rules.Remove(id) {
+		http.Error(w, "rule not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRuleFirings lists recent rule executions.
+func (s *Server) HandleRuleFirings(w http.ResponseWriter, r *http.Request) {
+	if s.Rules == nil {
+		http.Error(w, "rules engine disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Rules.Firings())
+}
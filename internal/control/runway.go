@@ -2,13 +2,22 @@ package control
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"math"
+	"sort"
 	"sync"
 	"time"
 )
 
-const minArrivalSpacing = 2 * time.Second
+const (
+	minArrivalSpacing = 2 * time.Second
+	landingDuration   = 5 * time.Second
+)
+
+// reasonDeferred is the holding reason for flights the assignment strategy
+// turned down although a runway was available.
+const reasonDeferred = "deferred by assignment strategy"
 
 // RunwayManager tracks runway availability and assigns inbound flights.
 type RunwayManager struct {
@@ -18,53 +27,265 @@ type RunwayManager struct {
 	vectors  map[int64]float64
 	holding  []Flight
 	order    []string
-	nextIdx  int
+	strategy AssignmentStrategy
 	wind     WindState
 	metrics  *SchedulerMetrics
-	lastUse  map[string]time.Time
+	events   *EventBus
+	wal      *DecisionLog
+	clock    Clock
+	onArrive func(Flight) Flight
+	// maxFlights bounds the arrivals sequenced, holding or going around;
+	// zero means no limit.
+	maxFlights int
+	// acceptance defers or refuses arrivals while holding is congested.
+	acceptance acceptanceState
+	// latency is how long pilots take to act on instructions, radio the
+	// frequency they hear them on, and transcript every instruction issued.
+	latency    PilotLatency
+	radio      frequencyState
+	transcript []Instruction
+	lastUse    map[string]time.Time
+	// assignedAt records when each flight in an assigned queue was cleared.
+	assignedAt map[int64]time.Time
+	// estimatedAt records when each flight was estimated to touch down
+	// when first sequenced.
+	estimatedAt map[int64]time.Time
+	// dueAt records when each scheduled landing is due to touch down;
+	// landings holds the cancel function of its timer.
+	dueAt    map[int64]time.Time
+	landings map[int64]func() bool
+	// stopped is set once the manager is shut down: no further landings
+	// complete and sequenced flights stay as they are to be handed over.
+	stopped bool
+	// delays tracks flights currently delayed and why.
+	delays map[int64]delayEntry
+	// emissions accumulates delay fuel burn for flights still airborne.
+	emissions       map[int64]*FlightEmissions
+	recentEmissions []FlightEmissions
+	// usage counts landings by runway and direction for noise estimates.
+	usage map[runwayDirection]int64
+	// accounts and charges hold landing fees billed per airline.
+	accounts map[string]account
+	charges  []Charge
+	// gates holds the parking stands; gatePlan maps flights to the contact
+	// gate reserved for them.
+	gates        map[string]*gateState
+	gateOrder    []string
+	gatePlan     map[int64]string
+	gateStrategy GateStrategy
+	gatesSince   time.Time
+	// taxiing holds departures on their way to the holding point;
+	// departures queues them there per runway.
+	taxiing    map[int64]taxiOut
+	departures map[string][]departure
+	takingOff  map[string]bool
+	// winter is the winter operations mode; deiceQueue holds departures
+	// waiting for one of the pads, deicing of which are in use.
+	winter     WinterOps
+	deiceQueue []deiceRequest
+	deicing    int
+	// deferred marks holding flights the assignment strategy turned down;
+	// they are offered again after each landing.
+	deferred map[int64]bool
+	// slas receives landings and taxi-outs for SLA tracking.
+	slas *SLATracker
+	// auction orders the holding stack by airline bids when it is released.
+	auction *SlotAuction
+	// boostUntil ends the tightened spacing of an applied recovery plan.
+	boostUntil time.Time
+	// transitions holds runway direction changes waiting for the arrivals
+	// sequenced in the old direction to land.
+	transitions map[string]*runwayTransition
+	// version counts changes to the operator-controlled state: runway
+	// closures, wind, visibility and ceiling.
+	version int64
+	// parallelMode couples arrivals to parallel runways.
+	parallelMode string
+	// turnarounds holds parked aircraft being turned around.
+	turnarounds map[int64]*turnaround
+	// airframes tracks aircraft across legs; flightIDs numbers the return
+	// legs of rotating airframes.
+	airframes map[string]*airframe
+	flightIDs func() int64
+	// friction schedules friction tests; frictionGen invalidates the timers
+	// of a replaced schedule.
+	friction     FrictionTests
+	frictionGen  int64
+	frictionRuns map[string]*frictionRun
+	// wildlife sets bird activity; dispersals records each runway's last
+	// precautionary closure for it.
+	wildlife   WildlifeHazard
+	dispersals map[string]time.Time
+	// atmosphere sets the density altitude aircraft perform at.
+	atmosphere Atmosphere
+	// geography places the airport and its runways on the map.
+	geography Geography
+	// atisBody is the content of the current ATIS information, atisLetter
+	// its letter, advanced whenever the content changes.
+	atisBody   string
+	atisLetter int
+	// storms holds thunderstorm cells by ID; stormBlocked marks runways
+	// whose final approach a cell sits on.
+	storms       map[string]stormCell
+	nextStormID  int64
+	stormTicking bool
+	stormBlocked map[string]bool
+	// ground holds vehicles and crossing aircraft occupying runways.
+	ground       map[string]GroundMovement
+	nextGroundID int64
+	// incursions maps runways with an unresolved incursion to its start.
+	incursions  map[string]time.Time
+	goingAround map[int64]Flight
+	// incidents holds active equipment failures by ID.
+	incidents      map[string]Incident
+	nextIncidentID int64
+	visibility     int64
+	// ceiling is the cloud ceiling in feet; with the visibility it decides
+	// whether low-visibility procedures are in force. taxiHeld holds
+	// departures kept at their stands by them.
+	ceiling  int64
+	lvp      lowVisibility
+	taxiHeld []heldTaxi
+	// phases holds the lifecycle phase of each flight in progress;
+	// phaseCounts counts flights per phase, those in terminal phases ever.
+	phases      map[int64]FlightPhase
+	phaseCounts map[FlightPhase]int
+	// chaos, when set, holds landings past their due time.
+	chaos *Chaos
+	// preferences holds the runways not preferred for arrivals.
+	preferences map[string]string
+	// timing holds the runways whose arrival timing is configured.
+	timing map[string]RunwayTiming
+	// aliases maps the name of each runway's opposite end to the runway.
+	aliases map[string]string
+	// holdingSpells times the flights holding now; heldFor totals each
+	// flight's earlier spells.
+	holdingSpells map[int64]holdingSpell
+	heldFor       map[int64]time.Duration
+	// pattern is the racetrack flown at the holding fix, and fixWaits how
+	// long each flight cleared out of it flew on to the fix.
+	pattern  HoldingPattern
+	fixWaits map[int64]time.Duration
+	// lastAssigned is the arrival last assigned to each runway, and
+	// conflicts the most recent spacing conflicts, oldest first.
+	lastAssigned map[string]Flight
+	conflicts    []Conflict
 }
 
-// WindState captures the current wind speed (knots) and direction (degrees true).
+// WindState captures the current wind speed (knots) and direction (degrees
+// true). Runway headings are magnetic; the wind is converted under the
+// configured magnetic variation before the two are compared.
 type WindState struct {
 	Speed     int64 `json:"speed"`
 	Direction int64 `json:"direction"`
+	// Gust is the peak gust speed in knots, zero while the wind is steady.
+	Gust int64 `json:"gust,omitempty"`
+	// Variable is the range the direction swings across, if it varies.
+	Variable *WindVariation `json:"variable,omitempty"`
 }
 
-// RunwayDefinition describes the reference heading for a runway's primary threshold.
+// RunwayDefinition describes the magnetic heading of a runway's primary
+// threshold, the best approach category it is equipped for (CAT I when
+// empty), the equipage arrivals need to use it, the exits they vacate by
+// and its length.
 type RunwayDefinition struct {
-	Name    string
-	Heading float64
+	Name     string       `json:"name"`
+	Heading  float64      `json:"heading"`
+	Approach string       `json:"approach,omitempty"`
+	Requires []string     `json:"requires,omitempty"`
+	Exits    []RunwayExit `json:"exits,omitempty"`
+	// Reciprocal names the opposite end of the strip; when empty it is
+	// derived from Name, as 27 from 09.
+	Reciprocal string `json:"reciprocal,omitempty"`
+	// Length is the landing distance in meters; zero leaves it unchecked.
+	Length float64 `json:"length,omitempty"`
 }
 
 type runwayState struct {
 	definition    RunwayDefinition
 	open          bool
 	activeHeading float64
+	// incidentClosed is set while an equipment failure in low visibility
+	// makes the runway unusable, independently of operator closures.
+	incidentClosed bool
+	// shortening is set while part of the runway is closed.
+	shortening *RunwayShortening
+	stats      runwayStats
+}
+
+func (r *runwayState) available() bool {
+	return r.open && !r.incidentClosed
 }
 
 // NewRunwayManager constructs a RunwayManager for the supplied runway names.
-func NewRunwayManager(runways []RunwayDefinition, metrics *SchedulerMetrics) *RunwayManager {
+// The event bus is optional; when nil no events are published.
+func NewRunwayManager(runways []RunwayDefinition, metrics *SchedulerMetrics, events *EventBus) *RunwayManager {
 	rm := &RunwayManager{
-		runways:  make(map[string]*runwayState, len(runways)),
-		assigned: make(map[string][]Flight, len(runways)),
-		vectors:  make(map[int64]float64),
-		order:    make([]string, 0, len(runways)),
-		wind:     WindState{Speed: 0, Direction: 0},
-		lastUse:  make(map[string]time.Time, len(runways)),
-		metrics:  metrics,
+		runways:       make(map[string]*runwayState, len(runways)),
+		assigned:      make(map[string][]Flight, len(runways)),
+		vectors:       make(map[int64]float64),
+		order:         make([]string, 0, len(runways)),
+		strategy:      &RoundRobinStrategy{},
+		clock:         RealClock{},
+		wind:          WindState{Speed: 0, Direction: 0},
+		lastUse:       make(map[string]time.Time, len(runways)),
+		assignedAt:    make(map[int64]time.Time),
+		dueAt:         make(map[int64]time.Time),
+		estimatedAt:   make(map[int64]time.Time),
+		landings:      make(map[int64]func() bool),
+		delays:        make(map[int64]delayEntry),
+		emissions:     make(map[int64]*FlightEmissions),
+		usage:         make(map[runwayDirection]int64),
+		accounts:      make(map[string]account),
+		gates:         make(map[string]*gateState),
+		gatePlan:      make(map[int64]string),
+		gateStrategy:  NearestGateStrategy{},
+		taxiing:       make(map[int64]taxiOut),
+		departures:    make(map[string][]departure),
+		takingOff:     make(map[string]bool),
+		winter:        WinterOps{Pads: 2, Precipitation: PrecipitationNone},
+		transitions:   make(map[string]*runwayTransition),
+		parallelMode:  ParallelIndependent,
+		deferred:      make(map[int64]bool),
+		turnarounds:   make(map[int64]*turnaround),
+		airframes:     make(map[string]*airframe),
+		frictionRuns:  make(map[string]*frictionRun),
+		dispersals:    make(map[string]time.Time),
+		atmosphere:    standardAtmosphere,
+		storms:        make(map[string]stormCell),
+		stormBlocked:  make(map[string]bool),
+		ground:        make(map[string]GroundMovement),
+		incursions:    make(map[string]time.Time),
+		goingAround:   make(map[int64]Flight),
+		incidents:     make(map[string]Incident),
+		phases:        make(map[int64]FlightPhase),
+		phaseCounts:   make(map[FlightPhase]int),
+		holdingSpells: make(map[int64]holdingSpell),
+		fixWaits:      make(map[int64]time.Duration),
+		heldFor:       make(map[int64]time.Duration),
+		lastAssigned:  make(map[string]Flight),
+		visibility:    defaultVisibility,
+		ceiling:       defaultCeiling,
+		metrics:       metrics,
+		events:        events,
 	}
 	for _, r := range runways {
-		rm.runways[r.Name] = &runwayState{definition: r, open: true, activeHeading: normalizeHeading(r.Heading)}
+		rm.runways[r.Name] = &runwayState{definition: r, open: true, activeHeading: normalizeHeading(r.Heading), stats: runwayStats{since: rm.clock.Now()}}
 		rm.order = append(rm.order, r.Name)
 	}
+	for _, r := range runways {
+		rm.addAliasLocked(r.Name)
+	}
 	rm.updateActiveHeadingsLocked()
 	return rm
 }
 
 // Run consumes flight arrivals and assigns them to available runways using
 // round-robin sequencing. Flights are diverted to holding if no runways are
-// available.
+// available. The manager stops when Run returns, as it does once the
+// context is canceled or flights is closed.
 func (rm *RunwayManager) Run(ctx context.Context, flights <-chan Flight) {
+	defer rm.Stop()
 	for {
 		select {
 		case <-ctx.Done():
@@ -73,88 +294,326 @@ func (rm *RunwayManager) Run(ctx context.Context, flights <-chan Flight) {
 			if !ok {
 				return
 			}
-			log.Printf("spawned flight %d (%s)", f.ID, f.Call)
-			rm.AssignFlight(f)
+			rm.Arrive(f)
 		}
 	}
 }
 
+// Arrive announces a newly spawned flight and routes it through assignment.
+func (rm *RunwayManager) Arrive(f Flight) {
+	rm.mu.Lock()
+	admitted := rm.admitArrivalLocked(f)
+	rm.mu.Unlock()
+	if !admitted {
+		rm.admitDeferred()
+		return
+	}
+	rm.enter(f)
+}
+
+// enter brings an admitted arrival into the airspace.
+func (rm *RunwayManager) enter(f Flight) {
+	rm.mu.Lock()
+	hook := rm.onArrive
+	rm.mu.Unlock()
+	if hook != nil {
+		f = hook(f)
+	}
+	log.Printf("spawned flight %d (%s)", f.ID, f.Call)
+	rm.mu.Lock()
+	rm.publishEventLocked(Event{Type: EventSpawned, FlightID: f.ID, Call: f.Call})
+	rm.spawnLocked(f)
+	if rm.maxFlights > 0 && rm.arrivalsLocked() >= rm.maxFlights {
+		rm.divertFlightLocked(f, fmt.Sprintf("flight limit of %d reached", rm.maxFlights))
+		rm.mu.Unlock()
+		return
+	}
+	rm.mu.Unlock()
+	rm.AssignFlight(f)
+}
+
+// SetMaxFlights diverts new arrivals while max flights are already
+// sequenced, holding or going around; zero removes the limit.
+func (rm *RunwayManager) SetMaxFlights(max int) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.maxFlights = max
+}
+
+// Arrivals counts the flights sequenced, holding or going around.
+func (rm *RunwayManager) Arrivals() int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.arrivalsLocked()
+}
+
+func (rm *RunwayManager) arrivalsLocked() int {
+	n := len(rm.holding) + len(rm.goingAround)
+	for _, name := range rm.order {
+		n += len(rm.assigned[name])
+	}
+	return n
+}
+
 // AssignFlight assigns a flight to the next available runway, or to holding
 // if none are available.
 func (rm *RunwayManager) AssignFlight(f Flight) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
+	if rm.divertIfIncompatibleLocked(f) {
+		return
+	}
 	rm.updateActiveHeadingsLocked()
-	runway := rm.nextRunway()
+	delete(rm.deferred, f.ID)
+	runway, reason, cause := rm.nextRunway(f)
 	if runway == "" {
+		if reason == reasonDeferred {
+			rm.deferred[f.ID] = true
+		}
+		rm.logDecisionLocked(DecisionHold, f, "")
+		rm.startDelayLocked(f, cause)
+		rm.transmitLocked(holdInstruction(f, "", SourceScheduler, reason))
+		delete(rm.radio.waits, f.ID)
 		rm.holding = append(rm.holding, f)
+		rm.setPhaseLocked(f, PhaseHolding, "")
 		rm.recordHoldingLocked(1)
 		rm.publishHoldingLocked()
-		log.Printf("flight %d (%s) holding: no runway available", f.ID, f.Call)
+		rm.publishEventLocked(Event{Type: EventHolding, FlightID: f.ID, Call: f.Call, Detail: reason})
+		log.Printf("flight %d (%s) holding: %s", f.ID, f.Call, reason)
 		return
 	}
 
+	rm.logDecisionLocked(DecisionAssign, f, runway)
+	rm.leaveFixLocked(f)
+	rm.endDelayLocked(f)
 	rm.assigned[runway] = append(rm.assigned[runway], f)
-	targetHeading := rm.runways[runway].activeHeading
+	rm.setPhaseLocked(f, PhaseSequenced, runway)
+	targetHeading := rm.arrivalHeadingLocked(runway, f.ID)
 	rm.vectors[f.ID] = rm.smoothVector(rm.vectors[f.ID], targetHeading)
-	rm.recordAssignmentLocked(time.Since(f.CreatedAt))
-	rm.detectConflictLocked(runway)
-	rm.lastUse[runway] = time.Now()
+	rm.vectorLocked(f, runway)
+	now := rm.clock.Now()
+	rm.recordAssignmentLocked(runway, now.Sub(f.CreatedAt))
+	rm.detectConflictLocked(runway, f)
+	rm.lastUse[runway] = now
+	rm.lastAssigned[runway] = f
 	rm.publishQueuesLocked(runway)
+	rm.publishEventLocked(Event{Type: EventAssigned, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: fmt.Sprintf("heading %.0f", rm.vectors[f.ID])})
 	log.Printf("flight %d (%s) assigned to %s on heading %.0f°", f.ID, f.Call, runway, rm.vectors[f.ID])
 
-	assignedAt := time.Now()
-	go rm.completeLanding(runway, f, assignedAt)
+	rm.assignedAt[f.ID] = now
+	if d := rm.stormDeviationLocked(runway); d > 0 {
+		if rm.metrics != nil {
+			rm.metrics.RecordDelay(DelayWeather, d)
+		}
+		log.Printf("flight %d (%s) vectored around thunderstorm on approach to %s", f.ID, f.Call, runway)
+	}
+	rm.recordSpacingDelayLocked(runway, f)
+	rm.scheduleLandingLocked(runway, f, now, rm.sequencedLandingLocked(runway, f))
+	rm.estimateTouchdownLocked(runway, f)
+	rm.planGateLocked(f, rm.dueAt[f.ID])
+}
+
+// SetClock replaces the clock driving landings and timestamps. It must be
+// called before any flights are assigned.
+func (rm *RunwayManager) SetClock(clock Clock) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.clock = clock
+	for _, r := range rm.runways {
+		r.stats.since = clock.Now()
+	}
+}
+
+// Clock returns the clock driving the scheduler.
+func (rm *RunwayManager) Clock() Clock {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.clock
+}
+
+// SetArrivalHook installs a function that may amend each flight before it is
+// announced and sequenced. The hook runs without the scheduler lock held.
+func (rm *RunwayManager) SetArrivalHook(hook func(Flight) Flight) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.onArrive = hook
+}
+
+// SetDecisionLog enables write-ahead logging of scheduler decisions.
+func (rm *RunwayManager) SetDecisionLog(wal *DecisionLog) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.wal = wal
+}
+
+// Restore reinstates outstanding work recovered from the decision log.
+// Landings in progress resume on their original runway with what remains of
+// their modeled approach and occupancy; flights whose runway is now unknown,
+// closed or shut by an incident go to holding. Deferred arrivals queue at
+// the acceptance gate again and enter as it allows.
+// Recovered flights are not counted as new arrivals.
+func (rm *RunwayManager) Restore(state RecoveredState) {
+	// Deferred arrivals enter once the lock is released, as the gate allows.
+	defer rm.admitDeferred()
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	for _, d := range state.InProgress {
+		r, ok := rm.runways[d.Runway]
+		if !ok || !r.available() {
+			rm.holding = append(rm.holding, d.Flight)
+			rm.setPhaseLocked(d.Flight, PhaseHolding, "")
+			continue
+		}
+		rm.assigned[d.Runway] = append(rm.assigned[d.Runway], d.Flight)
+		rm.setPhaseLocked(d.Flight, PhaseSequenced, d.Runway)
+		rm.assignedAt[d.Flight.ID] = d.At
+		rm.vectors[d.Flight.ID] = r.activeHeading
+		rm.publishQueuesLocked(d.Runway)
+		// The landing keeps its original assignment time, so the sequence
+		// leaves it only what remains of its modeled approach.
+		remaining := max(rm.sequencedLandingLocked(d.Runway, d.Flight), 0)
+		rm.scheduleLandingLocked(d.Runway, d.Flight, d.At, remaining)
+	}
+	rm.holding = append(rm.holding, state.Holding...)
+	for _, f := range state.Holding {
+		rm.setPhaseLocked(f, PhaseHolding, "")
+	}
+	rm.publishHoldingLocked()
+	for _, d := range state.Deferred {
+		rm.acceptance.deferred = append(rm.acceptance.deferred, deferredArrival{flight: d.Flight, since: d.At})
+	}
+	if n := len(state.InProgress) + len(state.Holding) + len(state.Deferred); n > 0 {
+		log.Printf("restored %d landings in progress, %d holding flights and %d deferred arrivals", len(state.InProgress), len(state.Holding), len(state.Deferred))
+	}
 }
 
 // SetRunwayClosed updates the runway state and handles diversion logic.
 func (rm *RunwayManager) SetRunwayClosed(runway string, closed bool) {
 	rm.mu.Lock()
+	reopened := rm.setRunwayClosedLocked(runway, closed)
+	rm.mu.Unlock()
+	if reopened {
+		rm.releaseHolding()
+		rm.proposeRecovery()
+	}
+}
+
+// setRunwayClosedLocked closes or reopens runway, reporting whether it
+// reopened, in which case the caller should release the holding stack and
+// propose a recovery plan once the lock is dropped.
+func (rm *RunwayManager) setRunwayClosedLocked(runway string, closed bool) bool {
+	runway = rm.resolveLocked(runway)
 	r, ok := rm.runways[runway]
 	if !ok {
 		// Unknown runway, nothing to do.
-		rm.mu.Unlock()
 		log.Printf("runway command ignored: unknown runway %s", runway)
-		return
+		return false
 	}
 
 	if closed {
 		if !r.open {
-			rm.mu.Unlock()
-			return
+			return false
 		}
-		r.open = false
-		diverted := rm.assigned[runway]
-		if len(diverted) > 0 {
-			rm.holding = append(rm.holding, diverted...)
-			rm.assigned[runway] = nil
-			rm.publishQueuesLocked(runway)
-			rm.recordHoldingLocked(len(diverted))
-			rm.publishHoldingLocked()
-			log.Printf("runway %s closed; diverted %d flights to holding", runway, len(diverted))
+		r.setOpen(false, rm.clock.Now())
+		rm.version++
+		rm.publishEventLocked(Event{Type: EventRunwayClosed, Runway: runway})
+		if n := rm.divertLocked(runway, SourceOperator, "diverted by closure"); n > 0 {
+			log.Printf("runway %s closed; diverted %d flights to holding", rm.runwayEndsLocked(runway), n)
 		} else {
-			log.Printf("runway %s closed", runway)
+			log.Printf("runway %s closed", rm.runwayEndsLocked(runway))
 		}
-		rm.mu.Unlock()
-		return
+		return false
 	}
 
 	if r.open {
-		rm.mu.Unlock()
-		return
+		return false
+	}
+
+	r.setOpen(true, rm.clock.Now())
+	rm.version++
+	rm.publishEventLocked(Event{Type: EventRunwayOpened, Runway: runway})
+	log.Printf("runway %s reopened", rm.runwayEndsLocked(runway))
+	return true
+}
+
+// divertLocked sends every flight queued for runway to holding and returns
+// how many were diverted.
+func (rm *RunwayManager) divertLocked(runway, source, reason string) int {
+	diverted := rm.assigned[runway]
+	if len(diverted) == 0 {
+		return 0
+	}
+	for _, f := range diverted {
+		rm.transmitLocked(holdInstruction(f, runway, source, reason))
+		delete(rm.radio.waits, f.ID)
+		rm.logDecisionLocked(DecisionHold, f, "")
+		rm.startDelayLocked(f, DelayRunwayClosure)
+		rm.cancelGateLocked(f.ID)
+		delete(rm.assignedAt, f.ID)
+		rm.setPhaseLocked(f, PhaseHolding, runway)
+	}
+	rm.holding = append(rm.holding, diverted...)
+	rm.assigned[runway] = nil
+	rm.publishQueuesLocked(runway)
+	rm.recordHoldingLocked(len(diverted))
+	rm.publishHoldingLocked()
+	for _, f := range diverted {
+		rm.publishEventLocked(Event{Type: EventHolding, FlightID: f.ID, Call: f.Call, Runway: runway, Detail: reason})
 	}
+	return len(diverted)
+}
 
-	r.open = true
+// releaseHolding resequences every holding flight and lines up waiting
+// departures, typically after a runway becomes available again.
+func (rm *RunwayManager) releaseHolding() {
+	rm.mu.Lock()
+	for _, name := range rm.order {
+		rm.releaseDepartureLocked(name)
+	}
 	holding := rm.holding
 	rm.holding = nil
 	rm.publishHoldingLocked()
+	auction, now := rm.auction, rm.clock.Now()
 	rm.mu.Unlock()
 
-	log.Printf("runway %s reopened; reassigning %d holding flights", runway, len(holding))
+	if auction != nil && len(holding) > 1 {
+		holding = auction.Allocate(holding, now)
+	}
+	if len(holding) > 0 {
+		log.Printf("reassigning %d holding flights", len(holding))
+	}
 	for _, f := range holding {
 		rm.AssignFlight(f)
 	}
+	rm.admitDeferred()
+}
+
+// Outstanding returns the landings in progress, the holding stack and the
+// arrivals deferred by the acceptance gate in the same shape the decision
+// log recovers, so the work can be resumed elsewhere.
+func (rm *RunwayManager) Outstanding() RecoveredState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	state := RecoveredState{InProgress: []Decision{}, Holding: []Flight{}, Deferred: []Decision{}}
+	for _, name := range rm.order {
+		for _, f := range rm.assigned[name] {
+			state.InProgress = append(state.InProgress, Decision{Kind: DecisionAssign, Flight: f, Runway: name, At: rm.assignedAt[f.ID]})
+		}
+	}
+	state.Holding = append(state.Holding, rm.holding...)
+	goingAround := make([]Flight, 0, len(rm.goingAround))
+	for _, f := range rm.goingAround {
+		goingAround = append(goingAround, f)
+	}
+	sort.Slice(goingAround, func(i, j int) bool { return goingAround[i].ID < goingAround[j].ID })
+	state.Holding = append(state.Holding, goingAround...)
+	for _, d := range rm.acceptance.deferred {
+		state.Deferred = append(state.Deferred, Decision{Kind: DecisionDefer, Flight: d.flight, At: d.since})
+	}
+	return state
 }
 
 // IsClosed returns true when the runway is currently closed.
@@ -162,7 +621,7 @@ func (rm *RunwayManager) IsClosed(runway string) bool {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	state, ok := rm.runways[runway]
+	state, ok := rm.runways[rm.resolveLocked(runway)]
 	if !ok {
 		return false
 	}
@@ -179,20 +638,85 @@ func (rm *RunwayManager) RunwayNames() []string {
 	return names
 }
 
-func (rm *RunwayManager) nextRunway() string {
+// Strategy returns the runway assignment strategy in use.
+func (rm *RunwayManager) Strategy() AssignmentStrategy {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.strategy
+}
+
+// SetStrategy replaces the runway assignment strategy.
+func (rm *RunwayManager) SetStrategy(strategy AssignmentStrategy) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.strategy = strategy
+}
+
+// nextRunway picks the runway for f among those open, compatible with its
+// equipage, within its approach minima and with a final approach clear of
+// thunderstorms. When there is none it returns the reason and the delay
+// cause.
+func (rm *RunwayManager) nextRunway(f Flight) (string, string, string) {
 	open := rm.openRunways()
 	if len(open) == 0 {
-		return ""
+		return "", "no runway available", DelayRunwayClosure
+	}
+	candidates := make([]RunwayCandidate, 0, len(open))
+	equipped, long, minima := false, false, false
+	for _, name := range open {
+		if !rm.equippedForLocked(name, f) {
+			continue
+		}
+		equipped = true
+		if !rm.longEnoughLocked(name, f) {
+			continue
+		}
+		long = true
+		if !rm.withinMinimaLocked(name, f) {
+			continue
+		}
+		minima = true
+		if rm.stormBlocked[name] {
+			continue
+		}
+		candidates = append(candidates, RunwayCandidate{
+			Name:        name,
+			Heading:     rm.runways[name].activeHeading,
+			QueueLength: len(rm.assigned[name]),
+			LastUse:     rm.lastUse[name],
+			Preference:  rm.preferenceLocked(name),
+			Weight:      preferenceWeights[rm.preferenceLocked(name)],
+		})
 	}
-	runway := open[rm.nextIdx%len(open)]
-	rm.nextIdx++
-	return runway
+	if len(candidates) == 0 {
+		if !equipped {
+			return "", "no open runway compatible with equipage", DelayRunwayClosure
+		}
+		if !long {
+			return "", "no open runway long enough", DelayRunwayClosure
+		}
+		if !minima {
+			return "", "below approach minima", DelayWeather
+		}
+		return "", "approach blocked by thunderstorm", DelayWeather
+	}
+	runway := rm.strategy.SelectRunway(f, candidates)
+	for _, c := range candidates {
+		if c.Name == runway {
+			return runway, "", ""
+		}
+	}
+	if runway == "" {
+		return "", reasonDeferred, DelayVolume
+	}
+	log.Printf("strategy chose unavailable runway %q for flight %d; holding", runway, f.ID)
+	return "", "no runway available", DelayRunwayClosure
 }
 
 func (rm *RunwayManager) openRunways() []string {
 	open := make([]string, 0, len(rm.order))
 	for _, name := range rm.order {
-		if rm.runways[name].open {
+		if rm.runways[name].available() {
 			open = append(open, name)
 		}
 	}
@@ -202,11 +726,22 @@ func (rm *RunwayManager) openRunways() []string {
 // SetWind updates the active wind state and re-vectors existing assignments to
 // fly toward the new into-wind threshold.
 func (rm *RunwayManager) SetWind(speed, direction int64) {
+	rm.SetWindState(WindState{Speed: speed, Direction: direction})
+}
+
+// SetWindState is SetWind for a wind that may gust or vary in direction.
+func (rm *RunwayManager) SetWindState(w WindState) {
 	rm.mu.Lock()
-	rm.wind = WindState{Speed: maxInt64(speed, 0), Direction: normalizeDirection(direction)}
+	rm.setWindLocked(w)
+	rm.mu.Unlock()
+}
+
+func (rm *RunwayManager) setWindLocked(w WindState) {
+	rm.wind = normalizeWind(w)
+	rm.version++
 	rm.updateActiveHeadingsLocked()
 	rm.revectorLocked()
-	rm.mu.Unlock()
+	rm.publishEventLocked(Event{Type: EventWindChanged, Detail: windDetail(rm.wind)})
 }
 
 // Wind returns the current wind state.
@@ -217,22 +752,31 @@ func (rm *RunwayManager) Wind() WindState {
 	return rm.wind
 }
 
+// updateActiveHeadingsLocked turns runways into the wind, sequencing the
+// change of direction around the arrivals already queued.
 func (rm *RunwayManager) updateActiveHeadingsLocked() {
-	for _, r := range rm.runways {
-		r.activeHeading = rm.bestHeading(r.definition)
+	for _, name := range rm.order {
+		rm.changeDirectionLocked(name, rm.bestHeading(rm.runways[name].definition))
 	}
 }
 
 func (rm *RunwayManager) bestHeading(def RunwayDefinition) float64 {
+	return headingForWind(def, rm.magneticWindLocked())
+}
+
+// headingForWind is the end of def facing most nearly into wind, given in
+// degrees magnetic like runway headings. A gusting or variable wind is
+// taken at its worst, so the end chosen is the one its tailwind can least
+// affect.
+func headingForWind(def RunwayDefinition, wind WindState) float64 {
 	base := normalizeHeading(def.Heading)
 	reciprocal := normalizeHeading(def.Heading + 180)
 
-	if rm.wind.Speed == 0 {
+	if wind.Speed == 0 {
 		return base
 	}
 
-	windDir := float64(rm.wind.Direction)
-	if angularDiff(windDir, base) <= angularDiff(windDir, reciprocal) {
+	if worstHeadwind(base, wind) >= worstHeadwind(reciprocal, wind) {
 		return base
 	}
 	return reciprocal
@@ -240,12 +784,12 @@ func (rm *RunwayManager) bestHeading(def RunwayDefinition) float64 {
 
 func (rm *RunwayManager) revectorLocked() {
 	for runway, flights := range rm.assigned {
-		target := rm.runways[runway].activeHeading
 		for _, f := range flights {
 			prev := rm.vectors[f.ID]
-			next := rm.smoothVector(prev, target)
+			next := rm.smoothVector(prev, rm.arrivalHeadingLocked(runway, f.ID))
 			rm.vectors[f.ID] = next
 			if prev != next {
+				rm.transmitLocked(headingInstruction(f, runway, next))
 				log.Printf("flight %d (%s) re-vectored toward heading %.0f° for runway %s", f.ID, f.Call, next, runway)
 			}
 		}
@@ -265,11 +809,12 @@ func (rm *RunwayManager) smoothVector(current, target float64) float64 {
 	return normalizeHeading(current + delta)
 }
 
-func (rm *RunwayManager) recordAssignmentLocked(wait time.Duration) {
+func (rm *RunwayManager) recordAssignmentLocked(runway string, wait time.Duration) {
 	if rm.metrics == nil {
 		return
 	}
 	rm.metrics.RecordAssignment(wait)
+	rm.metrics.RecordRunwayAssignment(runway, rm.preferenceLocked(runway))
 }
 
 func (rm *RunwayManager) recordHoldingLocked(count int) {
@@ -288,45 +833,187 @@ func (rm *RunwayManager) publishHoldingLocked() {
 	rm.metrics.SetHolding(len(rm.holding))
 }
 
+// publishQueuesLocked follows a change to runway's queue: its next arrival is
+// cleared to final and the queue length gauge updated.
 func (rm *RunwayManager) publishQueuesLocked(runway string) {
+	rm.clearToFinalLocked(runway)
 	if rm.metrics == nil {
 		return
 	}
 	rm.metrics.UpdateQueueLength(runway, len(rm.assigned[runway]))
 }
 
-func (rm *RunwayManager) detectConflictLocked(runway string) {
-	last, ok := rm.lastUse[runway]
-	if !ok {
+// logDecisionLocked durably records a decision before the caller acts on it.
+func (rm *RunwayManager) logDecisionLocked(kind string, f Flight, runway string) {
+	if rm.wal == nil {
 		return
 	}
-	delta := time.Since(last)
-	if delta < minArrivalSpacing {
-		if rm.metrics != nil {
-			rm.metrics.RecordConflict()
-		}
-		log.Printf("spacing conflict detected on %s (%.1fs apart)", runway, delta.Seconds())
+	if err := rm.wal.Append(Decision{Kind: kind, Flight: f, Runway: runway, At: rm.clock.Now()}); err != nil {
+		log.Printf("decision log append (%s flight %d): %v", kind, f.ID, err)
+	}
+}
+
+func (rm *RunwayManager) publishEventLocked(e Event) {
+	if rm.events == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = rm.clock.Now()
+	}
+	rm.events.Publish(e)
+}
+
+// scheduleLandingLocked arranges for the flight to touch down after the given
+// rollout time on the manager's clock, replacing any landing already
+// scheduled for it. Once the manager is stopped the due time is recorded but
+// no timer started.
+func (rm *RunwayManager) scheduleLandingLocked(runway string, f Flight, assignedAt time.Time, after time.Duration) {
+	due := rm.clock.Now().Add(after)
+	rm.dueAt[f.ID] = due
+	rm.clearToFinalLocked(runway)
+	rm.cancelLandingLocked(f.ID)
+	if rm.stopped {
+		return
 	}
+	// Chaos mode holds the landing past the due time it leaves recorded.
+	after += rm.chaos.landingDelay()
+	rm.landings[f.ID] = rm.clock.AfterFunc(after, func() { rm.completeLanding(runway, f, assignedAt, due) })
 }
 
-func (rm *RunwayManager) completeLanding(runway string, f Flight, assignedAt time.Time) {
-	const landingDuration = 5 * time.Second
-	time.Sleep(landingDuration)
+// cancelLandingLocked stops the landing timer of flight id, if any.
+func (rm *RunwayManager) cancelLandingLocked(id int64) {
+	if stop, ok := rm.landings[id]; ok {
+		stop()
+		delete(rm.landings, id)
+	}
+}
+
+// Stop shuts the manager down: pending landings are canceled and their
+// flights left sequenced, so Outstanding captures them for a successor to
+// Restore, and landing timers that already fired do nothing. Stop is safe
+// to call more than once; Run calls it on return.
+func (rm *RunwayManager) Stop() {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.stopped {
+		return
+	}
+	rm.stopped = true
+	pending := len(rm.landings)
+	for id := range rm.landings {
+		rm.cancelLandingLocked(id)
+	}
+	if pending > 0 {
+		log.Printf("scheduler stopped with %d landings in progress", pending)
+	}
+}
 
+func (rm *RunwayManager) completeLanding(runway string, f Flight, assignedAt, due time.Time) {
 	rm.mu.Lock()
+	// The timer may have fired just as the manager stopped; the flight is
+	// left sequenced for the successor.
+	if rm.stopped {
+		rm.mu.Unlock()
+		return
+	}
+	// A flight resequenced since this landing was scheduled lands at its
+	// own due time instead.
+	if d, ok := rm.dueAt[f.ID]; ok && !d.Equal(due) {
+		rm.mu.Unlock()
+		return
+	}
+	delete(rm.dueAt, f.ID)
+	delete(rm.landings, f.ID)
 	queue := rm.assigned[runway]
+	idx := -1
 	for i, candidate := range queue {
 		if candidate.ID == f.ID {
-			queue = append(queue[:i], queue[i+1:]...)
+			idx = i
 			break
 		}
 	}
-	rm.assigned[runway] = queue
+	if idx < 0 {
+		// Diverted off this runway before touchdown; nothing landed.
+		rm.mu.Unlock()
+		return
+	}
+	if rm.occupiedLocked(runway) {
+		rm.openIncursionLocked(runway, f)
+		rm.goAroundLocked(runway, f, idx, "runway occupied")
+		rm.mu.Unlock()
+		return
+	}
+	if rm.wildlifeGoAroundLocked(f, due) {
+		rm.goAroundLocked(runway, f, idx, "wildlife on final")
+		disperse := rm.dispersalDueLocked(runway)
+		rm.mu.Unlock()
+		if disperse {
+			rm.disperseWildlife(runway)
+		}
+		return
+	}
+	// A flight resequenced ahead of the one on final passes through final
+	// as it lands.
+	rm.setPhaseLocked(f, PhaseFinal, runway)
+	rm.setPhaseLocked(f, PhaseLanded, runway)
+	rm.logDecisionLocked(DecisionLand, f, runway)
+	occupancy := rm.landingTimeLocked(runway, f)
+	rm.closeEmissionsLocked(f)
+	rm.recordUsageLocked(runway)
+	rm.recordLandingStatsLocked(runway, occupancy)
+	rm.billLocked(runway, f)
+	rm.parkLocked(f)
+	rm.assigned[runway] = append(queue[:idx], queue[idx+1:]...)
+	delete(rm.assignedAt, f.ID)
 	rm.publishQueuesLocked(runway)
+	landedAt := rm.clock.Now()
+	landed := Event{Type: EventLanded, FlightID: f.ID, Call: f.Call, Runway: runway}
+	etaErr, estimated := rm.touchdownErrorLocked(f, landedAt)
+	if estimated {
+		landed.Detail = etaDetail(etaErr)
+	}
+	rm.publishEventLocked(landed)
+	slas := rm.slas
 	rm.mu.Unlock()
 
 	if rm.metrics != nil {
-		rm.metrics.RecordLanding(time.Since(assignedAt))
+		rm.metrics.RecordLanding(landedAt.Sub(assignedAt))
+		rm.metrics.RecordOccupancy(occupancy)
+		if estimated {
+			rm.metrics.RecordETAError(etaErr)
+		}
+	}
+	if slas != nil {
+		slas.Observe(SLAWait, landedAt.Sub(f.CreatedAt), landedAt)
+		slas.Observe(SLADelay, landedAt.Sub(scheduledArrival(f)), landedAt)
+	}
+	rm.releaseDeferred()
+	rm.admitDeferred()
+}
+
+// releaseDeferred offers the flights the assignment strategy deferred to it
+// again, in the order they entered holding.
+func (rm *RunwayManager) releaseDeferred() {
+	rm.mu.Lock()
+	if len(rm.deferred) == 0 {
+		rm.mu.Unlock()
+		return
+	}
+	var offered []Flight
+	kept := rm.holding[:0]
+	for _, f := range rm.holding {
+		if rm.deferred[f.ID] {
+			offered = append(offered, f)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	rm.holding = kept
+	rm.publishHoldingLocked()
+	rm.mu.Unlock()
+
+	for _, f := range offered {
+		rm.AssignFlight(f)
 	}
 }
 
@@ -0,0 +1,136 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// runwayStats accumulates one runway's movements and how its time was
+// spent since it was commissioned or the clock was set.
+type runwayStats struct {
+	since     time.Time
+	takeoffs  int64
+	landings  int64
+	occupancy time.Duration
+	takeoff   time.Duration
+	// unavailable totals the time the runway was closed or unusable, and
+	// unavailableAt is when the current spell began, zero while available.
+	unavailable   time.Duration
+	unavailableAt time.Time
+}
+
+// RunwayStats is a runway's traffic and utilization so far. Landings are
+// split by direction. The runway is occupied while arrivals land and
+// departures take off, unavailable while closed or unusable, and idle the
+// rest of the time; Utilization is the occupied share of the time it was
+// available.
+type RunwayStats struct {
+	Since                   time.Time     `json:"since"`
+	Landings                int64         `json:"landings"`
+	Takeoffs                int64         `json:"takeoffs"`
+	Directions              []RunwayUsage `json:"directions"`
+	AverageOccupancySeconds float64       `json:"averageOccupancySeconds"`
+	OccupiedSeconds         float64       `json:"occupiedSeconds"`
+	UnavailableSeconds      float64       `json:"unavailableSeconds"`
+	IdleSeconds             float64       `json:"idleSeconds"`
+	Utilization             float64       `json:"utilization"`
+}
+
+// setOpen applies an operator closure or reopening at now.
+func (r *runwayState) setOpen(open bool, now time.Time) {
+	was := r.available()
+	r.open = open
+	r.trackAvailability(was, now)
+}
+
+// setIncidentClosed applies an equipment closure or its end at now.
+func (r *runwayState) setIncidentClosed(closed bool, now time.Time) {
+	was := r.available()
+	r.incidentClosed = closed
+	r.trackAvailability(was, now)
+}
+
+// trackAvailability starts or ends a spell of unavailability once the
+// runway's availability changed from was.
+func (r *runwayState) trackAvailability(was bool, now time.Time) {
+	switch is := r.available(); {
+	case was && !is:
+		r.stats.unavailableAt = now
+	case !was && is:
+		r.stats.unavailable += now.Sub(r.stats.unavailableAt)
+		r.stats.unavailableAt = time.Time{}
+	}
+}
+
+// recordLandingStatsLocked counts a landing occupying runway for occupancy.
+func (rm *RunwayManager) recordLandingStatsLocked(runway string, occupancy time.Duration) {
+	s := &rm.runways[runway].stats
+	s.landings++
+	s.occupancy += occupancy
+}
+
+// recordTakeoffStatsLocked counts a departure occupying runway for
+// duration.
+func (rm *RunwayManager) recordTakeoffStatsLocked(runway string, duration time.Duration) {
+	s := &rm.runways[runway].stats
+	s.takeoffs++
+	s.takeoff += duration
+}
+
+// runwayStatsLocked reports runway's statistics as of now.
+func (rm *RunwayManager) runwayStatsLocked(runway string) RunwayStats {
+	r := rm.runways[runway]
+	s := r.stats
+	now := rm.clock.Now()
+	unavailable := s.unavailable
+	if !s.unavailableAt.IsZero() {
+		unavailable += now.Sub(s.unavailableAt)
+	}
+	occupied := s.occupancy + s.takeoff
+	available := max(now.Sub(s.since)-unavailable, 0)
+	stats := RunwayStats{
+		Since:              s.since,
+		Landings:           s.landings,
+		Takeoffs:           s.takeoffs,
+		Directions:         []RunwayUsage{},
+		OccupiedSeconds:    occupied.Seconds(),
+		UnavailableSeconds: unavailable.Seconds(),
+		IdleSeconds:        max(available-occupied, 0).Seconds(),
+	}
+	if s.landings > 0 {
+		stats.AverageOccupancySeconds = s.occupancy.Seconds() / float64(s.landings)
+	}
+	if available > 0 {
+		stats.Utilization = min(float64(occupied)/float64(available), 1)
+	}
+	for key, n := range rm.usage {
+		if key.runway == runway {
+			stats.Directions = append(stats.Directions, RunwayUsage{Runway: runway, Heading: key.heading, Landings: n})
+		}
+	}
+	sort.Slice(stats.Directions, func(i, j int) bool { return stats.Directions[i].Heading < stats.Directions[j].Heading })
+	return stats
+}
+
+// Runway reports one runway as it appears in the state snapshot, found by
+// the name of either end.
+func (rm *RunwayManager) Runway(name string) (RunwaySnapshot, bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	name = rm.resolveLocked(name)
+	if _, ok := rm.runways[name]; !ok {
+		return RunwaySnapshot{}, false
+	}
+	return rm.runwaySnapshotLocked(name), true
+}
+
+// handleRunwayDetail reports a runway with its queue and statistics.
+func (s *Server) handleRunwayDetail(w http.ResponseWriter, r *http.Request) {
+	runway, ok := s.Runways.Runway(r.PathValue("runway"))
+	if !ok {
+		http.Error(w, "unknown runway", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, runway)
+}
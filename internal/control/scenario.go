@@ -0,0 +1,467 @@
+package control
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// EventScenarioStarted is published when a scenario is started.
+const EventScenarioStarted = "scenarioStarted"
+
+//go:embed scenarios/*.json
+var scenarioFiles embed.FS
+
+// builtinScenarios are the scenarios shipped in scenarios/, by name.
+var builtinScenarios = loadBuiltinScenarios()
+
+// scenarioTemplate is a scenario file and its parameter defaults. Files are
+// text/templates over JSON in which {{param "name"}} is a parameter's value
+// and {{add a b}} a sum, as in an offset after a parameterised duration.
+type scenarioTemplate struct {
+	tmpl   *template.Template
+	params []ScenarioParam
+}
+
+// scenarioNumber is a parameter value, printed without an exponent so it
+// decodes into integer fields.
+type scenarioNumber float64
+
+func (n scenarioNumber) String() string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+// ScenarioParam is a value a scenario can be started with, such as a peak
+// rate or a closure duration.
+type ScenarioParam struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Default     float64 `json:"default"`
+}
+
+// ScenarioStartRequest starts a scenario with parameter values in place of
+// the defaults.
+type ScenarioStartRequest struct {
+	Params map[string]float64 `json:"params,omitempty"`
+}
+
+// ScenarioStep is one change a scenario makes AtSeconds after it starts:
+// exactly one of a command, an equipment failure, a storm cell or a
+// wildlife event.
+type ScenarioStep struct {
+	AtSeconds int64            `json:"atSeconds"`
+	Command   *Command         `json:"command,omitempty"`
+	Incident  *IncidentRequest `json:"incident,omitempty"`
+	Storm     *StormCell       `json:"storm,omitempty"`
+	Wildlife  *WildlifeEvent   `json:"wildlife,omitempty"`
+}
+
+// WildlifeEvent is bird activity a scenario injects: a new hazard, a flock
+// on a runway that closes it for dispersal, or both.
+type WildlifeEvent struct {
+	Hazard *WildlifeHazard `json:"hazard,omitempty"`
+	Runway string          `json:"runway,omitempty"`
+}
+
+// command is the wildlife command with the same effect as e.
+func (e WildlifeEvent) command() Command {
+	return Command{Type: "wildlife", Wildlife: e.Hazard, Runway: e.Runway}
+}
+
+// Scenario is a scripted sequence of operating conditions. ExpectedOutcome
+// documents what a correct scheduler does under it, so a run can be
+// checked against it.
+type Scenario struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Runways are the runways the steps refer to, which the airport must
+	// have.
+	Runways         []string        `json:"runways"`
+	DurationSeconds int64           `json:"durationSeconds"`
+	Params          []ScenarioParam `json:"params,omitempty"`
+	ExpectedOutcome []string        `json:"expectedOutcome"`
+	Steps           []ScenarioStep  `json:"steps"`
+}
+
+// ScenarioRun is the scenario started most recently and the parameter
+// values it was started with.
+type ScenarioRun struct {
+	Name      string             `json:"name"`
+	Params    map[string]float64 `json:"params,omitempty"`
+	StartedAt time.Time          `json:"startedAt"`
+	EndsAt    time.Time          `json:"endsAt"`
+}
+
+// scenarioState tracks the running scenario and its pending steps.
+type scenarioState struct {
+	mu     sync.Mutex
+	active *ScenarioRun
+	stops  []func() bool
+}
+
+func loadBuiltinScenarios() map[string]scenarioTemplate {
+	files, err := fs.Glob(scenarioFiles, "scenarios/*.json")
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]scenarioTemplate, len(files))
+	for _, name := range files {
+		data, err := scenarioFiles.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		st, sc, err := parseScenario(name, data)
+		if err != nil {
+			panic(err)
+		}
+		out[sc.Name] = st
+	}
+	return out
+}
+
+// parseScenario parses a scenario file, reading its declared parameters by
+// executing it once with every parameter zero.
+func parseScenario(name string, data []byte) (scenarioTemplate, Scenario, error) {
+	var st scenarioTemplate
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"param": func(string) scenarioNumber { return 0 },
+		"add":   func(a, b scenarioNumber) scenarioNumber { return a + b },
+	}).Parse(string(data))
+	if err != nil {
+		return st, Scenario{}, err
+	}
+	st.tmpl = tmpl
+	declared, err := st.execute(func(string) (scenarioNumber, error) { return 0, nil })
+	if err != nil {
+		return st, Scenario{}, fmt.Errorf("%s: %w", name, err)
+	}
+	st.params = declared.Params
+	sc, err := st.render(nil)
+	if err != nil {
+		return st, Scenario{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return st, sc, nil
+}
+
+// render executes the template with values in place of the declared
+// defaults, rejecting parameters the scenario does not declare.
+func (st scenarioTemplate) render(values map[string]float64) (Scenario, error) {
+	resolved := make(map[string]float64, len(st.params))
+	for _, p := range st.params {
+		resolved[p.Name] = p.Default
+	}
+	for name, v := range values {
+		if _, ok := resolved[name]; !ok {
+			return Scenario{}, fmt.Errorf("unknown parameter %q", name)
+		}
+		resolved[name] = v
+	}
+	return st.execute(func(name string) (scenarioNumber, error) {
+		v, ok := resolved[name]
+		if !ok {
+			return 0, fmt.Errorf("undeclared parameter %q", name)
+		}
+		return scenarioNumber(v), nil
+	})
+}
+
+// execute runs the template with param supplying parameter values and
+// decodes the result.
+func (st scenarioTemplate) execute(param func(string) (scenarioNumber, error)) (Scenario, error) {
+	tmpl, err := st.tmpl.Clone()
+	if err != nil {
+		return Scenario{}, err
+	}
+	tmpl.Funcs(template.FuncMap{"param": param})
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return Scenario{}, err
+	}
+	var sc Scenario
+	if err := json.Unmarshal(buf.Bytes(), &sc); err != nil {
+		return Scenario{}, err
+	}
+	sort.SliceStable(sc.Steps, func(i, j int) bool { return sc.Steps[i].AtSeconds < sc.Steps[j].AtSeconds })
+	return sc, nil
+}
+
+// Scenarios lists the built-in scenarios by name, with default parameters.
+func Scenarios() []Scenario {
+	out := make([]Scenario, 0, len(builtinScenarios))
+	for name := range builtinScenarios {
+		sc, _ := LookupScenario(name)
+		out = append(out, sc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// LookupScenario returns the built-in scenario called name with default
+// parameters.
+func LookupScenario(name string) (Scenario, bool) {
+	st, ok := builtinScenarios[name]
+	if !ok {
+		return Scenario{}, false
+	}
+	sc, err := st.render(nil)
+	return sc, err == nil
+}
+
+// RenderScenario returns the built-in scenario called name with values in
+// place of its parameter defaults.
+func RenderScenario(name string, values map[string]float64) (Scenario, error) {
+	st, ok := builtinScenarios[name]
+	if !ok {
+		return Scenario{}, fmt.Errorf("unknown scenario %q", name)
+	}
+	sc, err := st.render(values)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("scenario %s: %w", name, err)
+	}
+	return sc, nil
+}
+
+// validateScenario checks every step of sc can be applied on this server.
+func (s *Server) validateScenario(sc Scenario) error {
+	if s.Runways == nil {
+		return errors.New("scheduler unavailable")
+	}
+	runways := s.Runways.RunwayNames()
+	for _, name := range sc.Runways {
+		if !slices.Contains(runways, name) {
+			return fmt.Errorf("scenario needs runway %q", name)
+		}
+	}
+	for i, step := range sc.Steps {
+		var err error
+		switch {
+		case step.AtSeconds < 0:
+			err = errors.New("negative offset")
+		case step.Command != nil:
+			err = s.validateCommand(*step.Command)
+		case step.Incident != nil:
+			if _, ok := incidentProfiles[step.Incident.Type]; !ok {
+				err = fmt.Errorf("unknown incident type %q", step.Incident.Type)
+			} else if !slices.Contains(runways, step.Incident.Runway) {
+				err = fmt.Errorf("unknown runway %q", step.Incident.Runway)
+			}
+		case step.Storm != nil:
+			if len(step.Storm.Polygon) < 3 || step.Storm.Intensity < 1 || step.Storm.Intensity > 6 {
+				err = errors.New("storm cell needs a polygon and an intensity between 1 and 6")
+			}
+		case step.Wildlife != nil:
+			err = s.validateCommand(step.Wildlife.command())
+		default:
+			err = errors.New("empty step")
+		}
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// StartScenario starts the scenario called name on the simulation clock with
+// the given parameter values, nil for the defaults, cancelling the pending
+// steps of any scenario already running. Steps at offset zero are applied
+// before it returns.
+func (s *Server) StartScenario(name string, params map[string]float64) (ScenarioRun, error) {
+	sc, err := RenderScenario(name, params)
+	if err != nil {
+		return ScenarioRun{}, err
+	}
+	if err := s.validateScenario(sc); err != nil {
+		return ScenarioRun{}, err
+	}
+	clock := s.Runways.Clock()
+	now := clock.Now()
+	run := ScenarioRun{Name: sc.Name, Params: params, StartedAt: now, EndsAt: now.Add(time.Duration(sc.DurationSeconds) * time.Second)}
+
+	s.scenario.mu.Lock()
+	for _, stop := range s.scenario.stops {
+		stop()
+	}
+	s.scenario.active, s.scenario.stops = &run, nil
+	// Steps sharing an offset run in one callback so they apply in order.
+	var immediate []ScenarioStep
+	for start := 0; start < len(sc.Steps); {
+		end := start
+		for end < len(sc.Steps) && sc.Steps[end].AtSeconds == sc.Steps[start].AtSeconds {
+			end++
+		}
+		steps := sc.Steps[start:end]
+		if at := steps[0].AtSeconds; at == 0 {
+			immediate = steps
+		} else {
+			s.scenario.stops = append(s.scenario.stops, clock.AfterFunc(time.Duration(at)*time.Second, func() { s.applyScenarioSteps(steps) }))
+		}
+		start = end
+	}
+	s.scenario.mu.Unlock()
+
+	log.Printf("scenario %s started", sc.Name)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventScenarioStarted, Detail: fmt.Sprintf("%s: %d steps over %ds", sc.Name, len(sc.Steps), sc.DurationSeconds)})
+	}
+	s.applyScenarioSteps(immediate)
+	return run, nil
+}
+
+// stopScenario cancels the steps of the active scenario still to come.
+func (s *Server) stopScenario() {
+	s.scenario.mu.Lock()
+	defer s.scenario.mu.Unlock()
+	for _, stop := range s.scenario.stops {
+		stop()
+	}
+	s.scenario.stops = nil
+}
+
+// ActiveScenario returns the scenario started most recently, or nil.
+func (s *Server) ActiveScenario() *ScenarioRun {
+	s.scenario.mu.Lock()
+	defer s.scenario.mu.Unlock()
+	return s.scenario.active
+}
+
+func (s *Server) applyScenarioSteps(steps []ScenarioStep) {
+	for _, step := range steps {
+		switch {
+		case step.Command != nil:
+			s.applyCommand(*step.Command)
+		case step.Incident != nil:
+			repair := time.Duration(step.Incident.RepairSeconds) * time.Second
+			if _, err := s.Runways.InjectIncident(step.Incident.Type, step.Incident.Runway, repair); err != nil {
+				log.Printf("scenario incident ignored: %v", err)
+			}
+		case step.Storm != nil:
+			if _, err := s.Runways.AddStormCell(*step.Storm); err != nil {
+				log.Printf("scenario storm cell ignored: %v", err)
+			}
+		case step.Wildlife != nil:
+			s.applyWildlife(step.Wildlife.command())
+		}
+	}
+}
+
+// Inputs returns the scenario's steps as inputs to an in-process run, as
+// by a DeterminismCheck.
+func (sc Scenario) Inputs() []SimInput {
+	inputs := make([]SimInput, 0, len(sc.Steps))
+	for _, step := range sc.Steps {
+		step := step
+		inputs = append(inputs, SimInput{At: time.Duration(step.AtSeconds) * time.Second, Step: &step})
+	}
+	return inputs
+}
+
+// applyScenarioStep applies a scenario step to an in-process engine.
+func (e *Engine) applyScenarioStep(step ScenarioStep) {
+	var err error
+	switch {
+	case step.Command != nil:
+		cmd := step.Command
+		switch cmd.Type {
+		case "rate":
+			if cmd.RampSeconds > 0 {
+				e.Generator.RampRate(cmd.Rate, time.Duration(cmd.RampSeconds)*time.Second)
+			} else {
+				e.Generator.SetRate(cmd.Rate)
+			}
+		case "runway":
+			e.Runways.SetRunwayClosed(cmd.Runway, cmd.Closed)
+		case "wind":
+			e.Runways.SetWindState(*cmd.Wind)
+		case "visibility":
+			e.Runways.SetVisibility(cmd.Visibility.Meters)
+			if cmd.Visibility.CeilingFeet != nil {
+				e.Runways.SetCeiling(*cmd.Visibility.CeilingFeet)
+			}
+		case "wildlife":
+			err = e.applyWildlife(*cmd)
+		case "burst":
+			if err = validateBurst(cmd.Count); err == nil {
+				for range cmd.Count {
+					e.Runways.Arrive(e.Generator.spawn())
+				}
+			}
+		}
+	case step.Incident != nil:
+		_, err = e.Runways.InjectIncident(step.Incident.Type, step.Incident.Runway, time.Duration(step.Incident.RepairSeconds)*time.Second)
+	case step.Storm != nil:
+		_, err = e.Runways.AddStormCell(*step.Storm)
+	case step.Wildlife != nil:
+		err = e.applyWildlife(step.Wildlife.command())
+	}
+	if err != nil {
+		log.Printf("scenario step ignored: %v", err)
+	}
+}
+
+// applyWildlife applies a wildlife command to the engine's scheduler.
+func (e *Engine) applyWildlife(cmd Command) error {
+	if cmd.Wildlife != nil {
+		if err := e.Runways.SetWildlifeHazard(*cmd.Wildlife); err != nil {
+			return err
+		}
+	}
+	if cmd.Runway != "" {
+		return e.Runways.DisperseWildlife(cmd.Runway)
+	}
+	return nil
+}
+
+// HandleScenarios lists the built-in scenarios.
+func (s *Server) HandleScenarios(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Scenarios())
+}
+
+// HandleScenario describes the scenario named in the path, including its
+// expected outcome.
+func (s *Server) HandleScenario(w http.ResponseWriter, r *http.Request) {
+	sc, ok := LookupScenario(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "scenario not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, sc)
+}
+
+// HandleStartScenario starts the scenario named in the path, with any
+// parameter values in the body.
+func (s *Server) HandleStartScenario(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	name := strings.TrimSpace(r.PathValue("name"))
+	if _, ok := builtinScenarios[name]; !ok {
+		http.Error(w, "scenario not found", http.StatusNotFound)
+		return
+	}
+	var req ScenarioStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid scenario parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	run, err := s.StartScenario(name, req.Params)
+	if err != nil {
+		http.Error(w, "invalid scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, run)
+}
@@ -2,9 +2,12 @@ package control
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -16,6 +19,49 @@ type Message struct {
 	Runway string     `json:"runway,omitempty"`
 	Closed bool       `json:"closed,omitempty"`
 	Wind   *WindState `json:"wind,omitempty"`
+	Event  *Event     `json:"event,omitempty"`
+	// LogEvent summarizes a significant event, with its severity, for an
+	// operational event feed.
+	LogEvent *LogEvent `json:"logEvent,omitempty"`
+	// Voice is a transmission for a text-to-speech frontend, sent on the
+	// voice channel.
+	Voice *VoiceLine `json:"voice,omitempty"`
+	// DryRun asks for the command to be validated and its effect previewed
+	// without applying it; the server answers with a preview message.
+	DryRun  bool     `json:"dryRun,omitempty"`
+	Preview *Preview `json:"preview,omitempty"`
+	// Confirmation is a command held for a second controller to confirm.
+	Confirmation *PendingAction `json:"confirmation,omitempty"`
+	// IdempotencyKey deduplicates retried commands: a command repeating
+	// the key of one applied in the last ten minutes is answered with the
+	// original reply instead of being applied again.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// ExecuteAt queues the command to run at that simulation time; the
+	// server answers with a scheduled message.
+	ExecuteAt *time.Time        `json:"executeAt,omitempty"`
+	Scheduled *ScheduledCommand `json:"scheduled,omitempty"`
+	// Version is the state version a state message reflects. A command
+	// carrying one is applied only if the state is still at that version,
+	// and is otherwise answered with a conflict message.
+	Version int64 `json:"version,omitempty"`
+	// Snapshot is the full state, sent in answer to a resync message.
+	Snapshot *StateSnapshot `json:"snapshot,omitempty"`
+	// RampSeconds spreads a rate change over that many seconds instead of
+	// applying it on the next tick; Ramp reports a ramp in progress.
+	RampSeconds int64     `json:"rampSeconds,omitempty"`
+	Ramp        *RateRamp `json:"ramp,omitempty"`
+	// Clamped marks a rate ack whose rate was lowered to the maximum;
+	// RequestedRate is the rate asked for.
+	Clamped       bool  `json:"clamped,omitempty"`
+	RequestedRate int64 `json:"requestedRate,omitempty"`
+	// Count is the number of flights a burst spawns at once.
+	Count int `json:"count,omitempty"`
+	// Timers carries every flight's countdown. ServerTime is the simulation
+	// time on the server; a client sending a time message with its own
+	// ClientTime gets both back, to estimate its clock offset.
+	Timers     *FlightTimers `json:"timers,omitempty"`
+	ServerTime *time.Time    `json:"serverTime,omitempty"`
+	ClientTime *time.Time    `json:"clientTime,omitempty"`
 }
 
 // Server hosts control endpoints for updating the generator.
@@ -23,89 +69,340 @@ type Server struct {
 	Generator *Generator
 	Runways   *RunwayManager
 	Metrics   *SchedulerMetrics
-	upgrader  websocket.Upgrader
+	Events    *EventBus
+	// RecordingDir holds recorded sessions available for playback; empty
+	// disables the playback endpoints.
+	RecordingDir string
+	// Archive serves history queries; nil disables them.
+	Archive *EventArchive
+	// Rules manages automatic actions; nil disables the rules API.
+	Rules *RulesEngine
+	// Quotas reports airline quota adherence; nil disables it.
+	Quotas *QuotaStrategy
+	// Auction reports the slot auction sandbox; nil disables it.
+	Auction *SlotAuction
+	// Anomalies reports unusual metric behavior; nil disables it.
+	Anomalies *AnomalyDetector
+	// SLAs tracks service level attainment; nil disables the SLA API.
+	SLAs *SLATracker
+	// Confirmations holds high-impact actions for a second controller to
+	// confirm; nil applies them at once.
+	Confirmations *Confirmations
+	// Reports generates the end-of-day reports; nil disables them.
+	Reports *DailyReporter
+	// Tenants runs independent simulation instances; nil disables them.
+	Tenants *Tenants
+	// Chaos injects faults for resilience testing; nil disables chaos
+	// mode.
+	Chaos *Chaos
+	// Positions carries the position stream over WebRTC data channels;
+	// nil disables the experimental transport.
+	Positions *PositionChannels
+	// MaxClients bounds the websocket clients connected at once; zero
+	// means no limit.
+	MaxClients int
+	// ValidateMessages checks every outgoing websocket message against the
+	// published schema and drops the connection on a violation. Meant for
+	// development and contract testing.
+	ValidateMessages bool
+	upgrader         websocket.Upgrader
+	clients          clientRegistry
+	idempotency      idempotencyCache
+	commands         commandQueue
+	scenario         scenarioState
+	checkpoints      checkpointStore
+	// mutations serializes the version check and the change of commands
+	// applied at a version.
+	mutations sync.Mutex
 }
 
 // NewServer constructs a Server bound to the supplied generator.
-func NewServer(gen *Generator, runways *RunwayManager, metrics *SchedulerMetrics) *Server {
+func NewServer(gen *Generator, runways *RunwayManager, metrics *SchedulerMetrics, events *EventBus) *Server {
 	return &Server{
 		Generator: gen,
 		Runways:   runways,
 		Metrics:   metrics,
+		Events:    events,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
 		},
 	}
 }
 
+const eventBufferSize = 64
+
+// wsClient serializes writes to a websocket connection shared between the
+// control loop and the event forwarder, and keeps its message statistics.
+type wsClient struct {
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	validate bool
+	// chaos may drop messages instead of sending them.
+	chaos *Chaos
+
+	id        int64
+	kind      string
+	remote    string
+	connected time.Time
+	// controller is the authenticated controller, if any.
+	controller string
+	// sub is the client's event subscription, if it has one.
+	sub *Subscription
+	// sent counts messages written; windowSent those since windowStart,
+	// and rate is the rate over the last complete window.
+	sent        int64
+	windowSent  int64
+	windowStart time.Time
+	rate        float64
+}
+
+func (c *wsClient) send(msg Message) error {
+	if c.validate {
+		if err := validateMessage(msg); err != nil {
+			log.Printf("schema violation: %v", err)
+			return fmt.Errorf("schema violation: %w", err)
+		}
+	}
+	if c.chaos.drop() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.WriteJSON(msg); err != nil {
+		return err
+	}
+	c.recordSentLocked(time.Now())
+	return nil
+}
+
+func forwardEvents(client *wsClient, events <-chan Event) {
+	for e := range events {
+		for _, msg := range eventMessages(e) {
+			if err := client.send(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventMessages returns the messages broadcast for an event: the event
+// itself and, for events shown in the controller log, its log entry.
+func eventMessages(e Event) []Message {
+	msgs := []Message{{Type: "event", Event: &e}}
+	if entry, ok := logEventFor(e); ok {
+		msgs = append(msgs, Message{Type: "logEvent", LogEvent: &entry})
+	}
+	return msgs
+}
+
+// stateMessages returns the messages that bring a client to snap: the
+// rate, each runway and the wind.
+func stateMessages(snap StateSnapshot) []Message {
+	msgs := []Message{{Type: "rate", Rate: snap.Rate, Ramp: snap.Ramp, Version: snap.Version}}
+	for _, runway := range snap.Runways {
+		msgs = append(msgs, Message{Type: "runway", Runway: runway.Name, Closed: runway.Closed, Version: snap.Version})
+	}
+	return append(msgs, Message{Type: "wind", Wind: &snap.Wind, Version: snap.Version})
+}
+
 // HandleControl upgrades the HTTP connection to a websocket and listens for updates.
 func (s *Server) HandleControl(w http.ResponseWriter, r *http.Request) {
+	if s.clientLimitReached() {
+		http.Error(w, "client limit reached", http.StatusServiceUnavailable)
+		return
+	}
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("websocket upgrade failed: %v", err)
 		return
 	}
 	defer conn.Close()
+	client := s.connect(ClientControl, conn, r)
+	defer s.disconnect(client)
+
+	// Subscribe before taking the initial state so no event after it is
+	// missed; the forwarder starts once the state is sent.
+	var sub *Subscription
+	if s.Events != nil {
+		var unsubscribe func()
+		sub, unsubscribe = s.Events.Open(eventBufferSize)
+		defer unsubscribe()
+		client.mu.Lock()
+		client.sub = sub
+		client.mu.Unlock()
+	}
 
-	// Send initial state to client.
-	initialRate := Message{Type: "rate", Rate: s.Generator.Rate()}
-	if err := conn.WriteJSON(initialRate); err != nil {
+	// Send initial state to client, all from one snapshot.
+	snap := s.Snapshot()
+	initialRate := Message{Type: "rate", Rate: snap.Rate, Ramp: snap.Ramp, Version: snap.Version}
+	if err := client.send(initialRate); err != nil {
 		log.Printf("send initial rate: %v", err)
 		return
 	}
 
 	if s.Runways != nil {
-		for _, name := range s.Runways.RunwayNames() {
-			runwayState := Message{Type: "runway", Runway: name, Closed: s.Runways.IsClosed(name)}
-			if err := conn.WriteJSON(runwayState); err != nil {
-				log.Printf("send initial runway %s: %v", name, err)
+		for _, runway := range snap.Runways {
+			runwayState := Message{Type: "runway", Runway: runway.Name, Closed: runway.Closed, Version: snap.Version}
+			if err := client.send(runwayState); err != nil {
+				log.Printf("send initial runway %s: %v", runway.Name, err)
 				return
 			}
 		}
 
-		wind := s.Runways.Wind()
-		windState := Message{Type: "wind", Wind: &wind}
-		if err := conn.WriteJSON(windState); err != nil {
+		windState := Message{Type: "wind", Wind: &snap.Wind, Version: snap.Version}
+		if err := client.send(windState); err != nil {
 			log.Printf("send initial wind: %v", err)
 			return
 		}
 	}
 
+	now := s.ServerTime()
+	if err := client.send(Message{Type: "time", ServerTime: &now}); err != nil {
+		log.Printf("send time sync: %v", err)
+		return
+	}
+
+	if sub != nil {
+		go forwardEvents(client, sub.C)
+	}
+	if s.Runways != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go s.streamTimers(client, done)
+	}
+
 	for {
 		var msg Message
 		if err := conn.ReadJSON(&msg); err != nil {
 			log.Printf("control read error: %v", err)
 			return
 		}
-		switch msg.Type {
-		case "rate":
-			s.Generator.SetRate(msg.Rate)
-			if err := conn.WriteJSON(Message{Type: "rate", Rate: s.Generator.Rate()}); err != nil {
-				log.Printf("control ack error: %v", err)
+		if msg.Type == "time" {
+			now := s.ServerTime()
+			if err := client.send(Message{Type: "time", ServerTime: &now, ClientTime: msg.ClientTime}); err != nil {
+				log.Printf("control time sync error: %v", err)
 				return
 			}
-		case "runway":
-			if s.Runways != nil && msg.Runway != "" {
-				s.Runways.SetRunwayClosed(msg.Runway, msg.Closed)
-				if err := conn.WriteJSON(Message{Type: "runway", Runway: msg.Runway, Closed: s.Runways.IsClosed(msg.Runway)}); err != nil {
-					log.Printf("control runway ack error: %v", err)
-					return
-				}
+			continue
+		}
+		if msg.Type == "resync" {
+			snap := s.Snapshot()
+			if err := client.send(Message{Type: "snapshot", Snapshot: &snap}); err != nil {
+				log.Printf("control resync error: %v", err)
+				return
 			}
-		case "wind":
-			if s.Runways != nil && msg.Wind != nil {
-				s.Runways.SetWind(msg.Wind.Speed, msg.Wind.Direction)
-				latest := s.Runways.Wind()
-				if err := conn.WriteJSON(Message{Type: "wind", Wind: &latest}); err != nil {
-					log.Printf("control wind ack error: %v", err)
+			continue
+		}
+		if msg.DryRun {
+			if preview, ok := s.preview(msg); ok {
+				if err := client.send(Message{Type: "preview", Preview: &preview}); err != nil {
+					log.Printf("control preview error: %v", err)
 					return
 				}
 			}
+			continue
+		}
+		if reply, ok := s.replayCommand(msg); ok {
+			if err := client.send(*reply); err != nil {
+				log.Printf("control replay error: %v", err)
+				return
+			}
+			continue
+		}
+		if msg.ExecuteAt != nil {
+			scheduled, err := s.Schedule(Command{Type: msg.Type, Rate: msg.Rate, RampSeconds: msg.RampSeconds, Runway: msg.Runway, Closed: msg.Closed, Wind: msg.Wind, Count: msg.Count}, *msg.ExecuteAt, client.controller)
+			if err != nil {
+				log.Printf("control schedule error: %v", err)
+				continue
+			}
+			if err := s.ack(client, msg, Message{Type: "scheduled", Scheduled: &scheduled}); err != nil {
+				log.Printf("control schedule ack error: %v", err)
+				return
+			}
+			continue
+		}
+		if msg.Version != 0 {
+			if err := s.applyAtVersion(client, msg); err != nil {
+				log.Printf("control ack error: %v", err)
+				return
+			}
+			continue
+		}
+		if err := s.applyMessage(client, msg); err != nil {
+			log.Printf("control ack error: %v", err)
+			return
+		}
+	}
+}
+
+// applyMessage applies a control command and acknowledges it with the
+// resulting state.
+func (s *Server) applyMessage(client *wsClient, msg Message) error {
+	switch msg.Type {
+	case "rate":
+		s.setRate(msg.Rate, time.Duration(msg.RampSeconds)*time.Second)
+		reply := Message{Type: "rate", Rate: s.Generator.Rate(), Ramp: s.Generator.Ramp(), Version: s.StateVersion()}
+		if s.Generator.capRate(msg.Rate) < msg.Rate {
+			reply.Clamped, reply.RequestedRate = true, msg.Rate
+		}
+		return s.ack(client, msg, reply)
+	case "runway":
+		if s.Runways != nil && msg.Runway != "" {
+			apply := func() { s.Runways.SetRunwayClosed(msg.Runway, msg.Closed) }
+			held, err := s.holdForConfirmation(client, func() Preview { return s.Runways.PreviewRunwayClosed(msg.Runway, msg.Closed) }, apply)
+			if err != nil {
+				return fmt.Errorf("confirmation: %w", err)
+			}
+			if !held {
+				apply()
+			}
+			return s.ack(client, msg, Message{Type: "runway", Runway: msg.Runway, Closed: s.Runways.IsClosed(msg.Runway), Version: s.StateVersion()})
+		}
+	case "wind":
+		if s.Runways != nil && msg.Wind != nil {
+			s.Runways.SetWindState(*msg.Wind)
+			latest := s.Runways.Wind()
+			return s.ack(client, msg, Message{Type: "wind", Wind: &latest, Version: s.StateVersion()})
+		}
+	case "burst":
+		if s.Runways != nil {
+			flights, err := s.Burst(msg.Count)
+			if err != nil {
+				log.Printf("control burst error: %v", err)
+				return nil
+			}
+			return s.ack(client, msg, Message{Type: "burst", Count: len(flights), Version: s.StateVersion()})
 		}
 	}
+	return nil
 }
 
-// HandleRate allows non-websocket rate updates via form/query.
+// preview predicts the effect of a control message, reporting false for
+// messages that are not commands.
+func (s *Server) preview(msg Message) (Preview, bool) {
+	switch msg.Type {
+	case "rate":
+		return s.previewRate(msg.Rate), true
+	case "runway", "wind", "burst":
+		if s.Runways == nil {
+			return Preview{Command: msg.Type, Error: "scheduler unavailable"}, true
+		}
+		if msg.Type == "runway" {
+			return s.Runways.PreviewRunwayClosed(msg.Runway, msg.Closed), true
+		}
+		if msg.Type == "burst" {
+			return s.Runways.previewBurst(msg.Count), true
+		}
+		if msg.Wind == nil {
+			return Preview{Command: msg.Type, Error: "wind missing"}, true
+		}
+		return s.Runways.PreviewWind(*msg.Wind), true
+	}
+	return Preview{}, false
+}
+
+// HandleRate allows non-websocket rate updates via form/query. An optional
+// ramp spreads the change over that many seconds.
 func (s *Server) HandleRate(w http.ResponseWriter, r *http.Request) {
 	rateStr := r.FormValue("rate")
 	rate, err := strconv.ParseInt(rateStr, 10, 64)
@@ -113,10 +410,42 @@ func (s *Server) HandleRate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid rate", http.StatusBadRequest)
 		return
 	}
-	s.Generator.SetRate(rate)
+	var ramp int64
+	if v := r.FormValue("ramp"); v != "" {
+		if ramp, err = strconv.ParseInt(v, 10, 64); err != nil || ramp < 0 {
+			http.Error(w, "invalid ramp", http.StatusBadRequest)
+			return
+		}
+	}
+	if dryRun(r) {
+		writeJSON(w, http.StatusOK, s.previewRate(rate))
+		return
+	}
+	s.setRate(rate, time.Duration(ramp)*time.Second)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// setRate applies a rate change, ramped over the given duration if
+// positive, and announces it on the event bus.
+func (s *Server) setRate(rate int64, ramp time.Duration) {
+	var clamped string
+	if capped := s.Generator.capRate(rate); capped < rate {
+		clamped = fmt.Sprintf(" (clamped from %d/min)", rate)
+		rate = capped
+	}
+	if ramp > 0 {
+		s.Generator.RampRate(rate, ramp)
+		if s.Events != nil {
+			s.Events.Publish(Event{Type: EventRateChanged, Detail: fmt.Sprintf("ramping to %d/min over %s%s", max(rate, 1), ramp, clamped)})
+		}
+		return
+	}
+	s.Generator.SetRate(rate)
+	if s.Events != nil {
+		s.Events.Publish(Event{Type: EventRateChanged, Detail: strconv.FormatInt(s.Generator.Rate(), 10) + "/min" + clamped})
+	}
+}
+
 // HandleMetrics emits a snapshot of scheduler behavior for dashboards or tests.
 func (s *Server) HandleMetrics(w http.ResponseWriter, r *http.Request) {
 	if s.Metrics == nil {
@@ -129,3 +458,13 @@ func (s *Server) HandleMetrics(w http.ResponseWriter, r *http.Request) {
 		log.Printf("encode metrics: %v", err)
 	}
 }
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		log.Printf("encode response: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package control
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testRunways = []RunwayDefinition{{Name: "09L", Heading: 90}, {Name: "09R", Heading: 90}}
+
+// fastManager builds a runway manager on a clock running a thousand times
+// real time, so its landing timers fire within milliseconds.
+func fastManager() (*RunwayManager, *EventBus) {
+	clock := NewSimClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), 1000)
+	bus := NewEventBus()
+	bus.SetClock(clock)
+	metrics := NewSchedulerMetrics([]string{"09L", "09R"})
+	metrics.SetClock(clock)
+	rm := NewRunwayManager(testRunways, metrics, bus)
+	rm.SetClock(clock)
+	return rm, bus
+}
+
+func testFlight(id int64, clock Clock) Flight {
+	return Flight{ID: id, Call: fmt.Sprintf("TST%04d", id), CreatedAt: clock.Now(), Weight: WeightMedium}
+}
+
+// collect gathers the events published on bus until the returned function
+// is called, which returns every event published before the call.
+func collect(bus *EventBus) func() []Event {
+	events, unsubscribe := bus.SubscribeAll()
+	return func() []Event {
+		defer unsubscribe()
+		end := bus.Publish(Event{Type: "collected"})
+		var out []Event
+		for e := range events {
+			if e.Seq == end.Seq {
+				break
+			}
+			out = append(out, e)
+		}
+		return out
+	}
+}
+
+// TestStopRacesLandingTimers stops the scheduler while arrivals are being
+// sequenced and their landing timers are firing: no landing may complete
+// after Stop returns, and every flight not landed must be left outstanding
+// for a successor.
+func TestStopRacesLandingTimers(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		rm, bus := fastManager()
+		stopCollecting := collect(bus)
+
+		const arrivals = 50
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := int64(1); id <= arrivals; id++ {
+				rm.Arrive(testFlight(id, rm.Clock()))
+				time.Sleep(50 * time.Microsecond)
+			}
+		}()
+		time.Sleep(time.Duration(round) * 200 * time.Microsecond)
+		// Stop is safe to call more than once, including concurrently.
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rm.Stop()
+			}()
+		}
+		wg.Wait()
+		marker := bus.Publish(Event{Type: "stopped"})
+		// Long enough in simulation time for any timer armed before Stop
+		// to have fired.
+		time.Sleep(30 * time.Millisecond)
+		events := stopCollecting()
+
+		landed := make(map[int64]bool)
+		for _, e := range events {
+			if e.Type != EventLanded {
+				continue
+			}
+			if e.Seq > marker.Seq {
+				t.Fatalf("round %d: flight %d landed after Stop returned", round, e.FlightID)
+			}
+			landed[e.FlightID] = true
+		}
+		outstanding := make(map[int64]bool)
+		state := rm.Outstanding()
+		for _, d := range state.InProgress {
+			outstanding[d.Flight.ID] = true
+		}
+		for _, f := range state.Holding {
+			outstanding[f.ID] = true
+		}
+		for id := int64(1); id <= arrivals; id++ {
+			switch {
+			case landed[id] && outstanding[id]:
+				t.Fatalf("round %d: flight %d both landed and outstanding", round, id)
+			case !landed[id] && !outstanding[id]:
+				t.Fatalf("round %d: flight %d lost: neither landed nor outstanding", round, id)
+			}
+		}
+	}
+}
+
+// TestRestoreAfterStop hands the outstanding work of a stopped scheduler
+// to a successor, which lands every flight that was in progress.
+func TestRestoreAfterStop(t *testing.T) {
+	rm, _ := fastManager()
+	for id := int64(1); id <= 6; id++ {
+		rm.Arrive(testFlight(id, rm.Clock()))
+	}
+	rm.Stop()
+	state := rm.Outstanding()
+	if len(state.InProgress) == 0 {
+		t.Fatal("no landings in progress to hand over")
+	}
+
+	successor, bus := fastManager()
+	stopCollecting := collect(bus)
+	successor.Restore(state)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(successor.Outstanding().InProgress) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	successor.Stop()
+	events := stopCollecting()
+
+	landed := make(map[int64]bool)
+	for _, e := range events {
+		switch e.Type {
+		case EventLanded:
+			landed[e.FlightID] = true
+		case EventSpawned:
+			t.Errorf("restored flight %d counted as a new arrival", e.FlightID)
+		}
+	}
+	for _, d := range state.InProgress {
+		if !landed[d.Flight.ID] && !containsFlight(successor.Outstanding().Holding, d.Flight.ID) {
+			t.Errorf("flight %d in progress at handover neither landed nor holding", d.Flight.ID)
+		}
+	}
+}
+
+// TestDeferredArrivalsRecovered checks arrivals deferred by the acceptance
+// gate survive the decision log and a restore, and are dropped with the
+// rest when a branch discards the arrivals.
+func TestDeferredArrivalsRecovered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	gate := AcceptanceGate{HoldingThreshold: 1}
+	rm, _ := fastManager()
+	wal, _, err := OpenDecisionLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.SetDecisionLog(wal)
+	if err := rm.SetAcceptanceGate(gate); err != nil {
+		t.Fatal(err)
+	}
+	for _, def := range testRunways {
+		rm.SetRunwayClosed(def.Name, true)
+	}
+	for id := int64(1); id <= 4; id++ {
+		rm.Arrive(testFlight(id, rm.Clock()))
+	}
+	rm.Stop()
+	if n := len(rm.Outstanding().Deferred); n != 3 {
+		t.Fatalf("%d arrivals deferred, want 3", n)
+	}
+	wal.Close()
+
+	wal, recovered, err := OpenDecisionLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	if len(recovered.Holding) != 1 || len(recovered.Deferred) != 3 {
+		t.Fatalf("recovered %d holding and %d deferred, want 1 and 3", len(recovered.Holding), len(recovered.Deferred))
+	}
+	successor, _ := fastManager()
+	defer successor.Stop()
+	successor.SetDecisionLog(wal)
+	if err := successor.SetAcceptanceGate(gate); err != nil {
+		t.Fatal(err)
+	}
+	for _, def := range testRunways {
+		successor.SetRunwayClosed(def.Name, true)
+	}
+	successor.Restore(recovered)
+	if n := successor.Acceptance().Deferred; n != 3 {
+		t.Fatalf("%d arrivals deferred after restore, want 3", n)
+	}
+	if n := successor.discardArrivals(); n != 4 {
+		t.Errorf("discarded %d arrivals, want 4", n)
+	}
+	if n := successor.Acceptance().Deferred; n != 0 {
+		t.Errorf("%d arrivals still deferred after discarding", n)
+	}
+}
+
+func containsFlight(flights []Flight, id int64) bool {
+	for _, f := range flights {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event types for service level agreements.
+const (
+	EventSLABreached = "slaBreached"
+	EventSLARestored = "slaRestored"
+)
+
+// SLA measures.
+const (
+	// SLAWait is the time from a flight appearing to touching down.
+	SLAWait = "wait"
+	// SLADelay is how late a flight touches down against its schedule.
+	SLADelay = "delay"
+	// SLATaxiOut is the time from pushback to the runway holding point.
+	SLATaxiOut = "taxiOut"
+)
+
+var slaMeasures = []string{SLAWait, SLADelay, SLATaxiOut}
+
+const (
+	defaultSLAWindow = 200
+	// slaMinSamples is how many flights an SLA needs before it can be
+	// breached.
+	slaMinSamples = 20
+)
+
+// SLA is a service level: Target (a fraction) of flights must see Measure
+// under ThresholdSeconds, as in 95% of arrivals waiting under 10 minutes.
+// It is judged over the last Window flights, 200 by default.
+type SLA struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name,omitempty"`
+	Measure          string  `json:"measure"`
+	ThresholdSeconds float64 `json:"thresholdSeconds"`
+	Target           float64 `json:"target"`
+	Window           int     `json:"window,omitempty"`
+}
+
+func (s SLA) validate() error {
+	if !slices.Contains(slaMeasures, s.Measure) {
+		return fmt.Errorf("unknown SLA measure %q", s.Measure)
+	}
+	if s.ThresholdSeconds <= 0 {
+		return errors.New("thresholdSeconds must be positive")
+	}
+	if s.Target <= 0 || s.Target > 1 {
+		return errors.New("target must be a fraction between 0 and 1")
+	}
+	if s.Window < 0 {
+		return errors.New("window must not be negative")
+	}
+	return nil
+}
+
+// slaState tracks one SLA. recent holds whether each of the last Window
+// flights met it, oldest first.
+type slaState struct {
+	sla       SLA
+	met       int64
+	samples   int64
+	recent    []bool
+	breached  bool
+	breaches  int
+	breachAt  time.Time
+	breachFor time.Duration
+}
+
+func (st *slaState) windowAttainment() float64 {
+	if len(st.recent) == 0 {
+		return 1
+	}
+	met := 0
+	for _, ok := range st.recent {
+		if ok {
+			met++
+		}
+	}
+	return float64(met) / float64(len(st.recent))
+}
+
+// SLATracker continuously computes SLA attainment from landings and
+// taxi-outs, alerting on the event bus when an SLA is breached.
+type SLATracker struct {
+	metrics *SchedulerMetrics
+	events  *EventBus
+
+	mu     sync.Mutex
+	slas   map[string]*slaState
+	order  []string
+	nextID int
+}
+
+// NewSLATracker builds a tracker with no SLAs. Metrics and the event bus
+// are optional.
+func NewSLATracker(metrics *SchedulerMetrics, events *EventBus) *SLATracker {
+	return &SLATracker{metrics: metrics, events: events, slas: make(map[string]*slaState)}
+}
+
+// Add validates and stores an SLA, assigning an ID when none is given. An
+// SLA replacing one of the same ID starts afresh.
+func (t *SLATracker) Add(s SLA) (SLA, error) {
+	if err := s.validate(); err != nil {
+		return SLA{}, err
+	}
+	if s.Window == 0 {
+		s.Window = defaultSLAWindow
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s.ID == "" {
+		t.nextID++
+		s.ID = "sla-" + strconv.Itoa(t.nextID)
+	}
+	if _, exists := t.slas[s.ID]; !exists {
+		t.order = append(t.order, s.ID)
+	}
+	t.slas[s.ID] = &slaState{sla: s}
+	if t.metrics != nil {
+		t.metrics.SetSLAAttainment(s.ID, 1)
+	}
+	return s, nil
+}
+
+// Remove deletes an SLA, reporting whether it existed.
+func (t *SLATracker) Remove(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.slas[id]; !ok {
+		return false
+	}
+	delete(t.slas, id)
+	t.order = slices.DeleteFunc(t.order, func(candidate string) bool { return candidate == id })
+	if t.metrics != nil {
+		t.metrics.RemoveSLAAttainment(id)
+	}
+	return true
+}
+
+// Observe records a flight's value of measure at now against every SLA on
+// it.
+func (t *SLATracker) Observe(measure string, d time.Duration, now time.Time) {
+	t.mu.Lock()
+	var alerts []Event
+	for _, id := range t.order {
+		st := t.slas[id]
+		if st.sla.Measure != measure {
+			continue
+		}
+		met := d.Seconds() < st.sla.ThresholdSeconds
+		st.samples++
+		if met {
+			st.met++
+		}
+		st.recent = append(st.recent, met)
+		if len(st.recent) > st.sla.Window {
+			st.recent = st.recent[len(st.recent)-st.sla.Window:]
+		}
+		attainment := st.windowAttainment()
+		if t.metrics != nil {
+			t.metrics.SetSLAAttainment(id, attainment)
+		}
+		breached := st.samples >= slaMinSamples && attainment < st.sla.Target
+		switch {
+		case breached && !st.breached:
+			st.breaches++
+			st.breachAt = now
+			alerts = append(alerts, Event{Type: EventSLABreached, Time: now, Detail: fmt.Sprintf("%s: %.1f%% of the last %d flights under %.0fs, target %.1f%%", id, attainment*100, len(st.recent), st.sla.ThresholdSeconds, st.sla.Target*100)})
+		case !breached && st.breached:
+			st.breachFor += now.Sub(st.breachAt)
+			alerts = append(alerts, Event{Type: EventSLARestored, Time: now, Detail: fmt.Sprintf("%s: %.1f%%", id, attainment*100)})
+		}
+		st.breached = breached
+	}
+	t.mu.Unlock()
+
+	for _, e := range alerts {
+		log.Printf("%s %s", e.Type, e.Detail)
+		if t.events != nil {
+			t.events.Publish(e)
+		}
+	}
+}
+
+// SLAStatus is an SLA and how well it is being met.
+type SLAStatus struct {
+	SLA     SLA   `json:"sla"`
+	Samples int64 `json:"samples"`
+	// Attainment is the fraction of all flights so far that met the SLA;
+	// WindowAttainment of the last Window, which breaches are judged on.
+	Attainment       float64 `json:"attainment"`
+	WindowAttainment float64 `json:"windowAttainment"`
+	Breached         bool    `json:"breached"`
+	Breaches         int     `json:"breaches"`
+	// BreachedSeconds is the total time spent in breach.
+	BreachedSeconds float64 `json:"breachedSeconds"`
+}
+
+// Statuses reports every SLA in creation order. now closes the current
+// breach, if any, for BreachedSeconds.
+func (t *SLATracker) Statuses(now time.Time) []SLAStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]SLAStatus, 0, len(t.order))
+	for _, id := range t.order {
+		st := t.slas[id]
+		status := SLAStatus{SLA: st.sla, Samples: st.samples, Attainment: 1, WindowAttainment: st.windowAttainment(), Breached: st.breached, Breaches: st.breaches, BreachedSeconds: st.breachFor.Seconds()}
+		if st.samples > 0 {
+			status.Attainment = float64(st.met) / float64(st.samples)
+		}
+		if st.breached {
+			status.BreachedSeconds += now.Sub(st.breachAt).Seconds()
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// SetSLATracker feeds landings and taxi-outs to t; nil stops tracking.
+func (rm *RunwayManager) SetSLATracker(t *SLATracker) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.slas = t
+}
+
+// HandleSLAs lists SLA attainment (GET) or defines an SLA (POST).
+func (s *Server) HandleSLAs(w http.ResponseWriter, r *http.Request) {
+	if s.SLAs == nil {
+		http.Error(w, "sla tracking disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.SLAs.Statuses(s.ServerTime()))
+		return
+	}
+	var req SLA
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid sla: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := s.SLAs.Add(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// HandleSLA removes the SLA named in the path.
+func (s *Server) HandleSLA(w http.ResponseWriter, r *http.Request) {
+	if s.SLAs == nil {
+		http.Error(w, "sla tracking disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.SLAs.Remove(r.PathValue("id")) {
+		http.Error(w, "sla not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package control
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// StateSnapshot is the full simulation state taken under one consistent
+// view of the scheduler, so no flight is seen both queued and holding, or
+// counted by the metrics but missing from the queues.
+type StateSnapshot struct {
+	Time time.Time `json:"time"`
+	// Version is the state version the snapshot reflects.
+	Version int64 `json:"version"`
+	Rate    int64 `json:"rate"`
+	// Ramp is the rate change in progress, if any.
+	Ramp *RateRamp `json:"ramp,omitempty"`
+	Wind WindState `json:"wind"`
+	// MagneticWind is the wind with its direction in degrees magnetic, as
+	// the tower reports it.
+	MagneticWind WindState        `json:"magneticWind"`
+	Visibility   VisibilityState  `json:"visibility"`
+	Runways      []RunwaySnapshot `json:"runways"`
+	// Holding is the holding stack, including go-arounds waiting to rejoin
+	// it.
+	Holding []Flight         `json:"holding"`
+	Metrics *MetricsSnapshot `json:"metrics,omitempty"`
+}
+
+// RunwaySnapshot is one runway within a StateSnapshot.
+type RunwaySnapshot struct {
+	Name string `json:"name"`
+	// Closed is the operator closure; Available is false too while failed
+	// equipment makes the runway unusable.
+	Closed    bool `json:"closed"`
+	Available bool `json:"available"`
+	// Heading is the active heading in degrees magnetic, and TrueHeading
+	// the same in degrees true.
+	Heading     float64 `json:"heading"`
+	TrueHeading float64 `json:"trueHeading"`
+	// Reciprocal names the opposite end of the strip, and ActiveEnd the end
+	// in use for the wind.
+	Reciprocal string `json:"reciprocal,omitempty"`
+	ActiveEnd  string `json:"activeEnd"`
+	// HeadwindKnots is the wind along the active heading, negative for a
+	// tailwind, and LandingSeconds the landing time it leaves a medium
+	// arrival.
+	HeadwindKnots  float64 `json:"headwindKnots"`
+	LandingSeconds float64 `json:"landingSeconds"`
+	// Arrivals is the landing queue in sequence order; Departures counts
+	// the departures waiting at the holding point. Stats is the runway's
+	// traffic and utilization so far.
+	Arrivals   []QueuedArrival `json:"arrivals"`
+	Departures int             `json:"departures"`
+	Stats      RunwayStats     `json:"stats"`
+}
+
+// QueuedArrival is a flight cleared to land, when it is due to touch down,
+// if scheduled, and whether it is still sequenced or already on final.
+// EstimatedAt is the touchdown time estimated when it was first sequenced.
+type QueuedArrival struct {
+	Flight      Flight      `json:"flight"`
+	DueAt       *time.Time  `json:"dueAt,omitempty"`
+	EstimatedAt *time.Time  `json:"estimatedAt,omitempty"`
+	Phase       FlightPhase `json:"phase,omitempty"`
+}
+
+// Snapshot captures the scheduler state under its lock. complete, when
+// given, runs under the same lock to add state kept elsewhere.
+func (rm *RunwayManager) Snapshot(complete func(*StateSnapshot)) StateSnapshot {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	ceiling := rm.ceiling
+	snap := StateSnapshot{
+		Time:         rm.clock.Now(),
+		Version:      rm.version,
+		Wind:         rm.wind,
+		MagneticWind: rm.magneticWindLocked(),
+		Visibility:   VisibilityState{Meters: rm.visibility, CeilingFeet: &ceiling},
+		Runways:      make([]RunwaySnapshot, 0, len(rm.order)),
+		Holding:      append([]Flight{}, rm.holding...),
+	}
+	for _, name := range rm.order {
+		snap.Runways = append(snap.Runways, rm.runwaySnapshotLocked(name))
+	}
+	goingAround := make([]Flight, 0, len(rm.goingAround))
+	for _, f := range rm.goingAround {
+		goingAround = append(goingAround, f)
+	}
+	sort.Slice(goingAround, func(i, j int) bool { return goingAround[i].ID < goingAround[j].ID })
+	snap.Holding = append(snap.Holding, goingAround...)
+	if complete != nil {
+		complete(&snap)
+	}
+	return snap
+}
+
+// runwaySnapshotLocked captures runway's state, queue and statistics.
+func (rm *RunwayManager) runwaySnapshotLocked(name string) RunwaySnapshot {
+	r := rm.runways[name]
+	runway := RunwaySnapshot{Name: name, Closed: !r.open, Available: r.available(), Heading: r.activeHeading, Arrivals: make([]QueuedArrival, 0, len(rm.assigned[name])), Departures: len(rm.departures[name])}
+	runway.TrueHeading = rm.trueHeadingLocked(name)
+	runway.Reciprocal, runway.ActiveEnd = rm.reciprocalLocked(name), rm.activeEndLocked(name)
+	runway.HeadwindKnots = math.Round(headwindComponent(r.activeHeading, rm.magneticWindLocked())*10) / 10
+	runway.LandingSeconds = rm.landingTimeLocked(name, Flight{Weight: WeightMedium}).Seconds()
+	for _, f := range rm.assigned[name] {
+		arrival := QueuedArrival{Flight: f, Phase: rm.phases[f.ID]}
+		if due, ok := rm.dueAt[f.ID]; ok {
+			arrival.DueAt = &due
+		}
+		if est, ok := rm.estimatedAt[f.ID]; ok {
+			arrival.EstimatedAt = &est
+		}
+		runway.Arrivals = append(runway.Arrivals, arrival)
+	}
+	sort.SliceStable(runway.Arrivals, func(i, j int) bool {
+		a, b := runway.Arrivals[i].DueAt, runway.Arrivals[j].DueAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	runway.Stats = rm.runwayStatsLocked(name)
+	return runway
+}
+
+// Snapshot captures the full state: the scheduler's, the arrival rate and
+// the metrics, the latter read while the scheduler is held so they agree
+// with its queues.
+func (s *Server) Snapshot() StateSnapshot {
+	complete := func(snap *StateSnapshot) {
+		snap.Rate = s.Generator.Rate()
+		snap.Ramp = s.Generator.Ramp()
+		snap.Version += 1 + s.Generator.rateChanges.Load()
+		if s.Metrics != nil {
+			metrics := s.Metrics.Snapshot()
+			snap.Metrics = &metrics
+		}
+	}
+	if s.Runways != nil {
+		return s.Runways.Snapshot(complete)
+	}
+	snap := StateSnapshot{Time: s.ServerTime(), Runways: []RunwaySnapshot{}, Holding: []Flight{}}
+	complete(&snap)
+	return snap
+}
+
+// HandleState serves a consistent snapshot of the full state.
+func (s *Server) HandleState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Snapshot())
+}
@@ -0,0 +1,151 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	spectatorStateInterval = 2 * time.Second
+	spectatorEventsPerSec  = 4
+)
+
+// spectatorEventTypes lists the events exposed to the public stream. Anything
+// else (wind and rate changes are covered by the state frame) is withheld.
+var spectatorEventTypes = map[string]bool{
+	EventAssigned:     true,
+	EventHolding:      true,
+	EventLanded:       true,
+	EventConflict:     true,
+	EventIncursion:    true,
+	EventGoAround:     true,
+	EventDiverted:     true,
+	EventRunwayClosed: true,
+	EventRunwayOpened: true,
+	EventIncident:     true,
+}
+
+// SpectatorState is the sanitized view of the simulation shown to spectators.
+type SpectatorState struct {
+	Rate     int64           `json:"rate"`
+	Runways  map[string]bool `json:"runways"`
+	Wind     WindState       `json:"wind"`
+	Arrivals int64           `json:"arrivals"`
+	Holding  int64           `json:"holding"`
+}
+
+// SpectatorEvent is an event stripped of flight identity and free-form detail.
+type SpectatorEvent struct {
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+	Runway string    `json:"runway,omitempty"`
+}
+
+// HandleSpectate streams a read-only, rate-limited view of the simulation as
+// server-sent events. It accepts no input and requires no authentication, so
+// it is safe to project publicly during demos.
+func (s *Server) HandleSpectate(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var events <-chan Event
+	if s.Events != nil {
+		ch, unsubscribe := s.Events.Subscribe(eventBufferSize)
+		defer unsubscribe()
+		events = ch
+	}
+	streamSpectator(w, r, flusher, events, s.spectatorState)
+}
+
+// streamSpectator writes the spectator state every interval and the
+// spectator events as they arrive, rate-limited.
+func streamSpectator(w http.ResponseWriter, r *http.Request, flusher http.Flusher, events <-chan Event, state func() SpectatorState) {
+	ticker := time.NewTicker(spectatorStateInterval)
+	defer ticker.Stop()
+
+	if err := writeSSE(w, "state", state()); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	windowStart := time.Now()
+	sent := 0
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if err := writeSSE(w, "state", state()); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if !spectatorEventTypes[e.Type] {
+				continue
+			}
+			if time.Since(windowStart) >= time.Second {
+				windowStart = time.Now()
+				sent = 0
+			}
+			if sent >= spectatorEventsPerSec {
+				continue
+			}
+			sent++
+			if err := writeSSE(w, "event", SpectatorEvent{Type: e.Type, Time: e.Time, Runway: e.Runway}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) spectatorState() SpectatorState {
+	state := SpectatorState{Rate: s.Generator.Rate(), Runways: map[string]bool{}}
+	if s.Runways != nil {
+		for _, name := range s.Runways.RunwayNames() {
+			state.Runways[name] = !s.Runways.IsClosed(name)
+		}
+		state.Wind = s.Runways.Wind()
+	}
+	if s.Metrics != nil {
+		snapshot := s.Metrics.Snapshot()
+		state.Arrivals = snapshot.TotalArrivals
+		state.Holding = snapshot.HoldingCurrent
+	}
+	return state
+}
+
+// spectatorStateOf derives the spectator state from a full snapshot.
+func spectatorStateOf(snap StateSnapshot) SpectatorState {
+	state := SpectatorState{Rate: snap.Rate, Runways: map[string]bool{}, Wind: snap.Wind}
+	for _, r := range snap.Runways {
+		state.Runways[r.Name] = !r.Closed
+	}
+	if snap.Metrics != nil {
+		state.Arrivals = snap.Metrics.TotalArrivals
+		state.Holding = snap.Metrics.HoldingCurrent
+	}
+	return state
+}
+
+func writeSSE(w http.ResponseWriter, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("encode spectator %s: %v", event, err)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
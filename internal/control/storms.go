@@ -0,0 +1,348 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Event types for thunderstorm cells.
+const (
+	EventStormBlocked = "stormBlocked"
+	EventStormCleared = "stormCleared"
+)
+
+const (
+	// finalApproachNM is the length of the final approach, which arrivals
+	// cannot be vectored off; intermediateNM is where the approach joins
+	// from, past which a cell can be flown around.
+	finalApproachNM = 5.0
+	intermediateNM  = 15.0
+	// stormBlockingIntensity is the weakest cell, on the 1-6 VIP scale,
+	// that arrivals will not fly through.
+	stormBlockingIntensity = 3
+	// stormDeviation is the extra flying time of an arrival vectored around
+	// a cell on the intermediate approach.
+	stormDeviation = 3 * landingDuration
+	// stormInterval is how often cell positions are re-evaluated.
+	stormInterval = 5 * time.Second
+	// stormRange is how far from the airfield, in NM, a cell dissipates.
+	stormRange = 60.0
+)
+
+// Point is a position in nautical miles east (X) and north (Y) of the
+// airfield.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// StormCell is a convective cell: a polygon of the given Intensity on the
+// 1-6 VIP scale drifting with Velocity, in knots east and north.
+type StormCell struct {
+	ID        string  `json:"id"`
+	Polygon   []Point `json:"polygon"`
+	Intensity int     `json:"intensity"`
+	Velocity  Point   `json:"velocity"`
+}
+
+// stormCell is a cell as placed at since.
+type stormCell struct {
+	cell  StormCell
+	since time.Time
+}
+
+// at returns the cell where it has drifted to by t.
+func (c stormCell) at(t time.Time) StormCell {
+	hours := t.Sub(c.since).Hours()
+	moved := c.cell
+	moved.Polygon = make([]Point, len(c.cell.Polygon))
+	for i, p := range c.cell.Polygon {
+		moved.Polygon[i] = Point{X: p.X + c.cell.Velocity.X*hours, Y: p.Y + c.cell.Velocity.Y*hours}
+	}
+	return moved
+}
+
+// AddStormCell places a convective cell. Arrivals whose final approach it
+// now blocks miss the approach.
+func (rm *RunwayManager) AddStormCell(cell StormCell) (StormCell, error) {
+	if len(cell.Polygon) < 3 {
+		return StormCell{}, fmt.Errorf("polygon needs at least 3 points")
+	}
+	if cell.Intensity < 1 || cell.Intensity > 6 {
+		return StormCell{}, fmt.Errorf("intensity must be between 1 and 6")
+	}
+	rm.mu.Lock()
+	rm.nextStormID++
+	cell.ID = "cb-" + strconv.FormatInt(rm.nextStormID, 10)
+	rm.storms[cell.ID] = stormCell{cell: cell, since: rm.clock.Now()}
+	log.Printf("storm cell %s placed, intensity %d", cell.ID, cell.Intensity)
+	if !rm.stormTicking {
+		rm.stormTicking = true
+		rm.clock.AfterFunc(stormInterval, rm.trackStorms)
+	}
+	released := rm.updateStormsLocked()
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+	return cell, nil
+}
+
+// RemoveStormCell removes a cell, reporting whether it existed.
+func (rm *RunwayManager) RemoveStormCell(id string) bool {
+	rm.mu.Lock()
+	if _, ok := rm.storms[id]; !ok {
+		rm.mu.Unlock()
+		return false
+	}
+	delete(rm.storms, id)
+	log.Printf("storm cell %s removed", id)
+	released := rm.updateStormsLocked()
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+	return true
+}
+
+// trackStorms moves the cells on, dissipating those out of range, until
+// none are left.
+func (rm *RunwayManager) trackStorms() {
+	rm.mu.Lock()
+	now := rm.clock.Now()
+	for id, c := range rm.storms {
+		if stormOutOfRange(c.at(now)) {
+			delete(rm.storms, id)
+			log.Printf("storm cell %s dissipated", id)
+		}
+	}
+	released := rm.updateStormsLocked()
+	if len(rm.storms) > 0 {
+		rm.clock.AfterFunc(stormInterval, rm.trackStorms)
+	} else {
+		rm.stormTicking = false
+	}
+	rm.mu.Unlock()
+	if released {
+		rm.releaseHolding()
+	}
+}
+
+func stormOutOfRange(c StormCell) bool {
+	for _, p := range c.Polygon {
+		if math.Hypot(p.X, p.Y) < stormRange {
+			return false
+		}
+	}
+	return true
+}
+
+// updateStormsLocked publishes finals becoming blocked or clear, sending
+// arrivals on newly blocked finals to holding. It reports whether a final
+// cleared, in which case the caller should release the holding stack once
+// the lock is dropped.
+func (rm *RunwayManager) updateStormsLocked() bool {
+	released := false
+	for _, name := range rm.order {
+		blocked := rm.stormBlockedLocked(name, 0, finalApproachNM)
+		if blocked == rm.stormBlocked[name] {
+			continue
+		}
+		rm.stormBlocked[name] = blocked
+		if blocked {
+			rm.publishEventLocked(Event{Type: EventStormBlocked, Runway: name, Detail: "thunderstorm on final approach"})
+			missed := rm.missApproachesLocked(name, rm.clearOfStormsLocked, DelayWeather, "missed approach: thunderstorm on final")
+			log.Printf("thunderstorm on final %s; %d flights to holding", name, missed)
+			continue
+		}
+		rm.publishEventLocked(Event{Type: EventStormCleared, Runway: name})
+		log.Printf("final %s clear of thunderstorms", name)
+		released = true
+	}
+	return released
+}
+
+// clearOfStormsLocked reports whether f can fly the final approach to
+// runway.
+func (rm *RunwayManager) clearOfStormsLocked(runway string, f Flight) bool {
+	return !rm.stormBlocked[runway]
+}
+
+// approachPathLocked is runway's inbound approach from intermediateNM to
+// the threshold.
+func (rm *RunwayManager) approachPathLocked(runway string) []Point {
+	heading := rm.trueHeadingLocked(runway)
+	return []Point{approachPoint(heading, intermediateNM), approachPoint(heading, 0)}
+}
+
+// approachPoint is the point distance NM out on the approach to a runway
+// landed on heading, in degrees true, to the nearest thousandth of a mile.
+func approachPoint(heading, distance float64) Point {
+	rad := normalizeHeading(heading+180) * math.Pi / 180
+	round := func(v float64) float64 { return math.Round(v*1000)/1000 + 0 }
+	return Point{X: round(distance * math.Sin(rad)), Y: round(distance * math.Cos(rad))}
+}
+
+// stormBlockedLocked reports whether a blocking cell lies on the approach
+// to runway between from and to NM out.
+func (rm *RunwayManager) stormBlockedLocked(runway string, from, to float64) bool {
+	heading := rm.trueHeadingLocked(runway)
+	a, b := approachPoint(heading, from), approachPoint(heading, to)
+	now := rm.clock.Now()
+	for _, c := range rm.storms {
+		if c.cell.Intensity >= stormBlockingIntensity && segmentCrossesPolygon(a, b, c.at(now).Polygon) {
+			return true
+		}
+	}
+	return false
+}
+
+// stormDeviationLocked is the extra time an arrival to runway spends being
+// vectored around cells on the intermediate approach.
+func (rm *RunwayManager) stormDeviationLocked(runway string) time.Duration {
+	if rm.stormBlockedLocked(runway, finalApproachNM, intermediateNM) {
+		return stormDeviation
+	}
+	return 0
+}
+
+func segmentCrossesPolygon(a, b Point, polygon []Point) bool {
+	if pointInPolygon(a, polygon) || pointInPolygon(b, polygon) {
+		return true
+	}
+	for i := range polygon {
+		if segmentsIntersect(a, b, polygon[i], polygon[(i+1)%len(polygon)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func pointInPolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	cross := func(o, a, b Point) float64 { return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X) }
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	return (d1 > 0) != (d2 > 0) && (d3 > 0) != (d4 > 0)
+}
+
+// ApproachPath is a runway's approach, outermost point first, and how
+// thunderstorms affect it.
+type ApproachPath struct {
+	Runway string  `json:"runway"`
+	Path   []Point `json:"path"`
+	// Blocked is set when a cell sits on the final approach; Deviating
+	// when arrivals are vectored around one further out.
+	Blocked   bool `json:"blocked"`
+	Deviating bool `json:"deviating"`
+}
+
+// StormState is the thunderstorm cells at their current positions and the
+// approaches they affect.
+type StormState struct {
+	Time       time.Time      `json:"time"`
+	Cells      []StormCell    `json:"cells"`
+	Approaches []ApproachPath `json:"approaches"`
+}
+
+// Storms reports the cells where they are now.
+func (rm *RunwayManager) Storms() StormState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := rm.clock.Now()
+	state := StormState{Time: now, Cells: make([]StormCell, 0, len(rm.storms)), Approaches: make([]ApproachPath, 0, len(rm.order))}
+	for _, id := range sortedKeys(rm.storms) {
+		state.Cells = append(state.Cells, rm.storms[id].at(now))
+	}
+	for _, name := range rm.order {
+		state.Approaches = append(state.Approaches, ApproachPath{
+			Runway:    name,
+			Path:      rm.approachPathLocked(name),
+			Blocked:   rm.stormBlocked[name],
+			Deviating: rm.stormDeviationLocked(name) > 0,
+		})
+	}
+	return state
+}
+
+// HandleStorms lists thunderstorm cells (GET) or places one (POST).
+func (s *Server) HandleStorms(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Runways.Storms())
+		return
+	}
+	var req StormCell
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid storm cell: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	cell, err := s.Runways.AddStormCell(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, cell)
+}
+
+// HandleStormCell removes the cell named in the path.
+func (s *Server) HandleStormCell(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.Runways.RemoveStormCell(r.PathValue("id")) {
+		http.Error(w, "storm cell not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleStormStream streams cell positions as server-sent "storms" events
+// every stormInterval, for map display.
+func (s *Server) HandleStormStream(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(stormInterval)
+	defer ticker.Stop()
+	for {
+		if err := writeSSE(w, "storms", s.Runways.Storms()); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,596 @@
+package control
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxTenants bounds the simulation instances when the limit is unset.
+const defaultMaxTenants = 10
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// TenantDefaults is what each new simulation instance starts with unless
+// its template or TenantConfig overrides it.
+type TenantDefaults struct {
+	Runways []RunwayDefinition
+	Gates   []Gate
+	Wind    WindState
+	// Speed is the simulated seconds per real second, 1 when zero.
+	Speed float64
+	// MaxTenants bounds the instances running at once.
+	MaxTenants int
+	// Quota limits each instance unless created with its own.
+	Quota TenantQuota
+	// Registry resolves template strategies; nil allows only the
+	// default strategy.
+	Registry *Registry
+}
+
+// TenantQuota limits one simulation instance so a stress test in one
+// cannot starve the others; zero leaves a resource unlimited. Arrivals
+// beyond MaxFlights divert, rate changes above MaxRate are capped and
+// websocket clients beyond MaxClients are refused.
+type TenantQuota struct {
+	MaxFlights int   `json:"maxFlights,omitempty"`
+	MaxRate    int64 `json:"maxRate,omitempty"`
+	MaxClients int   `json:"maxClients,omitempty"`
+}
+
+// TenantUsage is an instance's current use of its quota.
+type TenantUsage struct {
+	Flights int   `json:"flights"`
+	Rate    int64 `json:"rate"`
+	Clients int   `json:"clients"`
+}
+
+// TenantTemplate is a named exercise environment, so an instructor can
+// create identical instances for a class: the airport, the scenario
+// started in it, the assignment strategy and who may use it. Unset
+// fields fall back to the server's defaults.
+type TenantTemplate struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Runways     []RunwayDefinition `json:"runways,omitempty"`
+	Gates       []Gate             `json:"gates,omitempty"`
+	Wind        *WindState         `json:"wind,omitempty"`
+	Rate        int64              `json:"rate,omitempty"`
+	Speed       float64            `json:"speed,omitempty"`
+	// Scenario names a built-in scenario started with Params as soon as
+	// the instance is created.
+	Scenario string             `json:"scenario,omitempty"`
+	Params   map[string]float64 `json:"params,omitempty"`
+	// Strategy names a registered assignment strategy.
+	Strategy string       `json:"strategy,omitempty"`
+	Quota    *TenantQuota `json:"quota,omitempty"`
+	// Access maps bearer tokens to the people allowed to use an instance;
+	// empty leaves it open to anyone.
+	Access map[string]string `json:"access,omitempty"`
+}
+
+// TenantTemplateView is a template as the API returns it: the access
+// tokens are secrets, so only the names they were issued to are listed.
+type TenantTemplateView struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Runways     []RunwayDefinition `json:"runways,omitempty"`
+	Gates       []Gate             `json:"gates,omitempty"`
+	Wind        *WindState         `json:"wind,omitempty"`
+	Rate        int64              `json:"rate,omitempty"`
+	Speed       float64            `json:"speed,omitempty"`
+	Scenario    string             `json:"scenario,omitempty"`
+	Params      map[string]float64 `json:"params,omitempty"`
+	Strategy    string             `json:"strategy,omitempty"`
+	Quota       *TenantQuota       `json:"quota,omitempty"`
+	// Users names the people granted access, sorted; empty when the
+	// template is open to anyone.
+	Users []string `json:"users,omitempty"`
+}
+
+func (tpl TenantTemplate) view() TenantTemplateView {
+	v := TenantTemplateView{
+		Name:        tpl.Name,
+		Description: tpl.Description,
+		Runways:     tpl.Runways,
+		Gates:       tpl.Gates,
+		Wind:        tpl.Wind,
+		Rate:        tpl.Rate,
+		Speed:       tpl.Speed,
+		Scenario:    tpl.Scenario,
+		Params:      tpl.Params,
+		Strategy:    tpl.Strategy,
+		Quota:       tpl.Quota,
+	}
+	for _, user := range tpl.Access {
+		v.Users = append(v.Users, user)
+	}
+	sort.Strings(v.Users)
+	return v
+}
+
+// TenantConfig creates a simulation instance, from Template if set.
+// Rate defaults to 5 planes per minute and Runways to the template's.
+type TenantConfig struct {
+	ID       string             `json:"id"`
+	Template string             `json:"template,omitempty"`
+	Rate     int64              `json:"rate,omitempty"`
+	Runways  []RunwayDefinition `json:"runways,omitempty"`
+	Speed    float64            `json:"speed,omitempty"`
+	// Quota replaces the template's limits.
+	Quota *TenantQuota `json:"quota,omitempty"`
+}
+
+// TenantInfo describes a running simulation instance. Its API is served
+// under BasePath, e.g. /tenants/acme/api/v1/state.
+type TenantInfo struct {
+	ID        string      `json:"id"`
+	BasePath  string      `json:"basePath"`
+	Template  string      `json:"template,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Runways   []string    `json:"runways"`
+	Scenario  string      `json:"scenario,omitempty"`
+	Quota     TenantQuota `json:"quota"`
+	Usage     TenantUsage `json:"usage"`
+	// Restricted is set when only the template's access list may use
+	// the instance.
+	Restricted bool `json:"restricted"`
+}
+
+type tenant struct {
+	id       string
+	template string
+	created  time.Time
+	server   *Server
+	quota    TenantQuota
+	access   map[string]string
+	handler  http.Handler
+	cancel   context.CancelFunc
+}
+
+// Tenants runs independent simulation instances in one process, each with
+// its own clock, generator, runways, metrics and events.
+type Tenants struct {
+	ctx      context.Context
+	defaults TenantDefaults
+
+	mu        sync.Mutex
+	byID      map[string]*tenant
+	templates map[string]TenantTemplate
+}
+
+// NewTenants returns an empty set of instances that run until ctx is
+// done.
+func NewTenants(ctx context.Context, defaults TenantDefaults) *Tenants {
+	if defaults.MaxTenants <= 0 {
+		defaults.MaxTenants = defaultMaxTenants
+	}
+	return &Tenants{ctx: ctx, defaults: defaults, byID: make(map[string]*tenant), templates: make(map[string]TenantTemplate)}
+}
+
+// SaveTemplate adds a template or replaces the one of the same name.
+func (t *Tenants) SaveTemplate(tpl TenantTemplate) error {
+	tpl.Name = strings.TrimSpace(tpl.Name)
+	if tpl.Name == "" {
+		return errors.New("name missing")
+	}
+	if tpl.Rate < 0 || tpl.Speed < 0 {
+		return errors.New("rate and speed must not be negative")
+	}
+	if err := tpl.Quota.validate(); err != nil {
+		return err
+	}
+	if _, err := runwayNames(tpl.Runways); err != nil {
+		return err
+	}
+	if tpl.Strategy != "" {
+		if _, err := t.strategy(tpl.Strategy); err != nil {
+			return err
+		}
+	}
+	if tpl.Scenario != "" {
+		if _, err := RenderScenario(tpl.Scenario, tpl.Params); err != nil {
+			return err
+		}
+	}
+	for token := range tpl.Access {
+		if token == "" {
+			return errors.New("empty access token")
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[tpl.Name] = tpl
+	return nil
+}
+
+// Templates lists the templates by name.
+func (t *Tenants) Templates() []TenantTemplate {
+	t.mu.Lock()
+	out := make([]TenantTemplate, 0, len(t.templates))
+	for _, tpl := range t.templates {
+		out = append(out, tpl)
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Template returns the template called name.
+func (t *Tenants) Template(name string) (TenantTemplate, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tpl, ok := t.templates[name]
+	return tpl, ok
+}
+
+// DeleteTemplate removes a template, reporting whether it existed.
+// Instances created from it keep running.
+func (t *Tenants) DeleteTemplate(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.templates[name]
+	delete(t.templates, name)
+	return ok
+}
+
+// runwayNames returns the names of an instance's runways, which must be
+// set and distinct.
+func runwayNames(defs []RunwayDefinition) ([]string, error) {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		if def.Name == "" {
+			return nil, errors.New("runway name missing")
+		}
+		if slices.Contains(names[:i], def.Name) {
+			return nil, fmt.Errorf("runway %q defined twice", def.Name)
+		}
+		names[i] = def.Name
+	}
+	return names, nil
+}
+
+// strategy looks up a registered assignment strategy for one instance.
+func (t *Tenants) strategy(name string) (AssignmentStrategy, error) {
+	if t.defaults.Registry == nil {
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+	strategy, err := t.defaults.Registry.Strategy(name)
+	if err != nil {
+		return nil, err
+	}
+	// Registered strategies are shared; round-robin keeps its own
+	// rotation per instance.
+	if _, ok := strategy.(*RoundRobinStrategy); ok {
+		strategy = &RoundRobinStrategy{}
+	}
+	return strategy, nil
+}
+
+func (q *TenantQuota) validate() error {
+	if q != nil && (q.MaxFlights < 0 || q.MaxRate < 0 || q.MaxClients < 0) {
+		return errors.New("quota limits must not be negative")
+	}
+	return nil
+}
+
+// Create starts a simulation instance.
+func (t *Tenants) Create(cfg TenantConfig) (TenantInfo, error) {
+	if !tenantIDPattern.MatchString(cfg.ID) {
+		return TenantInfo{}, errors.New("id must be 1-32 lowercase letters, digits or dashes")
+	}
+	if cfg.Rate < 0 || cfg.Speed < 0 {
+		return TenantInfo{}, errors.New("rate and speed must not be negative")
+	}
+	if err := cfg.Quota.validate(); err != nil {
+		return TenantInfo{}, err
+	}
+	var tpl TenantTemplate
+	if cfg.Template != "" {
+		var ok bool
+		if tpl, ok = t.Template(cfg.Template); !ok {
+			return TenantInfo{}, fmt.Errorf("unknown template %q", cfg.Template)
+		}
+	}
+	rate := cmp.Or(cfg.Rate, tpl.Rate, 5)
+	speed := cmp.Or(cfg.Speed, tpl.Speed, t.defaults.Speed, 1)
+	defs := cfg.Runways
+	if len(defs) == 0 {
+		defs = tpl.Runways
+	}
+	if len(defs) == 0 {
+		defs = t.defaults.Runways
+	}
+	names, err := runwayNames(defs)
+	if err != nil {
+		return TenantInfo{}, err
+	}
+	gates := tpl.Gates
+	if len(gates) == 0 {
+		gates = t.defaults.Gates
+	}
+	wind := t.defaults.Wind
+	if tpl.Wind != nil {
+		wind = *tpl.Wind
+	}
+	quota := t.defaults.Quota
+	if tpl.Quota != nil {
+		quota = *tpl.Quota
+	}
+	if cfg.Quota != nil {
+		quota = *cfg.Quota
+	}
+	var strategy AssignmentStrategy
+	if tpl.Strategy != "" {
+		var err error
+		if strategy, err = t.strategy(tpl.Strategy); err != nil {
+			return TenantInfo{}, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.byID[cfg.ID]; ok {
+		return TenantInfo{}, fmt.Errorf("tenant %q exists", cfg.ID)
+	}
+	if len(t.byID) >= t.defaults.MaxTenants {
+		return TenantInfo{}, fmt.Errorf("at most %d tenants", t.defaults.MaxTenants)
+	}
+
+	ctx, cancel := context.WithCancel(t.ctx)
+	clock := NewSimClock(time.Now(), speed)
+	generator := NewGenerator(rate)
+	generator.SetMaxRate(quota.MaxRate)
+	metrics := NewSchedulerMetrics(names)
+	events := NewEventBus()
+	runways := NewRunwayManager(defs, metrics, events)
+	generator.SetClock(clock)
+	runways.SetClock(clock)
+	events.SetClock(clock)
+	metrics.SetClock(clock)
+	runways.SetWindState(wind)
+	if len(gates) > 0 {
+		runways.SetGates(gates)
+	}
+	if strategy != nil {
+		runways.SetStrategy(strategy)
+	}
+	runways.SetRotations(generator.NextID)
+	runways.SetMaxFlights(quota.MaxFlights)
+	server := NewServer(generator, runways, metrics, events)
+	server.MaxClients = quota.MaxClients
+	if tpl.Scenario != "" {
+		if _, err := server.StartScenario(tpl.Scenario, tpl.Params); err != nil {
+			cancel()
+			return TenantInfo{}, fmt.Errorf("scenario: %w", err)
+		}
+	}
+	flights := make(chan Flight, 16)
+	go generator.Run(ctx, flights)
+	go runways.Run(ctx, flights)
+
+	mux := http.NewServeMux()
+	server.Register(mux)
+	ten := &tenant{id: cfg.ID, template: tpl.Name, created: time.Now(), server: server, quota: quota, access: tpl.Access, cancel: cancel}
+	ten.handler = http.StripPrefix("/tenants/"+cfg.ID, mux)
+	t.byID[cfg.ID] = ten
+	log.Printf("tenant %s created with runways %s at %d/min", cfg.ID, strings.Join(names, ","), generator.Rate())
+	return ten.info(), nil
+}
+
+// Destroy stops and removes an instance, reporting whether it existed.
+func (t *Tenants) Destroy(id string) bool {
+	t.mu.Lock()
+	ten, ok := t.byID[id]
+	delete(t.byID, id)
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+	ten.cancel()
+	ten.server.stopScenario()
+	log.Printf("tenant %s destroyed", id)
+	return true
+}
+
+// allows reports whether r carries a token on the instance's access list,
+// from an Authorization bearer header or, for websockets, the token query
+// parameter.
+func (ten *tenant) allows(r *http.Request) bool {
+	if len(ten.access) == 0 {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	for t := range ten.access {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// List describes the running instances by ID.
+func (t *Tenants) List() []TenantInfo {
+	t.mu.Lock()
+	out := make([]TenantInfo, 0, len(t.byID))
+	for _, ten := range t.byID {
+		out = append(out, ten.info())
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Get describes the instance id.
+func (t *Tenants) Get(id string) (TenantInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ten, ok := t.byID[id]
+	if !ok {
+		return TenantInfo{}, false
+	}
+	return ten.info(), true
+}
+
+// Server returns the API server of the instance id.
+func (t *Tenants) Server(id string) (*Server, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ten, ok := t.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return ten.server, true
+}
+
+func (ten *tenant) info() TenantInfo {
+	s := ten.server
+	info := TenantInfo{
+		ID:        ten.id,
+		BasePath:  "/tenants/" + ten.id,
+		Template:  ten.template,
+		CreatedAt: ten.created,
+		Runways:   s.Runways.RunwayNames(),
+		Quota:     ten.quota,
+		Usage: TenantUsage{
+			Flights: s.Runways.Arrivals(),
+			Rate:    s.Generator.Rate(),
+			Clients: len(s.Clients()),
+		},
+		Restricted: len(ten.access) > 0,
+	}
+	if run := s.ActiveScenario(); run != nil {
+		info.Scenario = run.Name
+	}
+	return info
+}
+
+// ServeHTTP serves an instance's API under /tenants/{tenant}/, the same
+// routes the server itself serves, to those on its access list.
+func (t *Tenants) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.Lock()
+	ten, ok := t.byID[r.PathValue("tenant")]
+	t.mu.Unlock()
+	if !ok {
+		http.Error(w, "tenant not found", http.StatusNotFound)
+		return
+	}
+	if !ten.allows(r) {
+		http.Error(w, "tenant access denied", http.StatusUnauthorized)
+		return
+	}
+	ten.handler.ServeHTTP(w, r)
+}
+
+// HandleTenants lists the instances (GET) or creates one (POST).
+func (s *Server) HandleTenants(w http.ResponseWriter, r *http.Request) {
+	if s.Tenants == nil {
+		http.Error(w, "multi-tenancy disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.Tenants.List())
+		return
+	}
+	var cfg TenantConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, "invalid tenant: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	info, err := s.Tenants.Create(cfg)
+	if err != nil {
+		http.Error(w, "invalid tenant: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// HandleTenant describes (GET) or destroys (DELETE) the instance named in
+// the path.
+func (s *Server) HandleTenant(w http.ResponseWriter, r *http.Request) {
+	if s.Tenants == nil {
+		http.Error(w, "multi-tenancy disabled", http.StatusServiceUnavailable)
+		return
+	}
+	id := r.PathValue("id")
+	if r.Method == http.MethodDelete {
+		if !s.Tenants.Destroy(id) {
+			http.Error(w, "tenant not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	info, ok := s.Tenants.Get(id)
+	if !ok {
+		http.Error(w, "tenant not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandleTenantTemplates lists the templates (GET) or saves one (POST).
+func (s *Server) HandleTenantTemplates(w http.ResponseWriter, r *http.Request) {
+	if s.Tenants == nil {
+		http.Error(w, "multi-tenancy disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		templates := s.Tenants.Templates()
+		views := make([]TenantTemplateView, len(templates))
+		for i, tpl := range templates {
+			views[i] = tpl.view()
+		}
+		writeJSON(w, http.StatusOK, views)
+		return
+	}
+	var tpl TenantTemplate
+	if err := json.NewDecoder(r.Body).Decode(&tpl); err != nil {
+		http.Error(w, "invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Tenants.SaveTemplate(tpl); err != nil {
+		http.Error(w, "invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	saved, _ := s.Tenants.Template(strings.TrimSpace(tpl.Name))
+	writeJSON(w, http.StatusCreated, saved.view())
+}
+
+// HandleTenantTemplate returns (GET) or deletes (DELETE) the template
+// named in the path.
+func (s *Server) HandleTenantTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.Tenants == nil {
+		http.Error(w, "multi-tenancy disabled", http.StatusServiceUnavailable)
+		return
+	}
+	name := r.PathValue("name")
+	if r.Method == http.MethodDelete {
+		if !s.Tenants.DeleteTemplate(name) {
+			http.Error(w, "template not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	tpl, ok := s.Tenants.Template(name)
+	if !ok {
+		http.Error(w, "template not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, tpl.view())
+}
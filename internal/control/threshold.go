@@ -0,0 +1,189 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Event types for temporarily shortened runways.
+const (
+	EventRunwayShortened = "runwayShortened"
+	EventRunwayRestored  = "runwayRestored"
+)
+
+// Ends of a runway that works in progress can close off.
+const (
+	// EndThreshold displaces the landing threshold: arrivals touch down
+	// further along and exits are that much closer.
+	EndThreshold = "threshold"
+	// EndFar cuts the far end off, with any exits beyond it.
+	EndFar = "far"
+)
+
+// landingDistances is the landing distance, in meters, each weight category
+// needs.
+var landingDistances = map[string]float64{
+	WeightLight:  900,
+	WeightMedium: 1800,
+	WeightHeavy:  2400,
+	WeightSuper:  2800,
+}
+
+// RunwayShortening closes Meters of a runway at one End, typically for
+// works in progress.
+type RunwayShortening struct {
+	Runway string  `json:"runway"`
+	End    string  `json:"end"`
+	Meters float64 `json:"meters"`
+	// LandingDistance is the length left to land on.
+	LandingDistance float64 `json:"landingDistance"`
+}
+
+// ShortenRunway closes part of runway at one end. Arrivals needing more
+// than the remaining length are sent from its queue to holding, and no
+// more are sequenced onto it until it is restored. Runways without a
+// defined length cannot be shortened.
+func (rm *RunwayManager) ShortenRunway(runway, end string, meters float64) (RunwayShortening, error) {
+	if end == "" {
+		end = EndThreshold
+	}
+	if end != EndThreshold && end != EndFar {
+		return RunwayShortening{}, fmt.Errorf("unknown runway end %q", end)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	runway = rm.resolveLocked(runway)
+	r, ok := rm.runways[runway]
+	if !ok {
+		return RunwayShortening{}, fmt.Errorf("unknown runway %q", runway)
+	}
+	if r.definition.Length <= 0 {
+		return RunwayShortening{}, fmt.Errorf("runway %s has no defined length", runway)
+	}
+	if meters <= 0 || meters >= r.definition.Length {
+		return RunwayShortening{}, fmt.Errorf("meters must be between 0 and the runway length %.0f", r.definition.Length)
+	}
+	r.shortening = &RunwayShortening{Runway: runway, End: end, Meters: meters, LandingDistance: r.definition.Length - meters}
+	rm.publishEventLocked(Event{Type: EventRunwayShortened, Runway: runway, Detail: fmt.Sprintf("%.0fm closed at %s end", meters, end)})
+	log.Printf("runway %s shortened by %.0fm at %s end", runway, meters, end)
+	rm.missApproachesLocked(runway, rm.longEnoughLocked, DelayRunwayClosure, "missed approach: runway too short")
+	return *r.shortening, nil
+}
+
+// RestoreRunway lifts a runway's shortening. It reports false if the runway
+// was not shortened.
+func (rm *RunwayManager) RestoreRunway(runway string) bool {
+	rm.mu.Lock()
+	runway = rm.resolveLocked(runway)
+	r, ok := rm.runways[runway]
+	if !ok || r.shortening == nil {
+		rm.mu.Unlock()
+		return false
+	}
+	r.shortening = nil
+	rm.publishEventLocked(Event{Type: EventRunwayRestored, Runway: runway})
+	log.Printf("runway %s restored to full length", runway)
+	rm.mu.Unlock()
+	rm.releaseHolding()
+	return true
+}
+
+// Shortenings lists the runways currently shortened.
+func (rm *RunwayManager) Shortenings() []RunwayShortening {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := []RunwayShortening{}
+	for _, name := range rm.order {
+		if s := rm.runways[name].shortening; s != nil {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
+
+// landingDistanceLocked is the length available to land on runway; zero
+// means unknown.
+func (rm *RunwayManager) landingDistanceLocked(runway string) float64 {
+	r := rm.runways[runway]
+	if r.shortening != nil {
+		return r.shortening.LandingDistance
+	}
+	return r.definition.Length
+}
+
+// longEnoughLocked reports whether runway is long enough for f to land at
+// the current density altitude. Runways of unknown length take any
+// aircraft.
+func (rm *RunwayManager) longEnoughLocked(runway string, f Flight) bool {
+	available := rm.landingDistanceLocked(runway)
+	if available <= 0 {
+		return true
+	}
+	required, ok := landingDistances[f.Weight]
+	if !ok {
+		required = landingDistances[WeightMedium]
+	}
+	return available >= required*rm.atmosphere.performanceFactor(landingPerThousand)
+}
+
+// exitsLocked lists the exits arrivals on runway can use, measured from the
+// threshold in use. Runways of known length can always be vacated at their
+// end.
+func (rm *RunwayManager) exitsLocked(runway string) []RunwayExit {
+	r := rm.runways[runway]
+	available := rm.landingDistanceLocked(runway)
+	exits := make([]RunwayExit, 0, len(r.definition.Exits)+1)
+	farthest := 0.0
+	for _, e := range r.definition.Exits {
+		if s := r.shortening; s != nil && s.End == EndThreshold {
+			e.Distance -= s.Meters
+		}
+		if e.Distance > 0 && (available <= 0 || e.Distance <= available) {
+			exits = append(exits, e)
+			farthest = max(farthest, e.Distance)
+		}
+	}
+	if available > 0 && farthest < available {
+		exits = append(exits, RunwayExit{Name: "end", Distance: available})
+	}
+	return exits
+}
+
+// HandleShortenings lists shortened runways.
+func (s *Server) HandleShortenings(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Shortenings())
+}
+
+// HandleShortening shortens (PUT) or restores (DELETE) a runway.
+func (s *Server) HandleShortening(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	runway := r.PathValue("runway")
+	if r.Method == http.MethodDelete {
+		if !s.Runways.RestoreRunway(runway) {
+			http.Error(w, "runway not shortened", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var req RunwayShortening
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid shortening: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	shortening, err := s.Runways.ShortenRunway(runway, req.End, req.Meters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, shortening)
+}
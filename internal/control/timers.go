@@ -0,0 +1,191 @@
+package control
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// timerStreamInterval is how often control clients are sent the flight
+// timers.
+const timerStreamInterval = time.Second
+
+// FlightTimer is the authoritative countdown of one flight, so every display
+// shows the same timers rather than computing them from its own clock.
+type FlightTimer struct {
+	FlightID int64  `json:"flightId"`
+	Call     string `json:"call"`
+	// Runway is the runway a landing flight is sequenced to; empty while
+	// holding.
+	Runway string `json:"runway,omitempty"`
+	// TouchdownAt and SecondsToTouchdown count down to a scheduled landing.
+	TouchdownAt        *time.Time `json:"touchdownAt,omitempty"`
+	SecondsToTouchdown *float64   `json:"secondsToTouchdown,omitempty"`
+	// HoldingSince and SecondsHolding count the time spent in holding.
+	HoldingSince   *time.Time `json:"holdingSince,omitempty"`
+	SecondsHolding *float64   `json:"secondsHolding,omitempty"`
+	// ExpectedApproachAt is the expected approach time (EAT) of a holding
+	// flight: when it would leave the stack were each flight ahead of it
+	// given the next landing slot in turn, at the first pass of the
+	// holding fix from then. It is unset while no runway is available.
+	ExpectedApproachAt *time.Time `json:"expectedApproachAt,omitempty"`
+}
+
+// FlightTimers is every flight's countdown as of ServerTime.
+type FlightTimers struct {
+	ServerTime time.Time     `json:"serverTime"`
+	Flights    []FlightTimer `json:"flights"`
+}
+
+// Timers returns the countdowns of the flights sequenced to land and of
+// those holding.
+func (rm *RunwayManager) Timers() FlightTimers {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := rm.clock.Now()
+	out := FlightTimers{ServerTime: now, Flights: []FlightTimer{}}
+
+	// last is the latest touchdown sequenced on each available runway.
+	last := make(map[string]time.Time)
+	for _, name := range rm.order {
+		var latest time.Time
+		for _, e := range rm.ladderLocked(name) {
+			timer := FlightTimer{FlightID: e.FlightID, Call: e.Call, Runway: name}
+			if due, ok := rm.dueAt[e.FlightID]; ok {
+				secs := max(due.Sub(now).Seconds(), 0)
+				timer.TouchdownAt, timer.SecondsToTouchdown = &due, &secs
+			}
+			out.Flights = append(out.Flights, timer)
+			if e.Target.After(latest) {
+				latest = e.Target
+			}
+		}
+		if rm.runways[name].available() {
+			last[name] = latest
+		}
+	}
+
+	for _, f := range rm.holding {
+		timer := FlightTimer{FlightID: f.ID, Call: f.Call}
+		if d, ok := rm.delays[f.ID]; ok {
+			since := d.since
+			secs := now.Sub(since).Seconds()
+			timer.HoldingSince, timer.SecondsHolding = &since, &secs
+		}
+		if len(last) > 0 {
+			runway := earliestFree(last)
+			approach := rm.approachTimeLocked(runway, f)
+			touchdown := last[runway].Add(rm.sequenceSpacingLocked(runway, f))
+			if earliest := now.Add(approach); touchdown.Before(earliest) {
+				touchdown = earliest
+			}
+			eat := touchdown.Add(-approach)
+			if spell, ok := rm.holdingSpells[f.ID]; ok {
+				eat = rm.pattern.nextFix(spell.fix, eat)
+				touchdown = eat.Add(approach)
+			}
+			last[runway] = touchdown
+			timer.ExpectedApproachAt = &eat
+		}
+		out.Flights = append(out.Flights, timer)
+	}
+	return out
+}
+
+// earliestFree names the runway whose last touchdown comes first, by name
+// on a tie so the estimate is deterministic.
+func earliestFree(last map[string]time.Time) string {
+	names := make([]string, 0, len(last))
+	for name := range last {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	best := names[0]
+	for _, name := range names[1:] {
+		if last[name].Before(last[best]) {
+			best = name
+		}
+	}
+	return best
+}
+
+// ClockInfo describes the simulation clock every API timestamp refers to.
+type ClockInfo struct {
+	Time time.Time `json:"time"`
+	// Epoch is the simulation time the clock started at and Speed the
+	// simulated seconds per real second; both are unset for a clock
+	// stepped by its embedding program.
+	Epoch *time.Time `json:"epoch,omitempty"`
+	Speed float64    `json:"speed,omitempty"`
+	// ElapsedSeconds is the simulation time since Epoch.
+	ElapsedSeconds float64 `json:"elapsedSeconds,omitempty"`
+	// WallTime is the server's wall clock, for reference only.
+	WallTime time.Time `json:"wallTime"`
+}
+
+// Clock describes the simulation clock.
+func (s *Server) Clock() ClockInfo {
+	info := ClockInfo{Time: s.ServerTime(), WallTime: time.Now()}
+	if s.Runways == nil {
+		info.Speed = 1
+		return info
+	}
+	clock := s.Runways.Clock()
+	// A clock wrapped by chaos mode still describes the one underneath.
+	for {
+		w, ok := clock.(interface{ Unwrap() Clock })
+		if !ok {
+			break
+		}
+		clock = w.Unwrap()
+	}
+	switch c := clock.(type) {
+	case *SimClock:
+		epoch := c.Epoch()
+		info.Epoch, info.Speed = &epoch, c.Speed()
+		info.ElapsedSeconds = info.Time.Sub(epoch).Seconds()
+	case RealClock:
+		info.Speed = 1
+	}
+	return info
+}
+
+// HandleTime describes the simulation clock.
+func (s *Server) HandleTime(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Clock())
+}
+
+// ServerTime is the simulation time clients synchronise their displays to.
+func (s *Server) ServerTime() time.Time {
+	if s.Runways != nil {
+		return s.Runways.Clock().Now()
+	}
+	return time.Now()
+}
+
+// streamTimers sends the flight timers to client every timerStreamInterval
+// until done is closed.
+func (s *Server) streamTimers(client *wsClient, done <-chan struct{}) {
+	ticker := time.NewTicker(timerStreamInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		timers := s.Runways.Timers()
+		if err := client.send(Message{Type: "timers", Timers: &timers}); err != nil {
+			return
+		}
+	}
+}
+
+// HandleTimers returns every flight's countdown.
+func (s *Server) HandleTimers(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Timers())
+}
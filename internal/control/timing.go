@@ -0,0 +1,214 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Limits on configured arrival timing. Spacing closer than minSafeSpacing or
+// occupancy shorter than minSafeLanding cannot be flown safely; anything
+// beyond maxArrivalTiming is taken for a unit mistake.
+const (
+	minSafeSpacing   = time.Second
+	minSafeLanding   = 2 * time.Second
+	maxArrivalTiming = 10 * time.Minute
+)
+
+// Wind shortens the landing rollout into a headwind and lengthens it with a
+// tailwind. As in aircraft performance tables, a knot of tailwind counts for
+// three of headwind; the factor is kept within minWindFactor and
+// maxWindFactor.
+const (
+	headwindCredit  = 0.01
+	tailwindPenalty = 0.03
+	minWindFactor   = 0.7
+	maxWindFactor   = 1.5
+)
+
+// ArrivalTiming overrides the spacing behind the preceding arrival and the
+// time from sequencing to touchdown. Zero keeps the value it would
+// otherwise have.
+type ArrivalTiming struct {
+	SpacingSeconds float64 `json:"spacingSeconds,omitempty"`
+	LandingSeconds float64 `json:"landingSeconds,omitempty"`
+}
+
+// RunwayTiming is one runway's arrival timing. Categories overrides it for
+// arrivals of a weight category; the runway's own values apply to the rest,
+// and the defaults to whatever neither sets.
+type RunwayTiming struct {
+	Runway         string                   `json:"runway"`
+	SpacingSeconds float64                  `json:"spacingSeconds,omitempty"`
+	LandingSeconds float64                  `json:"landingSeconds,omitempty"`
+	Categories     map[string]ArrivalTiming `json:"categories,omitempty"`
+}
+
+func (t ArrivalTiming) validate() error {
+	spacing := time.Duration(t.SpacingSeconds * float64(time.Second))
+	landing := time.Duration(t.LandingSeconds * float64(time.Second))
+	switch {
+	case t.SpacingSeconds < 0, t.SpacingSeconds > 0 && spacing < minSafeSpacing:
+		return fmt.Errorf("spacing %.1fs below the minimum of %s", t.SpacingSeconds, minSafeSpacing)
+	case spacing > maxArrivalTiming:
+		return fmt.Errorf("spacing %.1fs above the maximum of %s", t.SpacingSeconds, maxArrivalTiming)
+	case t.LandingSeconds < 0, t.LandingSeconds > 0 && landing < minSafeLanding:
+		return fmt.Errorf("landing time %.1fs below the minimum of %s", t.LandingSeconds, minSafeLanding)
+	case landing > maxArrivalTiming:
+		return fmt.Errorf("landing time %.1fs above the maximum of %s", t.LandingSeconds, maxArrivalTiming)
+	}
+	return nil
+}
+
+func (t RunwayTiming) validate() error {
+	if err := (ArrivalTiming{SpacingSeconds: t.SpacingSeconds, LandingSeconds: t.LandingSeconds}).validate(); err != nil {
+		return err
+	}
+	for category, c := range t.Categories {
+		if _, ok := landingFees[category]; !ok {
+			return fmt.Errorf("unknown weight category %q", category)
+		}
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("%s: %w", category, err)
+		}
+	}
+	return nil
+}
+
+// SetRunwayTiming replaces the arrival timing of a runway. Arrivals already
+// sequenced keep their landing times; the new timing applies from the next
+// assignment.
+func (rm *RunwayManager) SetRunwayTiming(runway string, t RunwayTiming) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	runway = rm.resolveLocked(runway)
+	if _, ok := rm.runways[runway]; !ok {
+		return fmt.Errorf("unknown runway %q", runway)
+	}
+	t.Runway = runway
+	if t.SpacingSeconds == 0 && t.LandingSeconds == 0 && len(t.Categories) == 0 {
+		delete(rm.timing, runway)
+		log.Printf("runway %s timing reset to defaults", runway)
+		return nil
+	}
+	if rm.timing == nil {
+		rm.timing = make(map[string]RunwayTiming)
+	}
+	rm.timing[runway] = t
+	log.Printf("runway %s timing: spacing %s, landing %s, %d category overrides", runway,
+		rm.runwaySpacingLocked(runway, Flight{}), rm.runwayLandingLocked(runway, Flight{}), len(t.Categories))
+	return nil
+}
+
+// RunwayTimings reports every runway's arrival timing in scheduling order,
+// with the defaults filled in for values the runway does not set.
+func (rm *RunwayManager) RunwayTimings() []RunwayTiming {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayTiming, 0, len(rm.order))
+	for _, name := range rm.order {
+		t := rm.timing[name]
+		t.Runway = name
+		t.SpacingSeconds = rm.runwaySpacingLocked(name, Flight{}).Seconds()
+		t.LandingSeconds = rm.runwayLandingLocked(name, Flight{}).Seconds()
+		out = append(out, t)
+	}
+	return out
+}
+
+// runwaySpacingLocked is the base spacing of f behind the arrival ahead of
+// it on runway, before low-visibility procedures or a recovery boost adjust
+// it.
+func (rm *RunwayManager) runwaySpacingLocked(runway string, f Flight) time.Duration {
+	t := rm.timing[runway]
+	if c, ok := t.Categories[f.Weight]; ok && c.SpacingSeconds > 0 {
+		return time.Duration(c.SpacingSeconds * float64(time.Second))
+	}
+	if t.SpacingSeconds > 0 {
+		return time.Duration(t.SpacingSeconds * float64(time.Second))
+	}
+	return minArrivalSpacing
+}
+
+// runwayLandingLocked is the time f takes from sequencing to touchdown on
+// runway, before exits and equipment failures stretch it.
+func (rm *RunwayManager) runwayLandingLocked(runway string, f Flight) time.Duration {
+	t := rm.timing[runway]
+	if c, ok := t.Categories[f.Weight]; ok && c.LandingSeconds > 0 {
+		return time.Duration(c.LandingSeconds * float64(time.Second))
+	}
+	if t.LandingSeconds > 0 {
+		return time.Duration(t.LandingSeconds * float64(time.Second))
+	}
+	return landingDuration
+}
+
+// headwindComponent is the wind along heading in knots, negative for a
+// tailwind. Wind directions are those the wind blows from, in degrees
+// magnetic like heading.
+func headwindComponent(heading float64, wind WindState) float64 {
+	return float64(wind.Speed) * math.Cos(angularDiff(float64(wind.Direction), heading)*math.Pi/180)
+}
+
+// windFactor scales the landing time for a headwind component.
+func windFactor(headwind float64) float64 {
+	factor := 1 - headwind*headwindCredit
+	if headwind < 0 {
+		factor = 1 - headwind*tailwindPenalty
+	}
+	return min(max(factor, minWindFactor), maxWindFactor)
+}
+
+// windFactorLocked scales the landing time on runway for the wind along its
+// active heading.
+func (rm *RunwayManager) windFactorLocked(runway string) float64 {
+	return windFactor(headwindComponent(rm.runways[runway].activeHeading, rm.magneticWindLocked()))
+}
+
+// sequenceSpacingLocked is the spacing of f behind the arrival ahead of it
+// on runway: the runway's spacing scaled as arrivalSpacingLocked scales the
+// default.
+func (rm *RunwayManager) sequenceSpacingLocked(runway string, f Flight) time.Duration {
+	scale := float64(rm.arrivalSpacingLocked()) / float64(minArrivalSpacing)
+	return time.Duration(float64(rm.runwaySpacingLocked(runway, f)) * scale)
+}
+
+// HandleTimings reports every runway's arrival timing.
+func (s *Server) HandleTimings(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.RunwayTimings())
+}
+
+// HandleRunwayTiming replaces (PUT) or resets (DELETE) one runway's arrival
+// timing.
+func (s *Server) HandleRunwayTiming(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var t RunwayTiming
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			http.Error(w, "invalid timing: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := s.Runways.SetRunwayTiming(r.PathValue("runway"), t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.RunwayTimings())
+}
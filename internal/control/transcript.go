@@ -0,0 +1,153 @@
+package control
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Instruction kinds.
+const (
+	InstructionApproach = "approach"
+	InstructionHold     = "hold"
+	InstructionGoAround = "goAround"
+	InstructionHeading  = "heading"
+	InstructionDivert   = "divert"
+)
+
+// Instruction sources: the scheduler acting on its own, or an operator's
+// command such as a runway closure.
+const (
+	SourceScheduler = "scheduler"
+	SourceOperator  = "operator"
+)
+
+// maxTranscript bounds the instructions kept; the oldest go first.
+const maxTranscript = 100000
+
+// Instruction is one instruction issued to a flight, as spoken on the
+// frequency.
+type Instruction struct {
+	// Time is when the instruction went out, after any wait for airtime.
+	Time     time.Time `json:"time"`
+	FlightID int64     `json:"flightId"`
+	Call     string    `json:"call"`
+	Runway   string    `json:"runway,omitempty"`
+	Kind     string    `json:"kind"`
+	Source   string    `json:"source"`
+	// Text is the instruction in controller phraseology, and Readback the
+	// pilot's reply.
+	Text     string `json:"text"`
+	Readback string `json:"readback"`
+	// Reason is why it was issued, where the phraseology does not say.
+	Reason string `json:"reason,omitempty"`
+}
+
+// approachInstructionLocked clears f for the approach to the end of runway
+// in use on heading.
+func (rm *RunwayManager) approachInstructionLocked(f Flight, runway string, heading float64) Instruction {
+	end := rm.activeEndLocked(runway)
+	return Instruction{FlightID: f.ID, Call: f.Call, Runway: runway, Kind: InstructionApproach, Source: SourceScheduler,
+		Text:     fmt.Sprintf("%s, fly heading %03.0f, cleared ILS approach runway %s", f.Call, heading, end),
+		Readback: fmt.Sprintf("heading %03.0f, cleared ILS approach runway %s, %s", heading, end, f.Call)}
+}
+
+// holdInstruction sends f to the holding stack.
+func holdInstruction(f Flight, runway, source, reason string) Instruction {
+	return Instruction{FlightID: f.ID, Call: f.Call, Runway: runway, Kind: InstructionHold, Source: source, Reason: reason,
+		Text:     fmt.Sprintf("%s, hold as published, expect further clearance", f.Call),
+		Readback: fmt.Sprintf("hold as published, %s", f.Call)}
+}
+
+// goAroundInstruction has f abort its landing on runway.
+func goAroundInstruction(f Flight, runway, reason string) Instruction {
+	return Instruction{FlightID: f.ID, Call: f.Call, Runway: runway, Kind: InstructionGoAround, Source: SourceScheduler, Reason: reason,
+		Text:     fmt.Sprintf("%s, go around, I say again, go around", f.Call),
+		Readback: fmt.Sprintf("going around, %s", f.Call)}
+}
+
+// headingInstruction turns f onto heading on its approach to runway.
+func headingInstruction(f Flight, runway string, heading float64) Instruction {
+	return Instruction{FlightID: f.ID, Call: f.Call, Runway: runway, Kind: InstructionHeading, Source: SourceScheduler,
+		Text:     fmt.Sprintf("%s, turn heading %03.0f", f.Call, heading),
+		Readback: fmt.Sprintf("heading %03.0f, %s", heading, f.Call)}
+}
+
+// divertInstruction sends f to its alternate.
+func divertInstruction(f Flight, reason string) Instruction {
+	return Instruction{FlightID: f.ID, Call: f.Call, Kind: InstructionDivert, Source: SourceScheduler, Reason: reason,
+		Text:     fmt.Sprintf("%s, unable to accept you, proceed to your alternate", f.Call),
+		Readback: fmt.Sprintf("proceeding to alternate, %s", f.Call)}
+}
+
+// recordInstructionLocked adds in, spoken at at, to the transcript and
+// publishes it once spoken.
+func (rm *RunwayManager) recordInstructionLocked(in Instruction, at time.Time) {
+	in.Time = at
+	rm.transcript = append(rm.transcript, in)
+	if len(rm.transcript) > maxTranscript {
+		rm.transcript = rm.transcript[len(rm.transcript)-maxTranscript:]
+	}
+	if wait := at.Sub(rm.clock.Now()); wait > 0 {
+		rm.clock.AfterFunc(wait, func() {
+			rm.mu.Lock()
+			defer rm.mu.Unlock()
+			rm.speakLocked(in)
+		})
+		return
+	}
+	rm.speakLocked(in)
+}
+
+// Transcript lists the instructions issued in the order they went out,
+// only those to flight when it is nonzero.
+func (rm *RunwayManager) Transcript(flight int64) []Instruction {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := []Instruction{}
+	for _, in := range rm.transcript {
+		if flight == 0 || in.FlightID == flight {
+			out = append(out, in)
+		}
+	}
+	// Instructions queued for airtime are recorded when issued; order them
+	// by when they are spoken.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// HandleTranscript exports the instruction transcript, for one flight with
+// ?flight=, as JSON or, with ?format=text, one line per instruction.
+func (s *Server) HandleTranscript(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var flight int64
+	if raw := r.URL.Query().Get("flight"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "invalid flight", http.StatusBadRequest)
+			return
+		}
+		flight = id
+	}
+	transcript := s.Runways.Transcript(flight)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, transcript)
+	case "text":
+		var b strings.Builder
+		for _, in := range transcript {
+			fmt.Fprintf(&b, "%s %s\n", in.Time.UTC().Format("15:04:05"), in.Text)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="transcript.txt"`)
+		w.Write([]byte(b.String()))
+	default:
+		http.Error(w, "unknown format "+strconv.Quote(format), http.StatusBadRequest)
+	}
+}
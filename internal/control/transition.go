@@ -0,0 +1,184 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Event types for runway direction changes.
+const (
+	EventRunwayTransition       = "runwayTransition"
+	EventRunwayDirectionChanged = "runwayDirectionChanged"
+)
+
+// directionChangeGap is the least time between the last landing in one
+// direction and the first in the other, long enough for the approach to be
+// reconfigured and opposite-direction traffic to be kept apart.
+const directionChangeGap = 3 * minArrivalSpacing
+
+// runwayTransition is a planned change of a runway's landing direction.
+// Flights in old are already sequenced to land in the old direction, the
+// last of them at lastOld; the first arrival in the new one lands no
+// earlier than firstNew. lost is the landing time given up to the change.
+type runwayTransition struct {
+	from, to float64
+	lastOld  time.Time
+	firstNew time.Time
+	old      map[int64]bool
+	lost     time.Duration
+}
+
+// changeDirectionLocked moves runway's landing direction to heading. With
+// no arrivals queued it changes at once. Otherwise the arrivals sequenced
+// ahead of the first gap in the landing sequence at least
+// directionChangeGap long keep the old direction and the rest use the new
+// one, so the change costs no slots if such a gap exists; if not, the
+// change follows the last queued arrival and the gap is lost.
+func (rm *RunwayManager) changeDirectionLocked(runway string, heading float64) {
+	r := rm.runways[runway]
+	if t, ok := rm.transitions[runway]; ok {
+		if heading == t.to {
+			return
+		}
+		delete(rm.transitions, runway)
+		log.Printf("runway %s direction change to %03.0f° cancelled", runway, t.to)
+	}
+	if heading == r.activeHeading {
+		return
+	}
+	now := rm.clock.Now()
+	ahead, queued, lastOld := rm.directionChangeCutLocked(runway)
+	cut := len(ahead)
+	if cut == 0 {
+		rm.completeDirectionChangeLocked(runway, &runwayTransition{from: r.activeHeading, to: heading, lastOld: now, firstNew: now})
+		return
+	}
+	t := &runwayTransition{from: r.activeHeading, to: heading, lastOld: lastOld, firstNew: lastOld.Add(directionChangeGap), old: make(map[int64]bool, cut)}
+	for _, id := range ahead {
+		t.old[id] = true
+	}
+	if cut == queued {
+		t.lost = max(directionChangeGap-rm.arrivalSpacingLocked(), 0)
+	}
+	rm.transitions[runway] = t
+	rm.publishEventLocked(Event{Type: EventRunwayTransition, Runway: runway, Detail: fmt.Sprintf("%03.0f° to %03.0f° after %d arrivals, %s lost", t.from, t.to, cut, t.lost)})
+	log.Printf("runway %s changing direction to %03.0f° after %d arrivals; first new-direction arrival %s", runway, heading, cut, t.firstNew.Format(time.TimeOnly))
+	// The runway is idle between the two landings; switch half way.
+	rm.clock.AfterFunc(lastOld.Add(directionChangeGap/2).Sub(now), func() {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+		if rm.transitions[runway] == t {
+			delete(rm.transitions, runway)
+			rm.completeDirectionChangeLocked(runway, t)
+		}
+	})
+}
+
+// directionChangeCutLocked finds where a change of runway's direction falls
+// in its landing sequence. ahead are the arrivals that keep the old
+// direction, the last of them landing at lastOld, out of queued sequenced
+// in all.
+func (rm *RunwayManager) directionChangeCutLocked(runway string) (ahead []int64, queued int, lastOld time.Time) {
+	type scheduled struct {
+		id  int64
+		due time.Time
+	}
+	var queue []scheduled
+	for _, f := range rm.assigned[runway] {
+		if due, ok := rm.dueAt[f.ID]; ok {
+			queue = append(queue, scheduled{id: f.ID, due: due})
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool { return queue[i].due.Before(queue[j].due) })
+	lastOld = rm.clock.Now()
+	for _, s := range queue {
+		if s.due.Sub(lastOld) >= directionChangeGap {
+			break
+		}
+		ahead = append(ahead, s.id)
+		lastOld = s.due
+	}
+	return ahead, len(queue), lastOld
+}
+
+func (rm *RunwayManager) completeDirectionChangeLocked(runway string, t *runwayTransition) {
+	rm.runways[runway].activeHeading = t.to
+	if rm.metrics != nil {
+		rm.metrics.RecordRunwayTransition(t.lost)
+	}
+	rm.publishEventLocked(Event{Type: EventRunwayDirectionChanged, Runway: runway, Detail: fmt.Sprintf("heading %03.0f°", t.to)})
+	log.Printf("runway %s now landing on heading %03.0f°", runway, t.to)
+}
+
+// arrivalHeadingLocked is the direction flight id lands on runway: the new
+// one during a direction change unless it is sequenced ahead of it.
+func (rm *RunwayManager) arrivalHeadingLocked(runway string, id int64) float64 {
+	if t, ok := rm.transitions[runway]; ok && !t.old[id] {
+		return t.to
+	}
+	return rm.runways[runway].activeHeading
+}
+
+// transitionSlotLocked holds flight id's landing target back until the
+// runway has changed direction, unless it lands in the old direction.
+func (rm *RunwayManager) transitionSlotLocked(runway string, id int64, target time.Time) time.Time {
+	if t, ok := rm.transitions[runway]; ok && !t.old[id] && target.Before(t.firstNew) {
+		return t.firstNew
+	}
+	return target
+}
+
+// RunwayTransition is a runway direction change in progress.
+type RunwayTransition struct {
+	Runway string  `json:"runway"`
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+	// LastOldArrival is when the last arrival in the old direction lands;
+	// FirstNewArrival the earliest an arrival in the new direction may.
+	LastOldArrival  time.Time `json:"lastOldArrival"`
+	FirstNewArrival time.Time `json:"firstNewArrival"`
+	OldArrivals     int       `json:"oldArrivals"`
+	GapSeconds      float64   `json:"gapSeconds"`
+	// LostSeconds and LostSlots are the landing capacity the change costs
+	// beyond normal spacing; zero when it fits a gap in the sequence.
+	LostSeconds float64 `json:"lostSeconds"`
+	LostSlots   int     `json:"lostSlots"`
+}
+
+// Transitions lists the runway direction changes in progress.
+func (rm *RunwayManager) Transitions() []RunwayTransition {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]RunwayTransition, 0, len(rm.transitions))
+	spacing := rm.arrivalSpacingLocked()
+	for _, name := range rm.order {
+		t, ok := rm.transitions[name]
+		if !ok {
+			continue
+		}
+		out = append(out, RunwayTransition{
+			Runway:          name,
+			From:            t.from,
+			To:              t.to,
+			LastOldArrival:  t.lastOld,
+			FirstNewArrival: t.firstNew,
+			OldArrivals:     len(t.old),
+			GapSeconds:      directionChangeGap.Seconds(),
+			LostSeconds:     t.lost.Seconds(),
+			LostSlots:       int((t.lost + spacing - 1) / spacing),
+		})
+	}
+	return out
+}
+
+// HandleTransitions lists runway direction changes in progress.
+func (s *Server) HandleTransitions(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Transitions())
+}
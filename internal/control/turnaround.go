@@ -0,0 +1,202 @@
+package control
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// EventTurnaround is published as a parked aircraft starts a turnaround step.
+const EventTurnaround = "turnaround"
+
+// Turnaround steps, in the order they are performed.
+const (
+	TurnaroundDeboarding = "deboarding"
+	TurnaroundFueling    = "fueling"
+	TurnaroundBoarding   = "boarding"
+)
+
+// turnaroundSteps plans each step for a medium aircraft; other weight
+// categories scale them by standTimeFactors.
+var turnaroundSteps = []struct {
+	name string
+	base time.Duration
+}{
+	{TurnaroundDeboarding, 50 * time.Second},
+	{TurnaroundFueling, 70 * time.Second},
+	{TurnaroundBoarding, 60 * time.Second},
+}
+
+// turnaround tracks a parked aircraft until it is ready to push back as the
+// outbound leg of the same airframe.
+type turnaround struct {
+	flight Flight
+	// gate is the stand, empty for aircraft parked on the apron.
+	gate      string
+	step      int
+	stepSince time.Time
+	landedAt  time.Time
+	// arrivalDelay is how late the inbound landed against its schedule;
+	// scheduledOff is the outbound's off-block time planned from that
+	// schedule.
+	arrivalDelay time.Duration
+	scheduledOff time.Time
+	ready        bool
+}
+
+// startTurnaroundLocked begins turning f around on gate and returns when it
+// is expected to leave. The outbound is scheduled a full planned turnaround
+// after the inbound's scheduled arrival, so a late arrival only delays the
+// departure by what the turnaround cannot absorb.
+func (rm *RunwayManager) startTurnaroundLocked(f Flight, gate string) time.Time {
+	now := rm.clock.Now()
+	scheduled := scheduledArrival(f)
+	t := &turnaround{
+		flight:       f,
+		gate:         gate,
+		landedAt:     now,
+		arrivalDelay: max(now.Sub(scheduled), 0),
+		scheduledOff: scheduled.Add(rm.standTime(f)),
+	}
+	rm.turnarounds[f.ID] = t
+	rm.recordLegLocked(f)
+	rm.runStepLocked(t)
+	return maxTime(now.Add(rm.standTime(f)), t.scheduledOff)
+}
+
+// runStepLocked starts t's current step and schedules the next one.
+func (rm *RunwayManager) runStepLocked(t *turnaround) {
+	step := turnaroundSteps[t.step]
+	t.stepSince = rm.clock.Now()
+	rm.publishEventLocked(Event{Type: EventTurnaround, FlightID: t.flight.ID, Call: t.flight.Call, Detail: step.name})
+	rm.clock.AfterFunc(rm.stepDuration(t.flight, t.step), func() {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+		t.step++
+		if t.step < len(turnaroundSteps) {
+			rm.runStepLocked(t)
+			return
+		}
+		rm.finishTurnaroundLocked(t)
+	})
+}
+
+// finishTurnaroundLocked pushes a turned-around aircraft back, holding it
+// until its scheduled off-block time when it is ready early.
+func (rm *RunwayManager) finishTurnaroundLocked(t *turnaround) {
+	t.ready = true
+	now := rm.clock.Now()
+	if now.Before(t.scheduledOff) {
+		rm.clock.AfterFunc(t.scheduledOff.Sub(now), func() {
+			rm.mu.Lock()
+			defer rm.mu.Unlock()
+			rm.offBlockLocked(t)
+		})
+		return
+	}
+	rm.offBlockLocked(t)
+}
+
+func (rm *RunwayManager) offBlockLocked(t *turnaround) {
+	delete(rm.turnarounds, t.flight.ID)
+	late := max(rm.clock.Now().Sub(t.scheduledOff), 0)
+	if rm.metrics != nil {
+		rm.metrics.RecordDepartureDelay(late, min(late, t.arrivalDelay))
+	}
+	if a := rm.airframeLocked(t.flight); a != nil {
+		a.lastDelay = late
+	}
+	log.Printf("flight %d (%s) turned around, off block %s late (inbound %s late)", t.flight.ID, t.flight.Call, late.Round(time.Second), t.arrivalDelay.Round(time.Second))
+	if t.gate == "" {
+		rm.departLocked(t.flight, 0)
+		return
+	}
+	rm.vacateStandLocked(t.gate, t.flight.ID)
+}
+
+// standTime is how long f is planned to occupy its stand.
+func (rm *RunwayManager) standTime(f Flight) time.Duration {
+	var total time.Duration
+	for _, step := range turnaroundSteps {
+		total += scaleByWeight(f, step.base)
+	}
+	return total
+}
+
+// stepDuration is how long turnaround step i actually takes for f. Steps run
+// between 80% and 150% of plan; the spread is derived from the flight ID so
+// replays reproduce it.
+func (rm *RunwayManager) stepDuration(f Flight, i int) time.Duration {
+	spread := 0.8 + 0.7*float64((f.ID*7+int64(i)*3)%8)/7
+	return time.Duration(float64(scaleByWeight(f, turnaroundSteps[i].base)) * spread)
+}
+
+// scaleByWeight scales a duration planned for a medium aircraft to f's
+// weight category.
+func scaleByWeight(f Flight, d time.Duration) time.Duration {
+	factor, ok := standTimeFactors[f.Weight]
+	if !ok {
+		factor = 1
+	}
+	return time.Duration(float64(d) * factor)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Turnaround is the progress of one aircraft being turned around.
+type Turnaround struct {
+	FlightID int64  `json:"flightId"`
+	Call     string `json:"call"`
+	// Gate is empty for aircraft parked on the apron.
+	Gate      string    `json:"gate,omitempty"`
+	Step      string    `json:"step"`
+	StepSince time.Time `json:"stepSince"`
+	LandedAt  time.Time `json:"landedAt"`
+	// Ready is set once boarding is complete and the aircraft waits for its
+	// scheduled off-block time.
+	Ready               bool      `json:"ready"`
+	ArrivalDelaySeconds float64   `json:"arrivalDelaySeconds"`
+	ScheduledOff        time.Time `json:"scheduledOffBlock"`
+}
+
+// Turnarounds lists the aircraft being turned around, earliest scheduled
+// off-block first.
+func (rm *RunwayManager) Turnarounds() []Turnaround {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	out := make([]Turnaround, 0, len(rm.turnarounds))
+	for _, t := range rm.turnarounds {
+		step := "ready"
+		if t.step < len(turnaroundSteps) {
+			step = turnaroundSteps[t.step].name
+		}
+		out = append(out, Turnaround{
+			FlightID:            t.flight.ID,
+			Call:                t.flight.Call,
+			Gate:                t.gate,
+			Step:                step,
+			StepSince:           t.stepSince,
+			LandedAt:            t.landedAt,
+			Ready:               t.ready,
+			ArrivalDelaySeconds: t.arrivalDelay.Seconds(),
+			ScheduledOff:        t.scheduledOff,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ScheduledOff.Before(out[j].ScheduledOff) })
+	return out
+}
+
+// HandleTurnarounds serves the aircraft being turned around.
+func (s *Server) HandleTurnarounds(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Turnarounds())
+}
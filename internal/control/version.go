@@ -0,0 +1,101 @@
+package control
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Version returns how many times the operator-controlled state has
+// changed.
+func (rm *RunwayManager) Version() int64 {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.version
+}
+
+// StateVersion identifies the operator-controlled state: the arrival rate,
+// runway closures, wind, visibility and ceiling. It starts at 1 and grows
+// with every change, whoever makes it.
+func (s *Server) StateVersion() int64 {
+	v := 1 + s.Generator.rateChanges.Load()
+	if s.Runways != nil {
+		v += s.Runways.Version()
+	}
+	return v
+}
+
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches reports whether an If-Match header names version.
+func etagMatches(header string, version int64) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == formatETag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionWriter stamps the state version as the ETag of a response when
+// it is written, so a mutation reports the version it produced.
+type versionWriter struct {
+	http.ResponseWriter
+	s       *Server
+	written bool
+}
+
+func (w *versionWriter) WriteHeader(status int) {
+	if !w.written {
+		w.written = true
+		w.Header().Set("ETag", formatETag(w.s.StateVersion()))
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *versionWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// versioned tags responses with the state version and applies mutations
+// carrying an If-Match header only if the state is still at the version it
+// names, answering 412 with the current version otherwise, so two
+// operators editing at once do not silently overwrite each other.
+func (s *Server) versioned(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		match := r.Header.Get("If-Match")
+		if match == "" || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next(&versionWriter{ResponseWriter: w, s: s}, r)
+			return
+		}
+		s.mutations.Lock()
+		defer s.mutations.Unlock()
+		if current := s.StateVersion(); !etagMatches(match, current) {
+			w.Header().Set("ETag", formatETag(current))
+			http.Error(w, fmt.Sprintf("state changed: now at version %d", current), http.StatusPreconditionFailed)
+			return
+		}
+		next(&versionWriter{ResponseWriter: w, s: s}, r)
+	}
+}
+
+// applyAtVersion applies a websocket command only if the state is still at
+// the version it carries, answering with a conflict message otherwise.
+func (s *Server) applyAtVersion(client *wsClient, msg Message) error {
+	s.mutations.Lock()
+	current := s.StateVersion()
+	if current != msg.Version {
+		s.mutations.Unlock()
+		return client.send(Message{Type: "conflict", Version: current})
+	}
+	err := s.applyMessage(client, msg)
+	s.mutations.Unlock()
+	return err
+}
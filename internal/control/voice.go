@@ -0,0 +1,102 @@
+package control
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Event types for instructions as spoken on the frequency: the tower's
+// transmission and the pilot's readback.
+const (
+	EventInstruction = "instruction"
+	EventReadback    = "readback"
+)
+
+// Speaker roles in the voice feed.
+const (
+	SpeakerTower = "tower"
+	SpeakerPilot = "pilot"
+)
+
+// VoiceLine is one transmission for a text-to-speech frontend to voice.
+type VoiceLine struct {
+	Seq      int64     `json:"seq"`
+	Time     time.Time `json:"time"`
+	Speaker  string    `json:"speaker"`
+	FlightID int64     `json:"flightId"`
+	Call     string    `json:"call"`
+	Runway   string    `json:"runway,omitempty"`
+	Text     string    `json:"text"`
+}
+
+// voiceLineOf renders an instruction or readback event as a voice line.
+func voiceLineOf(e Event) (VoiceLine, bool) {
+	speaker := SpeakerTower
+	switch e.Type {
+	case EventInstruction:
+	case EventReadback:
+		speaker = SpeakerPilot
+	default:
+		return VoiceLine{}, false
+	}
+	return VoiceLine{Seq: e.Seq, Time: e.Time, Speaker: speaker, FlightID: e.FlightID, Call: e.Call, Runway: e.Runway, Text: e.Detail}, true
+}
+
+// speakLocked publishes in and its readback as they go out on the
+// frequency.
+func (rm *RunwayManager) speakLocked(in Instruction) {
+	rm.publishEventLocked(Event{Type: EventInstruction, FlightID: in.FlightID, Call: in.Call, Runway: in.Runway, Detail: in.Text})
+	rm.publishEventLocked(Event{Type: EventReadback, FlightID: in.FlightID, Call: in.Call, Runway: in.Runway, Detail: in.Readback})
+}
+
+// HandleVoice streams instructions and readbacks as voice messages over a
+// websocket, for a text-to-speech frontend to voice the tower and pilots.
+// Clients send nothing.
+func (s *Server) HandleVoice(w http.ResponseWriter, r *http.Request) {
+	if s.Events == nil {
+		http.Error(w, "event bus unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if s.clientLimitReached() {
+		http.Error(w, "client limit reached", http.StatusServiceUnavailable)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("voice upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := s.connect(ClientVoice, conn, r)
+	defer s.disconnect(client)
+	events, unsubscribe := s.Events.Subscribe(eventBufferSize)
+	defer unsubscribe()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			line, ok := voiceLineOf(e)
+			if !ok {
+				continue
+			}
+			if err := client.send(Message{Type: "voice", Voice: &line}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Decision kinds recorded in the write-ahead log.
+const (
+	DecisionAssign = "assign"
+	DecisionHold   = "hold"
+	DecisionLand   = "land"
+	DecisionDivert = "divert"
+	// DecisionDefer records an arrival held back by the acceptance gate.
+	DecisionDefer = "defer"
+)
+
+// walCompactSlack is how many superseded entries may accumulate before the
+// log is rewritten with only outstanding decisions.
+const walCompactSlack = 1000
+
+// Decision is a single scheduler decision persisted before it is acted on.
+type Decision struct {
+	Kind   string    `json:"kind"`
+	Flight Flight    `json:"flight"`
+	Runway string    `json:"runway,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// RecoveredState is the outstanding work reconstructed from the log: flights
+// whose landing was in progress, flights that were holding and arrivals
+// deferred by the acceptance gate, At being when each was deferred.
+type RecoveredState struct {
+	InProgress []Decision `json:"inProgress"`
+	Holding    []Flight   `json:"holding"`
+	Deferred   []Decision `json:"deferred"`
+	LastID     int64      `json:"lastId"`
+}
+
+// DecisionLog is a crash-safe append-only log of scheduler decisions. Every
+// append is synced to disk before returning.
+type DecisionLog struct {
+	mu          sync.Mutex
+	path        string
+	file        *os.File
+	outstanding map[int64]Decision
+	entries     int
+	lastID      int64
+}
+
+// OpenDecisionLog replays any existing log at path, compacts it down to the
+// outstanding decisions and opens it for appending.
+func OpenDecisionLog(path string) (*DecisionLog, RecoveredState, error) {
+	l := &DecisionLog{path: path, outstanding: make(map[int64]Decision)}
+	if err := l.replay(); err != nil {
+		return nil, RecoveredState{}, err
+	}
+	if err := l.rewriteLocked(); err != nil {
+		return nil, RecoveredState{}, err
+	}
+
+	state := RecoveredState{InProgress: []Decision{}, Holding: []Flight{}, Deferred: []Decision{}, LastID: l.lastID}
+	for _, d := range l.sortedOutstanding() {
+		switch d.Kind {
+		case DecisionAssign:
+			state.InProgress = append(state.InProgress, d)
+		case DecisionHold:
+			state.Holding = append(state.Holding, d.Flight)
+		case DecisionDefer:
+			state.Deferred = append(state.Deferred, d)
+		}
+	}
+	return l, state, nil
+}
+
+// Append durably records a decision.
+func (l *DecisionLog) Append(d Decision) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.apply(d)
+	l.entries++
+	if l.entries > len(l.outstanding)+walCompactSlack {
+		if err := l.rewriteLocked(); err != nil {
+			log.Printf("decision log compaction: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the log file.
+func (l *DecisionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+func (l *DecisionLog) apply(d Decision) {
+	if d.Flight.ID > l.lastID {
+		l.lastID = d.Flight.ID
+	}
+	if d.Kind == DecisionLand || d.Kind == DecisionDivert {
+		delete(l.outstanding, d.Flight.ID)
+		return
+	}
+	l.outstanding[d.Flight.ID] = d
+}
+
+func (l *DecisionLog) replay() error {
+	file, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open decision log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var d Decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			// A torn final write from a crash; everything before it is intact.
+			log.Printf("decision log: ignoring partial entry: %v", err)
+			break
+		}
+		l.apply(d)
+	}
+	return scanner.Err()
+}
+
+// rewriteLocked atomically replaces the log with only outstanding decisions.
+func (l *DecisionLog) rewriteLocked() error {
+	tmp := l.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create decision log: %w", err)
+	}
+	enc := json.NewEncoder(file)
+	for _, d := range l.sortedOutstanding() {
+		if err := enc.Encode(d); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		file.Close()
+		return err
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	l.entries = len(l.outstanding)
+	return nil
+}
+
+func (l *DecisionLog) sortedOutstanding() []Decision {
+	out := make([]Decision, 0, len(l.outstanding))
+	for _, d := range l.outstanding {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
@@ -0,0 +1,216 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+const (
+	// positionChannelLabel and positionChannelID identify the negotiated
+	// data channel positions are sent on.
+	positionChannelLabel = "positions"
+	positionChannelID    = 0
+	// positionBufferLimit is how many bytes may wait to be sent on a
+	// channel before snapshots are skipped rather than queued behind them.
+	positionBufferLimit = 64 << 10
+	// signalingTimeout bounds ICE candidate gathering for an answer.
+	signalingTimeout = 10 * time.Second
+)
+
+// SessionDescription is a WebRTC offer or answer as browsers serialize
+// RTCSessionDescription.
+type SessionDescription struct {
+	Type string `json:"type"`
+	SDP  string `json:"sdp"`
+}
+
+// PositionChannels is the experimental WebRTC transport for the position
+// stream. Each peer receives a PositionState every positionInterval on an
+// unordered data channel without retransmissions, so a lost or late
+// snapshot is superseded by the next instead of holding it up as it would
+// on the websocket. Clients create the channel on their side, negotiated
+// with label "positions" and ID 0, before making the offer.
+type PositionChannels struct {
+	runways *RunwayManager
+	config  webrtc.Configuration
+
+	mu     sync.Mutex
+	peers  map[*webrtc.PeerConnection]struct{}
+	closed bool
+}
+
+// NewPositionChannels serves the positions of runways' arrivals to WebRTC
+// peers, gathering candidates through the STUN or TURN servers at
+// iceServers. With none, only host candidates are offered, which is enough
+// on a LAN.
+func NewPositionChannels(runways *RunwayManager, iceServers []string) *PositionChannels {
+	p := &PositionChannels{runways: runways, peers: make(map[*webrtc.PeerConnection]struct{})}
+	if len(iceServers) > 0 {
+		p.config.ICEServers = []webrtc.ICEServer{{URLs: iceServers}}
+	}
+	return p
+}
+
+// Peers is the number of peers connected or connecting.
+func (p *PositionChannels) Peers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.peers)
+}
+
+// Answer accepts a peer's offer and returns the answer, with every ICE
+// candidate gathered, once gathering completes or ctx is done.
+func (p *PositionChannels) Answer(ctx context.Context, offer SessionDescription) (SessionDescription, error) {
+	if offer.Type != "offer" {
+		return SessionDescription{}, fmt.Errorf("session description type %q, want offer", offer.Type)
+	}
+	pc, err := webrtc.NewPeerConnection(p.config)
+	if err != nil {
+		return SessionDescription{}, err
+	}
+	if err := p.add(pc); err != nil {
+		pc.Close()
+		return SessionDescription{}, err
+	}
+	answer, err := p.negotiate(ctx, pc, offer)
+	if err != nil {
+		p.remove(pc)
+		return SessionDescription{}, err
+	}
+	return answer, nil
+}
+
+func (p *PositionChannels) negotiate(ctx context.Context, pc *webrtc.PeerConnection, offer SessionDescription) (SessionDescription, error) {
+	negotiated, id := true, uint16(positionChannelID)
+	ordered, retransmits := false, uint16(0)
+	dc, err := pc.CreateDataChannel(positionChannelLabel, &webrtc.DataChannelInit{
+		Negotiated:     &negotiated,
+		ID:             &id,
+		Ordered:        &ordered,
+		MaxRetransmits: &retransmits,
+	})
+	if err != nil {
+		return SessionDescription{}, err
+	}
+	dc.OnOpen(func() { p.send(pc, dc) })
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		switch state {
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateClosed:
+			p.remove(pc)
+		}
+	})
+
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: offer.SDP}); err != nil {
+		return SessionDescription{}, fmt.Errorf("invalid offer: %w", err)
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return SessionDescription{}, err
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		return SessionDescription{}, err
+	}
+	select {
+	case <-gathered:
+	case <-ctx.Done():
+		return SessionDescription{}, fmt.Errorf("gathering ICE candidates: %w", ctx.Err())
+	}
+	local := pc.LocalDescription()
+	return SessionDescription{Type: local.Type.String(), SDP: local.SDP}, nil
+}
+
+// send writes a snapshot to dc every positionInterval until the channel
+// closes. Snapshots are skipped while the previous ones are still
+// buffered, so a slow peer sees fewer updates rather than older ones.
+func (p *PositionChannels) send(pc *webrtc.PeerConnection, dc *webrtc.DataChannel) {
+	ticker := time.NewTicker(positionInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if dc.ReadyState() != webrtc.DataChannelStateOpen {
+			return
+		}
+		if dc.BufferedAmount() > positionBufferLimit {
+			continue
+		}
+		data, err := json.Marshal(p.runways.Positions())
+		if err != nil {
+			log.Printf("encode positions: %v", err)
+			continue
+		}
+		if err := dc.Send(data); err != nil {
+			log.Printf("webrtc positions: %v", err)
+			p.remove(pc)
+			return
+		}
+	}
+}
+
+var errPositionChannelsClosed = errors.New("webrtc transport closed")
+
+func (p *PositionChannels) add(pc *webrtc.PeerConnection) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errPositionChannelsClosed
+	}
+	p.peers[pc] = struct{}{}
+	return nil
+}
+
+// remove forgets pc and closes it.
+func (p *PositionChannels) remove(pc *webrtc.PeerConnection) {
+	p.mu.Lock()
+	_, ok := p.peers[pc]
+	delete(p.peers, pc)
+	p.mu.Unlock()
+	if ok {
+		pc.Close()
+	}
+}
+
+// Close disconnects every peer and refuses new ones.
+func (p *PositionChannels) Close() {
+	p.mu.Lock()
+	p.closed = true
+	peers := p.peers
+	p.peers = make(map[*webrtc.PeerConnection]struct{})
+	p.mu.Unlock()
+	for pc := range peers {
+		pc.Close()
+	}
+}
+
+// HandlePositionOffer answers a WebRTC offer for the position data
+// channel.
+func (s *Server) HandlePositionOffer(w http.ResponseWriter, r *http.Request) {
+	if s.Positions == nil {
+		http.Error(w, "webrtc transport disabled", http.StatusServiceUnavailable)
+		return
+	}
+	var offer SessionDescription
+	if err := json.NewDecoder(r.Body).Decode(&offer); err != nil {
+		http.Error(w, "invalid offer: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), signalingTimeout)
+	defer cancel()
+	answer, err := s.Positions.Answer(ctx, offer)
+	switch {
+	case errors.Is(err, errPositionChannelsClosed):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, answer)
+}
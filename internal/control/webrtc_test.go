@@ -0,0 +1,98 @@
+package control
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// TestPositionChannel negotiates the position data channel through the
+// offer endpoint, as a browser would, and reads a snapshot from it.
+func TestPositionChannel(t *testing.T) {
+	runways := []RunwayDefinition{{Name: "09L", Heading: 90}, {Name: "09R", Heading: 90}}
+	rm := NewRunwayManager(runways, NewSchedulerMetrics([]string{"09L", "09R"}), NewEventBus())
+	rm.Arrive(Flight{ID: 1, Call: "TST0001", CreatedAt: time.Now(), Weight: WeightMedium})
+	s := NewServer(nil, rm, nil, nil)
+	s.Positions = NewPositionChannels(rm, nil)
+	defer s.Positions.Close()
+
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	negotiated, id := true, uint16(positionChannelID)
+	ordered, retransmits := false, uint16(0)
+	dc, err := pc.CreateDataChannel(positionChannelLabel, &webrtc.DataChannelInit{Negotiated: &negotiated, ID: &id, Ordered: &ordered, MaxRetransmits: &retransmits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots := make(chan []byte, 16)
+	dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+		select {
+		case snapshots <- msg.Data:
+		default:
+		}
+	})
+
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(offer); err != nil {
+		t.Fatal(err)
+	}
+	<-gathered
+	body, _ := json.Marshal(SessionDescription{Type: "offer", SDP: pc.LocalDescription().SDP})
+	rec := httptest.NewRecorder()
+	s.HandlePositionOffer(rec, httptest.NewRequest(http.MethodPost, "/api/v1/positions/webrtc", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("offer: %d %s", rec.Code, rec.Body)
+	}
+	var answer SessionDescription
+	if err := json.NewDecoder(rec.Body).Decode(&answer); err != nil {
+		t.Fatal(err)
+	}
+	if answer.Type != "answer" {
+		t.Fatalf("answer type %q", answer.Type)
+	}
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeAnswer, SDP: answer.SDP}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-snapshots:
+		var state PositionState
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatalf("decode snapshot %s: %v", data, err)
+		}
+		if len(state.Aircraft) != 1 || state.Aircraft[0].FlightID != 1 {
+			t.Fatalf("snapshot aircraft %+v, want flight 1", state.Aircraft)
+		}
+		if d := state.Aircraft[0].Distance; d <= 0 || d > intermediateNM {
+			t.Errorf("flight 1 %.3f NM out, want on the approach", d)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no position snapshot received")
+	}
+	if n := s.Positions.Peers(); n != 1 {
+		t.Errorf("%d peers, want 1", n)
+	}
+}
+
+func TestPositionOfferRejectsAnswer(t *testing.T) {
+	s := NewServer(nil, nil, nil, nil)
+	s.Positions = NewPositionChannels(nil, nil)
+	body, _ := json.Marshal(SessionDescription{Type: "answer", SDP: "v=0"})
+	rec := httptest.NewRecorder()
+	s.HandlePositionOffer(rec, httptest.NewRequest(http.MethodPost, "/api/v1/positions/webrtc", bytes.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,214 @@
+package control
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// EventWildlifeDispersal is published when a runway closes briefly so
+// wildlife can be dispersed.
+const EventWildlifeDispersal = "wildlifeDispersal"
+
+// Wildlife hazard levels.
+const (
+	WildlifeNone     = "none"
+	WildlifeLow      = "low"
+	WildlifeModerate = "moderate"
+	WildlifeHigh     = "high"
+)
+
+var wildlifeLevels = []string{WildlifeNone, WildlifeLow, WildlifeModerate, WildlifeHigh}
+
+// wildlifeGoArounds is the chance an arrival goes around for wildlife on
+// final at each hazard level.
+var wildlifeGoArounds = map[string]float64{
+	WildlifeNone:     0,
+	WildlifeLow:      0.01,
+	WildlifeModerate: 0.04,
+	WildlifeHigh:     0.12,
+}
+
+const (
+	// dispersalDuration is how long a precautionary closure for wildlife
+	// dispersal lasts.
+	dispersalDuration = 30 * time.Second
+	// dispersalInterval is the least time between dispersals on a runway.
+	dispersalInterval = 5 * time.Minute
+)
+
+// WildlifeHazard sets the bird activity by hour of the day: Hours[h] is the
+// level from h:00, with hours beyond the list at none. Override, when set,
+// applies regardless of the hour, as when a flock is reported. At the high
+// level a wildlife go-around closes the runway briefly for dispersal.
+type WildlifeHazard struct {
+	Hours    []string `json:"hours"`
+	Override string   `json:"override,omitempty"`
+}
+
+// summary describes w as its override level, or as set by hour.
+func (w WildlifeHazard) summary() string {
+	if w.Override != "" {
+		return w.Override
+	}
+	return "set by hour"
+}
+
+// validateWildlife checks w names only known levels for at most a day.
+func validateWildlife(w WildlifeHazard) error {
+	if len(w.Hours) > 24 {
+		return fmt.Errorf("at most 24 hourly levels, got %d", len(w.Hours))
+	}
+	for _, level := range append(slices.Clone(w.Hours), w.Override) {
+		if level != "" && !slices.Contains(wildlifeLevels, level) {
+			return fmt.Errorf("unknown wildlife hazard level %q", level)
+		}
+	}
+	return nil
+}
+
+// SetWildlifeHazard replaces the wildlife hazard levels.
+func (rm *RunwayManager) SetWildlifeHazard(w WildlifeHazard) error {
+	if err := validateWildlife(w); err != nil {
+		return err
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.setWildlifeLocked(w)
+	return nil
+}
+
+func (rm *RunwayManager) setWildlifeLocked(w WildlifeHazard) {
+	rm.wildlife = w
+	log.Printf("wildlife hazard now %s", rm.wildlifeLevelLocked())
+}
+
+// wildlifeLevelLocked is the hazard level at the current hour.
+func (rm *RunwayManager) wildlifeLevelLocked() string {
+	if rm.wildlife.Override != "" {
+		return rm.wildlife.Override
+	}
+	if h := rm.clock.Now().Hour(); h < len(rm.wildlife.Hours) && rm.wildlife.Hours[h] != "" {
+		return rm.wildlife.Hours[h]
+	}
+	return WildlifeNone
+}
+
+// wildlifeGoAroundLocked decides whether f, due to touch down at due, goes
+// around for wildlife. The draw is derived from the flight and its landing
+// time so runs are repeatable and a second approach draws afresh.
+func (rm *RunwayManager) wildlifeGoAroundLocked(f Flight, due time.Time) bool {
+	chance := wildlifeGoArounds[rm.wildlifeLevelLocked()]
+	if chance == 0 {
+		return false
+	}
+	h := fnv.New64a()
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(f.ID))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(due.UnixNano()))
+	h.Write(buf[:])
+	return float64(h.Sum64()%10000)/10000 < chance
+}
+
+// dispersalDueLocked reports whether a wildlife go-around on runway calls
+// for a precautionary closure, and reserves it if so.
+func (rm *RunwayManager) dispersalDueLocked(runway string) bool {
+	if rm.wildlifeLevelLocked() != WildlifeHigh || !rm.runways[runway].available() {
+		return false
+	}
+	now := rm.clock.Now()
+	if last, ok := rm.dispersals[runway]; ok && now.Sub(last) < dispersalInterval {
+		return false
+	}
+	rm.dispersals[runway] = now
+	return true
+}
+
+// DisperseWildlife closes runway for wildlife dispersal now, as when a flock
+// is reported on it, whatever the hazard level.
+func (rm *RunwayManager) DisperseWildlife(runway string) error {
+	rm.mu.Lock()
+	runway = rm.resolveLocked(runway)
+	r, ok := rm.runways[runway]
+	if !ok {
+		rm.mu.Unlock()
+		return fmt.Errorf("unknown runway %q", runway)
+	}
+	if !r.available() {
+		rm.mu.Unlock()
+		return fmt.Errorf("runway %s is not available", runway)
+	}
+	rm.dispersals[runway] = rm.clock.Now()
+	rm.mu.Unlock()
+	rm.disperseWildlife(runway)
+	return nil
+}
+
+// dispersingLocked lists the runways closed for wildlife dispersal now.
+func (rm *RunwayManager) dispersingLocked() []string {
+	now := rm.clock.Now()
+	var out []string
+	for _, name := range rm.order {
+		if at, ok := rm.dispersals[name]; ok && now.Sub(at) < dispersalDuration && !rm.runways[name].open {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// disperseWildlife closes runway for dispersalDuration.
+func (rm *RunwayManager) disperseWildlife(runway string) {
+	rm.mu.Lock()
+	rm.publishEventLocked(Event{Type: EventWildlifeDispersal, Runway: runway, Detail: fmt.Sprintf("closed for %s", dispersalDuration)})
+	rm.mu.Unlock()
+	log.Printf("runway %s closing for wildlife dispersal", runway)
+	rm.SetRunwayClosed(runway, true)
+	rm.clock.AfterFunc(dispersalDuration, func() { rm.SetRunwayClosed(runway, false) })
+}
+
+// WildlifeState is the wildlife hazard configuration, the level now and the
+// last dispersal on each runway.
+type WildlifeState struct {
+	Hazard     WildlifeHazard       `json:"hazard"`
+	Level      string               `json:"level"`
+	Dispersals map[string]time.Time `json:"dispersals"`
+}
+
+// Wildlife reports the wildlife hazard.
+func (rm *RunwayManager) Wildlife() WildlifeState {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	state := WildlifeState{Hazard: rm.wildlife, Level: rm.wildlifeLevelLocked(), Dispersals: make(map[string]time.Time, len(rm.dispersals))}
+	if state.Hazard.Hours == nil {
+		state.Hazard.Hours = []string{}
+	}
+	for runway, at := range rm.dispersals {
+		state.Dispersals[runway] = at
+	}
+	return state
+}
+
+// HandleWildlife reports (GET) or replaces (PUT) the wildlife hazard.
+func (s *Server) HandleWildlife(w http.ResponseWriter, r *http.Request) {
+	if s.Runways == nil {
+		http.Error(w, "scheduler unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var req WildlifeHazard
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid wildlife hazard: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Runways.SetWildlifeHazard(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.Runways.Wildlife())
+}
@@ -0,0 +1,89 @@
+package control
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// WindVariation is the range a variable wind's direction swings across,
+// clockwise From To, as 240V300 in a METAR.
+type WindVariation struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// contains reports whether direction dir, in degrees, lies within v.
+func (v WindVariation) contains(dir float64) bool {
+	return normalizeHeading(dir-float64(v.From)) <= normalizeHeading(float64(v.To-v.From))
+}
+
+// normalizeWind clamps w to a valid wind: speeds are not negative, gusts
+// not below the steady speed are dropped, and a variation that does not
+// vary is too.
+func normalizeWind(w WindState) WindState {
+	out := WindState{Speed: maxInt64(w.Speed, 0), Direction: normalizeDirection(w.Direction)}
+	if w.Gust > out.Speed {
+		out.Gust = w.Gust
+	}
+	if v := w.Variable; v != nil && normalizeDirection(v.From) != normalizeDirection(v.To) {
+		out.Variable = &WindVariation{From: normalizeDirection(v.From), To: normalizeDirection(v.To)}
+	}
+	return out
+}
+
+// windDetail describes w for the wind changed event, as
+// "12kt from 270 gusting 25kt varying 240V300".
+func windDetail(w WindState) string {
+	detail := fmt.Sprintf("%dkt from %03d", w.Speed, w.Direction)
+	if w.Gust > 0 {
+		detail += fmt.Sprintf(" gusting %dkt", w.Gust)
+	}
+	if v := w.Variable; v != nil {
+		detail += fmt.Sprintf(" varying %03dV%03d", v.From, v.To)
+	}
+	return detail
+}
+
+// parseWindDetail reads a wind back from its event detail.
+func parseWindDetail(detail string) (WindState, error) {
+	var w WindState
+	if _, err := fmt.Sscanf(detail, "%dkt from %d", &w.Speed, &w.Direction); err != nil {
+		return WindState{}, err
+	}
+	if _, gust, ok := strings.Cut(detail, " gusting "); ok {
+		if _, err := fmt.Sscanf(gust, "%dkt", &w.Gust); err != nil {
+			return WindState{}, err
+		}
+	}
+	if _, varying, ok := strings.Cut(detail, " varying "); ok {
+		var v WindVariation
+		if _, err := fmt.Sscanf(varying, "%dV%d", &v.From, &v.To); err != nil {
+			return WindState{}, err
+		}
+		w.Variable = &v
+	}
+	return w, nil
+}
+
+// worstHeadwind is the least headwind, negative for a tailwind, in knots
+// that wind can give arrivals landing on heading. The direction is taken at
+// its worst within any variable range; tailwinds count at gust strength,
+// headwinds only at the steady speed.
+func worstHeadwind(heading float64, wind WindState) float64 {
+	component := func(dir float64) float64 {
+		along := math.Cos(angularDiff(dir, heading) * math.Pi / 180)
+		if along < 0 {
+			return float64(max(wind.Gust, wind.Speed)) * along
+		}
+		return float64(wind.Speed) * along
+	}
+	worst := component(float64(wind.Direction))
+	if v := wind.Variable; v != nil {
+		if v.contains(normalizeHeading(heading + 180)) {
+			return component(normalizeHeading(heading + 180))
+		}
+		worst = min(worst, component(float64(v.From)), component(float64(v.To)))
+	}
+	return worst
+}
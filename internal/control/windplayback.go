@@ -0,0 +1,188 @@
+package control
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WindObservation is the wind reported at Time.
+type WindObservation struct {
+	Time time.Time `json:"time"`
+	Wind WindState `json:"wind"`
+}
+
+// windColumns are the CSV columns a wind series may carry; time, speed and
+// direction are required.
+var windColumns = []string{"time", "speed", "direction", "gust", "variableFrom", "variableTo"}
+
+// ReadWindObservations parses a wind series from CSV: a header naming the
+// columns, then one observation per row with its time in RFC 3339 and
+// speeds in knots. Empty gust and variable cells leave the wind steady.
+// Observations are returned in time order.
+func ReadWindObservations(r io.Reader) ([]WindObservation, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		known := false
+		for _, column := range windColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		index[name] = i
+	}
+	for _, column := range windColumns[:3] {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("missing column %q", column)
+		}
+	}
+	var observations []WindObservation
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		cell := func(column string) string {
+			if i, ok := index[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(column string) (int64, error) {
+			n, err := strconv.ParseInt(cell(column), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid %s %q", line, column, cell(column))
+			}
+			return n, nil
+		}
+		var o WindObservation
+		if o.Time, err = time.Parse(time.RFC3339, cell("time")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid time %q", line, cell("time"))
+		}
+		if o.Wind.Speed, err = number("speed"); err != nil {
+			return nil, err
+		}
+		if o.Wind.Direction, err = number("direction"); err != nil {
+			return nil, err
+		}
+		if cell("gust") != "" {
+			if o.Wind.Gust, err = number("gust"); err != nil {
+				return nil, err
+			}
+		}
+		if cell("variableFrom") != "" || cell("variableTo") != "" {
+			var v WindVariation
+			if v.From, err = number("variableFrom"); err != nil {
+				return nil, err
+			}
+			if v.To, err = number("variableTo"); err != nil {
+				return nil, err
+			}
+			o.Wind.Variable = &v
+		}
+		if o.Wind.Speed < 0 || o.Wind.Gust < 0 {
+			return nil, fmt.Errorf("line %d: negative wind speed", line)
+		}
+		observations = append(observations, o)
+	}
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("no observations")
+	}
+	sort.SliceStable(observations, func(i, j int) bool { return observations[i].Time.Before(observations[j].Time) })
+	return observations, nil
+}
+
+// WindPlayback is a WeatherSource replaying a historical wind series: the
+// first observation is applied when it starts running and each later one
+// as much simulation time after as separated it from the first. Looping,
+// the series starts over, one average observation interval after its
+// last, for as long as the simulation runs.
+type WindPlayback struct {
+	observations []WindObservation
+	clock        Clock
+	loop         bool
+}
+
+// NewWindPlayback replays observations on clock, starting over after the
+// last if loop is set.
+func NewWindPlayback(observations []WindObservation, clock Clock, loop bool) *WindPlayback {
+	return &WindPlayback{observations: observations, clock: clock, loop: loop}
+}
+
+// LoadWindPlayback reads a CSV wind series from path for replay on clock.
+func LoadWindPlayback(path string, clock Clock, loop bool) (*WindPlayback, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	observations, err := ReadWindObservations(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewWindPlayback(observations, clock, loop), nil
+}
+
+// span is the time the series covers.
+func (p *WindPlayback) span() time.Duration {
+	return p.observations[len(p.observations)-1].Time.Sub(p.observations[0].Time)
+}
+
+// Run implements WeatherSource.
+func (p *WindPlayback) Run(ctx context.Context, apply func(WindState)) {
+	n := len(p.observations)
+	if n == 0 {
+		return
+	}
+	cycle := time.Duration(0)
+	if n > 1 {
+		cycle = p.span() + p.span()/time.Duration(n-1)
+	}
+	log.Printf("wind playback: %d observations over %s", n, p.span())
+	start, first := p.clock.Now(), p.observations[0].Time
+	for lap := 0; ; lap++ {
+		for _, o := range p.observations {
+			if !p.waitUntil(ctx, start.Add(time.Duration(lap)*cycle+o.Time.Sub(first))) {
+				return
+			}
+			apply(o.Wind)
+		}
+		if !p.loop || cycle <= 0 {
+			log.Printf("wind playback complete")
+			return
+		}
+	}
+}
+
+// waitUntil blocks until simulation time at, reporting false if ctx is
+// canceled first.
+func (p *WindPlayback) waitUntil(ctx context.Context, at time.Time) bool {
+	due := make(chan struct{}, 1)
+	stop := p.clock.AfterFunc(at.Sub(p.clock.Now()), func() { due <- struct{}{} })
+	select {
+	case <-ctx.Done():
+		stop()
+		return false
+	case <-due:
+		return true
+	}
+}
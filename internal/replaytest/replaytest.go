@@ -0,0 +1,278 @@
+// Package replaytest replays recorded sessions through the scheduler on a
+// virtual clock and asserts on the metrics they end with, so a regression
+// seen in a real session can be kept as a fixture and checked on every
+// change.
+//
+// A fixture is a JSON file naming a recording made with -record-dir, the
+// simulation it replays into and the metrics expected at the end:
+//
+//	{
+//	  "name": "closure-recovery",
+//	  "recording": "closure-recovery.jsonl",
+//	  "runways": [{"name": "2L", "heading": 20}, {"name": "2R", "heading": 20}],
+//	  "rate": 30,
+//	  "expect": {
+//	    "totalArrivals": {"min": 100},
+//	    "holdingCurrent": {"max": 0},
+//	    "eta.p90AbsErrorSeconds": {"max": 60}
+//	  }
+//	}
+//
+// The rate, runway and wind changes of the recording are replayed at their
+// recorded offsets. Metrics are named by their JSON paths in the metrics
+// snapshot, nested fields joined with dots, as in "queueLengths.2L" or
+// "flightPhases.landed".
+package replaytest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"aircommand/internal/control"
+)
+
+// Fixture is a recorded session to replay and the metrics it must end with.
+type Fixture struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Recording is the session file, relative to the fixture.
+	Recording string                     `json:"recording"`
+	Runways   []control.RunwayDefinition `json:"runways"`
+	Rate      int64                      `json:"rate"`
+	Wind      control.WindState          `json:"wind"`
+	// DurationSeconds is the virtual time simulated; zero runs to the last
+	// recorded event.
+	DurationSeconds int64            `json:"durationSeconds,omitempty"`
+	Expect          map[string]Bound `json:"expect"`
+
+	path string
+}
+
+// Bound constrains one metric. Equals takes precedence over Min and Max.
+type Bound struct {
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Equals *float64 `json:"equals,omitempty"`
+}
+
+func (b Bound) check(v float64) error {
+	switch {
+	case b.Equals != nil && v != *b.Equals:
+		return fmt.Errorf("got %g, want %g", v, *b.Equals)
+	case b.Equals == nil && b.Min != nil && v < *b.Min:
+		return fmt.Errorf("got %g, want at least %g", v, *b.Min)
+	case b.Equals == nil && b.Max != nil && v > *b.Max:
+		return fmt.Errorf("got %g, want at most %g", v, *b.Max)
+	}
+	return nil
+}
+
+// Result is the outcome of replaying a fixture.
+type Result struct {
+	Metrics control.MetricsSnapshot
+	// Values holds every numeric metric by its dotted JSON path.
+	Values map[string]float64
+	Events []control.Event
+	// Failures lists the expectations not met, in metric order.
+	Failures []Failure
+}
+
+// Failure is an expectation a replay did not meet.
+type Failure struct {
+	Metric string
+	Err    error
+}
+
+func (f Failure) Error() string {
+	return f.Metric + ": " + f.Err.Error()
+}
+
+// Load reads the fixture at path.
+func Load(path string) (Fixture, error) {
+	var f Fixture
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Name == "" {
+		f.Name = filepath.Base(path)
+	}
+	if f.Recording == "" {
+		return f, fmt.Errorf("%s: recording missing", path)
+	}
+	if len(f.Runways) == 0 {
+		return f, fmt.Errorf("%s: runways missing", path)
+	}
+	f.path = path
+	return f, nil
+}
+
+// LoadDir reads every *.json fixture in dir, in name order.
+func LoadDir(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		f, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// recordingPath resolves the recording against the fixture's directory.
+func (f Fixture) recordingPath() string {
+	if filepath.IsAbs(f.Recording) || f.path == "" {
+		return f.Recording
+	}
+	return filepath.Join(filepath.Dir(f.path), f.Recording)
+}
+
+// Run replays the fixture and checks its expectations. The error reports a
+// replay that could not run; unmet expectations are in the result.
+func (f Fixture) Run() (Result, error) {
+	var res Result
+	recording := f.recordingPath()
+	inputs, err := control.InputsFromRecording(recording)
+	if err != nil {
+		return res, fmt.Errorf("recording: %w", err)
+	}
+	duration := time.Duration(f.DurationSeconds) * time.Second
+	if duration <= 0 {
+		if duration, err = recordingLength(recording); err != nil {
+			return res, fmt.Errorf("recording: %w", err)
+		}
+	}
+	check := control.DeterminismCheck{
+		Engine:   control.EngineConfig{Runways: f.Runways, Rate: f.Rate, Wind: f.Wind},
+		Duration: duration,
+		Inputs:   inputs,
+	}
+	engine, events, err := check.Simulate()
+	if err != nil {
+		return res, err
+	}
+	res.Metrics = engine.Metrics.Snapshot()
+	res.Events = events
+	if res.Values, err = flatten(res.Metrics); err != nil {
+		return res, err
+	}
+	metrics := make([]string, 0, len(f.Expect))
+	for name := range f.Expect {
+		metrics = append(metrics, name)
+	}
+	sort.Strings(metrics)
+	for _, name := range metrics {
+		v, ok := res.Values[name]
+		if !ok {
+			res.Failures = append(res.Failures, Failure{Metric: name, Err: fmt.Errorf("no such metric")})
+			continue
+		}
+		if err := f.Expect[name].check(v); err != nil {
+			res.Failures = append(res.Failures, Failure{Metric: name, Err: err})
+		}
+	}
+	return res, nil
+}
+
+// Check replays the fixture at path in a test, reporting each unmet
+// expectation as an error.
+func Check(tb testing.TB, path string) Result {
+	tb.Helper()
+	f, err := Load(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	res, err := f.Run()
+	if err != nil {
+		tb.Fatalf("%s: %v", f.Name, err)
+	}
+	for _, failure := range res.Failures {
+		tb.Errorf("%s: %v", f.Name, failure)
+	}
+	return res
+}
+
+// recordingLength returns the offset of the last frame of a recording.
+func recordingLength(path string) (time.Duration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var last int64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var frame control.RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return 0, err
+		}
+		last = max(last, frame.OffsetMillis)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if last == 0 {
+		return 0, fmt.Errorf("%s is empty", path)
+	}
+	return time.Duration(last) * time.Millisecond, nil
+}
+
+// flatten maps every number in the JSON form of v by its dotted path.
+func flatten(v any) (map[string]float64, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64)
+	var walk func(prefix string, node any)
+	walk = func(prefix string, node any) {
+		join := func(key string) string {
+			if prefix == "" {
+				return key
+			}
+			return prefix + "." + key
+		}
+		switch n := node.(type) {
+		case float64:
+			if !math.IsNaN(n) {
+				out[prefix] = n
+			}
+		case bool:
+			out[prefix] = 0
+			if n {
+				out[prefix] = 1
+			}
+		case map[string]any:
+			for key, child := range n {
+				walk(join(key), child)
+			}
+		case []any:
+			for i, child := range n {
+				walk(join(strconv.Itoa(i)), child)
+			}
+		}
+	}
+	walk("", tree)
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package replaytest
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFixtures replays every fixture under testdata.
+func TestFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixtures in testdata")
+	}
+	// The scheduler logs every decision; keep failures readable.
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			res := Check(t, path)
+			if len(res.Events) == 0 {
+				t.Error("replay produced no events")
+			}
+		})
+	}
+}
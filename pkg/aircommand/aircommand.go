@@ -0,0 +1,327 @@
+// Package aircommand is the public, importable surface of the AirCommand
+// simulator. It re-exports the engine components and event types from the
+// internal control package and provides a typed client for a running server.
+package aircommand
+
+//go:generate go run ../../cmd/apigen -root ../..
+
+import (
+	"time"
+
+	"aircommand/internal/control"
+)
+
+// Simulation components.
+type (
+	Generator        = control.Generator
+	RunwayManager    = control.RunwayManager
+	RunwayDefinition = control.RunwayDefinition
+	SchedulerMetrics = control.SchedulerMetrics
+	MetricsSnapshot  = control.MetricsSnapshot
+	EventBus         = control.EventBus
+	Server           = control.Server
+	Engine           = control.Engine
+	EngineConfig     = control.EngineConfig
+	Clock            = control.Clock
+	RealClock        = control.RealClock
+	VirtualClock     = control.VirtualClock
+	SimClock         = control.SimClock
+)
+
+// Data types exchanged with the simulator.
+type (
+	Flight                = control.Flight
+	WindState             = control.WindState
+	WindVariation         = control.WindVariation
+	WindObservation       = control.WindObservation
+	WindPlayback          = control.WindPlayback
+	Event                 = control.Event
+	Message               = control.Message
+	Decision              = control.Decision
+	RecoveredState        = control.RecoveredState
+	HourlyAggregate       = control.HourlyAggregate
+	FlightHistory         = control.FlightHistory
+	FlightSearchResult    = control.FlightSearchResult
+	DailyReport           = control.DailyReport
+	RunwayClosure         = control.RunwayClosure
+	AnalyticsResult       = control.AnalyticsResult
+	AnalyticsRow          = control.AnalyticsRow
+	Scenario              = control.Scenario
+	ScenarioStep          = control.ScenarioStep
+	ScenarioRun           = control.ScenarioRun
+	ScenarioParam         = control.ScenarioParam
+	ScenarioStartRequest  = control.ScenarioStartRequest
+	Checkpoint            = control.Checkpoint
+	CheckpointRequest     = control.CheckpointRequest
+	BranchResult          = control.BranchResult
+	TenantConfig          = control.TenantConfig
+	TenantInfo            = control.TenantInfo
+	TenantQuota           = control.TenantQuota
+	TenantUsage           = control.TenantUsage
+	TenantTemplate        = control.TenantTemplate
+	TenantTemplateView    = control.TenantTemplateView
+	TelemetryPacket       = control.TelemetryPacket
+	TelemetryRunway       = control.TelemetryRunway
+	MQTTArrival           = control.MQTTArrival
+	ReplicaStatus         = control.ReplicaStatus
+	Backpressure          = control.Backpressure
+	FlightPhase           = control.FlightPhase
+	ETAAccuracy           = control.ETAAccuracy
+	ETABucket             = control.ETABucket
+	ChaosConfig           = control.ChaosConfig
+	ChaosStatus           = control.ChaosStatus
+	RunwayPreference      = control.RunwayPreference
+	RunwayTiming          = control.RunwayTiming
+	HoldingFlight         = control.HoldingFlight
+	Conflict              = control.Conflict
+	LogEvent              = control.LogEvent
+	FlightIdentity        = control.FlightIdentity
+	AcceptanceGate        = control.AcceptanceGate
+	AcceptanceStatus      = control.AcceptanceStatus
+	PilotLatency          = control.PilotLatency
+	Frequency             = control.Frequency
+	FrequencyStatus       = control.FrequencyStatus
+	Instruction           = control.Instruction
+	VoiceLine             = control.VoiceLine
+	LatLon                = control.LatLon
+	RunwayThresholds      = control.RunwayThresholds
+	MapTiles              = control.MapTiles
+	Geography             = control.Geography
+	RunwayPlacement       = control.RunwayPlacement
+	GeographyState        = control.GeographyState
+	HoldingPattern        = control.HoldingPattern
+	HoldingOrbit          = control.HoldingOrbit
+	HoldingPatternStatus  = control.HoldingPatternStatus
+	ArrivalTiming         = control.ArrivalTiming
+	Rule                  = control.Rule
+	Condition             = control.Condition
+	Action                = control.Action
+	RuleFiring            = control.RuleFiring
+	GroundMovement        = control.GroundMovement
+	GroundMovementRequest = control.GroundMovementRequest
+	Incident              = control.Incident
+	IncidentRequest       = control.IncidentRequest
+	Timeline              = control.Timeline
+	RunwayTimeline        = control.RunwayTimeline
+	TimelineEntry         = control.TimelineEntry
+	DelayReport           = control.DelayReport
+	DelayCause            = control.DelayCause
+	EmissionsReport       = control.EmissionsReport
+	FlightEmissions       = control.FlightEmissions
+	NoiseReport           = control.NoiseReport
+	NoiseSector           = control.NoiseSector
+	RunwayUsage           = control.RunwayUsage
+	InvoiceReport         = control.InvoiceReport
+	Invoice               = control.Invoice
+	Charge                = control.Charge
+	Gate                  = control.Gate
+	GateReport            = control.GateReport
+	GateStatus            = control.GateStatus
+	GateOccupant          = control.GateOccupant
+	Turnaround            = control.Turnaround
+	AirframeStatus        = control.AirframeStatus
+	DepartureState        = control.DepartureState
+	TaxiingFlight         = control.TaxiingFlight
+	HoldingPointQueue     = control.HoldingPointQueue
+	DepartingFlight       = control.DepartingFlight
+	WinterOps             = control.WinterOps
+	WinterOpsState        = control.WinterOpsState
+	VisibilityState       = control.VisibilityState
+	RunwayExit            = control.RunwayExit
+	RunwayExits           = control.RunwayExits
+	RunwayShortening      = control.RunwayShortening
+	ParallelApproaches    = control.ParallelApproaches
+	AirlineQuota          = control.AirlineQuota
+	QuotaConfig           = control.QuotaConfig
+	QuotaAdherence        = control.QuotaAdherence
+	QuotaReport           = control.QuotaReport
+	AirlineAgent          = control.AirlineAgent
+	AgentStatus           = control.AgentStatus
+	AuctionReport         = control.AuctionReport
+	AuctionRound          = control.AuctionRound
+	SlotAward             = control.SlotAward
+	Bid                   = control.Bid
+	RecoveryPlan          = control.RecoveryPlan
+	RecoveryFlight        = control.RecoveryFlight
+	FrictionTests         = control.FrictionTests
+	FrictionRunway        = control.FrictionRunway
+	FrictionState         = control.FrictionState
+	WildlifeHazard        = control.WildlifeHazard
+	WildlifeState         = control.WildlifeState
+	ATIS                  = control.ATIS
+	WildlifeEvent         = control.WildlifeEvent
+	Atmosphere            = control.Atmosphere
+	AtmosphereState       = control.AtmosphereState
+	Point                 = control.Point
+	StormCell             = control.StormCell
+	StormState            = control.StormState
+	ApproachPath          = control.ApproachPath
+	AircraftPosition      = control.AircraftPosition
+	PositionState         = control.PositionState
+	SessionDescription    = control.SessionDescription
+	LVPState              = control.LVPState
+	RunwayTransition      = control.RunwayTransition
+	CustomMetric          = control.CustomMetric
+	Anomaly               = control.Anomaly
+	SLA                   = control.SLA
+	SLAStatus             = control.SLAStatus
+	ClientStats           = control.ClientStats
+	Preview               = control.Preview
+	ConfirmationPolicy    = control.ConfirmationPolicy
+	PendingAction         = control.PendingAction
+	Command               = control.Command
+	ScheduledCommand      = control.ScheduledCommand
+	BatchRequest          = control.BatchRequest
+	BatchResult           = control.BatchResult
+	CommandResult         = control.CommandResult
+	StateSnapshot         = control.StateSnapshot
+	RunwaySnapshot        = control.RunwaySnapshot
+	QueuedArrival         = control.QueuedArrival
+	RateRamp              = control.RateRamp
+	BurstResult           = control.BurstResult
+	FlightTimer           = control.FlightTimer
+	FlightTimers          = control.FlightTimers
+	ClockInfo             = control.ClockInfo
+)
+
+// Extension points.
+type (
+	Registry           = control.Registry
+	FlightSpawner      = control.FlightSpawner
+	AssignmentStrategy = control.AssignmentStrategy
+	RunwayCandidate    = control.RunwayCandidate
+	EventSink          = control.EventSink
+	WeatherSource      = control.WeatherSource
+	ClearingMechanism  = control.ClearingMechanism
+)
+
+// Event types.
+const (
+	EventSpawned                = control.EventSpawned
+	EventAssigned               = control.EventAssigned
+	EventHolding                = control.EventHolding
+	EventLanded                 = control.EventLanded
+	EventConflict               = control.EventConflict
+	EventRunwayClosed           = control.EventRunwayClosed
+	EventRunwayOpened           = control.EventRunwayOpened
+	EventWindChanged            = control.EventWindChanged
+	EventRateChanged            = control.EventRateChanged
+	EventIncursion              = control.EventIncursion
+	EventIncursionResolved      = control.EventIncursionResolved
+	EventGoAround               = control.EventGoAround
+	EventDiverted               = control.EventDiverted
+	EventIncident               = control.EventIncident
+	EventIncidentResolved       = control.EventIncidentResolved
+	EventVisibilityChanged      = control.EventVisibilityChanged
+	EventParked                 = control.EventParked
+	EventGateConflict           = control.EventGateConflict
+	EventTowed                  = control.EventTowed
+	EventPushback               = control.EventPushback
+	EventTurnaround             = control.EventTurnaround
+	EventRunwayShortened        = control.EventRunwayShortened
+	EventRunwayRestored         = control.EventRunwayRestored
+	EventTakeoff                = control.EventTakeoff
+	EventDeiced                 = control.EventDeiced
+	EventRecoveryProposed       = control.EventRecoveryProposed
+	EventRecoveryApplied        = control.EventRecoveryApplied
+	EventFrictionTest           = control.EventFrictionTest
+	EventWildlifeDispersal      = control.EventWildlifeDispersal
+	EventAtmosphereChanged      = control.EventAtmosphereChanged
+	EventStormBlocked           = control.EventStormBlocked
+	EventStormCleared           = control.EventStormCleared
+	EventLVPEntered             = control.EventLVPEntered
+	EventLVPExited              = control.EventLVPExited
+	EventRunwayTransition       = control.EventRunwayTransition
+	EventRunwayDirectionChanged = control.EventRunwayDirectionChanged
+	EventAnomaly                = control.EventAnomaly
+	EventSLABreached            = control.EventSLABreached
+	EventSLARestored            = control.EventSLARestored
+	EventConfirmationRequired   = control.EventConfirmationRequired
+	EventActionConfirmed        = control.EventActionConfirmed
+	EventActionRejected         = control.EventActionRejected
+	EventConfirmationExpired    = control.EventConfirmationExpired
+	EventCommandScheduled       = control.EventCommandScheduled
+	EventCommandExecuted        = control.EventCommandExecuted
+	EventCommandCancelled       = control.EventCommandCancelled
+	EventBurst                  = control.EventBurst
+	EventDailyReport            = control.EventDailyReport
+	EventScenarioStarted        = control.EventScenarioStarted
+	EventBranched               = control.EventBranched
+	EventPhaseChanged           = control.EventPhaseChanged
+	EventRunwayAdded            = control.EventRunwayAdded
+	EventRunwayRemoved          = control.EventRunwayRemoved
+	EventFlowRestricted         = control.EventFlowRestricted
+	EventFlowRestored           = control.EventFlowRestored
+	EventInstruction            = control.EventInstruction
+	EventReadback               = control.EventReadback
+)
+
+// Flight lifecycle phases.
+const (
+	PhaseSpawned   = control.PhaseSpawned
+	PhaseSequenced = control.PhaseSequenced
+	PhaseHolding   = control.PhaseHolding
+	PhaseFinal     = control.PhaseFinal
+	PhaseLanded    = control.PhaseLanded
+	PhaseDiverted  = control.PhaseDiverted
+	PhaseCancelled = control.PhaseCancelled
+)
+
+// Runway preferences.
+const (
+	PreferencePreferred  = control.PreferencePreferred
+	PreferenceSecondary  = control.PreferenceSecondary
+	PreferenceLastResort = control.PreferenceLastResort
+)
+
+// Acceptance gate modes.
+const (
+	AcceptanceDefer  = control.AcceptanceDefer
+	AcceptanceRefuse = control.AcceptanceRefuse
+)
+
+// NewEngine builds an in-process simulation driven by a virtual clock.
+func NewEngine(cfg EngineConfig) *Engine {
+	return control.NewEngine(cfg)
+}
+
+// NewVirtualClock returns a clock that only moves when advanced.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return control.NewVirtualClock(start)
+}
+
+// NewSimClock returns a clock running from epoch at speed simulated seconds
+// per real second.
+func NewSimClock(epoch time.Time, speed float64) *SimClock {
+	return control.NewSimClock(epoch, speed)
+}
+
+// NewGenerator constructs a flight generator producing ratePerMinute arrivals.
+func NewGenerator(ratePerMinute int64) *Generator {
+	return control.NewGenerator(ratePerMinute)
+}
+
+// NewSchedulerMetrics builds a metrics collector for the supplied runways.
+func NewSchedulerMetrics(runways []string) *SchedulerMetrics {
+	return control.NewSchedulerMetrics(runways)
+}
+
+// NewEventBus constructs an empty event bus.
+func NewEventBus() *EventBus {
+	return control.NewEventBus()
+}
+
+// NewRunwayManager constructs a scheduler for the supplied runways.
+func NewRunwayManager(runways []RunwayDefinition, metrics *SchedulerMetrics, events *EventBus) *RunwayManager {
+	return control.NewRunwayManager(runways, metrics, events)
+}
+
+// NewServer exposes the simulator over HTTP and websockets.
+func NewServer(gen *Generator, runways *RunwayManager, metrics *SchedulerMetrics, events *EventBus) *Server {
+	return control.NewServer(gen, runways, metrics, events)
+}
+
+// NewRegistry returns an extension registry with the built-in strategies.
+func NewRegistry() *Registry {
+	return control.NewRegistry()
+}
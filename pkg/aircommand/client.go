@@ -0,0 +1,145 @@
+package aircommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client talks to a running AirCommand server.
+type Client struct {
+	// BaseURL is the server root, e.g. http://localhost:8080.
+	BaseURL string
+	// HTTP is the client used for REST calls; http.DefaultClient when nil.
+	HTTP *http.Client
+	// Token, when set, authenticates the client as a controller under the
+	// two-person confirmation policy.
+	Token string
+}
+
+// NewClient returns a client for the server at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// call performs a REST request, sending body as JSON when non-nil and
+// decoding the response into out when non-nil. The generated methods in
+// client_gen.go are built on it.
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, payload)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Stream is a live control channel to the server. Reads must come from a
+// single goroutine; sends are safe for concurrent use.
+type Stream struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// Dial opens the control websocket.
+func (c *Client) Dial(ctx context.Context) (*Stream, error) {
+	wsURL := c.BaseURL + "/control"
+	switch {
+	case strings.HasPrefix(wsURL, "https://"):
+		wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+	case strings.HasPrefix(wsURL, "http://"):
+		wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+	}
+	var header http.Header
+	if c.Token != "" {
+		header = http.Header{"Authorization": {"Bearer " + c.Token}}
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", wsURL, err)
+	}
+	return &Stream{conn: conn}, nil
+}
+
+// Next blocks until the next message from the server arrives.
+func (s *Stream) Next() (Message, error) {
+	var msg Message
+	err := s.conn.ReadJSON(&msg)
+	return msg, err
+}
+
+// SetRate requests a new arrival rate.
+func (s *Stream) SetRate(rate int64) error {
+	return s.send(Message{Type: "rate", Rate: rate})
+}
+
+// SetRunwayClosed opens or closes a runway.
+func (s *Stream) SetRunwayClosed(runway string, closed bool) error {
+	return s.send(Message{Type: "runway", Runway: runway, Closed: closed})
+}
+
+// SetWind updates the wind.
+func (s *Stream) SetWind(speed, direction int64) error {
+	return s.send(Message{Type: "wind", Wind: &WindState{Speed: speed, Direction: direction}})
+}
+
+// SetWindState updates the wind, including gusts and a variable direction.
+func (s *Stream) SetWindState(w WindState) error {
+	return s.send(Message{Type: "wind", Wind: &w})
+}
+
+// Close closes the underlying connection.
+func (s *Stream) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Stream) send(msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(msg)
+}
@@ -0,0 +1,1117 @@
+// Code generated by cmd/apigen. DO NOT EDIT.
+
+package aircommand
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// GetAcceptance calls GET /api/v1/acceptance. The arrival acceptance gate and the demand it defers.
+func (c *Client) GetAcceptance(ctx context.Context) (AcceptanceStatus, error) {
+	var out AcceptanceStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/acceptance", query, nil, &out)
+	return out, err
+}
+
+// SetAcceptance calls PUT /api/v1/acceptance. Replace the arrival acceptance gate; a zero threshold disables it.
+func (c *Client) SetAcceptance(ctx context.Context, body AcceptanceGate) (AcceptanceStatus, error) {
+	var out AcceptanceStatus
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/acceptance", query, body, &out)
+	return out, err
+}
+
+// GetBackpressure calls GET /api/v1/admin/backpressure. Flights spawned on time versus held back because the scheduler stalled, by cause.
+func (c *Client) GetBackpressure(ctx context.Context) (Backpressure, error) {
+	var out Backpressure
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/admin/backpressure", query, nil, &out)
+	return out, err
+}
+
+// StopChaos calls DELETE /api/v1/admin/chaos. Stop injecting faults.
+func (c *Client) StopChaos(ctx context.Context) (ChaosStatus, error) {
+	var out ChaosStatus
+	query := url.Values{}
+	err := c.call(ctx, "DELETE", "/api/v1/admin/chaos", query, nil, &out)
+	return out, err
+}
+
+// GetChaos calls GET /api/v1/admin/chaos. Chaos mode: the faults configured, those injected and any scheduler invariants broken.
+func (c *Client) GetChaos(ctx context.Context) (ChaosStatus, error) {
+	var out ChaosStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/admin/chaos", query, nil, &out)
+	return out, err
+}
+
+// SetChaos calls PUT /api/v1/admin/chaos. Configure the faults chaos mode injects.
+func (c *Client) SetChaos(ctx context.Context, body ChaosConfig) (ChaosStatus, error) {
+	var out ChaosStatus
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/admin/chaos", query, body, &out)
+	return out, err
+}
+
+// ListClients calls GET /api/v1/admin/clients. Connected websocket clients with message rates, queue depth and dropped frames.
+func (c *Client) ListClients(ctx context.Context) ([]ClientStats, error) {
+	var out []ClientStats
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/admin/clients", query, nil, &out)
+	return out, err
+}
+
+// GetAirframes calls GET /api/v1/airframes. Airframes and the legs they have flown, most legs first.
+func (c *Client) GetAirframes(ctx context.Context) ([]AirframeStatus, error) {
+	var out []AirframeStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/airframes", query, nil, &out)
+	return out, err
+}
+
+// GetTimeline calls GET /api/v1/aman. Arrival manager landing ladder per runway.
+func (c *Client) GetTimeline(ctx context.Context) (Timeline, error) {
+	var out Timeline
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/aman", query, nil, &out)
+	return out, err
+}
+
+// GetAnalyticsParams holds the optional parameters of GetAnalytics.
+type GetAnalyticsParams struct {
+	// wait (default), holding, holdingCount, goArounds, diverted or flights.
+	Metric string
+	// Comma-separated dimensions: runway, hour, day, outcome.
+	Groupby string
+	// Earliest event time.
+	From time.Time
+	// Latest event time.
+	To time.Time
+}
+
+// GetAnalytics calls GET /api/v1/analytics. A metric of archived flights aggregated by group, as tidy rows.
+func (c *Client) GetAnalytics(ctx context.Context, params GetAnalyticsParams) (AnalyticsResult, error) {
+	var out AnalyticsResult
+	query := url.Values{}
+	if params.Metric != "" {
+		query.Set("metric", params.Metric)
+	}
+	if params.Groupby != "" {
+		query.Set("groupby", params.Groupby)
+	}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.Format(time.RFC3339))
+	}
+	err := c.call(ctx, "GET", "/api/v1/analytics", query, nil, &out)
+	return out, err
+}
+
+// ListAnomalies calls GET /api/v1/anomalies. Recent metric anomalies.
+func (c *Client) ListAnomalies(ctx context.Context) ([]Anomaly, error) {
+	var out []Anomaly
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/anomalies", query, nil, &out)
+	return out, err
+}
+
+// GetAtis calls GET /api/v1/atis. The current ATIS information: runways in use, weather, bird activity and wildlife dispersal closures.
+func (c *Client) GetAtis(ctx context.Context) (ATIS, error) {
+	var out ATIS
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/atis", query, nil, &out)
+	return out, err
+}
+
+// GetAtmosphere calls GET /api/v1/atmosphere. Temperature, pressure, density altitude and the runways each weight category can use.
+func (c *Client) GetAtmosphere(ctx context.Context) (AtmosphereState, error) {
+	var out AtmosphereState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/atmosphere", query, nil, &out)
+	return out, err
+}
+
+// SetAtmosphere calls PUT /api/v1/atmosphere. Set the temperature, altimeter setting and field elevation.
+func (c *Client) SetAtmosphere(ctx context.Context, body Atmosphere) (AtmosphereState, error) {
+	var out AtmosphereState
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/atmosphere", query, body, &out)
+	return out, err
+}
+
+// GetAuction calls GET /api/v1/auction. Slot auction agents and recent clearing rounds.
+func (c *Client) GetAuction(ctx context.Context) (AuctionReport, error) {
+	var out AuctionReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/auction", query, nil, &out)
+	return out, err
+}
+
+// SetAuctionAgents calls PUT /api/v1/auction. Replace the airline agents bidding in the slot auction.
+func (c *Client) SetAuctionAgents(ctx context.Context, body []AirlineAgent) (AuctionReport, error) {
+	var out AuctionReport
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/auction", query, body, &out)
+	return out, err
+}
+
+// ApplyBatchParams holds the optional parameters of ApplyBatch.
+type ApplyBatchParams struct {
+	// Validate and return the predicted effect as a Preview without applying the change.
+	DryRun bool
+}
+
+// ApplyBatch calls POST /api/v1/batch. Apply rate, runway, wind, visibility and burst commands together, all or nothing.
+func (c *Client) ApplyBatch(ctx context.Context, body BatchRequest, params ApplyBatchParams) (BatchResult, error) {
+	var out BatchResult
+	query := url.Values{}
+	if params.DryRun {
+		query.Set("dryRun", strconv.FormatBool(params.DryRun))
+	}
+	err := c.call(ctx, "POST", "/api/v1/batch", query, body, &out)
+	return out, err
+}
+
+// SpawnBurstParams holds the optional parameters of SpawnBurst.
+type SpawnBurstParams struct {
+	// Validate and return the predicted effect as a Preview without applying the change.
+	DryRun bool
+}
+
+// SpawnBurst calls POST /api/v1/burst. Spawn count flights at once, routed through normal runway assignment.
+func (c *Client) SpawnBurst(ctx context.Context, count int64, params SpawnBurstParams) (BurstResult, error) {
+	var out BurstResult
+	query := url.Values{}
+	query.Set("count", strconv.FormatInt(count, 10))
+	if params.DryRun {
+		query.Set("dryRun", strconv.FormatBool(params.DryRun))
+	}
+	err := c.call(ctx, "POST", "/api/v1/burst", query, nil, &out)
+	return out, err
+}
+
+// ListCheckpoints calls GET /api/v1/checkpoints. Named checkpoints of the live run, oldest first.
+func (c *Client) ListCheckpoints(ctx context.Context) ([]Checkpoint, error) {
+	var out []Checkpoint
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/checkpoints", query, nil, &out)
+	return out, err
+}
+
+// TakeCheckpoint calls POST /api/v1/checkpoints. Checkpoint the live run under a name.
+func (c *Client) TakeCheckpoint(ctx context.Context, body CheckpointRequest) (Checkpoint, error) {
+	var out Checkpoint
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/checkpoints", query, body, &out)
+	return out, err
+}
+
+// DeleteCheckpoint calls DELETE /api/v1/checkpoints/{name}. Delete a checkpoint.
+func (c *Client) DeleteCheckpoint(ctx context.Context, name string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/checkpoints/"+url.PathEscape(name), query, nil, nil)
+}
+
+// GetCheckpoint calls GET /api/v1/checkpoints/{name}. A checkpoint's conditions and arrivals.
+func (c *Client) GetCheckpoint(ctx context.Context, name string) (Checkpoint, error) {
+	var out Checkpoint
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/checkpoints/"+url.PathEscape(name), query, nil, &out)
+	return out, err
+}
+
+// BranchFromCheckpoint calls POST /api/v1/checkpoints/{name}/branch. Replace the current arrivals and conditions with a checkpoint's and continue from there.
+func (c *Client) BranchFromCheckpoint(ctx context.Context, name string) (BranchResult, error) {
+	var out BranchResult
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/checkpoints/"+url.PathEscape(name)+"/branch", query, nil, &out)
+	return out, err
+}
+
+// ListScheduledCommands calls GET /api/v1/commands. Commands queued to run at a later simulation time, soonest first.
+func (c *Client) ListScheduledCommands(ctx context.Context) ([]ScheduledCommand, error) {
+	var out []ScheduledCommand
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/commands", query, nil, &out)
+	return out, err
+}
+
+// ScheduleCommand calls POST /api/v1/commands. Queue a rate, runway, wind, visibility or burst command to run at executeAt.
+func (c *Client) ScheduleCommand(ctx context.Context, body ScheduledCommand) (ScheduledCommand, error) {
+	var out ScheduledCommand
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/commands", query, body, &out)
+	return out, err
+}
+
+// CancelScheduledCommand calls DELETE /api/v1/commands/{id}. Cancel a scheduled command.
+func (c *Client) CancelScheduledCommand(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/commands/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ListConfirmations calls GET /api/v1/confirmations. High-impact actions awaiting a second controller.
+func (c *Client) ListConfirmations(ctx context.Context) ([]PendingAction, error) {
+	var out []PendingAction
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/confirmations", query, nil, &out)
+	return out, err
+}
+
+// RejectAction calls DELETE /api/v1/confirmations/{id}. Reject a pending action.
+func (c *Client) RejectAction(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/confirmations/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ConfirmAction calls POST /api/v1/confirmations/{id}. Confirm and apply a pending action as a second controller.
+func (c *Client) ConfirmAction(ctx context.Context, id string) (PendingAction, error) {
+	var out PendingAction
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/confirmations/"+url.PathEscape(id), query, nil, &out)
+	return out, err
+}
+
+// ListConflictsParams holds the optional parameters of ListConflicts.
+type ListConflictsParams struct {
+	Limit int64
+}
+
+// ListConflicts calls GET /api/v1/conflicts. Recent spacing conflicts with the flights involved.
+func (c *Client) ListConflicts(ctx context.Context, params ListConflictsParams) ([]Conflict, error) {
+	var out []Conflict
+	query := url.Values{}
+	if params.Limit != 0 {
+		query.Set("limit", strconv.FormatInt(params.Limit, 10))
+	}
+	err := c.call(ctx, "GET", "/api/v1/conflicts", query, nil, &out)
+	return out, err
+}
+
+// GetDelayReport calls GET /api/v1/delays. Delay attributed to each cause.
+func (c *Client) GetDelayReport(ctx context.Context) (DelayReport, error) {
+	var out DelayReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/delays", query, nil, &out)
+	return out, err
+}
+
+// GetDepartures calls GET /api/v1/departures. Aircraft taxiing out and queued at runway holding points.
+func (c *Client) GetDepartures(ctx context.Context) (DepartureState, error) {
+	var out DepartureState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/departures", query, nil, &out)
+	return out, err
+}
+
+// GetEmissionsReport calls GET /api/v1/emissions. Fuel burn and CO2 from holding and vectoring.
+func (c *Client) GetEmissionsReport(ctx context.Context) (EmissionsReport, error) {
+	var out EmissionsReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/emissions", query, nil, &out)
+	return out, err
+}
+
+// GetExits calls GET /api/v1/exits. Runway exits with the occupancy and capacity they allow.
+func (c *Client) GetExits(ctx context.Context) ([]RunwayExits, error) {
+	var out []RunwayExits
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/exits", query, nil, &out)
+	return out, err
+}
+
+// SetExits calls PUT /api/v1/exits/{runway}. Replace a runway's exits.
+func (c *Client) SetExits(ctx context.Context, runway string, body []RunwayExit) ([]RunwayExits, error) {
+	var out []RunwayExits
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/exits/"+url.PathEscape(runway), query, body, &out)
+	return out, err
+}
+
+// GetFrequency calls GET /api/v1/frequency. The radio frequency's instruction throughput and load.
+func (c *Client) GetFrequency(ctx context.Context) (FrequencyStatus, error) {
+	var out FrequencyStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/frequency", query, nil, &out)
+	return out, err
+}
+
+// SetFrequency calls PUT /api/v1/frequency. Limit the instructions the frequency carries per minute; zero removes the limit.
+func (c *Client) SetFrequency(ctx context.Context, body Frequency) (FrequencyStatus, error) {
+	var out FrequencyStatus
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/frequency", query, body, &out)
+	return out, err
+}
+
+// GetFrictionTests calls GET /api/v1/friction. Friction test schedule, reserved gaps and latest readings.
+func (c *Client) GetFrictionTests(ctx context.Context) (FrictionState, error) {
+	var out FrictionState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/friction", query, nil, &out)
+	return out, err
+}
+
+// SetFrictionTests calls PUT /api/v1/friction. Schedule periodic friction tests slotted into gaps in the landing sequence.
+func (c *Client) SetFrictionTests(ctx context.Context, body FrictionTests) (FrictionState, error) {
+	var out FrictionState
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/friction", query, body, &out)
+	return out, err
+}
+
+// GetGates calls GET /api/v1/gates. Stand occupancy and utilization.
+func (c *Client) GetGates(ctx context.Context) (GateReport, error) {
+	var out GateReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/gates", query, nil, &out)
+	return out, err
+}
+
+// GetGeography calls GET /api/v1/geography. Airport reference point, magnetic variation, map tiles and runway threshold positions, with each runway's bearing and length.
+func (c *Client) GetGeography(ctx context.Context) (GeographyState, error) {
+	var out GeographyState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/geography", query, nil, &out)
+	return out, err
+}
+
+// ListGroundMovements calls GET /api/v1/ground. Vehicles and crossing aircraft occupying runways.
+func (c *Client) ListGroundMovements(ctx context.Context) ([]GroundMovement, error) {
+	var out []GroundMovement
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/ground", query, nil, &out)
+	return out, err
+}
+
+// AddGroundMovement calls POST /api/v1/ground. Place ground traffic on a runway.
+func (c *Client) AddGroundMovement(ctx context.Context, body GroundMovementRequest) (GroundMovement, error) {
+	var out GroundMovement
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/ground", query, body, &out)
+	return out, err
+}
+
+// ClearGroundMovement calls DELETE /api/v1/ground/{id}. Vacate a runway.
+func (c *Client) ClearGroundMovement(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/ground/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ListHistoryParams holds the optional parameters of ListHistory.
+type ListHistoryParams struct {
+	// Flight ID.
+	Flight int64
+	// Event type.
+	Type   string
+	Runway string
+	Limit  int64
+	// Earliest event time.
+	From time.Time
+	// Latest event time.
+	To time.Time
+}
+
+// ListHistory calls GET /api/v1/history. Archived events.
+func (c *Client) ListHistory(ctx context.Context, params ListHistoryParams) ([]Event, error) {
+	var out []Event
+	query := url.Values{}
+	if params.Flight != 0 {
+		query.Set("flight", strconv.FormatInt(params.Flight, 10))
+	}
+	if params.Type != "" {
+		query.Set("type", params.Type)
+	}
+	if params.Runway != "" {
+		query.Set("runway", params.Runway)
+	}
+	if params.Limit != 0 {
+		query.Set("limit", strconv.FormatInt(params.Limit, 10))
+	}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.Format(time.RFC3339))
+	}
+	err := c.call(ctx, "GET", "/api/v1/history", query, nil, &out)
+	return out, err
+}
+
+// SearchFlightHistoryParams holds the optional parameters of SearchFlightHistory.
+type SearchFlightHistoryParams struct {
+	// Callsign pattern; * and ? are wildcards.
+	Callsign string
+	// Maximum number of flights.
+	Limit int64
+	// Earliest event time.
+	From time.Time
+	// Latest event time.
+	To time.Time
+}
+
+// SearchFlightHistory calls GET /api/v1/history/flights. Archived flights matching a callsign pattern, with summary statistics.
+func (c *Client) SearchFlightHistory(ctx context.Context, params SearchFlightHistoryParams) (FlightSearchResult, error) {
+	var out FlightSearchResult
+	query := url.Values{}
+	if params.Callsign != "" {
+		query.Set("callsign", params.Callsign)
+	}
+	if params.Limit != 0 {
+		query.Set("limit", strconv.FormatInt(params.Limit, 10))
+	}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.Format(time.RFC3339))
+	}
+	err := c.call(ctx, "GET", "/api/v1/history/flights", query, nil, &out)
+	return out, err
+}
+
+// ListHourlyHistoryParams holds the optional parameters of ListHourlyHistory.
+type ListHourlyHistoryParams struct {
+	// Earliest event time.
+	From time.Time
+	// Latest event time.
+	To time.Time
+}
+
+// ListHourlyHistory calls GET /api/v1/history/hourly. Compacted hourly event counts.
+func (c *Client) ListHourlyHistory(ctx context.Context, params ListHourlyHistoryParams) ([]HourlyAggregate, error) {
+	var out []HourlyAggregate
+	query := url.Values{}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.Format(time.RFC3339))
+	}
+	err := c.call(ctx, "GET", "/api/v1/history/hourly", query, nil, &out)
+	return out, err
+}
+
+// GetHoldingPattern calls GET /api/v1/holding-pattern. The holding pattern, each orbit's time and fuel, and the holding flights' progress around it.
+func (c *Client) GetHoldingPattern(ctx context.Context) (HoldingPatternStatus, error) {
+	var out HoldingPatternStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/holding-pattern", query, nil, &out)
+	return out, err
+}
+
+// SetHoldingPattern calls PUT /api/v1/holding-pattern. Set the holding pattern's leg time and turn rate.
+func (c *Client) SetHoldingPattern(ctx context.Context, body HoldingPattern) (HoldingPatternStatus, error) {
+	var out HoldingPatternStatus
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/holding-pattern", query, body, &out)
+	return out, err
+}
+
+// ListIncidents calls GET /api/v1/incidents. Active equipment failures.
+func (c *Client) ListIncidents(ctx context.Context) ([]Incident, error) {
+	var out []Incident
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/incidents", query, nil, &out)
+	return out, err
+}
+
+// InjectIncident calls POST /api/v1/incidents. Inject an equipment failure on a runway.
+func (c *Client) InjectIncident(ctx context.Context, body IncidentRequest) (Incident, error) {
+	var out Incident
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/incidents", query, body, &out)
+	return out, err
+}
+
+// ResolveIncident calls DELETE /api/v1/incidents/{id}. Repair an equipment failure immediately.
+func (c *Client) ResolveIncident(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/incidents/"+url.PathEscape(id), query, nil, nil)
+}
+
+// GetInvoices calls GET /api/v1/invoices. Landing fees billed per airline.
+func (c *Client) GetInvoices(ctx context.Context) (InvoiceReport, error) {
+	var out InvoiceReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/invoices", query, nil, &out)
+	return out, err
+}
+
+// GetLVP calls GET /api/v1/lvp. Low-visibility procedures and time spent in them.
+func (c *Client) GetLVP(ctx context.Context) (LVPState, error) {
+	var out LVPState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/lvp", query, nil, &out)
+	return out, err
+}
+
+// ListCustomMetrics calls GET /api/v1/metrics/custom. Operator-defined counters and gauges.
+func (c *Client) ListCustomMetrics(ctx context.Context) ([]CustomMetric, error) {
+	var out []CustomMetric
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/metrics/custom", query, nil, &out)
+	return out, err
+}
+
+// CreateCustomMetric calls POST /api/v1/metrics/custom. Add or replace a custom metric.
+func (c *Client) CreateCustomMetric(ctx context.Context, body CustomMetric) (CustomMetric, error) {
+	var out CustomMetric
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/metrics/custom", query, body, &out)
+	return out, err
+}
+
+// DeleteCustomMetric calls DELETE /api/v1/metrics/custom/{name}. Remove a custom metric.
+func (c *Client) DeleteCustomMetric(ctx context.Context, name string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/metrics/custom/"+url.PathEscape(name), query, nil, nil)
+}
+
+// GetNoiseReport calls GET /api/v1/noise. Noise exposure per compass sector from runway usage so far.
+func (c *Client) GetNoiseReport(ctx context.Context) (NoiseReport, error) {
+	var out NoiseReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/noise", query, nil, &out)
+	return out, err
+}
+
+// EstimateNoise calls POST /api/v1/noise. Noise exposure for a hypothetical runway usage.
+func (c *Client) EstimateNoise(ctx context.Context, body []RunwayUsage) (NoiseReport, error) {
+	var out NoiseReport
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/noise", query, body, &out)
+	return out, err
+}
+
+// GetOpenAPI calls GET /api/v1/openapi.json. OpenAPI description of this API.
+func (c *Client) GetOpenAPI(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/openapi.json", query, nil, &out)
+	return out, err
+}
+
+// GetParallelApproaches calls GET /api/v1/parallel. Parallel approach mode and the runways it couples.
+func (c *Client) GetParallelApproaches(ctx context.Context) (ParallelApproaches, error) {
+	var out ParallelApproaches
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/parallel", query, nil, &out)
+	return out, err
+}
+
+// SetParallelApproaches calls PUT /api/v1/parallel. Select independent, dependent or single-stream parallel approaches.
+func (c *Client) SetParallelApproaches(ctx context.Context, body ParallelApproaches) (ParallelApproaches, error) {
+	var out ParallelApproaches
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/parallel", query, body, &out)
+	return out, err
+}
+
+// GetPilotLatency calls GET /api/v1/pilot-latency. How long pilots take to act on vectors and go-around instructions.
+func (c *Client) GetPilotLatency(ctx context.Context) (PilotLatency, error) {
+	var out PilotLatency
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/pilot-latency", query, nil, &out)
+	return out, err
+}
+
+// SetPilotLatency calls PUT /api/v1/pilot-latency. Replace the pilot response delays.
+func (c *Client) SetPilotLatency(ctx context.Context, body PilotLatency) (PilotLatency, error) {
+	var out PilotLatency
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/pilot-latency", query, body, &out)
+	return out, err
+}
+
+// GetPositions calls GET /api/v1/positions. Sequenced arrivals' positions on their approaches.
+func (c *Client) GetPositions(ctx context.Context) (PositionState, error) {
+	var out PositionState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/positions", query, nil, &out)
+	return out, err
+}
+
+// AnswerPositionOffer calls POST /api/v1/positions/webrtc. Answer a WebRTC offer for the experimental unordered, unreliable position data channel, negotiated as "positions" with ID 0.
+func (c *Client) AnswerPositionOffer(ctx context.Context, body SessionDescription) (SessionDescription, error) {
+	var out SessionDescription
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/positions/webrtc", query, body, &out)
+	return out, err
+}
+
+// GetPreferences calls GET /api/v1/preferences. How favored each runway is for arrivals.
+func (c *Client) GetPreferences(ctx context.Context) ([]RunwayPreference, error) {
+	var out []RunwayPreference
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/preferences", query, nil, &out)
+	return out, err
+}
+
+// SetPreference calls PUT /api/v1/preferences/{runway}. Make a runway preferred, secondary or last-resort.
+func (c *Client) SetPreference(ctx context.Context, runway string, body RunwayPreference) ([]RunwayPreference, error) {
+	var out []RunwayPreference
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/preferences/"+url.PathEscape(runway), query, body, &out)
+	return out, err
+}
+
+// GetQuotaReport calls GET /api/v1/quotas. Share of peak arrival slots each airline received against its quota.
+func (c *Client) GetQuotaReport(ctx context.Context) (QuotaReport, error) {
+	var out QuotaReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/quotas", query, nil, &out)
+	return out, err
+}
+
+// GetRecoveryPlan calls GET /api/v1/recovery. Catch-up plan for the current backlog: a temporary landing rate boost and longest-delayed flights first.
+func (c *Client) GetRecoveryPlan(ctx context.Context) (RecoveryPlan, error) {
+	var out RecoveryPlan
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/recovery", query, nil, &out)
+	return out, err
+}
+
+// ApplyRecoveryPlan calls POST /api/v1/recovery. Put a fresh recovery plan into effect.
+func (c *Client) ApplyRecoveryPlan(ctx context.Context) (RecoveryPlan, error) {
+	var out RecoveryPlan
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/recovery", query, nil, &out)
+	return out, err
+}
+
+// ListDailyReports calls GET /api/v1/reports/daily. Completed end-of-day summary reports, oldest first.
+func (c *Client) ListDailyReports(ctx context.Context) ([]DailyReport, error) {
+	var out []DailyReport
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/reports/daily", query, nil, &out)
+	return out, err
+}
+
+// GetDailyReportParams holds the optional parameters of GetDailyReport.
+type GetDailyReportParams struct {
+	// "json" (the default) or "html".
+	Format string
+}
+
+// GetDailyReport calls GET /api/v1/reports/daily/{date}. The end-of-day report for a date, or the day so far for "today".
+func (c *Client) GetDailyReport(ctx context.Context, date string, params GetDailyReportParams) (DailyReport, error) {
+	var out DailyReport
+	query := url.Values{}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	err := c.call(ctx, "GET", "/api/v1/reports/daily/"+url.PathEscape(date), query, nil, &out)
+	return out, err
+}
+
+// ListRules calls GET /api/v1/rules. Configured automation rules.
+func (c *Client) ListRules(ctx context.Context) ([]Rule, error) {
+	var out []Rule
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/rules", query, nil, &out)
+	return out, err
+}
+
+// CreateRule calls POST /api/v1/rules. Add or replace a rule.
+func (c *Client) CreateRule(ctx context.Context, body Rule) (Rule, error) {
+	var out Rule
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/rules", query, body, &out)
+	return out, err
+}
+
+// ListRuleFirings calls GET /api/v1/rules/firings. Recent rule executions.
+func (c *Client) ListRuleFirings(ctx context.Context) ([]RuleFiring, error) {
+	var out []RuleFiring
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/rules/firings", query, nil, &out)
+	return out, err
+}
+
+// DeleteRule calls DELETE /api/v1/rules/{id}. Remove a rule.
+func (c *Client) DeleteRule(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/rules/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ListRunways calls GET /api/v1/runways. Runway definitions in assignment order.
+func (c *Client) ListRunways(ctx context.Context) ([]RunwayDefinition, error) {
+	var out []RunwayDefinition
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/runways", query, nil, &out)
+	return out, err
+}
+
+// AddRunway calls POST /api/v1/runways. Commission a runway without restarting.
+func (c *Client) AddRunway(ctx context.Context, body RunwayDefinition) (RunwayDefinition, error) {
+	var out RunwayDefinition
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/runways", query, body, &out)
+	return out, err
+}
+
+// RemoveRunwayParams holds the optional parameters of RemoveRunway.
+type RemoveRunwayParams struct {
+	// Validate and return the predicted effect as a Preview without applying the change.
+	DryRun bool
+}
+
+// RemoveRunway calls DELETE /api/v1/runways/{runway}. Decommission a runway, sending its arrivals to holding.
+func (c *Client) RemoveRunway(ctx context.Context, runway string, params RemoveRunwayParams) error {
+	query := url.Values{}
+	if params.DryRun {
+		query.Set("dryRun", strconv.FormatBool(params.DryRun))
+	}
+	return c.call(ctx, "DELETE", "/api/v1/runways/"+url.PathEscape(runway), query, nil, nil)
+}
+
+// GetRunway calls GET /api/v1/runways/{runway}. A runway's state, queue and statistics: landings by direction, takeoffs, average occupancy, idle time and utilization.
+func (c *Client) GetRunway(ctx context.Context, runway string) (RunwaySnapshot, error) {
+	var out RunwaySnapshot
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/runways/"+url.PathEscape(runway), query, nil, &out)
+	return out, err
+}
+
+// ListScenarios calls GET /api/v1/scenarios. The built-in scenarios.
+func (c *Client) ListScenarios(ctx context.Context) ([]Scenario, error) {
+	var out []Scenario
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/scenarios", query, nil, &out)
+	return out, err
+}
+
+// GetScenario calls GET /api/v1/scenarios/{name}. A built-in scenario with its steps and expected outcome.
+func (c *Client) GetScenario(ctx context.Context, name string) (Scenario, error) {
+	var out Scenario
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/scenarios/"+url.PathEscape(name), query, nil, &out)
+	return out, err
+}
+
+// StartScenario calls POST /api/v1/scenarios/{name}/start. Start a built-in scenario with optional parameter values, cancelling the rest of any running one.
+func (c *Client) StartScenario(ctx context.Context, name string, body ScenarioStartRequest) (ScenarioRun, error) {
+	var out ScenarioRun
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/scenarios/"+url.PathEscape(name)+"/start", query, body, &out)
+	return out, err
+}
+
+// GetMessageSchema calls GET /api/v1/schema. AsyncAPI description of the websocket messages.
+func (c *Client) GetMessageSchema(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/schema", query, nil, &out)
+	return out, err
+}
+
+// ListShortenings calls GET /api/v1/shortening. Runways temporarily shortened.
+func (c *Client) ListShortenings(ctx context.Context) ([]RunwayShortening, error) {
+	var out []RunwayShortening
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/shortening", query, nil, &out)
+	return out, err
+}
+
+// RestoreRunway calls DELETE /api/v1/shortening/{runway}. Restore a shortened runway to full length.
+func (c *Client) RestoreRunway(ctx context.Context, runway string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/shortening/"+url.PathEscape(runway), query, nil, nil)
+}
+
+// ShortenRunway calls PUT /api/v1/shortening/{runway}. Close part of a runway at one end.
+func (c *Client) ShortenRunway(ctx context.Context, runway string, body RunwayShortening) (RunwayShortening, error) {
+	var out RunwayShortening
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/shortening/"+url.PathEscape(runway), query, body, &out)
+	return out, err
+}
+
+// ListSLAs calls GET /api/v1/slas. Service level agreements and their attainment.
+func (c *Client) ListSLAs(ctx context.Context) ([]SLAStatus, error) {
+	var out []SLAStatus
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/slas", query, nil, &out)
+	return out, err
+}
+
+// CreateSLA calls POST /api/v1/slas. Add or replace a service level agreement.
+func (c *Client) CreateSLA(ctx context.Context, body SLA) (SLA, error) {
+	var out SLA
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/slas", query, body, &out)
+	return out, err
+}
+
+// DeleteSLA calls DELETE /api/v1/slas/{id}. Remove a service level agreement.
+func (c *Client) DeleteSLA(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/slas/"+url.PathEscape(id), query, nil, nil)
+}
+
+// GetState calls GET /api/v1/state. Consistent snapshot of flights, queues, holding, wind, runways and metrics.
+func (c *Client) GetState(ctx context.Context) (StateSnapshot, error) {
+	var out StateSnapshot
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/state", query, nil, &out)
+	return out, err
+}
+
+// GetStorms calls GET /api/v1/storms. Thunderstorm cells at their current positions and the approaches they affect.
+func (c *Client) GetStorms(ctx context.Context) (StormState, error) {
+	var out StormState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/storms", query, nil, &out)
+	return out, err
+}
+
+// AddStormCell calls POST /api/v1/storms. Place a moving thunderstorm cell.
+func (c *Client) AddStormCell(ctx context.Context, body StormCell) (StormCell, error) {
+	var out StormCell
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/storms", query, body, &out)
+	return out, err
+}
+
+// RemoveStormCell calls DELETE /api/v1/storms/{id}. Remove a thunderstorm cell.
+func (c *Client) RemoveStormCell(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/storms/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ListTenantTemplates calls GET /api/v1/tenant-templates. Exercise environments simulation instances can be created from.
+func (c *Client) ListTenantTemplates(ctx context.Context) ([]TenantTemplateView, error) {
+	var out []TenantTemplateView
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/tenant-templates", query, nil, &out)
+	return out, err
+}
+
+// SaveTenantTemplate calls POST /api/v1/tenant-templates. Add or replace an exercise environment: airport, scenario, strategy and access list.
+func (c *Client) SaveTenantTemplate(ctx context.Context, body TenantTemplate) (TenantTemplateView, error) {
+	var out TenantTemplateView
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/tenant-templates", query, body, &out)
+	return out, err
+}
+
+// DeleteTenantTemplate calls DELETE /api/v1/tenant-templates/{name}. Delete an exercise environment; instances created from it keep running.
+func (c *Client) DeleteTenantTemplate(ctx context.Context, name string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/tenant-templates/"+url.PathEscape(name), query, nil, nil)
+}
+
+// GetTenantTemplate calls GET /api/v1/tenant-templates/{name}. An exercise environment.
+func (c *Client) GetTenantTemplate(ctx context.Context, name string) (TenantTemplateView, error) {
+	var out TenantTemplateView
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/tenant-templates/"+url.PathEscape(name), query, nil, &out)
+	return out, err
+}
+
+// ListTenants calls GET /api/v1/tenants. Simulation instances running alongside the primary one.
+func (c *Client) ListTenants(ctx context.Context) ([]TenantInfo, error) {
+	var out []TenantInfo
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/tenants", query, nil, &out)
+	return out, err
+}
+
+// CreateTenant calls POST /api/v1/tenants. Start an independent simulation instance served under /tenants/{id}/.
+func (c *Client) CreateTenant(ctx context.Context, body TenantConfig) (TenantInfo, error) {
+	var out TenantInfo
+	query := url.Values{}
+	err := c.call(ctx, "POST", "/api/v1/tenants", query, body, &out)
+	return out, err
+}
+
+// DeleteTenant calls DELETE /api/v1/tenants/{id}. Stop and remove a simulation instance.
+func (c *Client) DeleteTenant(ctx context.Context, id string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/tenants/"+url.PathEscape(id), query, nil, nil)
+}
+
+// GetTenant calls GET /api/v1/tenants/{id}. A simulation instance.
+func (c *Client) GetTenant(ctx context.Context, id string) (TenantInfo, error) {
+	var out TenantInfo
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/tenants/"+url.PathEscape(id), query, nil, &out)
+	return out, err
+}
+
+// GetClock calls GET /api/v1/time. The simulation clock: current sim time, speed factor and epoch.
+func (c *Client) GetClock(ctx context.Context) (ClockInfo, error) {
+	var out ClockInfo
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/time", query, nil, &out)
+	return out, err
+}
+
+// GetFlightTimers calls GET /api/v1/timers. Each flight's countdown to touchdown, time in holding and expected approach time.
+func (c *Client) GetFlightTimers(ctx context.Context) (FlightTimers, error) {
+	var out FlightTimers
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/timers", query, nil, &out)
+	return out, err
+}
+
+// GetTimings calls GET /api/v1/timing. Arrival spacing and landing time of each runway.
+func (c *Client) GetTimings(ctx context.Context) ([]RunwayTiming, error) {
+	var out []RunwayTiming
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/timing", query, nil, &out)
+	return out, err
+}
+
+// ResetTiming calls DELETE /api/v1/timing/{runway}. Return a runway to the default arrival timing.
+func (c *Client) ResetTiming(ctx context.Context, runway string) error {
+	query := url.Values{}
+	return c.call(ctx, "DELETE", "/api/v1/timing/"+url.PathEscape(runway), query, nil, nil)
+}
+
+// SetTiming calls PUT /api/v1/timing/{runway}. Set a runway's arrival spacing and landing time, overall and per weight category.
+func (c *Client) SetTiming(ctx context.Context, runway string, body RunwayTiming) ([]RunwayTiming, error) {
+	var out []RunwayTiming
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/timing/"+url.PathEscape(runway), query, body, &out)
+	return out, err
+}
+
+// GetTranscriptParams holds the optional parameters of GetTranscript.
+type GetTranscriptParams struct {
+	// Only instructions to this flight.
+	Flight int64
+	// json (the default) or text, one line per instruction.
+	Format string
+}
+
+// GetTranscript calls GET /api/v1/transcript. Every instruction issued, in controller phraseology, in the order spoken.
+func (c *Client) GetTranscript(ctx context.Context, params GetTranscriptParams) ([]Instruction, error) {
+	var out []Instruction
+	query := url.Values{}
+	if params.Flight != 0 {
+		query.Set("flight", strconv.FormatInt(params.Flight, 10))
+	}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	err := c.call(ctx, "GET", "/api/v1/transcript", query, nil, &out)
+	return out, err
+}
+
+// ListTransitions calls GET /api/v1/transitions. Runway direction changes in progress.
+func (c *Client) ListTransitions(ctx context.Context) ([]RunwayTransition, error) {
+	var out []RunwayTransition
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/transitions", query, nil, &out)
+	return out, err
+}
+
+// GetTurnarounds calls GET /api/v1/turnarounds. Aircraft being turned around, earliest scheduled off-block first.
+func (c *Client) GetTurnarounds(ctx context.Context) ([]Turnaround, error) {
+	var out []Turnaround
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/turnarounds", query, nil, &out)
+	return out, err
+}
+
+// GetVisibility calls GET /api/v1/visibility. Prevailing visibility and ceiling.
+func (c *Client) GetVisibility(ctx context.Context) (VisibilityState, error) {
+	var out VisibilityState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/visibility", query, nil, &out)
+	return out, err
+}
+
+// SetVisibilityParams holds the optional parameters of SetVisibility.
+type SetVisibilityParams struct {
+	// Validate and return the predicted effect as a Preview without applying the change.
+	DryRun bool
+}
+
+// SetVisibility calls PUT /api/v1/visibility. Set the prevailing visibility and ceiling.
+func (c *Client) SetVisibility(ctx context.Context, body VisibilityState, params SetVisibilityParams) (VisibilityState, error) {
+	var out VisibilityState
+	query := url.Values{}
+	if params.DryRun {
+		query.Set("dryRun", strconv.FormatBool(params.DryRun))
+	}
+	err := c.call(ctx, "PUT", "/api/v1/visibility", query, body, &out)
+	return out, err
+}
+
+// GetWildlifeHazard calls GET /api/v1/wildlife. Wildlife hazard level now, by hour, and recent dispersal closures.
+func (c *Client) GetWildlifeHazard(ctx context.Context) (WildlifeState, error) {
+	var out WildlifeState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/wildlife", query, nil, &out)
+	return out, err
+}
+
+// SetWildlifeHazard calls PUT /api/v1/wildlife. Set the wildlife hazard level by hour of day or override it.
+func (c *Client) SetWildlifeHazard(ctx context.Context, body WildlifeHazard) (WildlifeState, error) {
+	var out WildlifeState
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/wildlife", query, body, &out)
+	return out, err
+}
+
+// GetWinterOps calls GET /api/v1/winter. Winter operations mode and de-icing pad usage.
+func (c *Client) GetWinterOps(ctx context.Context) (WinterOpsState, error) {
+	var out WinterOpsState
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/api/v1/winter", query, nil, &out)
+	return out, err
+}
+
+// SetWinterOps calls PUT /api/v1/winter. Configure winter operations and precipitation.
+func (c *Client) SetWinterOps(ctx context.Context, body WinterOps) (WinterOpsState, error) {
+	var out WinterOpsState
+	query := url.Values{}
+	err := c.call(ctx, "PUT", "/api/v1/winter", query, body, &out)
+	return out, err
+}
+
+// GetMetrics calls GET /metrics. Current scheduler metrics.
+func (c *Client) GetMetrics(ctx context.Context) (MetricsSnapshot, error) {
+	var out MetricsSnapshot
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/metrics", query, nil, &out)
+	return out, err
+}
+
+// SetRateParams holds the optional parameters of SetRate.
+type SetRateParams struct {
+	// Seconds over which to ramp to the new rate.
+	Ramp int64
+	// Validate and return the predicted effect as a Preview without applying the change.
+	DryRun bool
+}
+
+// SetRate calls POST /rate. Set the arrival rate in planes per minute.
+func (c *Client) SetRate(ctx context.Context, rate int64, params SetRateParams) error {
+	query := url.Values{}
+	query.Set("rate", strconv.FormatInt(rate, 10))
+	if params.Ramp != 0 {
+		query.Set("ramp", strconv.FormatInt(params.Ramp, 10))
+	}
+	if params.DryRun {
+		query.Set("dryRun", strconv.FormatBool(params.DryRun))
+	}
+	return c.call(ctx, "POST", "/rate", query, nil, nil)
+}
+
+// ListRecordings calls GET /recordings. Recorded sessions available for playback.
+func (c *Client) ListRecordings(ctx context.Context) ([]string, error) {
+	var out []string
+	query := url.Values{}
+	err := c.call(ctx, "GET", "/recordings", query, nil, &out)
+	return out, err
+}